@@ -20,10 +20,10 @@ const (
 // without a standard Go state directory API.
 func StateDir() (string, error) {
 	if override := strings.TrimSpace(os.Getenv(EnvStateDir)); override != "" {
-		return filepath.Clean(expandHome(override)), nil
+		return filepath.Clean(ExpandHome(override)), nil
 	}
 	if base := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); base != "" {
-		return filepath.Join(expandHome(base), AppName), nil
+		return filepath.Join(ExpandHome(base), AppName), nil
 	}
 	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
 		home, err := os.UserHomeDir()
@@ -86,7 +86,9 @@ func legacyPath(base string, parts ...string) string {
 	return filepath.Join(all...)
 }
 
-func expandHome(path string) string {
+// ExpandHome replaces a leading "~" or "~/" with the user's home dir. Other
+// tildes, such as "~user" or "a/~/b", are left alone.
+func ExpandHome(path string) string {
 	path = strings.TrimSpace(path)
 	if path == "~" {
 		if home, err := os.UserHomeDir(); err == nil && strings.TrimSpace(home) != "" {
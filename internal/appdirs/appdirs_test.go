@@ -67,6 +67,29 @@ func TestStateDirExpandsHomeAndPreservesSpacesCI(t *testing.T) {
 	}
 }
 
+func TestExpandHomeOnlyExpandsLeadingTilde(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "home")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "~", want: home},
+		{in: " ~/sub/dir ", want: filepath.Join(home, "sub", "dir")},
+		{in: "/abs/path", want: "/abs/path"},
+		{in: "rel/~/path", want: "rel/~/path"},
+		{in: "~other/path", want: "~other/path"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := ExpandHome(tt.in); got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestStateDirUsesLocalStateDefaultOnUnix(t *testing.T) {
 	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
 		t.Skip("platform default is intentionally platform-specific")
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+const aaaForceUsage = "Launch in AAA mode even when the git repo has uncommitted changes (see config aaa-guard)"
+
+var (
+	gitUncommittedChangesFn = gitUncommittedChanges
+	// aaaGuardConfirm asks whether to launch anyway. ok is false when there
+	// is no terminal to ask on.
+	aaaGuardConfirm = func(question string) (yes bool, ok bool) {
+		if !isTerminalFile(os.Stdin) || !isTerminalFile(os.Stderr) {
+			return false, false
+		}
+		yes, err := promptSkillYesNo(os.Stdin, os.Stderr, question, false)
+		return yes && err == nil, true
+	}
+)
+
+type aaaGuardForceKey struct{}
+
+// withAAAGuardFromFlags skips the clean-tree guard for the launches made
+// with ctx when cmd has --force set.
+func withAAAGuardFromFlags(ctx context.Context, cmd *cobra.Command) context.Context {
+	if flag := cmd.Flags().Lookup("force"); flag != nil && flag.Value.String() == "true" {
+		return context.WithValue(ctx, aaaGuardForceKey{}, true)
+	}
+	return ctx
+}
+
+// checkAAACleanTree enforces the opt-in aaa-guard: an auto-approved launch
+// from a git repo with uncommitted changes needs a yes on the terminal, or
+// --force when there is none. Directories outside a repo, or without git
+// installed, pass.
+func checkAAACleanTree(ctx context.Context, store *config.Store, cwd string, agentAutoApprove bool) error {
+	if !agentAutoApprove || store == nil {
+		return nil
+	}
+	if forced, _ := ctx.Value(aaaGuardForceKey{}).(bool); forced {
+		return nil
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if !resolveAAARequireClean(cfg) {
+		return nil
+	}
+	changes, err := gitUncommittedChangesFn(ctx, cwd)
+	if err != nil || len(changes) == 0 {
+		return nil
+	}
+	question := fmt.Sprintf("%s has %d uncommitted change(s) and AAA mode lets Codex edit files without asking. Launch anyway?", cwd, len(changes))
+	yes, asked := aaaGuardConfirm(question)
+	switch {
+	case yes:
+		return nil
+	case asked:
+		return fmt.Errorf("launch cancelled: %s has uncommitted changes", cwd)
+	default:
+		return fmt.Errorf("refusing to launch in AAA mode: %s has uncommitted changes; commit or stash them, or pass --force", cwd)
+	}
+}
+
+// gitUncommittedChanges lists the `git status --porcelain` entries of the
+// repo dir is in, untracked files included. It fails when dir is not in a
+// repo or git cannot be run.
+func gitUncommittedChanges(ctx context.Context, dir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git status: %s", msg)
+		}
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	var changes []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			changes = append(changes, line)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+func TestGitUncommittedChanges(t *testing.T) {
+	requireCLIGit(t)
+	if _, err := gitUncommittedChanges(context.Background(), t.TempDir()); err == nil {
+		t.Fatal("expected an error outside a git repo")
+	}
+
+	repo := t.TempDir()
+	cliGitRun(t, repo, "init", "-q")
+	changes, err := gitUncommittedChanges(context.Background(), repo)
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("clean repo: changes=%q err=%v", changes, err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("draft\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = gitUncommittedChanges(context.Background(), repo)
+	if err != nil || len(changes) != 1 || !strings.HasSuffix(changes[0], "notes.txt") {
+		t.Fatalf("dirty repo: changes=%q err=%v", changes, err)
+	}
+}
+
+func TestCheckAAACleanTree(t *testing.T) {
+	lockCLITestHooks(t)
+	prevChanges := gitUncommittedChangesFn
+	prevConfirm := aaaGuardConfirm
+	t.Cleanup(func() {
+		gitUncommittedChangesFn = prevChanges
+		aaaGuardConfirm = prevConfirm
+	})
+	gitUncommittedChangesFn = func(context.Context, string) ([]string, error) {
+		return []string{" M main.go"}, nil
+	}
+	var asked []string
+	answer, canAsk := false, false
+	aaaGuardConfirm = func(question string) (bool, bool) {
+		asked = append(asked, question)
+		return answer, canAsk
+	}
+
+	store, err := config.NewStore(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := checkAAACleanTree(ctx, store, "/work", true); err != nil || len(asked) != 0 {
+		t.Fatalf("guard is opt-in: err=%v asked=%q", err, asked)
+	}
+
+	enabled := true
+	if err := store.Update(func(cfg *config.Config) error {
+		cfg.AgentAutoApproveRequireClean = &enabled
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkAAACleanTree(ctx, store, "/work", false); err != nil || len(asked) != 0 {
+		t.Fatalf("launches without AAA are not checked: err=%v asked=%q", err, asked)
+	}
+
+	err = checkAAACleanTree(ctx, store, "/work", true)
+	if err == nil || !strings.Contains(err.Error(), "pass --force") {
+		t.Fatalf("expected refusal without a terminal, got %v", err)
+	}
+
+	canAsk = true
+	err = checkAAACleanTree(ctx, store, "/work", true)
+	if err == nil || !strings.Contains(err.Error(), "launch cancelled") {
+		t.Fatalf("expected a declined prompt to cancel, got %v", err)
+	}
+	if len(asked) != 2 || !strings.Contains(asked[1], "/work has 1 uncommitted change(s)") {
+		t.Fatalf("questions = %q", asked)
+	}
+
+	answer = true
+	if err := checkAAACleanTree(ctx, store, "/work", true); err != nil {
+		t.Fatalf("confirmed launch: %v", err)
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("force", false, aaaForceUsage)
+	_ = cmd.Flags().Set("force", "true")
+	answer, canAsk = false, false
+	asked = nil
+	if err := checkAAACleanTree(withAAAGuardFromFlags(ctx, cmd), store, "/work", true); err != nil || len(asked) != 0 {
+		t.Fatalf("--force should skip the guard: err=%v asked=%q", err, asked)
+	}
+
+	gitUncommittedChangesFn = func(context.Context, string) ([]string, error) { return nil, nil }
+	if err := checkAAACleanTree(ctx, store, "/work", true); err != nil {
+		t.Fatalf("clean tree: %v", err)
+	}
+}
+
+func TestConfigAAAGuardCmd(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := newConfigAAAGuardCmd(&rootOptions{configPath: cfgPath})
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run(); err != nil || out != "off\n" {
+		t.Fatalf("default = %q, %v", out, err)
+	}
+	if _, err := run("on"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run(); err != nil || out != "on\n" {
+		t.Fatalf("after on = %q, %v", out, err)
+	}
+	if _, err := run("off"); err != nil {
+		t.Fatal(err)
+	}
+	store, err := config.NewStore(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AgentAutoApproveRequireClean == nil || *cfg.AgentAutoApproveRequireClean {
+		t.Fatalf("after off = %v", cfg.AgentAutoApproveRequireClean)
+	}
+	if _, err := run("maybe"); err == nil || !strings.Contains(err.Error(), "expected on or off") {
+		t.Fatalf("expected a bad-argument error, got %v", err)
+	}
+}
@@ -12,3 +12,7 @@ func persistAAAEnabled(store *config.Store, enabled bool) error {
 		return nil
 	})
 }
+
+func resolveAAARequireClean(cfg config.Config) bool {
+	return cfg.AgentAutoApproveRequireClean != nil && *cfg.AgentAutoApproveRequireClean
+}
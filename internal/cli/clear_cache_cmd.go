@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+	"github.com/baaaaaaaka/codex-helper/internal/migration"
+)
+
+func newClearCacheCmd(root *rootOptions) *cobra.Command {
+	var codexDir string
+	var requirements bool
+	var sessions bool
+	var codexPath bool
+	cmd := &cobra.Command{
+		Use:   "clear-cache",
+		Short: "Delete cached Codex requirements, session metadata or the cached Codex path",
+		Long: "Delete the caches picked with the flags and print each file removed. Caches that do\n" +
+			"not exist are skipped. --requirements removes Codex's cloud-requirements-cache.json\n" +
+			"from the Codex dir, e.g. after a failed run left it stale; --sessions removes the\n" +
+			"session metadata, history index and preview caches, so the next listing rereads\n" +
+			"every rollout file; --codex-path forgets the Codex binary found last time.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !requirements && !sessions && !codexPath {
+				return fmt.Errorf("nothing to clear; pass --requirements, --sessions or --codex-path")
+			}
+			paths, err := resolveEffectivePaths(root.configPath, codexDir, "")
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			var removed []string
+			if requirements {
+				path, ok, err := migration.RemoveCloudRequirementsCache(paths.CodexDir)
+				if err != nil {
+					return fmt.Errorf("remove %s: %w", path, err)
+				}
+				if ok {
+					removed = append(removed, path)
+				}
+			}
+			if sessions {
+				files, err := codexhistory.ClearPersistentCache(cmd.Context(), paths.CodexDir)
+				removed = append(removed, files...)
+				if err != nil {
+					printRemoved(cmd, removed)
+					return fmt.Errorf("clear session caches: %w", err)
+				}
+			}
+			if codexPath {
+				if path := cachedCodexPathFile(); path != "" {
+					if err := os.Remove(path); err == nil {
+						removed = append(removed, path)
+					} else if !errors.Is(err, os.ErrNotExist) {
+						printRemoved(cmd, removed)
+						return fmt.Errorf("remove %s: %w", path, err)
+					}
+				}
+			}
+			if len(removed) == 0 {
+				_, _ = fmt.Fprintln(out, "Nothing to clear")
+				return nil
+			}
+			printRemoved(cmd, removed)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&codexDir, "codex-dir", "", "Override Codex data dir (default: ~/.codex)")
+	cmd.Flags().BoolVar(&requirements, "requirements", false, "Remove the cloud requirements cache in the Codex dir")
+	cmd.Flags().BoolVar(&sessions, "sessions", false, "Remove the session metadata, history index and preview caches")
+	cmd.Flags().BoolVar(&codexPath, "codex-path", false, "Remove the cached path of the Codex binary")
+	return cmd
+}
+
+func printRemoved(cmd *cobra.Command, paths []string) {
+	for _, path := range paths {
+		logLaunchEvent(launchEvent{Event: "cache_deleted", Path: path})
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", path)
+	}
+}
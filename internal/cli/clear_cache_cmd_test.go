@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClearCacheCmdRemovesRequirementsAndCodexPath(t *testing.T) {
+	lockCLITestHooks(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LOCALAPPDATA", t.TempDir())
+	codexDir := t.TempDir()
+	requirements := filepath.Join(codexDir, "cloud-requirements-cache.json")
+	if err := os.WriteFile(requirements, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeCachedCodexPath(filepath.Join(t.TempDir(), "codex"))
+	cachedPath := cachedCodexPathFile()
+	if _, err := os.Stat(cachedPath); err != nil {
+		t.Fatalf("codex path cache not written: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := newClearCacheCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")})
+		cmd.SetContext(context.Background())
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append([]string{"--codex-dir", codexDir}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--requirements", "--codex-path")
+	if err != nil {
+		t.Fatalf("clear-cache: %v", err)
+	}
+	if want := "Removed " + requirements + "\nRemoved " + cachedPath + "\n"; out != want {
+		t.Fatalf("output = %q, want %q", out, want)
+	}
+	for _, path := range []string{requirements, cachedPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s still present: %v", path, err)
+		}
+	}
+
+	if out, err := run("--requirements", "--codex-path"); err != nil || out != "Nothing to clear\n" {
+		t.Fatalf("second run = %q, %v", out, err)
+	}
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "nothing to clear") {
+		t.Fatalf("expected an error without flags, got %v", err)
+	}
+}
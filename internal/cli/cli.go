@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
 )
 
 var (
@@ -15,7 +20,18 @@ var (
 
 type rootOptions struct {
 	configPath   string
+	resetConfig  bool
 	upgradeCodex bool
+	logJSON      string
+	logJSONFile  string
+	// codexProbeTimeout is the --codex-probe-timeout value; it wins over
+	// CODEX_HELPER_PROBE_TIMEOUT.
+	codexProbeTimeout time.Duration
+	// codexInstallTimeout is the --install-timeout value; it wins over
+	// CODEX_HELPER_INSTALL_TIMEOUT.
+	codexInstallTimeout time.Duration
+	// closeLaunchLog stops the --log-json log after the command ran.
+	closeLaunchLog func() error
 }
 
 func Execute() int {
@@ -23,22 +39,63 @@ func Execute() int {
 		_, _ = fmt.Fprintf(os.Stderr, "Error: helper update compatibility check failed: %v\n", err)
 		return 1
 	}
-	cmd := newRootCmd()
-	if err := cmd.Execute(); err != nil {
+	opts := &rootOptions{}
+	cmd := newRootCmdWithOptions(opts)
+	if err := executeRootCmd(cmd, opts); err != nil {
+		var fileErr *config.FileError
+		if errors.As(err, &fileErr) {
+			_, _ = fmt.Fprintf(os.Stderr, "Hint: fix %s, or rerun with --reset-config to move it aside and start fresh\n", fileErr.Path)
+		}
 		return 1
 	}
 	return 0
 }
 
+// executeRootCmd runs cmd and then closes the --log-json log, which a
+// post-run hook would leave open when the command fails.
+func executeRootCmd(cmd *cobra.Command, opts *rootOptions) error {
+	err := cmd.Execute()
+	if opts.closeLaunchLog != nil {
+		if closeErr := opts.closeLaunchLog(); err == nil {
+			err = closeErr
+		}
+		opts.closeLaunchLog = nil
+	}
+	return err
+}
+
 func newRootCmd() *cobra.Command {
-	opts := &rootOptions{}
+	return newRootCmdWithOptions(&rootOptions{})
+}
 
+func newRootCmdWithOptions(opts *rootOptions) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "cxp [profile]",
 		Short:         "Browse Codex history in a terminal UI",
 		SilenceErrors: false,
 		SilenceUsage:  true,
 		Version:       buildVersion(),
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			cmd.SetContext(withCodexTimeoutFlags(cmd.Context(), opts))
+			logTarget := opts.logJSON
+			if opts.logJSONFile != "" {
+				if logTarget != "" {
+					return fmt.Errorf("--log-json and --log-json-file cannot be combined")
+				}
+				logTarget = opts.logJSONFile
+			}
+			if logTarget != "" {
+				closeLog, err := openLaunchLog(logTarget, cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+				opts.closeLaunchLog = closeLog
+			}
+			if !opts.resetConfig {
+				return nil
+			}
+			return resetConfigFile(cmd.ErrOrStderr(), opts.configPath)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_ = args
 			if opts.upgradeCodex {
@@ -49,6 +106,12 @@ func newRootCmd() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVar(&opts.configPath, "config", "", "Override config file path (default: OS user config dir)")
+	cmd.PersistentFlags().BoolVar(&opts.resetConfig, "reset-config", false, "Move the config file aside and start from defaults")
+	cmd.PersistentFlags().DurationVar(&opts.codexProbeTimeout, "codex-probe-timeout", 0, "How long codex --version may take before Codex is treated as not functional (default 5s, env "+codexProbeTimeoutEnv+")")
+	cmd.PersistentFlags().DurationVar(&opts.codexInstallTimeout, "install-timeout", 0, "How long installing or upgrading Codex may take as a whole before it is abandoned (default 15m, env "+codexInstallTimeoutEnv+")")
+	cmd.PersistentFlags().StringVar(&opts.logJSON, "log-json", "", "Write launch events (Codex resolved, migration, cache deleted, session launched/exited) as JSON lines to stderr, or to a file given as --log-json=<file>")
+	cmd.PersistentFlags().Lookup("log-json").NoOptDefVal = launchLogStderr
+	cmd.PersistentFlags().StringVar(&opts.logJSONFile, "log-json-file", "", "Like --log-json, appending the events to this file")
 	cmd.Flags().BoolVar(&opts.upgradeCodex, "upgrade-codex", false, "Reinstall Codex CLI using its detected install source")
 
 	cmd.AddCommand(
@@ -66,13 +129,39 @@ func newRootCmd() *cobra.Command {
 		newResponsesCmd(opts),
 		newSkillsCmd(opts),
 		newUpgradeCmd(opts),
+		newInstallCmd(opts),
+		newClearCacheCmd(opts),
 		newHistoryCmd(opts),
+		newDoctorCmd(opts),
+		newConfigCmd(opts),
 		newSelftestCmd(opts),
+		newVersionCmd(),
 	)
 
 	return cmd
 }
 
+// resetConfigFile moves the config file aside with a timestamped name so a
+// broken file does not block every command.
+func resetConfigFile(out io.Writer, configPath string) error {
+	paths, err := resolveEffectivePaths(configPath, "", "")
+	if err != nil {
+		return err
+	}
+	store, err := config.NewStore(paths.ConfigPath)
+	if err != nil {
+		return err
+	}
+	backup, err := store.Reset(time.Now())
+	if err != nil {
+		return err
+	}
+	if backup != "" {
+		_, _ = fmt.Fprintf(out, "Moved config aside to %s\n", backup)
+	}
+	return nil
+}
+
 // RuntimeVersion is the build identity used by the immutable cxp runtime
 // launcher before Cobra or any user configuration is initialized.
 func RuntimeVersion() string {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/baaaaaaaka/codex-helper/internal/appdirs"
 	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
 )
 
@@ -22,7 +23,7 @@ func resolveCodexHome(override string, workingDir string) (string, error) {
 }
 
 func resolveCodexHomePath(raw string, workingDir string) (string, error) {
-	path := filepath.Clean(os.ExpandEnv(strings.TrimSpace(raw)))
+	path := filepath.Clean(filepath.FromSlash(appdirs.ExpandHome(os.ExpandEnv(strings.TrimSpace(raw)))))
 	if filepath.IsAbs(path) {
 		return path, nil
 	}
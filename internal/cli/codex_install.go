@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -18,12 +19,19 @@ import (
 const (
 	codexPathCacheFile                         = "codex_path"
 	codexInstallLockName                       = "codex_install.lock"
-	codexProbeTimeout                          = 5 * time.Second
+	defaultCodexProbeTimeout                   = 5 * time.Second
+	codexProbeTimeoutEnv                       = "CODEX_HELPER_PROBE_TIMEOUT"
+	defaultCodexInstallTimeout                 = 15 * time.Minute
+	codexInstallTimeoutEnv                     = "CODEX_HELPER_INSTALL_TIMEOUT"
 	codexInstallProbeTimeout                   = 30 * time.Second
 	codexInstallDiskExit                       = 75
 	codexInstallFailureExit                    = 76
 	nativeWindowsCodexInstallerStartMessage    = "installing codex with native Windows managed installer..."
 	nativeWindowsCodexInstallerFallbackMessage = "native Windows managed installer failed; trying PowerShell fallback:"
+
+	// codexInstallWaitDelay bounds how long the child processes of a killed
+	// installer may keep its output pipes open.
+	codexInstallWaitDelay = 10 * time.Second
 )
 
 var (
@@ -1341,13 +1349,125 @@ if (-not $probeOk) {
 `
 
 // probeCodex runs a quick smoke test to verify the codex binary is functional.
-// Returns true if `codex --version` exits 0 within 5 seconds.
+// Returns true if `codex --version` exits 0 within codexProbeTimeout(ctx).
 func probeCodex(ctx context.Context, codexPath string) bool {
 	return probeCodexVersion(ctx, codexPath) == nil
 }
 
 func probeCodexVersion(ctx context.Context, codexPath string) error {
-	return probeCodexVersionWithTimeout(ctx, codexPath, codexProbeTimeout)
+	return probeCodexVersionWithTimeout(ctx, codexPath, codexProbeTimeout(ctx))
+}
+
+// codexVersionString runs `codex --version` and returns its trimmed output,
+// e.g. "codex-cli 0.46.0".
+func codexVersionString(ctx context.Context, codexPath string) (string, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, codexProbeTimeout(ctx))
+	defer cancel()
+	out, err := exec.CommandContext(probeCtx, codexPath, "--version").CombinedOutput()
+	if err != nil {
+		if output := summarizeProbeOutput(out); output != "" {
+			return "", fmt.Errorf("--version failed: %w: %s", err, output)
+		}
+		return "", fmt.Errorf("--version failed: %w", err)
+	}
+	version := summarizeProbeOutput(out)
+	if version == "" {
+		version = "unknown version"
+	}
+	return version, nil
+}
+
+// codexVersionNumber picks the version token out of `codex --version` output,
+// so "codex-cli 0.46.0" becomes "0.46.0". Unrecognized output is returned as-is.
+func codexVersionNumber(output string) string {
+	fields := strings.Fields(output)
+	for i := len(fields) - 1; i >= 0; i-- {
+		candidate := strings.TrimPrefix(fields[i], "v")
+		if candidate != "" && candidate[0] >= '0' && candidate[0] <= '9' && strings.Contains(candidate, ".") {
+			return candidate
+		}
+	}
+	return strings.TrimSpace(output)
+}
+
+type (
+	codexProbeTimeoutKey   struct{}
+	codexInstallTimeoutKey struct{}
+)
+
+// withCodexTimeoutFlags carries the root --codex-probe-timeout and
+// --install-timeout values in ctx down to the probes and installers, which
+// run deep below the commands.
+func withCodexTimeoutFlags(ctx context.Context, root *rootOptions) context.Context {
+	if root.codexProbeTimeout > 0 {
+		ctx = context.WithValue(ctx, codexProbeTimeoutKey{}, root.codexProbeTimeout)
+	}
+	if root.codexInstallTimeout > 0 {
+		ctx = context.WithValue(ctx, codexInstallTimeoutKey{}, root.codexInstallTimeout)
+	}
+	return ctx
+}
+
+// codexProbeTimeout returns how long `codex --version` may take before the
+// binary is treated as not functional. Slow machines can raise the 5s default
+// with --codex-probe-timeout or CODEX_HELPER_PROBE_TIMEOUT, which accepts a Go
+// duration ("15s") or a number of seconds ("15").
+func codexProbeTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(codexProbeTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	if timeout, ok := parseCodexTimeout(os.Getenv(codexProbeTimeoutEnv)); ok {
+		return timeout
+	}
+	return defaultCodexProbeTimeout
+}
+
+// codexInstallTimeout returns how long installing or upgrading Codex may take
+// as a whole before it is abandoned. It is set like codexProbeTimeout, with
+// --install-timeout or CODEX_HELPER_INSTALL_TIMEOUT, and defaults to a
+// generous 15m because a cold npm install can be slow.
+func codexInstallTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(codexInstallTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	if timeout, ok := parseCodexTimeout(os.Getenv(codexInstallTimeoutEnv)); ok {
+		return timeout
+	}
+	return defaultCodexInstallTimeout
+}
+
+// withCodexInstallTimeout runs an install or upgrade under the
+// codexInstallTimeout deadline, so a stalled download fails with an error,
+// releasing the install lock, instead of hanging forever.
+func withCodexInstallTimeout(ctx context.Context, action string, run func(context.Context) error) error {
+	timeout := codexInstallTimeout(ctx)
+	installCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := run(installCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(installCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("codex %s did not finish within %s (raise with --install-timeout or %s): %w", action, timeout, codexInstallTimeoutEnv, err)
+	}
+	return err
+}
+
+// parseCodexTimeout reads a timeout env var as a Go duration or a number of
+// seconds; anything else, or a value that is not positive, is ignored.
+func parseCodexTimeout(raw string) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil {
+		if seconds <= 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	timeout, err := time.ParseDuration(raw)
+	if err != nil || timeout <= 0 {
+		return 0, false
+	}
+	return timeout, true
 }
 
 func probeCodexVersionWithTimeout(ctx context.Context, codexPath string, timeout time.Duration) error {
@@ -1356,7 +1476,10 @@ func probeCodexVersionWithTimeout(ctx context.Context, codexPath string, timeout
 	cmd := exec.CommandContext(ctx, codexPath, "--version")
 	out, err := cmd.CombinedOutput()
 	if ctx.Err() != nil {
-		return fmt.Errorf("--version timed out after %s", timeout)
+		if output := summarizeProbeOutput(out); output != "" {
+			return fmt.Errorf("--version timed out after %s (raise with --codex-probe-timeout or %s): %s", timeout, codexProbeTimeoutEnv, output)
+		}
+		return fmt.Errorf("--version timed out after %s (raise with --codex-probe-timeout or %s)", timeout, codexProbeTimeoutEnv)
 	}
 	if err != nil {
 		output := summarizeProbeOutput(out)
@@ -1450,7 +1573,14 @@ func codexPathAllowsAutomaticUpgrade(codexPath string) bool {
 	return value == "" || strings.EqualFold(value, "codex") || strings.EqualFold(value, "codex.exe")
 }
 
-func ensureCodexInstalledWithOptions(ctx context.Context, codexPath string, out io.Writer, opts codexInstallOptions) (string, error) {
+func ensureCodexInstalledWithOptions(ctx context.Context, codexPath string, out io.Writer, opts codexInstallOptions) (resolved string, err error) {
+	defer func() {
+		ev := launchEvent{Event: "codex_resolved", Path: resolved, Detail: strings.TrimSpace(codexPath)}
+		if err != nil {
+			ev.Event, ev.Error = "codex_resolve_failed", err.Error()
+		}
+		logLaunchEvent(ev)
+	}()
 	if opts.upgradeCodex {
 		if strings.TrimSpace(codexPath) != "" {
 			return "", fmt.Errorf("--upgrade-codex cannot be used with --codex-path")
@@ -1512,17 +1642,16 @@ func ensureCodexInstalledWithOptions(ctx context.Context, codexPath string, out
 			return nil
 		}
 
-		runInstall := func(installerEnv []string) error {
-			return runCodexInstallerWithOptions(ctx, out, installerEnv, opts.configureInstallerCommand)
-		}
-		if opts.withInstallerEnv != nil {
-			if err := opts.withInstallerEnv(ctx, runInstall); err != nil {
-				return err
+		if err := withCodexInstallTimeout(ctx, "installation", func(ctx context.Context) error {
+			runInstall := func(installerEnv []string) error {
+				return runCodexInstallerWithOptions(ctx, out, installerEnv, opts.configureInstallerCommand)
 			}
-		} else {
-			if err := runInstall(opts.installerEnv); err != nil {
-				return err
+			if opts.withInstallerEnv != nil {
+				return opts.withInstallerEnv(ctx, runInstall)
 			}
+			return runInstall(opts.installerEnv)
+		}); err != nil {
+			return err
 		}
 
 		path, err := findInstalledCodex(ctx)
@@ -1559,17 +1688,16 @@ func upgradeCodexInstalledWithOptions(ctx context.Context, out io.Writer, opts c
 			return err
 		}
 
-		runUpgrade := func(installerEnv []string) error {
-			return runCodexUpgradeBySource(ctx, out, installerEnv, source)
-		}
-		if opts.withInstallerEnv != nil {
-			if err := opts.withInstallerEnv(ctx, runUpgrade); err != nil {
-				return err
+		if err := withCodexInstallTimeout(ctx, "upgrade", func(ctx context.Context) error {
+			runUpgrade := func(installerEnv []string) error {
+				return runCodexUpgradeBySource(ctx, out, installerEnv, source)
 			}
-		} else {
-			if err := runUpgrade(opts.installerEnv); err != nil {
-				return err
+			if opts.withInstallerEnv != nil {
+				return opts.withInstallerEnv(ctx, runUpgrade)
 			}
+			return runUpgrade(opts.installerEnv)
+		}); err != nil {
+			return err
 		}
 
 		path, err := resolveUpgradedCodexPath(ctx, source.codexPath)
@@ -1783,6 +1911,7 @@ func runSystemNpmCodexUpgrade(ctx context.Context, out io.Writer, installerEnv [
 	}
 
 	cmd := exec.CommandContext(ctx, npmPath, "install", "-g", "--include=optional", "@openai/codex")
+	cmd.WaitDelay = codexInstallWaitDelay
 	cmd.Env = codexNPMInstallEnv(installerEnv)
 	cmd.Stdout = out
 	cmd.Stderr = out
@@ -2304,7 +2433,9 @@ func writeCachedCodexPath(path string) {
 	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o755); err != nil {
 		return
 	}
-	_ = os.WriteFile(cacheFile, []byte(path+"\n"), 0o600)
+	// Another instance may be reading or writing the cache at the same time;
+	// a rename never leaves it half written.
+	_ = writeFileAtomically(cacheFile, []byte(path+"\n"), 0o600)
 }
 
 func clearCachedCodexPath() {
@@ -2351,6 +2482,7 @@ func runCodexInstallerWithOptions(ctx context.Context, out io.Writer, installerE
 		}
 
 		cmd := exec.CommandContext(ctx, candidate.path, candidate.args...)
+		cmd.WaitDelay = codexInstallWaitDelay
 		if len(installerEnv) > 0 {
 			cmd.Env = installerEnv
 		}
@@ -2364,6 +2496,9 @@ func runCodexInstallerWithOptions(ctx context.Context, out io.Writer, installerE
 		}
 		if err := cmd.Run(); err != nil {
 			attemptError := fmt.Sprintf("%s: %v", installerAttemptLabel(candidate), err)
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to install codex CLI for %s (%s): %w", runtime.GOOS, attemptError, ctx.Err())
+			}
 			if exitCode, ok := commandExitCode(err); ok {
 				if exitCode == codexInstallDiskExit || exitCode == codexInstallFailureExit {
 					return fmt.Errorf("failed to install codex CLI for %s (%s)", runtime.GOOS, attemptError)
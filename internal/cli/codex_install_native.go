@@ -229,6 +229,7 @@ func nativeWindowsInstallCodexPackage(ctx context.Context, out io.Writer, cfg na
 	env = setEnvValue(env, "NPM_CONFIG_CACHE", cfg.npmCacheDir)
 
 	cmd := exec.CommandContext(ctx, npmCmd, "install", "-g", "--prefix", cfg.npmPrefix, "--include=optional", "@openai/codex")
+	cmd.WaitDelay = codexInstallWaitDelay
 	cmd.Env = env
 	cmd.Stdout = out
 	cmd.Stderr = out
@@ -11,10 +11,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
+
 	"github.com/baaaaaaaka/codex-helper/internal/codexbinary"
 	"github.com/baaaaaaaka/codex-helper/internal/codexrunner"
 )
@@ -346,6 +350,41 @@ func TestEnsureCodexInstalledUsesCachedPath(t *testing.T) {
 	}
 }
 
+func TestWriteCachedCodexPathConcurrentWritersLeaveWholePath(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LOCALAPPDATA", t.TempDir())
+
+	base := t.TempDir()
+	paths := []string{
+		filepath.Join(base, "first", "codex"),
+		filepath.Join(base, "second-with-a-much-longer-directory-name", "codex"),
+	}
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				writeCachedCodexPath(path)
+				if got := readCachedCodexPath(); !slices.Contains(paths, got) {
+					t.Errorf("cached codex path = %q, want one of %q", got, paths)
+					return
+				}
+			}
+		}(path)
+	}
+	wg.Wait()
+
+	if got := readCachedCodexPath(); !slices.Contains(paths, got) {
+		t.Fatalf("cached codex path = %q, want one of %q", got, paths)
+	}
+	leftovers, _ := filepath.Glob(cachedCodexPathFile() + ".tmp-*")
+	if len(leftovers) != 0 {
+		t.Fatalf("temporary cache files left behind: %v", leftovers)
+	}
+}
+
 func TestEnsureCodexInstalledPrefersPathOverCachedPath(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("skip cache precedence test on windows")
@@ -372,6 +411,32 @@ func TestEnsureCodexInstalledPrefersPathOverCachedPath(t *testing.T) {
 	}
 }
 
+func TestInstallCommandPrintsPathAndNoInstallOnlyChecks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip install command test on windows")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	pathDir := t.TempDir()
+	pathCodex := writeProbeableCodex(t, pathDir, true)
+	t.Setenv("PATH", pathDir)
+
+	out, err := runBeaconRootCommand(t, "install")
+	if err != nil || strings.TrimSpace(out) != pathCodex {
+		t.Fatalf("install = %q, %v; want %s", out, err, pathCodex)
+	}
+	if cached := readCachedCodexPath(); cached != pathCodex {
+		t.Fatalf("expected install to cache %q, got %q", pathCodex, cached)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	out, err = runBeaconRootCommand(t, "install", "--no-install")
+	if err == nil || !strings.Contains(err.Error(), "codex is not installed") || strings.Contains(out, "installing") {
+		t.Fatalf("install --no-install without codex = %q, %v", out, err)
+	}
+}
+
 func TestEnsureCodexInstalledDoesNotCacheProvidedPath(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("skip provided-path cache test on windows")
@@ -533,6 +598,62 @@ func TestEnsureCodexInstalledReportsNonfunctionalAfterInstall(t *testing.T) {
 	}
 }
 
+func TestEnsureCodexInstalledTimesOutStalledInstaller(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip shell script test on windows")
+	}
+	lockCLITestHooks(t)
+	ctx := withCodexTimeoutFlags(context.Background(), &rootOptions{codexInstallTimeout: 200 * time.Millisecond})
+
+	binDir := t.TempDir()
+	fallbackMarker := filepath.Join(t.TempDir(), "fallback-ran")
+	writeExecutable(t, filepath.Join(binDir, "bash"), "#!/bin/sh\nexec sleep 30\n")
+	writeExecutable(t, filepath.Join(binDir, "sh"), "#!/bin/sh\necho fallback > \""+fallbackMarker+"\"\nexit 0\n")
+	writeExecutable(t, filepath.Join(binDir, "sleep"), "#!/bin/sh\nexec /bin/sleep \"$@\"\n")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("PATH", binDir)
+
+	start := time.Now()
+	_, err := ensureCodexInstalled(ctx, "", io.Discard)
+	if err == nil {
+		t.Fatal("expected the stalled install to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("install took %s despite a 200ms timeout", elapsed)
+	}
+	if !strings.Contains(err.Error(), "codex installation did not finish within 200ms") || !strings.Contains(err.Error(), codexInstallTimeoutEnv) {
+		t.Fatalf("expected a clear timeout error, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if _, statErr := os.Stat(fallbackMarker); !os.IsNotExist(statErr) {
+		t.Fatalf("fallback installer should not run after the timeout, stat err=%v", statErr)
+	}
+	if lockPath := codexInstallLockPath(); lockPath != "" {
+		if _, statErr := os.Stat(lockPath); !os.IsNotExist(statErr) {
+			t.Fatalf("install lock %s should be released, stat err=%v", lockPath, statErr)
+		}
+	}
+}
+
+func TestCodexInstallTimeoutPrefersFlagThenEnv(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(codexInstallTimeoutEnv, "")
+	if got := codexInstallTimeout(ctx); got != defaultCodexInstallTimeout {
+		t.Fatalf("default timeout = %s, want %s", got, defaultCodexInstallTimeout)
+	}
+	t.Setenv(codexInstallTimeoutEnv, "1h")
+	if got := codexInstallTimeout(ctx); got != time.Hour {
+		t.Fatalf("env timeout = %s, want 1h", got)
+	}
+	ctx = withCodexTimeoutFlags(ctx, &rootOptions{codexInstallTimeout: time.Minute})
+	if got := codexInstallTimeout(ctx); got != time.Minute {
+		t.Fatalf("flag override = %s, want 1m", got)
+	}
+}
+
 func TestRunCodexInstallerStopsAfterDiagnosedInstallFailure(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("skip shell script test on windows")
@@ -2701,6 +2822,69 @@ func TestProbeCodexVersionWithTimeoutReportsConfiguredTimeout(t *testing.T) {
 	if !strings.Contains(err.Error(), "--version timed out after 10ms") {
 		t.Fatalf("expected configured timeout in error, got %v", err)
 	}
+	if !strings.Contains(err.Error(), codexProbeTimeoutEnv) {
+		t.Fatalf("expected timeout error to name %s, got %v", codexProbeTimeoutEnv, err)
+	}
+}
+
+func TestProbeCodexVersionIncludesFailureOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip shell probe test on windows")
+	}
+	codexPath := filepath.Join(t.TempDir(), "codex")
+	script := "#!/bin/sh\necho 'Error: Cannot find module node:sqlite' >&2\nexit 1\n"
+	if err := os.WriteFile(codexPath, []byte(script), 0o700); err != nil {
+		t.Fatalf("write codex: %v", err)
+	}
+	err := probeCodexVersion(context.Background(), codexPath)
+	if err == nil || !strings.Contains(err.Error(), "Cannot find module node:sqlite") {
+		t.Fatalf("expected probe error to include codex output, got %v", err)
+	}
+}
+
+func TestCodexProbeTimeoutPrefersFlagThenEnv(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(codexProbeTimeoutEnv, "")
+	if got := codexProbeTimeout(ctx); got != defaultCodexProbeTimeout {
+		t.Fatalf("default timeout = %s, want %s", got, defaultCodexProbeTimeout)
+	}
+	for raw, want := range map[string]time.Duration{
+		"15":    15 * time.Second,
+		"1m30s": 90 * time.Second,
+		"0":     defaultCodexProbeTimeout,
+		"-3s":   defaultCodexProbeTimeout,
+		"slow":  defaultCodexProbeTimeout,
+	} {
+		t.Setenv(codexProbeTimeoutEnv, raw)
+		if got := codexProbeTimeout(ctx); got != want {
+			t.Fatalf("timeout for %s=%q = %s, want %s", codexProbeTimeoutEnv, raw, got, want)
+		}
+	}
+
+	t.Setenv(codexProbeTimeoutEnv, "15")
+	ctx = withCodexTimeoutFlags(ctx, &rootOptions{codexProbeTimeout: 2 * time.Second})
+	if got := codexProbeTimeout(ctx); got != 2*time.Second {
+		t.Fatalf("flag override = %s, want 2s", got)
+	}
+}
+
+func TestRootCodexTimeoutFlagsReachCommands(t *testing.T) {
+	var probe, install time.Duration
+	cmd := newRootCmd()
+	cmd.AddCommand(&cobra.Command{
+		Use: "timeouts",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			probe, install = codexProbeTimeout(cmd.Context()), codexInstallTimeout(cmd.Context())
+			return nil
+		},
+	})
+	cmd.SetArgs([]string{"--config", filepath.Join(t.TempDir(), "config.json"), "--codex-probe-timeout", "42s", "--install-timeout", "3m", "timeouts"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if probe != 42*time.Second || install != 3*time.Minute {
+		t.Fatalf("timeouts = %s, %s; want the 42s and 3m flag values", probe, install)
+	}
 }
 
 func TestEnsureCodexInstalledSkipsBrokenInPath(t *testing.T) {
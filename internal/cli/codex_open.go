@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -39,6 +41,74 @@ func normalizeWorkingDir(cwd string) (string, error) {
 	return cwd, nil
 }
 
+// resumeDirWarning explains where a resume starts when that may surprise
+// the user: the recorded directory is gone and cwd is the fallback, or cwd
+// is outside wd, the directory the user ran the command from. Directories
+// inside cwd are fine. It only warns; resuming across directories is
+// sometimes intended.
+func resumeDirWarning(wd string, cwd string, recorded string) string {
+	if strings.TrimSpace(cwd) == "" {
+		return ""
+	}
+	if recorded = strings.TrimSpace(recorded); recorded != "" && cwd != recorded {
+		if _, err := os.Stat(recorded); errors.Is(err, os.ErrNotExist) {
+			return fmt.Sprintf("%s no longer exists; resuming in %s", recorded, cwd)
+		}
+	}
+	if strings.TrimSpace(wd) == "" {
+		return ""
+	}
+	want := comparablePath(absPath(cwd))
+	got := comparablePath(absPath(wd))
+	if rel, err := filepath.Rel(want, got); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return fmt.Sprintf("resuming in the session's directory %s, outside the current directory %s", cwd, wd)
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// projectCodexPath returns codexPath, or when it is empty the Codex binary
+// configured for the project containing cwd. An empty result leaves the
+// choice to the normal Codex resolution.
+func projectCodexPath(store *config.Store, codexPath string, cwd string) string {
+	if strings.TrimSpace(codexPath) != "" || store == nil || strings.TrimSpace(cwd) == "" {
+		return codexPath
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		return codexPath
+	}
+	return cfg.ProjectCodexPath(absPath(cwd))
+}
+
+// projectLaunchEnv returns the environment variables configured for the
+// project containing cwd as sorted NAME=VALUE entries. A malformed name,
+// which only a hand-edited config can hold, stops the launch.
+func projectLaunchEnv(store *config.Store, cwd string) ([]string, error) {
+	if store == nil || strings.TrimSpace(cwd) == "" {
+		return nil, nil
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	project, vars := cfg.ProjectEnvFor(absPath(cwd))
+	entries := make([]string, 0, len(vars))
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		if err := config.ValidateEnvName(name); err != nil {
+			return nil, fmt.Errorf("project environment for %s: %w", project, err)
+		}
+		entries = append(entries, name+"="+vars[name])
+	}
+	return entries, nil
+}
+
 func runCodexSession(
 	ctx context.Context,
 	root *rootOptions,
@@ -52,18 +122,35 @@ func runCodexSession(
 	useProxy bool,
 	log io.Writer,
 ) error {
-	cwd := codexhistory.SessionWorkingDir(session)
+	home, _ := os.UserHomeDir()
+	cwd := codexhistory.SessionWorkingDirWithFallback(session, home)
 	if cwd == "" {
 		cwd = project.Path
 	}
+	wd, _ := os.Getwd()
+	if warning := resumeDirWarning(wd, cwd, codexhistory.SessionWorkingDir(session)); warning != "" && log != nil {
+		_, _ = fmt.Fprintf(log, "warning: %s\n", warning)
+	}
 	if strings.TrimSpace(session.SessionID) == "" {
 		return fmt.Errorf("missing session id")
 	}
-	agentAutoApprove, err := aaaPreference(store)
+	codexPath = projectCodexPath(store, codexPath, cwd)
+	agentAutoApprove, err := aaaPreference(ctx, store)
+	if err != nil {
+		return err
+	}
+	projectEnv, err := projectLaunchEnv(store, cwd)
 	if err != nil {
 		return err
 	}
-	return runCodexTUIViaBroker(ctx, root, store, profile, instances, cwd, session.SessionID, codexPath, codexDir, useProxy, agentAutoApprove, "", log)
+	logLaunchEvent(launchEvent{Event: "session_resume", Cwd: cwd, Session: session.SessionID})
+	if err := runCodexTUIViaBroker(ctx, root, store, profile, instances, cwd, session.SessionID, codexPath, codexDir, useProxy, agentAutoApprove, launchModelProfile(ctx), projectEnv, log); err != nil {
+		return err
+	}
+	if err := recordSessionResume(store, session.SessionID); err != nil && log != nil {
+		_, _ = fmt.Fprintf(log, "warning: failed to record resume of %s: %v\n", session.SessionID, err)
+	}
+	return nil
 }
 
 func runCodexNewSession(
@@ -73,19 +160,48 @@ func runCodexNewSession(
 	profile *config.Profile,
 	instances []config.Instance,
 	cwd string,
+	prompt string,
 	codexPath string,
 	codexDir string,
 	useProxy bool,
 	log io.Writer,
 ) error {
-	agentAutoApprove, err := aaaPreference(store)
+	codexPath = projectCodexPath(store, codexPath, cwd)
+	agentAutoApprove, err := aaaPreference(ctx, store)
 	if err != nil {
 		return err
 	}
-	return runCodexTUIViaBroker(ctx, root, store, profile, instances, cwd, "", codexPath, codexDir, useProxy, agentAutoApprove, "", log)
+	projectEnv, err := projectLaunchEnv(store, cwd)
+	if err != nil {
+		return err
+	}
+	var tail []string
+	if prompt = strings.TrimSpace(prompt); prompt != "" {
+		if !codexAcceptsInitialPrompt(ctx, codexPath) {
+			return fmt.Errorf("this Codex does not accept an initial prompt; start the session without --prompt and type it instead")
+		}
+		// "--" keeps a prompt that starts with a dash from parsing as a flag.
+		tail = []string{"--", prompt}
+	}
+	return runCodexTUIInvocationViaBroker(ctx, root, store, profile, instances, cwd, codexPath, codexDir, useProxy, agentAutoApprove, launchModelProfile(ctx), nil, tail, nil, projectEnv, log)
+}
+
+// codexAcceptsInitialPrompt checks `codex --help` for a [PROMPT] argument.
+// When the help cannot be read it says yes and lets Codex report the problem.
+var codexAcceptsInitialPrompt = func(ctx context.Context, codexPath string) bool {
+	help, err := codexTopLevelHelp(ctx, codexPath)
+	if err != nil {
+		return true
+	}
+	return codexHelpAcceptsPrompt(help)
 }
 
-func aaaPreference(store *config.Store) (bool, error) {
+// aaaPreference returns the auto-approve setting of the launch profile in
+// ctx, or else the persisted toggle.
+func aaaPreference(ctx context.Context, store *config.Store) (bool, error) {
+	if p := launchProfileFrom(ctx); p.AutoApprove != nil {
+		return *p.AutoApprove, nil
+	}
 	if store == nil {
 		return false, nil
 	}
@@ -116,13 +232,14 @@ func runCodexTUIViaBroker(
 	useProxy bool,
 	agentAutoApprove bool,
 	modelProfileRef string,
+	projectEnv []string,
 	log io.Writer,
 ) error {
 	tail := []string{}
 	if sessionID = strings.TrimSpace(sessionID); sessionID != "" {
 		tail = []string{"resume", sessionID}
 	}
-	return runCodexTUIInvocationViaBroker(ctx, root, store, profile, instances, cwd, codexPath, codexDir, useProxy, agentAutoApprove, modelProfileRef, nil, tail, nil, log)
+	return runCodexTUIInvocationViaBroker(ctx, root, store, profile, instances, cwd, codexPath, codexDir, useProxy, agentAutoApprove, modelProfileRef, nil, tail, nil, projectEnv, log)
 }
 
 func runCodexTUIInvocationViaBroker(
@@ -140,12 +257,16 @@ func runCodexTUIInvocationViaBroker(
 	tuiGlobalArgs []string,
 	tuiTail []string,
 	appServerExtraArgs []string,
+	projectEnv []string,
 	log io.Writer,
 ) error {
 	cwd, err := normalizeWorkingDir(cwd)
 	if err != nil {
 		return err
 	}
+	if err := checkAAACleanTree(ctx, store, cwd, agentAutoApprove); err != nil {
+		return err
+	}
 	installOptions := codexInstallOptions{}
 	if useProxy {
 		if profile == nil {
@@ -178,7 +299,9 @@ func runCodexTUIInvocationViaBroker(
 	if err := prepareRuntimeMigration(store, paths, codexPath, log); err != nil {
 		return err
 	}
-	extraEnv := mergeCLIEnvironment(runtimeContract.Environment, codexHomeEnv(paths.CodexDir))
+	// Project variables go under the ones the helper manages, so they can
+	// set things like OPENAI_BASE_URL but not move CODEX_HOME or the proxy.
+	extraEnv := mergeCLIEnvironment(mergeCLIEnvironment(runtimeContract.Environment, projectEnv), codexHomeEnv(paths.CodexDir))
 	proxyURL := ""
 	if useProxy {
 		proxyURL, err = codexAppEnsureProxyURLFn(ctx, store, *profile, instances, log)
@@ -274,6 +397,7 @@ func runCodexTUIInvocationViaBroker(
 		"--remote-auth-token-env", codexrunner.RemoteBrokerAuthTokenEnv,
 	)
 	args = append(args, tuiTail...)
+	logLaunchEvent(launchEvent{Event: "session_launched", Path: codexPath, Cwd: cwd, Args: redactLaunchArgs(append(append([]string{}, tuiGlobalArgs...), tuiTail...))})
 	runErr := runTargetSupervisedWithOptions(ctx, append([]string{codexPath}, args...), "", nil, broker.Done(), runTargetOptions{
 		Cwd:          cwd,
 		ExtraEnv:     extraEnv,
@@ -281,6 +405,7 @@ func runCodexTUIInvocationViaBroker(
 		ExecIdentity: paths.ExecIdentity,
 		Log:          log,
 	})
+	logLaunchEvent(launchEvent{Event: "session_exited", Path: codexPath, Cwd: cwd, Error: errorText(runErr)})
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	closeErr := broker.Close(shutdownCtx)
 	cancel()
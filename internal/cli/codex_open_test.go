@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -23,7 +24,7 @@ func TestRunCodexNewSessionUsesOriginalTUIAndPolicyAppServer(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, fixture.workDir, fixture.path, "", false, io.Discard); err != nil {
+	if err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, fixture.workDir, "", fixture.path, "", false, io.Discard); err != nil {
 		t.Fatalf("runCodexNewSession: %v", err)
 	}
 	after, err := hashFileSHA256(fixture.path)
@@ -37,12 +38,87 @@ func TestRunCodexNewSessionUsesManagedNodeWithoutSystemNode(t *testing.T) {
 	fixture := writeCodexTUIBrokerFixture(t)
 	wrapper := writeManagedNodeCodexWrapperFixture(t, fixture.path)
 	store := newCodexOpenTestStore(t)
-	if err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, fixture.workDir, wrapper, "", false, io.Discard); err != nil {
+	if err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, fixture.workDir, "", wrapper, "", false, io.Discard); err != nil {
 		t.Fatalf("runCodexNewSession through managed Node: %v", err)
 	}
 	assertStandardBrokerLaunch(t, fixture)
 }
 
+func TestRunCodexNewSessionUsesProjectCodexPath(t *testing.T) {
+	fixture := writeCodexTUIBrokerFixture(t)
+	store := newCodexOpenTestStore(t)
+	if err := store.Update(func(cfg *config.Config) error {
+		cfg.SetProjectCodexPath(fixture.workDir, fixture.path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, fixture.workDir, "", "", "", false, io.Discard); err != nil {
+		t.Fatalf("runCodexNewSession: %v", err)
+	}
+	assertStandardBrokerLaunch(t, fixture)
+}
+
+func TestProjectCodexPathPrefersExplicitPath(t *testing.T) {
+	store := newCodexOpenTestStore(t)
+	project := t.TempDir()
+	if err := store.Update(func(cfg *config.Config) error {
+		cfg.SetProjectCodexPath(project, "/opt/codex-patched/codex")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := projectCodexPath(store, "", filepath.Join(project, "sub")); got != "/opt/codex-patched/codex" {
+		t.Fatalf("projectCodexPath = %q", got)
+	}
+	if got := projectCodexPath(store, "/usr/bin/codex", project); got != "/usr/bin/codex" {
+		t.Fatalf("projectCodexPath with --codex-path = %q", got)
+	}
+	if got := projectCodexPath(store, "", t.TempDir()); got != "" {
+		t.Fatalf("projectCodexPath outside the project = %q", got)
+	}
+}
+
+func TestRunCodexNewSessionPassesInitialPrompt(t *testing.T) {
+	lockCLITestHooks(t)
+	prev := codexAcceptsInitialPrompt
+	t.Cleanup(func() { codexAcceptsInitialPrompt = prev })
+	codexAcceptsInitialPrompt = func(context.Context, string) bool { return true }
+	fixture := writeCodexTUIBrokerFixture(t)
+	store := newCodexOpenTestStore(t)
+	if err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, fixture.workDir, "  --fix-the-build ", fixture.path, "", false, io.Discard); err != nil {
+		t.Fatalf("runCodexNewSession: %v", err)
+	}
+	tuiArgs := readArgLines(t, fixture.tuiArgs)
+	if len(tuiArgs) != 8 || tuiArgs[2] != "--remote" || tuiArgs[6] != "--" || tuiArgs[7] != "--fix-the-build" {
+		t.Fatalf("TUI args = %#v", tuiArgs)
+	}
+}
+
+func TestRunCodexNewSessionRejectsPromptWhenCodexLacksIt(t *testing.T) {
+	lockCLITestHooks(t)
+	prev := codexAcceptsInitialPrompt
+	t.Cleanup(func() { codexAcceptsInitialPrompt = prev })
+	codexAcceptsInitialPrompt = func(context.Context, string) bool { return false }
+	store := newCodexOpenTestStore(t)
+	err := runCodexNewSession(context.Background(), &rootOptions{configPath: store.Path()}, store, nil, nil, t.TempDir(), "hello", "codex", "", false, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "does not accept an initial prompt") {
+		t.Fatalf("err = %v, want initial prompt rejection", err)
+	}
+}
+
+func TestCodexHelpAcceptsPrompt(t *testing.T) {
+	for help, want := range map[string]bool{
+		"Codex CLI\n\nUsage: codex [OPTIONS] [PROMPT]\n       codex [OPTIONS] <COMMAND> [ARGS]\n": true,
+		"Usage: codex [OPTIONS] <COMMAND>\n\nArguments:\n  [PROMPT]  unrelated\n":                 false,
+		"": false,
+	} {
+		if got := codexHelpAcceptsPrompt(help); got != want {
+			t.Errorf("codexHelpAcceptsPrompt(%q) = %v, want %v", help, got, want)
+		}
+	}
+}
+
 func TestRunCodexSessionPreservesResumeExperience(t *testing.T) {
 	fixture := writeCodexTUIBrokerFixture(t)
 	store := newCodexOpenTestStore(t)
@@ -55,6 +131,36 @@ func TestRunCodexSessionPreservesResumeExperience(t *testing.T) {
 		t.Fatalf("TUI args = %#v", tuiArgs)
 	}
 	assertBrokerCapabilityToken(t, fixture)
+	if got := loadResumeCounts(resumeCountsFile(store))["session-existing"]; got != 1 {
+		t.Fatalf("resume count = %d, want 1", got)
+	}
+}
+
+func TestResumeDirWarning(t *testing.T) {
+	project := t.TempDir()
+	sub := filepath.Join(project, "sub")
+	if err := os.Mkdir(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	other := t.TempDir()
+	gone := filepath.Join(project, "gone")
+	for _, tc := range []struct {
+		wd, cwd, recorded, want string
+	}{
+		{project, project, project, ""},
+		{sub, project, project, ""},
+		{project + string(filepath.Separator), project, project, ""},
+		{"", project, project, ""},
+		{other, project, project, "outside the current directory " + other},
+		{project + "-sibling", project, project, "outside the current directory"},
+		{project, project, gone, gone + " no longer exists; resuming in " + project},
+		{other, project, gone, gone + " no longer exists; resuming in " + project},
+	} {
+		got := resumeDirWarning(tc.wd, tc.cwd, tc.recorded)
+		if (tc.want == "") != (got == "") || !strings.Contains(got, tc.want) {
+			t.Errorf("resumeDirWarning(%q, %q, %q) = %q, want %q", tc.wd, tc.cwd, tc.recorded, got, tc.want)
+		}
+	}
 }
 
 func TestNormalizeWorkingDirRejectsMissingDirectory(t *testing.T) {
@@ -254,3 +360,38 @@ func readArgLines(t *testing.T, path string) []string {
 	}
 	return strings.Fields(strings.TrimSpace(string(raw)))
 }
+
+func TestProjectLaunchEnvUsesNearestProject(t *testing.T) {
+	store := newCodexOpenTestStore(t)
+	project := filepath.Join(t.TempDir(), "repo")
+	if err := store.Update(func(cfg *config.Config) error {
+		if err := cfg.SetProjectEnvVar(project, "ZED", "z"); err != nil {
+			return err
+		}
+		return cfg.SetProjectEnvVar(project, "ALPHA", "a=b")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := projectLaunchEnv(store, filepath.Join(project, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(env, []string{"ALPHA=a=b", "ZED=z"}) {
+		t.Fatalf("projectLaunchEnv = %#v", env)
+	}
+	if env, err := projectLaunchEnv(store, filepath.Dir(project)); err != nil || len(env) != 0 {
+		t.Fatalf("projectLaunchEnv(parent) = %#v, %v", env, err)
+	}
+
+	// A hand-edited config can hold a name the command would have rejected.
+	if err := store.Update(func(cfg *config.Config) error {
+		cfg.ProjectEnv[project]["BAD-NAME"] = "x"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := projectLaunchEnv(store, project); err == nil || !strings.Contains(err.Error(), "BAD-NAME") {
+		t.Fatalf("expected an invalid name error, got %v", err)
+	}
+}
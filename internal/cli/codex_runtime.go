@@ -210,7 +210,7 @@ func runCodexCLIInvocation(
 		if invocation.Command != "" {
 			tail = append([]string{invocation.Command}, invocation.Args...)
 		}
-		return runCodexTUIInvocationViaBroker(ctx, root, store, profile, instances, cwd, cmdArgs[0], "", useProxy, opts.AgentAutoApprove, opts.ModelProfileRef, invocation.GlobalArgs, tail, appServerArgs, opts.Log)
+		return runCodexTUIInvocationViaBroker(ctx, root, store, profile, instances, cwd, cmdArgs[0], "", useProxy, opts.AgentAutoApprove, opts.ModelProfileRef, invocation.GlobalArgs, tail, appServerArgs, nil, opts.Log)
 	case "exec", "e":
 		execArgs := codexFacadeArgsWithGlobalInputs(invocation, invocation.Args)
 		return runCodexExecFacade(ctx, root, store, profile, instances, cmdArgs[0], cwd, useProxy, opts, appServerArgs, execArgs)
@@ -310,22 +310,12 @@ func discoverCodexTopLevelCommands(ctx context.Context, codexPath string) map[st
 	for command := range codexSubcommands {
 		commands[command] = true
 	}
-	probePath := codexPath
-	if codexPathAllowsAutomaticUpgrade(probePath) {
-		if resolved, err := exec.LookPath("codex"); err == nil {
-			probePath = resolved
-		} else if resolved, err := findInstalledCodexWithoutProbe(); err == nil {
-			probePath = resolved
-		}
-	}
-	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-	output, err := exec.CommandContext(probeCtx, probePath, "--help").Output()
+	output, err := codexTopLevelHelp(ctx, codexPath)
 	if err != nil {
 		return commands
 	}
 	inCommands := false
-	for _, line := range strings.Split(string(output), "\n") {
+	for _, line := range strings.Split(output, "\n") {
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "Commands:" {
 			inCommands = true
@@ -357,6 +347,34 @@ func discoverCodexTopLevelCommands(ctx context.Context, codexPath string) map[st
 	return commands
 }
 
+// codexTopLevelHelp returns the output of `codex --help` for the binary a
+// launch through codexPath would use.
+func codexTopLevelHelp(ctx context.Context, codexPath string) (string, error) {
+	probePath := codexPath
+	if codexPathAllowsAutomaticUpgrade(probePath) {
+		if resolved, err := exec.LookPath("codex"); err == nil {
+			probePath = resolved
+		} else if resolved, err := findInstalledCodexWithoutProbe(); err == nil {
+			probePath = resolved
+		}
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(probeCtx, probePath, "--help").Output()
+	return string(output), err
+}
+
+// codexHelpAcceptsPrompt reports whether a `codex --help` usage line takes
+// an initial [PROMPT] argument.
+func codexHelpAcceptsPrompt(help string) bool {
+	for _, line := range strings.Split(help, "\n") {
+		if usage, ok := strings.CutPrefix(strings.TrimSpace(line), "Usage:"); ok && strings.Contains(usage, "[PROMPT]") {
+			return true
+		}
+	}
+	return false
+}
+
 type codexExecFacadeOptions struct {
 	JSONOutput     bool
 	Resume         bool
@@ -531,6 +549,9 @@ func runCodexExecFacade(
 	if options.Prompt == "" {
 		return fmt.Errorf("codex exec prompt is required")
 	}
+	if err := checkAAACleanTree(ctx, store, options.WorkingDir, runOptions.AgentAutoApprove); err != nil {
+		return err
+	}
 
 	installOptions := codexInstallOptions{}
 	if useProxy {
@@ -633,6 +654,7 @@ func runCodexExecFacade(
 		EventHandler:   handler,
 		Ephemeral:      options.Ephemeral,
 	}
+	logLaunchEvent(launchEvent{Event: "session_launched", Path: codexPath, Cwd: options.WorkingDir, Args: redactLaunchArgs(append([]string{"exec"}, execArgs...))})
 	var result codexrunner.TurnResult
 	if options.Resume {
 		threadID := strings.TrimSpace(options.ThreadID)
@@ -671,6 +693,7 @@ func runCodexExecFacade(
 	} else {
 		result, err = runner.StartThread(ctx, input)
 	}
+	logLaunchEvent(launchEvent{Event: "session_exited", Path: codexPath, Cwd: options.WorkingDir, Session: result.ThreadID, Error: errorText(err)})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,502 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+	"github.com/baaaaaaaka/codex-helper/internal/modelprofile"
+)
+
+func newConfigCmd(root *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Back up, share and adjust helper settings",
+	}
+	cmd.AddCommand(
+		newConfigExportCmd(root),
+		newConfigImportCmd(root),
+		newConfigProjectCodexCmd(root),
+		newConfigProjectEnvCmd(root),
+		newConfigLaunchProfileCmd(root),
+		newConfigAAAGuardCmd(root),
+	)
+	return cmd
+}
+
+func newConfigAAAGuardCmd(root *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "aaa-guard [on|off]",
+		Short: "Confirm before AAA launches in git repos with uncommitted changes",
+		Long: "With the guard on, launching Codex in AAA mode from a git repo with uncommitted\n" +
+			"changes asks for confirmation first, and is refused when there is no terminal to ask\n" +
+			"on. --force skips the check for one launch. Without an argument the current setting\n" +
+			"is printed.",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"on", "off"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := newRootStore(root, "")
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(args) == 0 {
+				cfg, err := store.Load()
+				if err != nil {
+					return err
+				}
+				if resolveAAARequireClean(cfg) {
+					_, _ = fmt.Fprintln(out, "on")
+				} else {
+					_, _ = fmt.Fprintln(out, "off")
+				}
+				return nil
+			}
+			var enabled bool
+			switch strings.ToLower(strings.TrimSpace(args[0])) {
+			case "on":
+				enabled = true
+			case "off":
+			default:
+				return fmt.Errorf("expected on or off, got %q", args[0])
+			}
+			if err := store.Update(func(cfg *config.Config) error {
+				cfg.AgentAutoApproveRequireClean = &enabled
+				return nil
+			}); err != nil {
+				return err
+			}
+			if enabled {
+				_, _ = fmt.Fprintln(out, "AAA launches in git repos with uncommitted changes now need confirmation")
+			} else {
+				_, _ = fmt.Fprintln(out, "AAA launches no longer check for uncommitted changes")
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newConfigExportCmd(root *rootOptions) *cobra.Command {
+	var output string
+	var includeSecrets bool
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write proxy profiles, model profiles and preferences as JSON",
+		Long: "Write proxy profiles, model profiles and preferences as JSON. Running instances and\n" +
+			"other machine-local state are left out. Model profile API key references other than\n" +
+			"env: ones are dropped unless --include-secrets is given; keys saved in the local\n" +
+			"secret store are never exported.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, _, err := newRootStore(root, "")
+			if err != nil {
+				return err
+			}
+			cfg, err := store.Load()
+			if err != nil {
+				return err
+			}
+			data, err := config.Encode(exportConfig(cfg, includeSecrets))
+			if err != nil {
+				return err
+			}
+			if output == "" || output == "-" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o600); err != nil {
+				return fmt.Errorf("write %s: %w", output, err)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Exported config to %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Keep every model profile API key reference")
+	return cmd
+}
+
+// exportConfig is cfg's portable part, with API key references that only mean
+// something on this machine removed unless includeSecrets is set.
+func exportConfig(cfg config.Config, includeSecrets bool) config.Config {
+	out := cfg.Portable()
+	if includeSecrets {
+		return out
+	}
+	for name, p := range out.ModelProfiles {
+		if !strings.HasPrefix(strings.TrimSpace(p.APIKeyRef), modelprofile.EnvRefPrefix) {
+			p.APIKeyRef = ""
+			out.ModelProfiles[name] = p
+		}
+	}
+	return out
+}
+
+func newConfigImportCmd(root *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Merge an exported config into this one",
+		Long: "Merge a file written by `config export` into the config. Profiles and model profiles\n" +
+			"are added or replaced by ID and name; everything else is kept. Files from a newer\n" +
+			"codex-helper or with unknown fields are refused.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("read %s: %w", args[0], err)
+			}
+			in, err := config.DecodePortable(data)
+			if err != nil {
+				return err
+			}
+			store, _, err := newRootStore(root, "")
+			if err != nil {
+				return err
+			}
+			if err := store.Update(func(cfg *config.Config) error {
+				cfg.MergePortable(in)
+				return nil
+			}); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Imported %d proxy profile(s) and %d model profile(s)\n", len(in.Profiles), len(in.ModelProfiles))
+			return nil
+		},
+	}
+}
+
+func newConfigProjectCodexCmd(root *rootOptions) *cobra.Command {
+	var project string
+	var clearCustom bool
+	cmd := &cobra.Command{
+		Use:   "project-codex [codex-path]",
+		Short: "Launch a project's sessions with a specific Codex binary",
+		Long: "Launch new and resumed sessions in a project, and its subdirectories, with the given\n" +
+			"Codex binary instead of the one found on PATH or installed by the helper. Without an\n" +
+			"argument the current setting is printed; --clear goes back to the normal resolution.\n" +
+			"An explicit --codex-path still wins.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearCustom && len(args) > 0 {
+				return fmt.Errorf("--clear cannot be combined with a Codex path")
+			}
+			if project == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				project = wd
+			}
+			project, err := filepath.Abs(project)
+			if err != nil {
+				return err
+			}
+			store, _, err := newRootStore(root, "")
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if !clearCustom && len(args) == 0 {
+				cfg, err := store.Load()
+				if err != nil {
+					return err
+				}
+				if path := cfg.ProjectCodexPath(project); path != "" {
+					_, _ = fmt.Fprintln(out, path)
+				} else {
+					_, _ = fmt.Fprintf(out, "No custom Codex for %s\n", project)
+				}
+				return nil
+			}
+			codexPath := ""
+			if !clearCustom {
+				if codexPath, err = exec.LookPath(args[0]); err != nil {
+					return fmt.Errorf("codex binary %q: %w", args[0], err)
+				}
+				if codexPath, err = filepath.Abs(codexPath); err != nil {
+					return err
+				}
+			}
+			if err := store.Update(func(cfg *config.Config) error {
+				cfg.SetProjectCodexPath(project, codexPath)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if clearCustom {
+				_, _ = fmt.Fprintf(out, "Sessions in %s use the default Codex\n", project)
+			} else {
+				_, _ = fmt.Fprintf(out, "Sessions in %s use %s\n", project, codexPath)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&project, "project", "", "Project directory (default: current directory)")
+	cmd.Flags().BoolVar(&clearCustom, "clear", false, "Remove the project's custom Codex")
+	return cmd
+}
+
+func newConfigProjectEnvCmd(root *rootOptions) *cobra.Command {
+	var project string
+	var unset []string
+	var clearAll bool
+	var showValues bool
+	cmd := &cobra.Command{
+		Use:   "project-env [NAME=VALUE...]",
+		Short: "Set environment variables for a project's sessions",
+		Long: "Set environment variables, such as OPENAI_BASE_URL, for new and resumed sessions in a\n" +
+			"project and its subdirectories. They apply on top of the helper's environment and\n" +
+			"under the variables it manages, so proxy mode and CODEX_HOME keep working. Without\n" +
+			"arguments the project's variables are listed with their values hidden; --show-values\n" +
+			"prints them.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearAll && (len(args) > 0 || len(unset) > 0) {
+				return fmt.Errorf("--clear cannot be combined with variables or --unset")
+			}
+			type assignment struct{ name, value string }
+			assignments := make([]assignment, 0, len(args))
+			for _, arg := range args {
+				name, value, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("%q is not NAME=VALUE", arg)
+				}
+				if err := config.ValidateEnvName(name); err != nil {
+					return err
+				}
+				assignments = append(assignments, assignment{name, value})
+			}
+			for _, name := range unset {
+				if err := config.ValidateEnvName(name); err != nil {
+					return err
+				}
+			}
+			if project == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				project = wd
+			}
+			project, err := filepath.Abs(project)
+			if err != nil {
+				return err
+			}
+			store, _, err := newRootStore(root, "")
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if !clearAll && len(assignments) == 0 && len(unset) == 0 {
+				cfg, err := store.Load()
+				if err != nil {
+					return err
+				}
+				dir, vars := cfg.ProjectEnvFor(project)
+				if len(vars) == 0 {
+					_, _ = fmt.Fprintf(out, "No environment variables for %s\n", project)
+					return nil
+				}
+				_, _ = fmt.Fprintf(out, "Environment for sessions in %s:\n", dir)
+				for _, name := range slices.Sorted(maps.Keys(vars)) {
+					value := "<redacted>"
+					if showValues {
+						value = vars[name]
+					}
+					_, _ = fmt.Fprintf(out, "  %s=%s\n", name, value)
+				}
+				return nil
+			}
+			if err := store.Update(func(cfg *config.Config) error {
+				if clearAll {
+					cfg.UnsetProjectEnvVar(project, "")
+					return nil
+				}
+				for _, name := range unset {
+					cfg.UnsetProjectEnvVar(project, name)
+				}
+				for _, a := range assignments {
+					if err := cfg.SetProjectEnvVar(project, a.name, a.value); err != nil {
+						return err
+					}
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if clearAll {
+				_, _ = fmt.Fprintf(out, "Removed the environment variables of %s\n", project)
+				return nil
+			}
+			if len(unset) > 0 {
+				_, _ = fmt.Fprintf(out, "Removed %s from %s\n", strings.Join(unset, ", "), project)
+			}
+			if len(assignments) > 0 {
+				names := make([]string, 0, len(assignments))
+				for _, a := range assignments {
+					names = append(names, a.name)
+				}
+				_, _ = fmt.Fprintf(out, "Sessions in %s get %s\n", project, strings.Join(names, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&project, "project", "", "Project directory (default: current directory)")
+	cmd.Flags().StringArrayVar(&unset, "unset", nil, "Remove this variable from the project (repeatable)")
+	cmd.Flags().BoolVar(&clearAll, "clear", false, "Remove all of the project's variables")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "Print values when listing instead of hiding them")
+	return cmd
+}
+
+func newConfigLaunchProfileCmd(root *rootOptions) *cobra.Command {
+	var proxy, autoApprove, remove bool
+	var proxyProfile, modelProfile string
+	cmd := &cobra.Command{
+		Use:   "launch-profile [name]",
+		Short: "Define named launch settings to pick in the history TUI",
+		Long: "Define a launch profile: a named preset of proxy mode, the proxy profile, agent\n" +
+			"auto-approve and the model profile. Press w in the history TUI to pick one for the\n" +
+			"sessions it launches; settings a profile leaves unset follow the usual toggles.\n" +
+			"Flags given for an existing profile update it. Without flags prints the profile,\n" +
+			"and without a name lists them all. Use --proxy=false or --auto-approve=false to\n" +
+			"switch a setting off.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := newRootStore(root, "")
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			cfg, err := store.Load()
+			if err != nil {
+				return err
+			}
+			edits := 0
+			for _, flag := range []string{"proxy", "proxy-profile", "auto-approve", "model-profile"} {
+				if cmd.Flags().Changed(flag) {
+					edits++
+				}
+			}
+			if len(args) == 0 {
+				if remove || edits > 0 {
+					return fmt.Errorf("name the launch profile to change")
+				}
+				if len(cfg.LaunchProfiles) == 0 {
+					_, _ = fmt.Fprintln(out, "No launch profiles")
+					return nil
+				}
+				for _, name := range cfg.LaunchProfileNames() {
+					_, _ = fmt.Fprintf(out, "%s: %s\n", name, describeLaunchProfile(cfg.LaunchProfiles[name]))
+				}
+				return nil
+			}
+			name := strings.TrimSpace(args[0])
+			if remove {
+				if edits > 0 {
+					return fmt.Errorf("--delete cannot be combined with other flags")
+				}
+				removed := false
+				if err := store.Update(func(cfg *config.Config) error {
+					removed = cfg.RemoveLaunchProfile(name)
+					return nil
+				}); err != nil {
+					return err
+				}
+				if !removed {
+					return fmt.Errorf("launch profile %q not found", name)
+				}
+				_, _ = fmt.Fprintf(out, "Removed launch profile %s\n", name)
+				return nil
+			}
+			if edits == 0 {
+				p, ok := cfg.LaunchProfiles[name]
+				if !ok {
+					return fmt.Errorf("launch profile %q not found", name)
+				}
+				_, _ = fmt.Fprintf(out, "%s: %s\n", name, describeLaunchProfile(p))
+				return nil
+			}
+			if proxyProfile = strings.TrimSpace(proxyProfile); proxyProfile != "" {
+				if _, ok := cfg.FindProfile(proxyProfile); !ok {
+					return fmt.Errorf("proxy profile %q not found", proxyProfile)
+				}
+			}
+			if modelProfile = strings.TrimSpace(modelProfile); modelProfile != "" {
+				if _, ok := cfg.FindModelProfile(modelProfile); !ok {
+					return fmt.Errorf("model profile %q not found", modelProfile)
+				}
+			}
+			var saved config.LaunchProfile
+			if err := store.Update(func(cfg *config.Config) error {
+				p := cfg.LaunchProfiles[name]
+				if cmd.Flags().Changed("proxy") {
+					p.Proxy = &proxy
+				}
+				if cmd.Flags().Changed("proxy-profile") {
+					p.ProxyProfile = proxyProfile
+				}
+				if cmd.Flags().Changed("auto-approve") {
+					p.AutoApprove = &autoApprove
+				}
+				if cmd.Flags().Changed("model-profile") {
+					p.ModelProfile = modelProfile
+				}
+				saved = p
+				return cfg.SetLaunchProfile(name, p)
+			}); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(out, "Saved launch profile %s: %s\n", name, describeLaunchProfile(saved))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&proxy, "proxy", false, "Launch with proxy mode on (--proxy=false for off)")
+	cmd.Flags().StringVar(&proxyProfile, "proxy-profile", "", "Proxy profile to use when proxy mode is on (default: the usual one)")
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Launch with agent auto-approve on (--auto-approve=false for off)")
+	cmd.Flags().StringVar(&modelProfile, "model-profile", "", "Model profile to launch with (default: the default model profile)")
+	cmd.Flags().BoolVar(&remove, "delete", false, "Delete the launch profile")
+	return cmd
+}
+
+// describeLaunchProfile summarizes the settings p overrides.
+func describeLaunchProfile(p config.LaunchProfile) string {
+	var parts []string
+	if p.Proxy != nil {
+		proxy := "proxy off"
+		if *p.Proxy {
+			proxy = "proxy on"
+		}
+		parts = append(parts, proxy)
+	}
+	if p.ProxyProfile != "" {
+		parts = append(parts, "proxy profile "+p.ProxyProfile)
+	}
+	if p.AutoApprove != nil {
+		aaa := "auto-approve off"
+		if *p.AutoApprove {
+			aaa = "auto-approve on"
+		}
+		parts = append(parts, aaa)
+	}
+	if p.ModelProfile != "" {
+		parts = append(parts, "model profile "+p.ModelProfile)
+	}
+	if len(parts) == 0 {
+		return "no overrides"
+	}
+	return strings.Join(parts, ", ")
+}
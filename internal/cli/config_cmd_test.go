@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+func TestConfigExportImportRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "src.json")
+	src, err := config.NewStore(srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Save(config.Config{
+		Profiles:  []config.Profile{{ID: "p1", Name: "work", Host: "jump", Port: 22}},
+		Instances: []config.Instance{{ID: "i1", ProfileID: "p1"}},
+		ModelProfiles: map[string]config.ModelProfile{
+			"env":    {Provider: "deepseek", APIKeyRef: "env:DEEPSEEK_API_KEY"},
+			"stored": {Provider: "kimi", APIKeyRef: "secret:model-profile/stored/api-key"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	exportPath := filepath.Join(dir, "export.json")
+	if out, err := runBeaconRootCommand(t, "--config", srcPath, "config", "export", "-o", exportPath); err != nil {
+		t.Fatalf("export: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	if strings.Contains(text, "secret:") || strings.Contains(text, "instances") {
+		t.Fatalf("export leaked local state:\n%s", text)
+	}
+	if !strings.Contains(text, "env:DEEPSEEK_API_KEY") {
+		t.Fatalf("export dropped the env key reference:\n%s", text)
+	}
+
+	dstPath := filepath.Join(dir, "dst.json")
+	if out, err := runBeaconRootCommand(t, "--config", dstPath, "config", "import", exportPath); err != nil {
+		t.Fatalf("import: %v\n%s", err, out)
+	}
+	dst, err := config.NewStore(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := dst.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := cfg.FindProfile("work"); !ok || p.Host != "jump" {
+		t.Fatalf("imported profiles = %+v", cfg.Profiles)
+	}
+	if len(cfg.ModelProfiles) != 2 || len(cfg.Instances) != 0 {
+		t.Fatalf("imported config = %+v", cfg)
+	}
+}
+
+func TestConfigExportIncludeSecretsKeepsKeyRefs(t *testing.T) {
+	cfg := config.Config{ModelProfiles: map[string]config.ModelProfile{
+		"stored": {Provider: "kimi", APIKeyRef: "secret:model-profile/stored/api-key"},
+	}}
+	if got := exportConfig(cfg, true).ModelProfiles["stored"].APIKeyRef; got == "" {
+		t.Fatal("--include-secrets dropped the key reference")
+	}
+	if got := exportConfig(cfg, false).ModelProfiles["stored"].APIKeyRef; got != "" {
+		t.Fatalf("key reference exported without --include-secrets: %q", got)
+	}
+	if cfg.ModelProfiles["stored"].APIKeyRef == "" {
+		t.Fatal("exportConfig modified its input")
+	}
+}
+
+func TestConfigProjectCodexSetShowAndClear(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	project := filepath.Join(dir, "repo")
+	codex := filepath.Join(dir, "codex-patched")
+	if err := os.WriteFile(codex, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := runBeaconRootCommand(t, "--config", cfgPath, "config", "project-codex", "--project", project, codex); err != nil {
+		t.Fatalf("set: %v\n%s", err, out)
+	}
+	out, err := runBeaconRootCommand(t, "--config", cfgPath, "config", "project-codex", "--project", filepath.Join(project, "sub"))
+	if err != nil || strings.TrimSpace(out) != codex {
+		t.Fatalf("show = %q, %v; want %s", out, err, codex)
+	}
+
+	if out, err := runBeaconRootCommand(t, "--config", cfgPath, "config", "project-codex", "--project", project, "--clear"); err != nil {
+		t.Fatalf("clear: %v\n%s", err, out)
+	}
+	store, err := config.NewStore(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ProjectCodexPaths) != 0 {
+		t.Fatalf("ProjectCodexPaths after --clear = %#v", cfg.ProjectCodexPaths)
+	}
+
+	if _, err := runBeaconRootCommand(t, "--config", cfgPath, "config", "project-codex", "--project", project, filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("accepted a Codex path that does not exist")
+	}
+	out, err = runBeaconRootCommand(t, "--config", cfgPath, "config", "project-codex", "--project", project, "--clear", codex)
+	if err == nil || !strings.Contains(err.Error(), "--clear cannot be combined with a Codex path") {
+		t.Fatalf("--clear with a path = %v\n%s", err, out)
+	}
+}
+
+func TestConfigProjectEnvSetListAndUnset(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	project := filepath.Join(dir, "repo")
+	run := func(args ...string) (string, error) {
+		return runBeaconRootCommand(t, append([]string{"--config", cfgPath, "config", "project-env", "--project", project}, args...)...)
+	}
+
+	if out, err := run("FEATURE_X=1", "DATABASE_URL=postgres://u:secret@db/dev"); err != nil {
+		t.Fatalf("set: %v\n%s", err, out)
+	}
+	out, err := run()
+	if err != nil || !strings.Contains(out, "DATABASE_URL=<redacted>") || strings.Contains(out, "secret") {
+		t.Fatalf("list = %q, %v", out, err)
+	}
+	if strings.Index(out, "DATABASE_URL") > strings.Index(out, "FEATURE_X") {
+		t.Fatalf("list is not sorted:\n%s", out)
+	}
+	out, err = run("--show-values")
+	if err != nil || !strings.Contains(out, "DATABASE_URL=postgres://u:secret@db/dev") {
+		t.Fatalf("list --show-values = %q, %v", out, err)
+	}
+
+	if out, err := run("--unset", "FEATURE_X"); err != nil {
+		t.Fatalf("unset: %v\n%s", err, out)
+	}
+	store, err := config.NewStore(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env := cfg.ProjectEnv[project]; len(env) != 1 || env["DATABASE_URL"] == "" {
+		t.Fatalf("ProjectEnv after --unset = %#v", cfg.ProjectEnv)
+	}
+
+	if _, err := run("1BAD=x"); err == nil {
+		t.Fatal("accepted an invalid variable name")
+	}
+	if _, err := run("NOVALUE"); err == nil {
+		t.Fatal("accepted an argument without =")
+	}
+
+	if out, err := run("--clear"); err != nil {
+		t.Fatalf("clear: %v\n%s", err, out)
+	}
+	out, err = run()
+	if err != nil || !strings.Contains(out, "No environment variables") {
+		t.Fatalf("list after --clear = %q, %v", out, err)
+	}
+}
+
+func TestConfigLaunchProfileSetShowAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	store, err := config.NewStore(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(config.Config{
+		Version:  config.CurrentVersion,
+		Profiles: []config.Profile{{ID: "p1", Name: "work", Host: "example.com", Port: 22, User: "coder"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) (string, error) {
+		return runBeaconRootCommand(t, append([]string{"--config", cfgPath, "config", "launch-profile"}, args...)...)
+	}
+
+	if out, err := run(); err != nil || !strings.Contains(out, "No launch profiles") {
+		t.Fatalf("empty list = %q, %v", out, err)
+	}
+	if out, err := run("safe", "--auto-approve=false", "--proxy=false"); err != nil {
+		t.Fatalf("set safe: %v\n%s", err, out)
+	}
+	if out, err := run("remote", "--proxy", "--proxy-profile", "work"); err != nil {
+		t.Fatalf("set remote: %v\n%s", err, out)
+	}
+	if out, err := run("remote", "--auto-approve"); err != nil {
+		t.Fatalf("update remote: %v\n%s", err, out)
+	}
+	out, err := run()
+	if err != nil || out != "remote: proxy on, proxy profile work, auto-approve on\nsafe: proxy off, auto-approve off\n" {
+		t.Fatalf("list = %q, %v", out, err)
+	}
+	if out, err := run("remote"); err != nil || !strings.HasPrefix(out, "remote: proxy on") {
+		t.Fatalf("show = %q, %v", out, err)
+	}
+
+	if _, err := run("bad", "--proxy-profile", "missing"); err == nil {
+		t.Fatal("accepted a proxy profile that does not exist")
+	}
+	if _, err := run("bad", "--model-profile", "missing"); err == nil {
+		t.Fatal("accepted a model profile that does not exist")
+	}
+	if _, err := run("--proxy"); err == nil {
+		t.Fatal("accepted flags without a profile name")
+	}
+
+	if out, err := run("safe", "--delete"); err != nil {
+		t.Fatalf("delete: %v\n%s", err, out)
+	}
+	if _, err := run("safe", "--delete"); err == nil {
+		t.Fatal("deleting a missing profile should fail")
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.LaunchProfileNames(), []string{"remote"}) {
+		t.Fatalf("launch profiles after delete = %#v", cfg.LaunchProfiles)
+	}
+}
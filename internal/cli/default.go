@@ -8,5 +8,5 @@ func runDefaultTui(cmd *cobra.Command, root *rootOptions) error {
 		return err
 	}
 
-	return runHistoryTui(cmd, root, profileRef, "", "", defaultRefreshInterval)
+	return runHistoryTui(cmd, root, profileRef, "", "", historyTuiFlags{refreshInterval: defaultRefreshInterval})
 }
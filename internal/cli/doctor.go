@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+const (
+	doctorOK   = "OK"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+)
+
+var (
+	doctorFindInstalledCodex = findInstalledCodex
+	doctorCodexVersion       = codexVersionString
+	doctorManagedNodeDirs    = managedNodeBinCandidates
+)
+
+type doctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string
+}
+
+func newDoctorCmd(root *rootOptions) *cobra.Command {
+	var codexDir string
+	var codexPath string
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the local Codex, Node, and config setup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			checks := runDoctorChecks(cmd.Context(), root, codexDir, codexPath)
+			failed := printDoctorChecks(cmd.OutOrStdout(), checks)
+			if failed > 0 {
+				return fmt.Errorf("doctor: %d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&codexDir, "codex-dir", "", "Override Codex data dir (default: ~/.codex)")
+	cmd.Flags().StringVar(&codexPath, "codex-path", "", "Override Codex CLI path (default: search PATH)")
+	return cmd
+}
+
+func runDoctorChecks(ctx context.Context, root *rootOptions, codexDir string, codexPath string) []doctorCheck {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	checks := []doctorCheck{
+		doctorCheckCodex(ctx, codexPath),
+		doctorCheckManagedNode(),
+	}
+	paths, err := resolveEffectivePaths(root.configPath, codexDir, "")
+	if err != nil {
+		return append(checks, doctorCheck{
+			Name:   "paths",
+			Status: doctorFail,
+			Detail: err.Error(),
+			Hint:   "pass --codex-dir and --config explicitly",
+		})
+	}
+	return append(checks,
+		doctorCheckCodexDir(ctx, paths.CodexDir),
+		doctorCheckConfig(paths.ConfigPath),
+	)
+}
+
+func printDoctorChecks(out io.Writer, checks []doctorCheck) int {
+	failed := 0
+	for _, check := range checks {
+		if check.Status == doctorFail {
+			failed++
+		}
+		_, _ = fmt.Fprintf(out, "%-4s %s: %s\n", check.Status, check.Name, check.Detail)
+		if check.Status != doctorOK && check.Hint != "" {
+			_, _ = fmt.Fprintf(out, "     hint: %s\n", check.Hint)
+		}
+	}
+	return failed
+}
+
+func doctorCheckCodex(ctx context.Context, codexPath string) doctorCheck {
+	check := doctorCheck{Name: "codex"}
+	path := strings.TrimSpace(codexPath)
+	if path == "" {
+		found, err := doctorFindInstalledCodex(ctx)
+		if err != nil {
+			check.Status = doctorFail
+			check.Detail = err.Error()
+			check.Hint = "run `codex-proxy` once to install Codex, or pass --codex-path"
+			return check
+		}
+		path = found
+	}
+	version, err := doctorCodexVersion(ctx, path)
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%s: %v", path, err)
+		check.Hint = "reinstall with `codex-proxy --upgrade-codex`"
+		return check
+	}
+	check.Status = doctorOK
+	check.Detail = fmt.Sprintf("%s (%s)", path, version)
+	return check
+}
+
+func doctorCheckManagedNode() doctorCheck {
+	check := doctorCheck{Name: "managed node"}
+	var found []string
+	for _, dir := range doctorManagedNodeDirs() {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			found = append(found, dir)
+		}
+	}
+	if len(found) == 0 {
+		check.Status = doctorWarn
+		check.Detail = "no managed Node install found"
+		check.Hint = "Codex will use node from PATH; run `codex-proxy` to install a managed copy if that fails"
+		return check
+	}
+	check.Status = doctorOK
+	check.Detail = strings.Join(found, ", ")
+	return check
+}
+
+func doctorCheckCodexDir(ctx context.Context, codexDir string) doctorCheck {
+	check := doctorCheck{Name: "codex dir"}
+	projects, err := codexhistory.DiscoverProjectsContext(ctx, codexDir)
+	if err != nil && len(projects) == 0 {
+		if codexhistory.IsSessionsDirNotFound(err) {
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("%s has no sessions yet", codexDir)
+			check.Hint = "start Codex once, or pass --codex-dir if your data lives elsewhere"
+			return check
+		}
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%s: %v", codexDir, err)
+		check.Hint = "check the directory permissions"
+		return check
+	}
+	sessions := 0
+	for _, project := range projects {
+		sessions += len(project.Sessions)
+	}
+	check.Status = doctorOK
+	check.Detail = fmt.Sprintf("%s (%d sessions in %d projects)", codexDir, sessions, len(projects))
+	return check
+}
+
+func doctorCheckConfig(configPath string) doctorCheck {
+	check := doctorCheck{Name: "config"}
+	store, err := config.NewStore(configPath)
+	if err == nil {
+		check.Detail = store.Path()
+		err = store.Validate()
+	}
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("%s: %s", invalid.Path, strings.Join(invalid.Problems, "; "))
+		check.Hint = "edit the config file, or rerun with --reset-config to move it aside"
+		return check
+	}
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%s: %v", configPath, err)
+		var fileErr *config.FileError
+		if errors.As(err, &fileErr) {
+			check.Detail = fileErr.Error()
+		}
+		check.Hint = "fix the config file, or rerun with --reset-config to move it aside; it is recreated on the next run"
+		return check
+	}
+	check.Status = doctorOK
+	return check
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func stubDoctorHooks(t *testing.T, find func(context.Context) (string, error), version func(context.Context, string) (string, error), nodeDirs []string) {
+	t.Helper()
+	lockCLITestHooks(t)
+	prevFind, prevVersion, prevNode := doctorFindInstalledCodex, doctorCodexVersion, doctorManagedNodeDirs
+	doctorFindInstalledCodex = find
+	doctorCodexVersion = version
+	doctorManagedNodeDirs = func() []string { return nodeDirs }
+	t.Cleanup(func() {
+		doctorFindInstalledCodex, doctorCodexVersion, doctorManagedNodeDirs = prevFind, prevVersion, prevNode
+	})
+}
+
+func runDoctor(t *testing.T, configPath string, args ...string) (string, error) {
+	t.Helper()
+	cmd := newDoctorCmd(&rootOptions{configPath: configPath})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestDoctorReportsHealthyEnvironment(t *testing.T) {
+	nodeDir := t.TempDir()
+	stubDoctorHooks(t,
+		func(context.Context) (string, error) { return "/opt/codex/bin/codex", nil },
+		func(_ context.Context, path string) (string, error) { return "codex-cli 1.2.3", nil },
+		[]string{nodeDir, filepath.Join(nodeDir, "missing")},
+	)
+	codexDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(codexDir, "sessions"), 0o755); err != nil {
+		t.Fatalf("mkdir sessions: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	out, err := runDoctor(t, configPath, "--codex-dir", codexDir)
+	if err != nil {
+		t.Fatalf("doctor: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"OK   codex: /opt/codex/bin/codex (codex-cli 1.2.3)",
+		"OK   managed node: " + nodeDir + "\n",
+		"OK   codex dir: " + codexDir + " (0 sessions in 0 projects)",
+		"OK   config: " + configPath,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestDoctorFailsWithHintsWhenCodexMissing(t *testing.T) {
+	stubDoctorHooks(t,
+		func(context.Context) (string, error) { return "", errors.New("codex not found") },
+		func(context.Context, string) (string, error) {
+			t.Fatal("version probed without a codex path")
+			return "", nil
+		},
+		nil,
+	)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	out, err := runDoctor(t, configPath, "--codex-dir", filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Fatalf("expected doctor to fail when codex is missing:\n%s", out)
+	}
+	for _, want := range []string{
+		"FAIL codex: codex not found",
+		"hint: run `codex-proxy` once to install Codex",
+		"WARN managed node:",
+		"WARN codex dir:",
+		"OK   config: " + configPath,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestDoctorWarnsAboutInvalidConfig(t *testing.T) {
+	stubDoctorHooks(t,
+		func(context.Context) (string, error) { return "/opt/codex/bin/codex", nil },
+		func(context.Context, string) (string, error) { return "codex-cli 1.2.3", nil },
+		nil,
+	)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	doc := `{"version":1,"profiles":[{"id":"p1","port":22}],"bogus":true}`
+	if err := os.WriteFile(configPath, []byte(doc), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	out, _ := runDoctor(t, configPath, "--codex-dir", t.TempDir())
+	for _, want := range []string{
+		`WARN config: ` + configPath + `: unknown key "bogus"; profiles.0: missing host`,
+		"hint: edit the config file, or rerun with --reset-config",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestResetConfigFlagMovesBrokenConfigAside(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, []byte("{\n  \"version\": 1,\n  oops\n}"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	stubDoctorHooks(t,
+		func(context.Context) (string, error) { return "/opt/codex/bin/codex", nil },
+		func(context.Context, string) (string, error) { return "codex-cli 1.2.3", nil },
+		nil,
+	)
+
+	out, _ := runDoctor(t, configPath, "--codex-dir", t.TempDir())
+	for _, want := range []string{"FAIL config: config file " + configPath + ": parse config: line 3, column 3", "--reset-config"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	cmd := newRootCmd()
+	var stderr bytes.Buffer
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--config", configPath, "--reset-config", "doctor", "--codex-dir", t.TempDir()})
+	_ = cmd.Execute()
+	if !strings.Contains(stderr.String(), "Moved config aside to "+configPath+".bak-") {
+		t.Fatalf("expected reset message, got %q", stderr.String())
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("expected config to be moved aside, stat err=%v", err)
+	}
+	backups, _ := filepath.Glob(configPath + ".bak-*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/baaaaaaaka/codex-helper/internal/appdirs"
 	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
 	"github.com/baaaaaaaka/codex-helper/internal/config"
 )
@@ -108,7 +109,7 @@ func resolveEffectivePathsWithMode(
 	paths.CodexDirSource = source
 
 	if v := strings.TrimSpace(configPathOverride); v != "" {
-		paths.ConfigPath = v
+		paths.ConfigPath = appdirs.ExpandHome(v)
 		paths.ConfigPathSource = "override:config"
 		return paths, nil
 	}
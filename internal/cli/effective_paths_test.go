@@ -39,6 +39,46 @@ func setEffectivePathsHooksForTest(t *testing.T) {
 	})
 }
 
+func TestResolveCodexHomePathCleansSeparatorsAndDots(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cases use unix-style absolute paths")
+	}
+	workDir := filepath.FromSlash("/work")
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "/custom/path/", want: "/custom/path"},
+		{raw: " /custom/./path// ", want: "/custom/path"},
+		{raw: "/custom/x/../path", want: "/custom/path"},
+		{raw: "rel/codex/", want: "/work/rel/codex"},
+		{raw: "./rel/../codex", want: "/work/codex"},
+	}
+	for _, tt := range tests {
+		got, err := resolveCodexHomePath(tt.raw, workDir)
+		if err != nil {
+			t.Fatalf("resolveCodexHomePath(%q): %v", tt.raw, err)
+		}
+		if want := filepath.FromSlash(tt.want); got != want {
+			t.Fatalf("resolveCodexHomePath(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestResolveCodexHomePathExpandsTilde(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "home")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	got, err := resolveCodexHomePath("~/my-codex", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "my-codex"); got != want {
+		t.Fatalf("resolveCodexHomePath = %q, want %q", got, want)
+	}
+}
+
 func TestResolveEffectivePaths_KeepsAliasRootHome(t *testing.T) {
 	lockCLITestHooks(t)
 	setEffectivePathsHooksForTest(t)
@@ -1,13 +1,16 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -20,9 +23,11 @@ import (
 
 var (
 	selectSession              = tui.SelectSession
+	tuiCodexVersion            = detectTuiCodexVersion
 	runCodexSessionFunc        = runCodexSession
 	runCodexNewSessionFn       = runCodexNewSession
 	findSessionWithProjectFunc = codexhistory.FindSessionWithProject
+	readSessionFileFunc        = codexhistory.ReadSessionFile
 	ensureProxyPreferenceFunc  = ensureProxyPreference
 	ensureProfileFunc          = ensureProfile
 	persistProxyPreferenceFunc = persistProxyPreference
@@ -48,66 +53,186 @@ func newHistoryCmd(root *rootOptions) *cobra.Command {
 		newHistoryTuiCmd(root, &codexDir, &codexPath, &profileRef),
 		newHistoryListCmd(root, &codexDir),
 		newHistoryShowCmd(root, &codexDir),
+		newHistoryExportCmd(root, &codexDir),
 		newHistoryOpenCmd(root, &codexDir, &codexPath, &profileRef),
+		newHistoryPruneCacheCmd(root, &codexDir),
 	)
 	return cmd
 }
 
+const historyExcludeUsage = "Skip sessions dir paths matching this glob, relative to the sessions dir; a pattern without / matches any path element (repeatable)"
+
 func newHistoryTuiCmd(root *rootOptions, codexDir *string, codexPath *string, profileRef *string) *cobra.Command {
-	var refreshInterval time.Duration
+	var flags historyTuiFlags
 	cmd := &cobra.Command{
 		Use:   "tui",
 		Short: "Browse history in a terminal UI",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runHistoryTui(cmd, root, *profileRef, *codexDir, *codexPath, refreshInterval)
+			return runHistoryTui(cmd, root, *profileRef, *codexDir, *codexPath, flags)
 		},
 	}
-	cmd.Flags().DurationVar(&refreshInterval, "refresh-interval", defaultRefreshInterval, "Auto-refresh interval (0 to disable)")
+	addHistoryTuiFlags(cmd, &flags)
 	return cmd
 }
 
 func newHistoryListCmd(root *rootOptions, codexDir *string) *cobra.Command {
 	var pretty bool
 	var includeHelper bool
+	var fromStdin bool
+	var includeEmpty bool
+	var digest bool
+	var limit int
+	var projectOnly bool
+	var resumableOnly bool
+	var templateText string
+	var format string
+	var exclude []string
+	var follow bool
+	var interval time.Duration
 
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List discovered projects and sessions as JSON",
+		Short: "List discovered projects and sessions as JSON or CSV",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			paths, err := resolveEffectivePaths(root.configPath, *codexDir, "")
+			listFormat, err := normalizeHistoryListFormat(format)
 			if err != nil {
 				return err
 			}
-			projects, err := codexhistory.DiscoverProjects(paths.CodexDir)
-			if err != nil && len(projects) == 0 {
+			if listFormat != "json" && cmd.Flags().Changed("template") {
+				return fmt.Errorf("--template cannot be combined with --format %s", listFormat)
+			}
+			if err := codexhistory.ValidateExcludePatterns(exclude); err != nil {
 				return err
 			}
-			if !includeHelper {
-				projects = codexhistory.FilterUserVisibleProjects(projects)
+			if follow && fromStdin {
+				return fmt.Errorf("--follow cannot be combined with --stdin")
 			}
-			payload := map[string]any{"projects": projects}
-			out, err := json.MarshalIndent(payload, "", "  ")
-			if err != nil {
-				return err
+			if cmd.Flags().Changed("interval") && !follow {
+				return fmt.Errorf("--interval requires --follow")
 			}
-			if !pretty {
-				out, err = json.Marshal(payload)
+			var tmpl *template.Template
+			if cmd.Flags().Changed("template") {
+				parsed, err := parseHistoryTemplate(templateText)
 				if err != nil {
 					return err
 				}
+				tmpl = parsed
 			}
-			_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(out))
-			return nil
+			opts := codexhistory.DiscoverOptions{IncludeEmpty: includeEmpty, ContentDigest: digest, Limit: limit, Exclude: exclude}
+			if projectOnly {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				opts.ProjectPath = cwd
+			}
+			output := historyListOutput{
+				includeHelper: includeHelper,
+				resumableOnly: resumableOnly,
+				tmpl:          tmpl,
+				format:        listFormat,
+				pretty:        pretty,
+			}
+			if !follow {
+				return listHistory(root, *codexDir, fromStdin, cmd.InOrStdin(), opts, output, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			}
+			ctx, stop := withSignalContext(cmd.Context())
+			defer stop()
+			return followHistory(ctx, cmd.OutOrStdout(), interval, "codex-proxy history list", func(out io.Writer) error {
+				return listHistory(root, *codexDir, false, nil, opts, output, out, out)
+			})
 		},
 	}
 	cmd.Flags().BoolVar(&pretty, "pretty", false, "Pretty-print JSON")
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json, or csv with one row per session (project, session_id, created, modified, messages, first_prompt)")
 	cmd.Flags().BoolVar(&includeHelper, "include-helper", false, "Include codex-helper control/debug sessions")
+	cmd.Flags().BoolVar(&includeEmpty, "include-empty", false, "Include sessions without any prompt or messages")
+	cmd.Flags().BoolVar(&digest, "digest", false, "Fill ContentDigest with a SHA-256 of each session's rollout files")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Only read the N most recent session files (0 for all)")
+	cmd.Flags().BoolVar(&projectOnly, "project-only", false, "Only list sessions recorded in the current directory")
+	cmd.Flags().BoolVar(&resumableOnly, "resumable-only", false, "Only list sessions that history open can resume (skip ones without a valid session ID)")
+	cmd.Flags().StringVar(&templateText, "template", "", "Print each session with a Go text/template instead of JSON (funcs: ago, date, oneline, trunc)")
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read rollout file paths from stdin (one per line) instead of scanning the Codex dir")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, historyExcludeUsage)
+	cmd.Flags().BoolVar(&follow, "follow", false, "Clear the screen and list again every --interval until Ctrl+C, like watch")
+	cmd.Flags().DurationVar(&interval, "interval", defaultRefreshInterval, "How often --follow lists again")
 	return cmd
 }
 
+// historyListOutput is how `history list` filters and prints what it found.
+type historyListOutput struct {
+	includeHelper bool
+	resumableOnly bool
+	tmpl          *template.Template
+	format        string
+	pretty        bool
+}
+
+// listHistory runs one `history list` pass: discover, filter and print.
+func listHistory(root *rootOptions, codexDir string, fromStdin bool, stdin io.Reader, opts codexhistory.DiscoverOptions, output historyListOutput, out io.Writer, errOut io.Writer) error {
+	var projects []codexhistory.Project
+	var err error
+	if fromStdin {
+		files, readErr := readFileList(stdin)
+		if readErr != nil {
+			return fmt.Errorf("read file list: %w", readErr)
+		}
+		projects, err = codexhistory.DiscoverFromFiles(files, opts)
+	} else {
+		paths, pathsErr := resolveEffectivePaths(root.configPath, codexDir, "")
+		if pathsErr != nil {
+			return pathsErr
+		}
+		projects, err = codexhistory.DiscoverProjects(paths.CodexDir, opts)
+	}
+	if err != nil && len(projects) == 0 {
+		return err
+	}
+	if !output.includeHelper {
+		projects = codexhistory.FilterUserVisibleProjects(projects)
+	}
+	if output.resumableOnly {
+		projects = codexhistory.FilterResumableSessions(projects)
+	}
+	if opts.ProjectPath != "" && len(projects) == 0 {
+		_, _ = fmt.Fprintln(errOut, "no sessions for this project.")
+	}
+	if output.tmpl != nil {
+		return writeHistoryTemplate(out, output.tmpl, projects)
+	}
+	if output.format == "csv" {
+		return writeHistoryCSV(out, projects)
+	}
+	payload := map[string]any{"projects": projects}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	if !output.pretty {
+		data, err = json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintln(out, string(data))
+	return nil
+}
+
+// readFileList reads one path per line.
+func readFileList(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
 func newHistoryShowCmd(root *rootOptions, codexDir *string) *cobra.Command {
+	var offset int
+	var limit int
 	cmd := &cobra.Command{
 		Use:   "show <session-id>",
 		Short: "Print full history for a session",
@@ -126,26 +251,83 @@ func newHistoryShowCmd(root *rootOptions, codexDir *string) *cobra.Command {
 				return fmt.Errorf("session %q not found", sessionID)
 			}
 			txt := codexhistory.FormatSession(*session)
+			if cmd.Flags().Changed("offset") || cmd.Flags().Changed("limit") {
+				txt = codexhistory.FormatSessionRange(*session, offset, limit)
+			}
 			_, _ = fmt.Fprintln(cmd.OutOrStdout(), txt)
 			return nil
 		},
 	}
+	cmd.Flags().IntVar(&offset, "offset", 0, "Index of the first message to print (enables paging from the start of the session)")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Number of messages to print when paging (0 for all)")
 	return cmd
 }
 
+func newHistoryPruneCacheCmd(root *rootOptions, codexDir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune-cache",
+		Short: "Drop cached session data for rollout files that were deleted or changed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			paths, err := resolveEffectivePaths(root.configPath, *codexDir, "")
+			if err != nil {
+				return err
+			}
+			stats, err := codexhistory.PrunePersistentCache(cmd.Context(), paths.CodexDir)
+			if err != nil {
+				return fmt.Errorf("prune cache: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d stale cache entries, kept %d\n", stats.Removed, stats.Kept)
+			return nil
+		},
+	}
+}
+
 func newHistoryOpenCmd(root *rootOptions, codexDir *string, codexPath *string, profileRef *string) *cobra.Command {
+	var sessionFile string
+	var notify string
 	cmd := &cobra.Command{
-		Use:   "open <session-id>",
+		Use:   "open <session-id> | --file <rollout.jsonl>",
 		Short: "Open a session in Codex",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// A rollout file is parsed before any proxy setup, so a file
+			// that is not a resumable session fails without side effects.
+			var fileSession *codexhistory.Session
+			var fileProject *codexhistory.Project
+			switch {
+			case sessionFile != "" && len(args) > 0:
+				return fmt.Errorf("pass either a session ID or --file, not both")
+			case sessionFile != "":
+				var err error
+				fileSession, fileProject, err = readSessionFileFunc(sessionFile)
+				if err != nil {
+					return err
+				}
+			case len(args) == 0:
+				return fmt.Errorf("a session ID or --file is required")
+			}
+
 			ctx, stop := withSignalContext(cmd.Context())
 			defer stop()
+			ctx = withAAAGuardFromFlags(ctx, cmd)
 
 			store, paths, err := newRootStore(root, *codexDir)
 			if err != nil {
 				return err
 			}
+			if fileSession != nil {
+				// Codex resumes by ID from its own sessions dir, so the
+				// rollout has to be there for it to open this file.
+				staged, err := codexhistory.StageSessionFile(paths.CodexDir, *fileSession)
+				if err != nil {
+					return err
+				}
+				if staged != fileSession.FilePath {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Copied %s to %s so Codex can resume it\n", fileSession.FilePath, staged)
+					fileSession.FilePath = staged
+				}
+			}
 
 			useProxy, cfg, err := historyProxyPreference(ctx, store, *profileRef, cmd.ErrOrStderr())
 			if err != nil {
@@ -168,19 +350,33 @@ func newHistoryOpenCmd(root *rootOptions, codexDir *string, codexPath *string, p
 				}
 				profile = &p
 			}
-			sessionID := args[0]
-			session, project, err := findSessionWithProjectFunc(paths.CodexDir, sessionID)
+			notifyMode, err := sessionNotifyFlag(notify, cmd.Flags().Changed("notify"), resolveTUIPreferences(cfg).Notify)
 			if err != nil {
 				return err
 			}
+			session, project := fileSession, fileProject
 			if session == nil {
-				return fmt.Errorf("session %q not found", sessionID)
+				sessionID := args[0]
+				session, project, err = findSessionWithProjectFunc(paths.CodexDir, sessionID)
+				var subErr *codexhistory.SubagentSessionError
+				if errors.As(err, &subErr) {
+					return fmt.Errorf("session %q is a subagent of %s; open that session, or read the subagent with `history show %s`", sessionID, subErr.Parent.SessionID, sessionID)
+				}
+				if err != nil {
+					return err
+				}
+				if session == nil {
+					return fmt.Errorf("session %q not found", sessionID)
+				}
+				if !session.Resumable() {
+					return fmt.Errorf("session %q cannot be resumed: Codex only resumes sessions with a UUID session ID", session.SessionID)
+				}
 			}
 			proj := codexhistory.Project{}
 			if project != nil {
 				proj = *project
 			}
-			return runCodexSessionFunc(
+			return notifySessionExit(notifyMode, runCodexSessionFunc(
 				ctx,
 				root,
 				store,
@@ -192,29 +388,52 @@ func newHistoryOpenCmd(root *rootOptions, codexDir *string, codexPath *string, p
 				*codexDir,
 				useProxy,
 				cmd.ErrOrStderr(),
-			)
+			))
 		},
 	}
+	cmd.Flags().StringVar(&notify, "notify", "", sessionNotifyUsage)
+	cmd.Flags().StringVar(&sessionFile, "file", "", "Open the session recorded in this rollout file, copying it into the sessions dir when it lives elsewhere")
+	cmd.Flags().Bool("force", false, aaaForceUsage)
 	return cmd
 }
 
-func runHistoryTui(cmd *cobra.Command, root *rootOptions, profileRef string, codexDir string, codexPath string, refreshInterval time.Duration) error {
+func runHistoryTui(cmd *cobra.Command, root *rootOptions, profileRef string, codexDir string, codexPath string, flags historyTuiFlags) error {
 	ctx, stop := withSignalContext(cmd.Context())
 	defer stop()
+	ctx = withAAAGuardFromFlags(ctx, cmd)
 
 	store, paths, err := newRootStore(root, codexDir)
 	if err != nil {
 		return err
 	}
-	startSkillsDailyAutoSync(ctx, paths)
-
+	readOnly := flags.readOnly
+	exclude := flags.exclude
+	if err := codexhistory.ValidateExcludePatterns(exclude); err != nil {
+		return err
+	}
+	viewStatePath := ""
+	if flags.rememberView {
+		viewStatePath = tuiViewStateFile(store)
+	}
+	// Read-only mode must not run anything: no skills sync, no codex
+	// --version probe, no interactive proxy setup. Otherwise the codex
+	// version comes from its cache, or is detected once the TUI is up.
+	codexVersion := ""
+	var loadCodexVersion func(context.Context) string
+	if !readOnly {
+		startSkillsDailyAutoSync(ctx, paths)
+		codexVersion = readCachedTuiCodexVersion(codexPath)
+		if codexVersion == "" {
+			loadCodexVersion = func(ctx context.Context) string { return tuiCodexVersion(ctx, codexPath) }
+		}
+	}
 	for {
 		useProxy, cfg, err := historyProxyPreference(ctx, store, profileRef, cmd.ErrOrStderr())
 		if err != nil {
 			return err
 		}
 		var profile *config.Profile
-		if useProxy {
+		if useProxy && !readOnly {
 			p, cfgWithProfile, err := ensureProfileFunc(ctx, store, profileRef, true, cmd.OutOrStdout())
 			if err != nil {
 				return err
@@ -230,21 +449,126 @@ func runHistoryTui(cmd *cobra.Command, root *rootOptions, profileRef string, cod
 			profile = &p
 		}
 		agentAutoApprove := resolveAAAEnabled(cfg)
+		tuiPrefs := resolveTUIPreferences(cfg)
+		location, err := resolveDisplayLocation(tuiPrefs.TimeZone)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v; showing times as recorded\n", err)
+		}
+		newAgentAtBottom, err := resolveNewAgentAtBottom(tuiPrefs.NewAgentPosition)
+		if err != nil {
+			return err
+		}
+		statusMode := tuiPrefs.Status
+		if flags.status != "" {
+			statusMode = flags.status
+		}
+		minimalStatus, err := resolveMinimalStatus(statusMode)
+		if err != nil {
+			return err
+		}
+		themeName := tuiPrefs.Theme
+		if flags.theme != "" {
+			themeName = flags.theme
+		}
+		theme, err := resolveTheme(themeName)
+		if err != nil {
+			return err
+		}
+		notifyMode, err := sessionNotifyFlag(flags.notify, flags.notify != "", tuiPrefs.Notify)
+		if err != nil {
+			return err
+		}
+		projectFilter := strings.TrimSpace(flags.project)
+		showEmpty := flags.showEmpty
+		hideSubagents := flags.hideSubagents
+		resumeLast := tuiPrefs.ResumeLast || flags.resumeLast
+		initialPrompt := flags.prompt
+		projectOnly := flags.projectOnly
+		sessionLimit := flags.limit
+		// NO_COLOR follows https://no-color.org: any non-empty value disables color.
+		monochrome := os.Getenv("NO_COLOR") != "" || flags.noColor
 
 		defaultCwd, _ := os.Getwd()
+		projectPath := ""
+		recentSessions := tui.DefaultRecentSessions
+		if projectOnly {
+			projectPath = defaultCwd
+			recentSessions = 0
+		}
+		// An explicit --codex-path overrides the per-project binaries, so
+		// the preview only mentions them when it is not set.
+		var customCodex func(string) string
+		if strings.TrimSpace(codexPath) == "" {
+			customCodex = cfg.ProjectCodexPath
+		}
+		var initialView *tui.ViewState
+		var persistView func(tui.ViewState) error
+		if viewStatePath != "" {
+			initialView = loadTUIViewState(viewStatePath)
+			persistView = func(view tui.ViewState) error {
+				return saveTUIViewState(viewStatePath, view)
+			}
+		}
 		selection, err := selectSession(ctx, tui.Options{
 			LoadProjects: func(ctx context.Context) ([]codexhistory.Project, error) {
-				return codexhistory.DiscoverProjectsContext(ctx, paths.CodexDir)
+				return codexhistory.DiscoverProjectsContext(ctx, paths.CodexDir, codexhistory.DiscoverOptions{
+					Progress:     tui.LoadProgressReporter(ctx),
+					IncludeEmpty: tui.ShowEmptySessions(ctx),
+					Limit:        tui.SessionLimit(ctx),
+					ProjectPath:  projectPath,
+					Exclude:      exclude,
+				})
 			},
-			Version:         version,
-			ProxyEnabled:    useProxy,
-			ProxyConfigured: len(cfg.Profiles) > 0,
-			AAAEnabled:      agentAutoApprove,
-			RefreshInterval: refreshInterval,
-			DefaultCwd:      defaultCwd,
+			Version:          version,
+			CodexVersion:     codexVersion,
+			LoadCodexVersion: loadCodexVersion,
+			LaunchProfiles:   cfg.LaunchProfileNames(),
+			ProxyEnabled:     useProxy,
+			ProxyConfigured:  len(cfg.Profiles) > 0,
+			AAAEnabled:       agentAutoApprove,
+			RefreshInterval:  flags.refreshInterval,
+			DefaultCwd:       defaultCwd,
 			PersistAAA: func(enabled bool) error {
 				return persistAAAEnabled(store, enabled)
 			},
+			PersistProxy: func(enabled bool) error {
+				return persistProxyPreferenceFunc(store, enabled)
+			},
+			PaneWidthBias: tuiPrefs.PaneWidthBias,
+			PersistPaneWidthBias: func(bias int) error {
+				return persistPaneWidthBias(store, bias)
+			},
+			LayoutMode: tuiPrefs.LayoutMode,
+			PersistLayoutMode: func(mode string) error {
+				return persistLayoutMode(store, mode)
+			},
+			TabWidth:             tuiPrefs.TabWidth,
+			PreviewMaxWidth:      tuiPrefs.PreviewMaxWidth,
+			LargeSessionBytes:    int64(tuiPrefs.LargeSessionMB) << 20,
+			LargeSessionMessages: tuiPrefs.LargeSessionMessages,
+			Location:             location,
+			ResumeCounts:         loadResumeCounts(resumeCountsFile(store)),
+			ProjectCodexPath:     customCodex,
+			ShowLastReply:        tuiPrefs.ShowLastReply,
+			ShowAgentsMarker:     tuiPrefs.AgentsMarker,
+			ProjectFilter:        projectFilter,
+			ShowEmptySessions:    showEmpty,
+			HideSubagents:        hideSubagents,
+			SelectMostRecent:     resumeLast,
+			NewAgentLabel:        tuiPrefs.NewAgentLabel,
+			NewAgentAtBottom:     newAgentAtBottom,
+			MinimalStatus:        minimalStatus,
+			Theme:                theme,
+			SessionLimit:         sessionLimit,
+			Monochrome:           monochrome,
+			ReadOnly:             readOnly,
+			RecentSessions:       recentSessions,
+			ProjectOnly:          projectOnly,
+			InitialView:          initialView,
+			PersistView:          persistView,
+			PersistShowLastReply: func(show bool) error {
+				return persistShowLastReply(store, show)
+			},
 			CheckUpdate: func(ctx context.Context) update.Status {
 				return update.CheckForUpdate(ctx, update.CheckOptions{
 					InstalledVersion: version,
@@ -279,26 +603,57 @@ func runHistoryTui(cmd *cobra.Command, root *rootOptions, profileRef string, cod
 			if errors.Is(err, context.Canceled) {
 				return nil
 			}
+			if errors.Is(err, tui.ErrNotTerminal) {
+				return fmt.Errorf("%w; use `codex-proxy history list` to list sessions and `codex-proxy history open <session-id>` to resume one", err)
+			}
 			return err
 		}
-		if selection == nil {
+		if selection == nil || readOnly {
 			return nil
 		}
+		if selection.LaunchProfile != "" {
+			launch, err := findLaunchProfile(cfg, selection.LaunchProfile)
+			if err != nil {
+				return err
+			}
+			if launch.Proxy != nil {
+				selection.UseProxy = *launch.Proxy
+			}
+			if selection.UseProxy && launch.ProxyProfile != "" {
+				p, cfgWithProfile, err := ensureProfileFunc(ctx, store, launch.ProxyProfile, false, cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+				cfg = cfgWithProfile
+				profile = &p
+			}
+			ctx = withLaunchProfile(ctx, launch)
+		}
+		if selection.UseProxy && profile == nil {
+			// Proxy mode was switched on inside the TUI.
+			p, cfgWithProfile, err := ensureProfileFunc(ctx, store, profileRef, true, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			cfg = cfgWithProfile
+			profile = &p
+		}
 		if selection.Cwd != "" {
-			return runCodexNewSessionFn(
+			return notifySessionExit(notifyMode, runCodexNewSessionFn(
 				ctx,
 				root,
 				store,
 				profile,
 				cfg.Instances,
 				selection.Cwd,
+				initialPrompt,
 				codexPath,
 				codexDir,
 				selection.UseProxy,
 				cmd.ErrOrStderr(),
-			)
+			))
 		}
-		return runCodexSessionFunc(
+		return notifySessionExit(notifyMode, runCodexSessionFunc(
 			ctx,
 			root,
 			store,
@@ -310,8 +665,96 @@ func runHistoryTui(cmd *cobra.Command, root *rootOptions, profileRef string, cod
 			codexDir,
 			selection.UseProxy,
 			cmd.ErrOrStderr(),
-		)
+		))
+	}
+}
+
+// tuiCodexVersionCacheFile remembers the last detected codex version, next to
+// the cached codex path, so the TUI can show it without running the binary.
+const tuiCodexVersionCacheFile = "codex_version.json"
+
+// cachedTuiCodexVersion is what tuiCodexVersionCacheFile holds: the version
+// of the binary at Path while it keeps the recorded size and mtime.
+type cachedTuiCodexVersion struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Version string    `json:"version"`
+}
+
+func tuiCodexVersionCachePath() string {
+	cacheFile := cachedCodexPathFile()
+	if cacheFile == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(cacheFile), tuiCodexVersionCacheFile)
+}
+
+// readCachedTuiCodexVersion returns the cached version of the binary a
+// session would launch: codexPath, or else the cached codex path. It never
+// runs anything, and returns "" when the binary changed since it was cached.
+func readCachedTuiCodexVersion(codexPath string) string {
+	path := strings.TrimSpace(codexPath)
+	if path == "" {
+		path = readCachedCodexPath()
+	}
+	cacheFile := tuiCodexVersionCachePath()
+	if path == "" || cacheFile == "" {
+		return ""
+	}
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return ""
+	}
+	var cached cachedTuiCodexVersion
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Path != normalizeExecutablePath(path) {
+		return ""
+	}
+	info, err := os.Stat(cached.Path)
+	if err != nil || info.Size() != cached.Size || !info.ModTime().Equal(cached.ModTime) {
+		return ""
+	}
+	return cached.Version
+}
+
+func writeCachedTuiCodexVersion(codexPath string, version string) {
+	path := normalizeExecutablePath(codexPath)
+	cacheFile := tuiCodexVersionCachePath()
+	if path == "" || version == "" || cacheFile == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cachedTuiCodexVersion{Path: path, Size: info.Size(), ModTime: info.ModTime(), Version: version})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o755); err != nil {
+		return
+	}
+	_ = writeFileAtomically(cacheFile, append(data, '\n'), 0o600)
+}
+
+// detectTuiCodexVersion resolves the codex binary a session would launch and
+// returns its version for the TUI status bar, or "" when none is usable.
+func detectTuiCodexVersion(ctx context.Context, codexPath string) string {
+	path := strings.TrimSpace(codexPath)
+	if path == "" {
+		found, err := findInstalledCodex(ctx)
+		if err != nil {
+			return ""
+		}
+		path = found
+	}
+	output, err := codexVersionString(ctx, path)
+	if err != nil {
+		return ""
 	}
+	version := codexVersionNumber(output)
+	writeCachedTuiCodexVersion(path, version)
+	return version
 }
 
 func historyProxyPreference(ctx context.Context, store *config.Store, profileRef string, out io.Writer) (bool, config.Config, error) {
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+// historyListFormats are the values `history list --format` accepts.
+var historyListFormats = []string{"json", "csv"}
+
+// historyListColumn is one column of the per-session `history list` output.
+type historyListColumn struct {
+	name  string
+	value func(codexhistory.Project, codexhistory.Session) string
+}
+
+// historyListColumns is the column set of the flat `history list` formats,
+// one row per session.
+var historyListColumns = []historyListColumn{
+	{"project", func(p codexhistory.Project, _ codexhistory.Session) string {
+		if strings.TrimSpace(p.Path) != "" {
+			return p.Path
+		}
+		return p.Key
+	}},
+	{"session_id", func(_ codexhistory.Project, s codexhistory.Session) string { return s.SessionID }},
+	{"created", func(_ codexhistory.Project, s codexhistory.Session) string { return historyListTime(s.CreatedAt) }},
+	{"modified", func(_ codexhistory.Project, s codexhistory.Session) string { return historyListTime(s.ModifiedAt) }},
+	{"messages", func(_ codexhistory.Project, s codexhistory.Session) string { return strconv.Itoa(s.MessageCount) }},
+	{"first_prompt", func(_ codexhistory.Project, s codexhistory.Session) string { return s.FirstPrompt }},
+}
+
+func historyListTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// normalizeHistoryListFormat validates a --format value before anything is
+// scanned.
+func normalizeHistoryListFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	for _, known := range historyListFormats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --format %q: want one of %s", format, strings.Join(historyListFormats, ", "))
+}
+
+// writeHistoryCSV writes a header row and one row per session. Fields with
+// commas, quotes or newlines, which first prompts often have, are quoted.
+func writeHistoryCSV(w io.Writer, projects []codexhistory.Project) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, len(historyListColumns))
+	for i, col := range historyListColumns {
+		record[i] = col.name
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	for _, project := range projects {
+		for _, session := range project.Sessions {
+			for i, col := range historyListColumns {
+				record[i] = col.value(project, session)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
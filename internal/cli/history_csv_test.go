@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+func TestHistoryListCmdWritesCSV(t *testing.T) {
+	lockCLITestHooks(t)
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", projectDir, `fix a, b and \"c\"`)
+
+	cmd := newHistoryListCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+	cmd.SetContext(context.Background())
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "csv"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history list --format csv: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV output: %v\n%s", err, out.String())
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected a header and one row, got %q", records)
+	}
+	if got := strings.Join(records[0], ","); got != "project,session_id,created,modified,messages,first_prompt" {
+		t.Fatalf("header = %q", got)
+	}
+	row := records[1]
+	if row[1] != "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee" || row[5] != `fix a, b and "c"` {
+		t.Fatalf("row = %q", row)
+	}
+	if !strings.Contains(out.String(), `"fix a, b and ""c"""`) {
+		t.Fatalf("first prompt was not quoted:\n%s", out.String())
+	}
+}
+
+func TestWriteHistoryCSVQuotesMultilinePrompts(t *testing.T) {
+	var out strings.Builder
+	projects := []codexhistory.Project{{Key: "/tmp/one", Path: "/tmp/one", Sessions: []codexhistory.Session{
+		{SessionID: "sess-1", FirstPrompt: "line one\nline two"},
+	}}}
+	if err := writeHistoryCSV(&out, projects); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "\"line one\nline two\"") {
+		t.Fatalf("multi-line prompt was not quoted:\n%s", out.String())
+	}
+}
+
+func TestHistoryListCmdRejectsBadFormats(t *testing.T) {
+	missingDir := filepath.Join(t.TempDir(), "no-codex")
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--format", "xml"}, want: `invalid --format "xml"`},
+		{args: []string{"--format", "csv", "--template", "{{.SessionID}}"}, want: "cannot be combined"},
+	} {
+		cmd := newHistoryListCmd(&rootOptions{}, &missingDir)
+		cmd.SetContext(context.Background())
+		cmd.SetOut(&strings.Builder{})
+		cmd.SetErr(&strings.Builder{})
+		cmd.SetArgs(tc.args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+}
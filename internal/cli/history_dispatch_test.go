@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -56,7 +57,7 @@ func TestRunHistoryTuiSkillsMenuReturnsToTuiLoop(t *testing.T) {
 	cmd.SetIn(strings.NewReader("6\n"))
 	var out strings.Builder
 	cmd.SetOut(&out)
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", codexDir, "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", codexDir, "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 	if selectCalls != 2 {
@@ -105,7 +106,7 @@ func TestRunHistoryTuiLoadsAndPersistsAAA(t *testing.T) {
 	}
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", t.TempDir(), "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", t.TempDir(), "", historyTuiFlags{}); err != nil {
 		t.Fatal(err)
 	}
 	cfg, err := store.Load()
@@ -117,6 +118,459 @@ func TestRunHistoryTuiLoadsAndPersistsAAA(t *testing.T) {
 	}
 }
 
+func TestRunHistoryTuiPassesProjectFilter(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	var got string
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		got = opts.ProjectFilter
+		return nil, nil
+	}
+	codexDir := t.TempDir()
+	cmd := newHistoryTuiCmd(&rootOptions{configPath: cfgPath}, &codexDir, new(string), new(string))
+	cmd.SetArgs([]string{"--project", " codex-helper "})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got != "codex-helper" {
+		t.Fatalf("ProjectFilter = %q, want codex-helper", got)
+	}
+}
+
+func TestRunHistoryTuiPassesExcludeToDiscovery(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", projectDir, "current prompt")
+	archived := writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", projectDir, "archived prompt")
+	archiveDir := filepath.Join(codexDir, "sessions", "archive")
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(archived, filepath.Join(archiveDir, filepath.Base(archived))); err != nil {
+		t.Fatal(err)
+	}
+	var prompts []string
+	selectSession = func(ctx context.Context, opts tui.Options) (*tui.Selection, error) {
+		projects, err := opts.LoadProjects(ctx)
+		if err != nil {
+			t.Fatalf("LoadProjects: %v", err)
+		}
+		for _, project := range projects {
+			for _, session := range project.Sessions {
+				prompts = append(prompts, session.FirstPrompt)
+			}
+		}
+		return nil, nil
+	}
+	root := &rootOptions{configPath: cfgPath}
+	for name, cmd := range map[string]*cobra.Command{
+		"history tui": newHistoryTuiCmd(root, &codexDir, new(string), new(string)),
+		"tui":         newTuiCmd(root),
+	} {
+		prompts = nil
+		args := []string{"--exclude", "archive"}
+		if name == "tui" {
+			args = append(args, "--codex-dir", codexDir)
+		}
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(prompts) != 1 || prompts[0] != "current prompt" {
+			t.Fatalf("%s: loaded prompts = %q, want only the one outside archive/", name, prompts)
+		}
+	}
+}
+
+func TestRunHistoryTuiResumeLast(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+	})
+	var prefs *config.TUIPreferences
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion, TUI: prefs}, nil
+	}
+	var got bool
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		got = opts.SelectMostRecent
+		return nil, nil
+	}
+	for _, tc := range []struct {
+		name   string
+		tui    bool
+		flag   bool
+		config bool
+		want   bool
+	}{
+		{name: "default"},
+		{name: "flag", flag: true, want: true},
+		{name: "config", config: true, want: true},
+		{name: "tui flag", tui: true, flag: true, want: true},
+	} {
+		prefs = &config.TUIPreferences{ResumeLast: tc.config}
+		root := &rootOptions{configPath: cfgPath}
+		codexDir := t.TempDir()
+		cmd := newHistoryTuiCmd(root, &codexDir, new(string), new(string))
+		var args []string
+		if tc.tui {
+			cmd = newTuiCmd(root)
+			args = append(args, "--codex-dir", codexDir)
+		}
+		if tc.flag {
+			args = append(args, "--resume-last")
+		}
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: SelectMostRecent = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRunHistoryTuiStatusFlag(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+	})
+	var prefs *config.TUIPreferences
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion, TUI: prefs}, nil
+	}
+	var got bool
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		got = opts.MinimalStatus
+		return nil, nil
+	}
+	for _, tc := range []struct {
+		name   string
+		tui    bool
+		flag   string
+		config string
+		want   bool
+	}{
+		{name: "history tui minimal", flag: "minimal", want: true},
+		{name: "tui minimal", tui: true, flag: "minimal", want: true},
+		{name: "tui full over config", tui: true, flag: "full", config: "minimal"},
+		{name: "tui config", tui: true, config: "minimal", want: true},
+	} {
+		prefs = &config.TUIPreferences{Status: tc.config}
+		root := &rootOptions{configPath: cfgPath}
+		codexDir := t.TempDir()
+		cmd := newHistoryTuiCmd(root, &codexDir, new(string), new(string))
+		var args []string
+		if tc.tui {
+			cmd = newTuiCmd(root)
+			args = append(args, "--codex-dir", codexDir)
+		}
+		if tc.flag != "" {
+			args = append(args, "--status", tc.flag)
+		}
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: MinimalStatus = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTuiCmdNotifyFlag(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	prevEnsureProxy := ensureProxyPreferenceFunc
+	prevSelect := selectSession
+	prevRunNew := runCodexNewSessionFn
+	prevStdout, prevIsTerminal := sessionNotifyStdout, sessionNotifyIsTerminal
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = prevEnsureProxy
+		selectSession = prevSelect
+		runCodexNewSessionFn = prevRunNew
+		sessionNotifyStdout, sessionNotifyIsTerminal = prevStdout, prevIsTerminal
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	selectSession = func(context.Context, tui.Options) (*tui.Selection, error) {
+		return &tui.Selection{Cwd: t.TempDir()}, nil
+	}
+	runCodexNewSessionFn = func(context.Context, *rootOptions, *config.Store, *config.Profile, []config.Instance, string, string, string, string, bool, io.Writer) error {
+		return nil
+	}
+	var out strings.Builder
+	sessionNotifyStdout = &out
+	sessionNotifyIsTerminal = func() bool { return true }
+
+	cmd := newTuiCmd(&rootOptions{configPath: cfgPath})
+	cmd.SetArgs([]string{"--codex-dir", t.TempDir(), "--codex-path", "codex-bin", "--notify", "bell"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\a" {
+		t.Fatalf("bell output = %q, want a bell after the session exits", out.String())
+	}
+
+	cmd = newTuiCmd(&rootOptions{configPath: cfgPath})
+	cmd.SetArgs([]string{"--codex-dir", t.TempDir(), "--notify", "email"})
+	if err := cmd.ExecuteContext(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid notify mode") {
+		t.Fatalf("expected an invalid --notify to be rejected, got %v", err)
+	}
+}
+
+func TestRunHistoryTuiWarnsOnInvalidTimeZone(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion, TUI: &config.TUIPreferences{TimeZone: "Not/AZone"}}, nil
+	}
+	called := false
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		called = true
+		if opts.Location != nil {
+			t.Fatalf("Location = %v, want nil after an invalid zone", opts.Location)
+		}
+		return nil, nil
+	}
+	root := &rootOptions{configPath: cfgPath}
+	cmd := newHistoryTuiCmd(root, new(string), new(string), new(string))
+	cmd.SetContext(context.Background())
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+	if err := runHistoryTui(cmd, root, "", t.TempDir(), "", historyTuiFlags{}); err != nil {
+		t.Fatalf("an invalid zone should not stop the TUI: %v", err)
+	}
+	if !called {
+		t.Fatal("expected the TUI to start")
+	}
+	if !strings.Contains(stderr.String(), `warning: invalid tui.timeZone "Not/AZone"`) {
+		t.Fatalf("stderr = %q, want a time zone warning", stderr.String())
+	}
+}
+
+func TestRunHistoryTuiRememberView(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	var got []*tui.ViewState
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		got = append(got, opts.InitialView)
+		if opts.PersistView != nil {
+			if err := opts.PersistView(tui.ViewState{Project: "/tmp/one", Session: "sess-1", Focus: "sessions"}); err != nil {
+				t.Fatalf("PersistView: %v", err)
+			}
+		}
+		return nil, nil
+	}
+	run := func(remember bool) {
+		t.Helper()
+		root := &rootOptions{configPath: cfgPath}
+		codexDir := t.TempDir()
+		cmd := newHistoryTuiCmd(root, &codexDir, new(string), new(string))
+		cmd.SetArgs(nil)
+		if remember {
+			cmd.SetArgs([]string{"--remember-view"})
+		}
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	statePath := filepath.Join(filepath.Dir(cfgPath), "tui-view.json")
+
+	run(false)
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Fatalf("view saved without --remember-view: %v", err)
+	}
+	run(true)
+	run(true)
+	if len(got) != 3 || got[0] != nil || got[1] != nil {
+		t.Fatalf("initial views = %v, want none before the first save", got)
+	}
+	if got[2] == nil || got[2].Session != "sess-1" || got[2].Focus != "sessions" {
+		t.Fatalf("restored view = %+v", got[2])
+	}
+
+	if err := os.WriteFile(statePath, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	run(true)
+	if got[3] != nil {
+		t.Fatalf("corrupt view file restored as %+v", got[3])
+	}
+}
+
+func TestRunHistoryTuiPassesCodexVersion(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	previousVersion := tuiCodexVersion
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+		tuiCodexVersion = previousVersion
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	var gotPath string
+	tuiCodexVersion = func(_ context.Context, codexPath string) string {
+		gotPath = codexPath
+		return "0.46.0"
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	var gotOpts tui.Options
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		gotOpts = opts
+		return nil, nil
+	}
+	run := func(codexPath string) {
+		t.Helper()
+		cmd := newHistoryTuiCmd(&rootOptions{configPath: cfgPath}, new(string), new(string), new(string))
+		cmd.SetContext(context.Background())
+		if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", t.TempDir(), codexPath, historyTuiFlags{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nothing cached: the TUI starts without running codex and detects
+	// the version in the background.
+	run("/opt/codex")
+	if gotOpts.CodexVersion != "" || gotOpts.LoadCodexVersion == nil || gotPath != "" {
+		t.Fatalf("startup should not detect the version: CodexVersion=%q path=%q", gotOpts.CodexVersion, gotPath)
+	}
+	if got := gotOpts.LoadCodexVersion(context.Background()); got != "0.46.0" || gotPath != "/opt/codex" {
+		t.Fatalf("LoadCodexVersion = %q from path %q, want 0.46.0 from /opt/codex", got, gotPath)
+	}
+
+	// A detected version is cached for the next start until the binary
+	// changes.
+	codexPath := writeFakeCodexVersionCommand(t)
+	tuiCodexVersion = detectTuiCodexVersion
+	run(codexPath)
+	if got := gotOpts.LoadCodexVersion(context.Background()); got != "0.0.0" {
+		t.Fatalf("detected version = %q", got)
+	}
+	tuiCodexVersion = func(context.Context, string) string {
+		t.Fatal("a cached version should not run codex")
+		return ""
+	}
+	run(codexPath)
+	if gotOpts.CodexVersion != "0.0.0" || gotOpts.LoadCodexVersion != nil {
+		t.Fatalf("cached start: CodexVersion=%q, background load set=%v", gotOpts.CodexVersion, gotOpts.LoadCodexVersion != nil)
+	}
+	if err := os.WriteFile(codexPath, []byte("#!/bin/sh\necho 'codex 0.1.0'\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	run(codexPath)
+	if gotOpts.CodexVersion != "" || gotOpts.LoadCodexVersion == nil {
+		t.Fatalf("a changed binary should be detected again: CodexVersion=%q", gotOpts.CodexVersion)
+	}
+}
+
+func TestRunHistoryTuiHonorsNoColor(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	previousVersion := tuiCodexVersion
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+		tuiCodexVersion = previousVersion
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	tuiCodexVersion = func(context.Context, string) string { return "" }
+	var got bool
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		got = opts.Monochrome
+		return nil, nil
+	}
+	run := func(noColorEnv string, flag bool) bool {
+		t.Helper()
+		t.Setenv("NO_COLOR", noColorEnv)
+		codexDir := t.TempDir()
+		cmd := newHistoryTuiCmd(&rootOptions{configPath: cfgPath}, &codexDir, new(string), new(string))
+		cmd.SetArgs(nil)
+		if flag {
+			cmd.SetArgs([]string{"--no-color"})
+		}
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if run("", false) {
+		t.Fatal("Monochrome should be off by default")
+	}
+	if !run("1", false) {
+		t.Fatal("NO_COLOR should turn Monochrome on")
+	}
+	if !run("", true) {
+		t.Fatal("--no-color should turn Monochrome on")
+	}
+}
+
+func TestCodexVersionNumber(t *testing.T) {
+	for output, want := range map[string]string{
+		"codex-cli 0.46.0":        "0.46.0",
+		"codex-cli v0.47.1-alpha": "0.47.1-alpha",
+		"0.45.0":                  "0.45.0",
+		"weird":                   "weird",
+	} {
+		if got := codexVersionNumber(output); got != want {
+			t.Fatalf("codexVersionNumber(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
 func TestRunHistoryTuiStartsDailyAutoSyncWithoutBlockingSelection(t *testing.T) {
 	lockCLITestHooks(t)
 	setEffectivePathsHooksForTest(t)
@@ -185,7 +639,7 @@ func TestRunHistoryTuiStartsDailyAutoSyncWithoutBlockingSelection(t *testing.T)
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", codexDir, "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", codexDir, "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 }
@@ -201,11 +655,13 @@ func TestRunHistoryTuiOpensNewSessionSelection(t *testing.T) {
 	lockCLITestHooks(t)
 	cfgPath := filepath.Join(t.TempDir(), "config.json")
 	prevEnsureProxy := ensureProxyPreferenceFunc
+	prevEnsureProfile := ensureProfileFunc
 	prevSelect := selectSession
 	prevRunNew := runCodexNewSessionFn
 	prevRunSession := runCodexSessionFunc
 	t.Cleanup(func() {
 		ensureProxyPreferenceFunc = prevEnsureProxy
+		ensureProfileFunc = prevEnsureProfile
 		selectSession = prevSelect
 		runCodexNewSessionFn = prevRunNew
 		runCodexSessionFunc = prevRunSession
@@ -214,6 +670,11 @@ func TestRunHistoryTuiOpensNewSessionSelection(t *testing.T) {
 	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
 		return false, config.Config{Version: config.CurrentVersion}, nil
 	}
+	// Proxy was off when the TUI opened and switched on inside it, so the
+	// profile is resolved only after the selection.
+	ensureProfileFunc = func(context.Context, *config.Store, string, bool, io.Writer) (config.Profile, config.Config, error) {
+		return config.Profile{ID: "p1", Name: "p1"}, config.Config{Version: config.CurrentVersion}, nil
+	}
 	selectSession = func(_ context.Context, _ tui.Options) (*tui.Selection, error) {
 		return &tui.Selection{
 			Cwd:      "/tmp/project",
@@ -242,9 +703,10 @@ func TestRunHistoryTuiOpensNewSessionSelection(t *testing.T) {
 		_ context.Context,
 		_ *rootOptions,
 		_ *config.Store,
-		_ *config.Profile,
+		profile *config.Profile,
 		_ []config.Instance,
 		cwd string,
+		prompt string,
 		codexPath string,
 		codexDir string,
 		useProxy bool,
@@ -254,15 +716,21 @@ func TestRunHistoryTuiOpensNewSessionSelection(t *testing.T) {
 		if cwd != "/tmp/project" || codexPath != "codex-bin" || codexDir != "codex-home" {
 			t.Fatalf("unexpected selection args: cwd=%q codexPath=%q codexDir=%q", cwd, codexPath, codexDir)
 		}
+		if prompt != "fix the build" {
+			t.Fatalf("prompt = %q, want the --prompt value", prompt)
+		}
 		if !useProxy {
 			t.Fatalf("expected proxy selection to propagate")
 		}
+		if profile == nil || profile.ID != "p1" {
+			t.Fatalf("profile = %#v, want the resolved proxy profile", profile)
+		}
 		return nil
 	}
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "codex-home", "codex-bin", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "codex-home", "codex-bin", historyTuiFlags{prompt: "fix the build"}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 	if !called {
@@ -270,6 +738,63 @@ func TestRunHistoryTuiOpensNewSessionSelection(t *testing.T) {
 	}
 }
 
+func TestRunHistoryTuiReadOnlyNeverLaunchesCodex(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	prevEnsureProxy := ensureProxyPreferenceFunc
+	prevEnsureProfile := ensureProfileFunc
+	prevVersion := tuiCodexVersion
+	prevSelect := selectSession
+	prevRunNew := runCodexNewSessionFn
+	prevRunSession := runCodexSessionFunc
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = prevEnsureProxy
+		ensureProfileFunc = prevEnsureProfile
+		tuiCodexVersion = prevVersion
+		selectSession = prevSelect
+		runCodexNewSessionFn = prevRunNew
+		runCodexSessionFunc = prevRunSession
+	})
+
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return true, config.Config{Version: config.CurrentVersion}, nil
+	}
+	ensureProfileFunc = func(context.Context, *config.Store, string, bool, io.Writer) (config.Profile, config.Config, error) {
+		t.Fatal("read-only mode must not set up a proxy profile")
+		return config.Profile{}, config.Config{}, nil
+	}
+	tuiCodexVersion = func(context.Context, string) string {
+		t.Fatal("read-only mode must not run codex --version")
+		return ""
+	}
+	var gotOpts tui.Options
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		gotOpts = opts
+		return &tui.Selection{Cwd: "/tmp/project"}, nil
+	}
+	runCodexSessionFunc = func(context.Context, *rootOptions, *config.Store, *config.Profile, []config.Instance, codexhistory.Session, codexhistory.Project, string, string, bool, io.Writer) error {
+		t.Fatal("read-only mode must not resume a session")
+		return nil
+	}
+	runCodexNewSessionFn = func(context.Context, *rootOptions, *config.Store, *config.Profile, []config.Instance, string, string, string, string, bool, io.Writer) error {
+		t.Fatal("read-only mode must not start a session")
+		return nil
+	}
+
+	codexDir, codexPath := "codex-home", "codex-bin"
+	cmd := newHistoryTuiCmd(&rootOptions{configPath: cfgPath}, &codexDir, &codexPath, new(string))
+	cmd.SetArgs([]string{"--read-only"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("runHistoryTui error: %v", err)
+	}
+	if !gotOpts.ReadOnly {
+		t.Fatal("expected tui.Options.ReadOnly to be set")
+	}
+	if gotOpts.LoadCodexVersion != nil {
+		t.Fatal("read-only mode must not detect the codex version in the background")
+	}
+}
+
 func TestRunHistoryTuiOpensExistingSessionSelection(t *testing.T) {
 	lockCLITestHooks(t)
 	cfgPath := filepath.Join(t.TempDir(), "config.json")
@@ -304,6 +829,7 @@ func TestRunHistoryTuiOpensExistingSessionSelection(t *testing.T) {
 		string,
 		string,
 		string,
+		string,
 		bool,
 		io.Writer,
 	) error {
@@ -340,7 +866,7 @@ func TestRunHistoryTuiOpensExistingSessionSelection(t *testing.T) {
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "codex-home", "codex-bin", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "codex-home", "codex-bin", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 	if !called {
@@ -363,7 +889,13 @@ func TestHistoryOpenReturnsSessionNotFound(t *testing.T) {
 	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
 		return false, config.Config{Version: config.CurrentVersion}, nil
 	}
-	findSessionWithProjectFunc = func(string, string) (*codexhistory.Session, *codexhistory.Project, error) {
+	findSessionWithProjectFunc = func(_ string, id string) (*codexhistory.Session, *codexhistory.Project, error) {
+		if id == "child" {
+			return nil, nil, &codexhistory.SubagentSessionError{
+				Subagent: codexhistory.SubagentSession{SessionID: id},
+				Parent:   codexhistory.Session{SessionID: "parent"},
+			}
+		}
 		return nil, nil, nil
 	}
 	runCodexSessionFunc = func(
@@ -398,6 +930,302 @@ func TestHistoryOpenReturnsSessionNotFound(t *testing.T) {
 	if !errors.Is(err, context.Canceled) && err.Error() != `session "missing" not found` {
 		t.Fatalf("unexpected error: %v", err)
 	}
+
+	cmd = newHistoryOpenCmd(root, &codexDir, &codexPath, &profileRef)
+	cmd.SetContext(context.Background())
+	cmd.SetArgs([]string{"child"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "subagent of parent") {
+		t.Fatalf("subagent ID error = %v, want it to name the parent session", err)
+	}
+}
+
+func TestHistoryOpenRefusesNonResumableSession(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	prevEnsureProxy := ensureProxyPreferenceFunc
+	prevFind := findSessionWithProjectFunc
+	prevRun := runCodexSessionFunc
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = prevEnsureProxy
+		findSessionWithProjectFunc = prevFind
+		runCodexSessionFunc = prevRun
+	})
+
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	findSessionWithProjectFunc = func(string, string) (*codexhistory.Session, *codexhistory.Project, error) {
+		return &codexhistory.Session{SessionID: "legacy-id"}, &codexhistory.Project{Path: t.TempDir()}, nil
+	}
+	runCodexSessionFunc = func(
+		context.Context,
+		*rootOptions,
+		*config.Store,
+		*config.Profile,
+		[]config.Instance,
+		codexhistory.Session,
+		codexhistory.Project,
+		string,
+		string,
+		bool,
+		io.Writer,
+	) error {
+		t.Fatal("expected history open to refuse a non-resumable session")
+		return nil
+	}
+
+	codexDir := ""
+	codexPath := ""
+	profileRef := ""
+	cmd := newHistoryOpenCmd(&rootOptions{configPath: cfgPath}, &codexDir, &codexPath, &profileRef)
+	cmd.SetContext(context.Background())
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"legacy-id"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), `session "legacy-id" cannot be resumed`) {
+		t.Fatalf("history open error = %v, want a not-resumable error", err)
+	}
+}
+
+func TestHistoryOpenFileLaunchesCopiedRollout(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	prevEnsureProxy := ensureProxyPreferenceFunc
+	prevFind := findSessionWithProjectFunc
+	prevRun := runCodexSessionFunc
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = prevEnsureProxy
+		findSessionWithProjectFunc = prevFind
+		runCodexSessionFunc = prevRun
+	})
+
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	findSessionWithProjectFunc = func(string, string) (*codexhistory.Session, *codexhistory.Project, error) {
+		t.Fatal("--file must not search the sessions dir")
+		return nil, nil, nil
+	}
+	var launched codexhistory.Session
+	var launchedProject codexhistory.Project
+	runCodexSessionFunc = func(
+		_ context.Context,
+		_ *rootOptions,
+		_ *config.Store,
+		_ *config.Profile,
+		_ []config.Instance,
+		session codexhistory.Session,
+		project codexhistory.Project,
+		_ string,
+		_ string,
+		_ bool,
+		_ io.Writer,
+	) error {
+		launched = session
+		launchedProject = project
+		return nil
+	}
+
+	sessionID := "aaaaaaaa-bbbb-cccc-dddd-000000000042"
+	projectDir := t.TempDir()
+	original := writeCodexSessionFile(t, setupCodexHistoryDir(t), sessionID, projectDir, "copied elsewhere")
+	data, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := filepath.Join(t.TempDir(), "backup.jsonl")
+	if err := os.WriteFile(copied, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &rootOptions{configPath: cfgPath}
+	codexDir := setupCodexHistoryDir(t)
+	codexPath := ""
+	profileRef := ""
+	cmd := newHistoryOpenCmd(root, &codexDir, &codexPath, &profileRef)
+	cmd.SetContext(context.Background())
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--file", copied})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("history open --file: %v", err)
+	}
+	staged := filepath.Join(codexDir, "sessions", "2026", "03", "10", "rollout-2026-03-10T10-00-00-"+sessionID+".jsonl")
+	if launched.SessionID != sessionID || launched.FilePath != staged {
+		t.Fatalf("launched session = %#v, want file %s", launched, staged)
+	}
+	if got, err := os.ReadFile(staged); err != nil || string(got) != string(data) {
+		t.Fatalf("staged copy = %q, %v", got, err)
+	}
+	if launchedProject.Path != projectDir {
+		t.Fatalf("launched project path = %q, want %q", launchedProject.Path, projectDir)
+	}
+
+	notRollout := filepath.Join(t.TempDir(), "notes.jsonl")
+	if err := os.WriteFile(notRollout, []byte(`{"hello":"world"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	launched = codexhistory.Session{}
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--file", notRollout}, want: "no session ID"},
+		{args: []string{"--file", filepath.Join(t.TempDir(), "missing.jsonl")}, want: "missing.jsonl"},
+		{args: []string{"--file", copied, sessionID}, want: "not both"},
+		{args: nil, want: "is required"},
+	} {
+		cmd := newHistoryOpenCmd(root, &codexDir, &codexPath, &profileRef)
+		cmd.SetContext(context.Background())
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(tc.args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+	if launched.SessionID != "" {
+		t.Fatalf("expected no launch for invalid input, got %#v", launched)
+	}
+}
+
+func TestHistoryOpenFileResumesStagedRolloutInCodex(t *testing.T) {
+	lockCLITestHooks(t)
+	prevEnsureProxy := ensureProxyPreferenceFunc
+	t.Cleanup(func() { ensureProxyPreferenceFunc = prevEnsureProxy })
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	fixture := writeCodexTUIBrokerFixture(t)
+	store := newCodexOpenTestStore(t)
+
+	sessionID := "aaaaaaaa-bbbb-cccc-dddd-000000000043"
+	backup := filepath.Join(t.TempDir(), "backup.jsonl")
+	data, err := os.ReadFile(writeCodexSessionFile(t, setupCodexHistoryDir(t), sessionID, fixture.workDir, "from a backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backup, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	codexDir := setupCodexHistoryDir(t)
+	codexPath := fixture.path
+	profileRef := ""
+	open := func() error {
+		cmd := newHistoryOpenCmd(&rootOptions{configPath: store.Path()}, &codexDir, &codexPath, &profileRef)
+		cmd.SetContext(context.Background())
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--file", backup})
+		return cmd.Execute()
+	}
+	if err := open(); err != nil {
+		t.Fatalf("history open --file: %v", err)
+	}
+	tuiArgs := readArgLines(t, fixture.tuiArgs)
+	if len(tuiArgs) != 8 || tuiArgs[6] != "resume" || tuiArgs[7] != sessionID {
+		t.Fatalf("TUI args = %#v", tuiArgs)
+	}
+	staged := filepath.Join(codexDir, "sessions", "2026", "03", "10", "rollout-2026-03-10T10-00-00-"+sessionID+".jsonl")
+	if got, err := os.ReadFile(staged); err != nil || string(got) != string(data) {
+		t.Fatalf("Codex would not find the rollout it was asked to resume: %q, %v", got, err)
+	}
+
+	// Opening the same backup again reuses the staged copy.
+	if err := open(); err != nil {
+		t.Fatalf("second history open --file: %v", err)
+	}
+
+	// A different rollout under the same ID would be the one Codex resumes.
+	if err := os.WriteFile(staged, append(data, []byte(`{"type":"event_msg","payload":{"type":"agent_message","message":"diverged"}}`+"\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(fixture.tuiArgs); err != nil {
+		t.Fatal(err)
+	}
+	if err := open(); err == nil || !strings.Contains(err.Error(), "already recorded in "+staged) {
+		t.Fatalf("expected a conflicting rollout to be refused, got %v", err)
+	}
+	if _, err := os.Stat(fixture.tuiArgs); !os.IsNotExist(err) {
+		t.Fatalf("codex should not run for a conflicting rollout: %v", err)
+	}
+}
+
+func TestHistoryListCmdProjectOnly(t *testing.T) {
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-000000000001", projectDir, "in this project")
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-000000000002", t.TempDir(), "somewhere else")
+	t.Chdir(projectDir)
+
+	run := func() (string, string) {
+		t.Helper()
+		cmd := newHistoryListCmd(&rootOptions{}, &codexDir)
+		cmd.SetContext(context.Background())
+		var out, errOut strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(&errOut)
+		cmd.SetArgs([]string{"--project-only"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute history list --project-only: %v", err)
+		}
+		return out.String(), errOut.String()
+	}
+
+	out, _ := run()
+	var payload struct {
+		Projects []codexhistory.Project `json:"projects"`
+	}
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("unmarshal history list output: %v\noutput: %s", err, out)
+	}
+	if len(payload.Projects) != 1 || len(payload.Projects[0].Sessions) != 1 || payload.Projects[0].Sessions[0].FirstPrompt != "in this project" {
+		t.Fatalf("unexpected --project-only payload: %+v", payload)
+	}
+
+	t.Chdir(t.TempDir())
+	if _, errOut := run(); !strings.Contains(errOut, "no sessions for this project.") {
+		t.Fatalf("stderr = %q, want the empty project note", errOut)
+	}
+}
+
+func TestHistoryListCmdResumableOnly(t *testing.T) {
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-000000000003", projectDir, "resumable")
+	writeCodexSessionFile(t, codexDir, "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz", projectDir, "broken id")
+
+	run := func(args ...string) []codexhistory.Session {
+		t.Helper()
+		cmd := newHistoryListCmd(&rootOptions{}, &codexDir)
+		cmd.SetContext(context.Background())
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute history list %v: %v", args, err)
+		}
+		var payload struct {
+			Projects []codexhistory.Project `json:"projects"`
+		}
+		if err := json.Unmarshal([]byte(out.String()), &payload); err != nil {
+			t.Fatalf("unmarshal history list output: %v\noutput: %s", err, out.String())
+		}
+		var sessions []codexhistory.Session
+		for _, project := range payload.Projects {
+			sessions = append(sessions, project.Sessions...)
+		}
+		return sessions
+	}
+
+	if got := run(); len(got) != 2 {
+		t.Fatalf("history list sessions = %+v, want both", got)
+	}
+	got := run("--resumable-only")
+	if len(got) != 1 || got[0].SessionID != "aaaaaaaa-bbbb-cccc-dddd-000000000003" {
+		t.Fatalf("history list --resumable-only sessions = %+v, want only the resumable one", got)
+	}
 }
 
 func TestHistoryListCmdPrintsDiscoveredProjects(t *testing.T) {
@@ -512,6 +1340,62 @@ func setupCodexHistoryDir(t *testing.T) string {
 	return root
 }
 
+func TestHistoryListCmdReadsFileListFromStdin(t *testing.T) {
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	listed := writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", projectDir, "listed prompt")
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", projectDir, "unlisted prompt")
+
+	missingDir := filepath.Join(t.TempDir(), "no-codex")
+	cmd := newHistoryListCmd(&rootOptions{}, &missingDir)
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(listed + "\n"))
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--stdin"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history list --stdin: %v", err)
+	}
+	if !strings.Contains(out.String(), "listed prompt") || strings.Contains(out.String(), "unlisted prompt") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestHistoryListCmdExcludesSessionSubtrees(t *testing.T) {
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", projectDir, "current prompt")
+	archived := writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", projectDir, "archived prompt")
+	archiveDir := filepath.Join(codexDir, "sessions", "archive")
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(archived, filepath.Join(archiveDir, filepath.Base(archived))); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newHistoryListCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+	cmd.SetContext(context.Background())
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--exclude", "archive"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history list --exclude: %v", err)
+	}
+	if !strings.Contains(out.String(), "current prompt") || strings.Contains(out.String(), "archived prompt") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	cmd = newHistoryListCmd(&rootOptions{}, &codexDir)
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	cmd.SetArgs([]string{"--exclude", "[archive"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid exclude pattern") {
+		t.Fatalf("expected a bad pattern error, got %v", err)
+	}
+}
+
 func writeCodexSessionFile(t *testing.T, codexDir string, sessionID string, projectDir string, prompt string) string {
 	t.Helper()
 	sessionsDir := filepath.Join(codexDir, "sessions")
@@ -527,3 +1411,92 @@ func writeCodexSessionFile(t *testing.T, codexDir string, sessionID string, proj
 	}
 	return path
 }
+
+func TestRunHistoryTuiSuggestsNonInteractiveCommandsWithoutTerminal(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	previousVersion := tuiCodexVersion
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+		tuiCodexVersion = previousVersion
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	tuiCodexVersion = func(context.Context, string) string { return "" }
+	selectSession = func(context.Context, tui.Options) (*tui.Selection, error) {
+		return nil, tui.ErrNotTerminal
+	}
+	cmd := newHistoryTuiCmd(&rootOptions{configPath: cfgPath}, new(string), new(string), new(string))
+	cmd.SetContext(context.Background())
+	err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", t.TempDir(), "/opt/codex", historyTuiFlags{})
+	if !errors.Is(err, tui.ErrNotTerminal) {
+		t.Fatalf("error = %v, want ErrNotTerminal", err)
+	}
+	if !strings.Contains(err.Error(), "history list") || !strings.Contains(err.Error(), "history open") {
+		t.Fatalf("error %q does not point at the non-interactive commands", err)
+	}
+}
+
+func TestRunHistoryTuiPassesSessionLimit(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	previousEnsure := ensureProxyPreferenceFunc
+	previousSelect := selectSession
+	previousVersion := tuiCodexVersion
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = previousEnsure
+		selectSession = previousSelect
+		tuiCodexVersion = previousVersion
+	})
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, config.Config{Version: config.CurrentVersion}, nil
+	}
+	tuiCodexVersion = func(context.Context, string) string { return "" }
+	var got int
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		got = opts.SessionLimit
+		return nil, nil
+	}
+	cmd := newTuiCmd(&rootOptions{configPath: cfgPath})
+	cmd.SetArgs([]string{"--codex-dir", t.TempDir(), "--limit", "250"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got != 250 {
+		t.Fatalf("SessionLimit = %d, want 250", got)
+	}
+}
+
+func TestHistoryPruneCacheDropsDeletedSessions(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("LOCALAPPDATA", cacheDir)
+	codexDir := setupCodexHistoryDir(t)
+	sessionFile := writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", t.TempDir(), "soon deleted")
+
+	list := newHistoryListCmd(&rootOptions{}, &codexDir)
+	list.SetContext(context.Background())
+	list.SetOut(io.Discard)
+	if err := list.Execute(); err != nil {
+		t.Fatalf("execute history list: %v", err)
+	}
+	if err := os.Remove(sessionFile); err != nil {
+		t.Fatalf("remove session: %v", err)
+	}
+
+	cmd := newHistoryCmd(&rootOptions{})
+	cmd.SetContext(context.Background())
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"prune-cache", "--codex-dir", codexDir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history prune-cache: %v", err)
+	}
+	if !regexp.MustCompile(`^Pruned [1-9]\d* stale cache entries, kept 0\n$`).MatchString(out.String()) {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
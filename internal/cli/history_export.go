@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+func newHistoryExportCmd(root *rootOptions, codexDir *string) *cobra.Command {
+	var format string
+	var pretty bool
+	cmd := &cobra.Command{
+		Use:   "export <session-id>",
+		Short: "Print a session and its subagents, or one subagent, as Markdown or JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("unknown --format %q (want markdown or json)", format)
+			}
+			if pretty && format != "json" {
+				return fmt.Errorf("--pretty only applies to --format json")
+			}
+			sessionID := strings.TrimSpace(args[0])
+			paths, err := resolveEffectivePaths(root.configPath, *codexDir, "")
+			if err != nil {
+				return err
+			}
+			session, _, err := findSessionWithProjectFunc(paths.CodexDir, sessionID)
+			var subErr *codexhistory.SubagentSessionError
+			if errors.As(err, &subErr) {
+				session, err = subagentExportSession(subErr.Subagent, subErr.Parent), nil
+			}
+			if err != nil {
+				return err
+			}
+			export, err := codexhistory.ExportSession(*session)
+			if err != nil {
+				return err
+			}
+			if format == "markdown" {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), codexhistory.FormatSessionMarkdown(export))
+				return nil
+			}
+			var out []byte
+			if pretty {
+				out, err = json.MarshalIndent(export, "", "  ")
+			} else {
+				out, err = json.Marshal(export)
+			}
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown or json")
+	cmd.Flags().BoolVar(&pretty, "pretty", false, "Pretty-print JSON")
+	return cmd
+}
+
+// subagentExportSession wraps a subagent as a session of its own, so export
+// prints just its transcript, under its parent's project.
+func subagentExportSession(sub codexhistory.SubagentSession, parent codexhistory.Session) *codexhistory.Session {
+	return &codexhistory.Session{
+		SessionID:    sub.SessionID,
+		Summary:      sub.Summary,
+		FirstPrompt:  sub.FirstPrompt,
+		MessageCount: sub.MessageCount,
+		CreatedAt:    sub.CreatedAt,
+		ModifiedAt:   sub.ModifiedAt,
+		ProjectPath:  parent.ProjectPath,
+		FilePath:     sub.FilePath,
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+func TestHistoryExportCmdWritesMarkdownAndJSON(t *testing.T) {
+	lockCLITestHooks(t)
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	sessionID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	writeCodexSessionFile(t, codexDir, sessionID, projectDir, "ship it")
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := newHistoryExportCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+		cmd.SetContext(context.Background())
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute history export %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	md := run(sessionID)
+	if !strings.HasPrefix(md, "# Session "+sessionID+"\n") || !strings.Contains(md, "\n## User\n\nship it\n") {
+		t.Fatalf("markdown export:\n%s", md)
+	}
+
+	var decoded struct {
+		SessionID   string
+		ProjectPath string
+		Messages    []struct{ Role, Content string }
+	}
+	if err := json.Unmarshal([]byte(run(sessionID, "--format", "json")), &decoded); err != nil {
+		t.Fatalf("parse JSON export: %v", err)
+	}
+	if decoded.SessionID != sessionID || decoded.ProjectPath != projectDir || len(decoded.Messages) != 1 || decoded.Messages[0].Content != "ship it" {
+		t.Fatalf("JSON export = %+v", decoded)
+	}
+}
+
+func TestHistoryExportCmdRejectsBadFlags(t *testing.T) {
+	lockCLITestHooks(t)
+	codexDir := setupCodexHistoryDir(t)
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "--format", "html"}, `unknown --format "html"`},
+		{[]string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "--pretty"}, "--pretty only applies to --format json"},
+	} {
+		cmd := newHistoryExportCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+		cmd.SetContext(context.Background())
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(tc.args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
+
+func TestHistoryExportCmdPrintsOnlyTheSubagent(t *testing.T) {
+	lockCLITestHooks(t)
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	childID := "11111111-2222-3333-4444-555555555555"
+	childFile := writeCodexSessionFile(t, codexDir, childID, projectDir, "child task")
+	prevFind := findSessionWithProjectFunc
+	t.Cleanup(func() { findSessionWithProjectFunc = prevFind })
+	findSessionWithProjectFunc = func(string, string) (*codexhistory.Session, *codexhistory.Project, error) {
+		return nil, nil, &codexhistory.SubagentSessionError{
+			Subagent: codexhistory.SubagentSession{SessionID: childID, FilePath: childFile},
+			Parent:   codexhistory.Session{SessionID: "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", ProjectPath: projectDir, FirstPrompt: "parent task"},
+		}
+	}
+
+	cmd := newHistoryExportCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+	cmd.SetContext(context.Background())
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{childID})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history export: %v", err)
+	}
+	md := out.String()
+	if !strings.HasPrefix(md, "# Session "+childID+"\n") || !strings.Contains(md, "child task") || strings.Contains(md, "parent task") {
+		t.Fatalf("subagent export:\n%s", md)
+	}
+}
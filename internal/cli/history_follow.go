@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// clearScreen moves the cursor home and erases the terminal, like watch(1).
+const clearScreen = "\x1b[H\x1b[2J"
+
+// followNow is swapped in tests to pin the header timestamp.
+var followNow = time.Now
+
+// followHistory calls render every interval until ctx is done, reprinting
+// its output on a cleared screen under a one-line header. A failed pass is
+// shown in place of the output and retried on the next tick, so a rollout
+// file caught mid-write does not end the loop. Cancelling ctx (Ctrl+C) is a
+// clean exit.
+func followHistory(ctx context.Context, out io.Writer, interval time.Duration, title string, render func(io.Writer) error) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive, got %s", interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "Every %s: %s    %s\n\n", interval, title, followNow().Format("2006-01-02 15:04:05"))
+		if err := render(&buf); err != nil {
+			fmt.Fprintf(&buf, "error: %v\n", err)
+		}
+		if _, err := io.WriteString(out, clearScreen+buf.String()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
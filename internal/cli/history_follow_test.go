@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFollowHistoryReprintsUntilCancelled(t *testing.T) {
+	lockCLITestHooks(t)
+	prevNow := followNow
+	followNow = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() { followNow = prevNow })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	passes := 0
+	var out strings.Builder
+	err := followHistory(ctx, &out, time.Millisecond, "history list", func(w io.Writer) error {
+		passes++
+		switch passes {
+		case 2:
+			return errors.New("rollout half written")
+		case 3:
+			cancel()
+		}
+		_, err := fmt.Fprintf(w, "pass %d\n", passes)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("followHistory: %v", err)
+	}
+	if passes != 3 {
+		t.Fatalf("passes = %d, want 3", passes)
+	}
+	screens := strings.Split(out.String(), clearScreen)
+	if len(screens) != 4 || screens[0] != "" {
+		t.Fatalf("expected three cleared screens, got %q", out.String())
+	}
+	if want := "Every 1ms: history list    2026-01-02 03:04:05\n\npass 1\n"; screens[1] != want {
+		t.Fatalf("first screen = %q, want %q", screens[1], want)
+	}
+	if !strings.Contains(screens[2], "error: rollout half written") {
+		t.Fatalf("failed pass should be shown and retried, got %q", screens[2])
+	}
+	if !strings.HasSuffix(screens[3], "pass 3\n") {
+		t.Fatalf("last screen = %q", screens[3])
+	}
+}
+
+func TestFollowHistoryRejectsNonPositiveInterval(t *testing.T) {
+	err := followHistory(context.Background(), io.Discard, 0, "history list", func(io.Writer) error {
+		t.Fatal("render should not run")
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "--interval") {
+		t.Fatalf("expected --interval error, got %v", err)
+	}
+}
+
+func TestHistoryListCmdFollowPrintsSessions(t *testing.T) {
+	lockCLITestHooks(t)
+	codexDir := setupCodexHistoryDir(t)
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", t.TempDir(), "watch me")
+
+	// An already cancelled context stands in for Ctrl+C after the first pass.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cmd := newHistoryListCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+	cmd.SetContext(ctx)
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--follow", "--interval", "1h", "--template", "{{.SessionID}} {{.FirstPrompt}}"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history list --follow: %v", err)
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, clearScreen+"Every 1h0m0s: codex-proxy history list") {
+		t.Fatalf("missing cleared screen and header:\n%q", got)
+	}
+	if !strings.Contains(got, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee watch me\n") {
+		t.Fatalf("session missing from output:\n%s", got)
+	}
+}
+
+func TestHistoryListCmdRejectsBadFollowFlags(t *testing.T) {
+	lockCLITestHooks(t)
+	codexDir := setupCodexHistoryDir(t)
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--follow", "--stdin"}, "--follow cannot be combined with --stdin"},
+		{[]string{"--interval", "10s"}, "--interval requires --follow"},
+	} {
+		cmd := newHistoryListCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+		cmd.SetContext(context.Background())
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(tc.args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
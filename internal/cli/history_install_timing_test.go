@@ -36,7 +36,7 @@ func TestRunHistoryTuiDoesNotRequireCodexBeforeSelection(t *testing.T) {
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 }
@@ -72,7 +72,7 @@ func TestRunHistoryTuiLoadProjectsHonorsContext(t *testing.T) {
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -59,7 +60,7 @@ func TestRunHistoryTuiPersistsProxyEnabledAfterProfileSetup(t *testing.T) {
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 	if persistCalls != 1 {
@@ -108,7 +109,7 @@ func TestRunHistoryTuiDoesNotPersistProxyEnabledWhenProfileSetupFails(t *testing
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	err = runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", 0)
+	err = runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{})
 	if !errors.Is(err, profileErr) {
 		t.Fatalf("expected profile setup error, got %v", err)
 	}
@@ -181,7 +182,7 @@ func TestRunHistoryTuiToggleEnablePersistsAfterInitSuccess(t *testing.T) {
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 
@@ -246,7 +247,7 @@ func TestRunHistoryTuiToggleEnableDoesNotPersistWhenInitFails(t *testing.T) {
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	err = runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", 0)
+	err = runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{})
 	if !errors.Is(err, initErr) {
 		t.Fatalf("expected init error, got %v", err)
 	}
@@ -305,7 +306,7 @@ func TestHistoryOpenPersistsProxyEnabledAfterProfileSetup(t *testing.T) {
 	}
 
 	findSessionWithProjectFunc = func(string, string) (*codexhistory.Session, *codexhistory.Project, error) {
-		s := &codexhistory.Session{SessionID: "sid"}
+		s := &codexhistory.Session{SessionID: "11111111-1111-1111-1111-111111111111"}
 		p := &codexhistory.Project{Path: t.TempDir()}
 		return s, p, nil
 	}
@@ -331,7 +332,7 @@ func TestHistoryOpenPersistsProxyEnabledAfterProfileSetup(t *testing.T) {
 	profileRef := ""
 	cmd := newHistoryOpenCmd(root, &codexDir, &codexPath, &profileRef)
 	cmd.SetContext(context.Background())
-	cmd.SetArgs([]string{"sid"})
+	cmd.SetArgs([]string{"11111111-1111-1111-1111-111111111111"})
 	if err := cmd.Execute(); err != nil {
 		t.Fatalf("execute history open: %v", err)
 	}
@@ -399,7 +400,7 @@ func TestHistoryOpenExplicitProfileUsesProxyDespiteDisabledPreference(t *testing
 		return nil
 	}
 	findSessionWithProjectFunc = func(string, string) (*codexhistory.Session, *codexhistory.Project, error) {
-		return &codexhistory.Session{SessionID: "sid"}, &codexhistory.Project{Path: t.TempDir()}, nil
+		return &codexhistory.Session{SessionID: "11111111-1111-1111-1111-111111111111"}, &codexhistory.Project{Path: t.TempDir()}, nil
 	}
 	runCodexSessionFunc = func(
 		_ context.Context,
@@ -429,7 +430,7 @@ func TestHistoryOpenExplicitProfileUsesProxyDespiteDisabledPreference(t *testing
 	profileRef := "dev"
 	cmd := newHistoryOpenCmd(root, &codexDir, &codexPath, &profileRef)
 	cmd.SetContext(context.Background())
-	cmd.SetArgs([]string{"sid"})
+	cmd.SetArgs([]string{"11111111-1111-1111-1111-111111111111"})
 	if err := cmd.Execute(); err != nil {
 		t.Fatalf("execute history open: %v", err)
 	}
@@ -530,7 +531,7 @@ func TestRunHistoryTuiExplicitProfileUsesProxyDespiteDisabledPreference(t *testi
 
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
-	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "dev", "", "", 0); err != nil {
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "dev", "", "", historyTuiFlags{}); err != nil {
 		t.Fatalf("runHistoryTui error: %v", err)
 	}
 
@@ -542,3 +543,93 @@ func TestRunHistoryTuiExplicitProfileUsesProxyDespiteDisabledPreference(t *testi
 		t.Fatalf("expected ProxyEnabled=false to remain unchanged, got %v", updated.ProxyEnabled)
 	}
 }
+
+func TestRunHistoryTuiAppliesSelectedLaunchProfile(t *testing.T) {
+	lockCLITestHooks(t)
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	store, err := config.NewStore(cfgPath)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	work := config.Profile{ID: "p2", Name: "work", Host: "work.example.com", Port: 22, User: "coder"}
+	seed := config.Config{
+		Version:      config.CurrentVersion,
+		ProxyEnabled: boolPtr(false),
+		Profiles:     []config.Profile{{ID: "p1", Name: "dev", Host: "example.com", Port: 22, User: "coder"}, work},
+		LaunchProfiles: map[string]config.LaunchProfile{
+			"work": {Proxy: boolPtr(true), ProxyProfile: "work", AutoApprove: boolPtr(true), ModelProfile: "deepseek"},
+		},
+	}
+	if err := store.Save(seed); err != nil {
+		t.Fatalf("seed config: %v", err)
+	}
+
+	prevEnsureProxy := ensureProxyPreferenceFunc
+	prevEnsureProfile := ensureProfileFunc
+	prevSelect := selectSession
+	prevRun := runCodexSessionFunc
+	t.Cleanup(func() {
+		ensureProxyPreferenceFunc = prevEnsureProxy
+		ensureProfileFunc = prevEnsureProfile
+		selectSession = prevSelect
+		runCodexSessionFunc = prevRun
+	})
+
+	ensureProxyPreferenceFunc = func(context.Context, *config.Store, string, io.Writer) (bool, config.Config, error) {
+		return false, seed, nil
+	}
+	ensureProfileFunc = func(_ context.Context, _ *config.Store, profileRef string, _ bool, _ io.Writer) (config.Profile, config.Config, error) {
+		if profileRef != "work" {
+			t.Fatalf("profile ref = %q, want the launch profile's proxy profile", profileRef)
+		}
+		return work, seed, nil
+	}
+	selectSession = func(_ context.Context, opts tui.Options) (*tui.Selection, error) {
+		if !slices.Equal(opts.LaunchProfiles, []string{"work"}) {
+			t.Fatalf("LaunchProfiles = %#v", opts.LaunchProfiles)
+		}
+		return &tui.Selection{
+			Session:       codexhistory.Session{SessionID: "sid"},
+			Project:       codexhistory.Project{Path: t.TempDir()},
+			LaunchProfile: "work",
+		}, nil
+	}
+	launched := false
+	runCodexSessionFunc = func(
+		ctx context.Context,
+		_ *rootOptions,
+		_ *config.Store,
+		gotProfile *config.Profile,
+		_ []config.Instance,
+		_ codexhistory.Session,
+		_ codexhistory.Project,
+		_ string,
+		_ string,
+		useProxy bool,
+		_ io.Writer,
+	) error {
+		launched = true
+		if !useProxy || gotProfile == nil || gotProfile.ID != work.ID {
+			t.Fatalf("useProxy = %v, profile = %#v; want proxy through %s", useProxy, gotProfile, work.ID)
+		}
+		if aaa, err := aaaPreference(ctx, store); err != nil || !aaa {
+			t.Fatalf("aaaPreference = %v, %v; want the launch profile's auto-approve", aaa, err)
+		}
+		if got := launchModelProfile(ctx); got != "deepseek" {
+			t.Fatalf("model profile = %q", got)
+		}
+		return nil
+	}
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	if err := runHistoryTui(cmd, &rootOptions{configPath: cfgPath}, "", "", "", historyTuiFlags{}); err != nil {
+		t.Fatalf("runHistoryTui error: %v", err)
+	}
+	if !launched {
+		t.Fatal("expected the session to launch")
+	}
+	if aaa, _ := aaaPreference(context.Background(), store); aaa {
+		t.Fatal("the launch profile should not change the persisted auto-approve toggle")
+	}
+}
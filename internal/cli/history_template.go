@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+var historyTemplateNow = time.Now
+
+// parseHistoryTemplate parses a `history list --template` argument. Each
+// session is rendered with a codexhistory.Session as the data, so fields are
+// used directly, e.g. '{{.SessionID}} {{ago .ModifiedAt}} {{.FirstPrompt}}'.
+func parseHistoryTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("session").Funcs(historyTemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func historyTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"ago": func(t time.Time) string {
+			return relativeAge(t, historyTemplateNow())
+		},
+		"date": func(t time.Time) string {
+			if t.IsZero() {
+				return "unknown"
+			}
+			return t.Local().Format("2006-01-02 15:04")
+		},
+		"oneline": func(s string) string {
+			return strings.Join(strings.Fields(s), " ")
+		},
+		"trunc": func(n int, s string) string {
+			runes := []rune(s)
+			if n < 0 || len(runes) <= n {
+				return s
+			}
+			return string(runes[:n])
+		},
+	}
+}
+
+// relativeAge renders how long before now t was, in the largest whole unit.
+func relativeAge(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
+
+// writeHistoryTemplate executes tmpl once per session, project by project,
+// ending each rendering with a newline unless the template already did.
+func writeHistoryTemplate(w io.Writer, tmpl *template.Template, projects []codexhistory.Project) error {
+	var buf bytes.Buffer
+	for _, project := range projects {
+		for _, session := range project.Sessions {
+			buf.Reset()
+			if err := tmpl.Execute(&buf, session); err != nil {
+				return fmt.Errorf("render session %s: %w", session.SessionID, err)
+			}
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteByte('\n')
+			}
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryListCmdRendersTemplatePerSession(t *testing.T) {
+	lockCLITestHooks(t)
+	prevNow := historyTemplateNow
+	historyTemplateNow = func() time.Time { return time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { historyTemplateNow = prevNow })
+
+	codexDir := setupCodexHistoryDir(t)
+	projectDir := t.TempDir()
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", projectDir, "first prompt")
+	writeCodexSessionFile(t, codexDir, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", projectDir, "second prompt")
+
+	cmd := newHistoryListCmd(&rootOptions{configPath: filepath.Join(t.TempDir(), "config.json")}, &codexDir)
+	cmd.SetContext(context.Background())
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--template", `{{.SessionID}} {{ago .CreatedAt}} {{trunc 5 .FirstPrompt}}`})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute history list --template: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per session, got %q", out.String())
+	}
+	for _, want := range []string{
+		"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee 3h ago first",
+		"aaaaaaaa-bbbb-cccc-dddd-ffffffffffff 3h ago secon",
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestHistoryListCmdRejectsBadTemplateBeforeScanning(t *testing.T) {
+	missingDir := filepath.Join(t.TempDir(), "no-codex")
+	cmd := newHistoryListCmd(&rootOptions{}, &missingDir)
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	cmd.SetArgs([]string{"--template", "{{.SessionID"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "parse --template") {
+		t.Fatalf("expected template parse error, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newInstallCmd(_ *rootOptions) *cobra.Command {
+	var noInstall bool
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install Codex CLI if needed and print its path, without launching it",
+		Long: "Find a working Codex CLI, installing it the same way a launch would when none is\n" +
+			"found, and print its path. Progress goes to stderr, so scripts can capture the path\n" +
+			"from stdout, e.g. while building a CI image. With --no-install nothing is installed\n" +
+			"and the command fails when Codex is missing.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			var path string
+			var err error
+			if noInstall {
+				path, err = findInstalledCodex(ctx)
+				if err != nil {
+					return fmt.Errorf("codex is not installed: %w", err)
+				}
+			} else {
+				path, err = ensureCodexInstalled(ctx, "", cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&noInstall, "no-install", false, "Only check for a working Codex; never install it")
+	return cmd
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// launchLogStderr is the --log-json value that sends events to stderr; it is
+// also what the bare flag means.
+const launchLogStderr = "-"
+
+// redactedArg replaces launch arguments that may hold prompts or secrets.
+const redactedArg = "[redacted]"
+
+// launchEvent is one line of the --log-json log: a significant step of the
+// launch pipeline, for log aggregators rather than people.
+type launchEvent struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Path    string    `json:"path,omitempty"`
+	Cwd     string    `json:"cwd,omitempty"`
+	Session string    `json:"session,omitempty"`
+	Args    []string  `json:"args,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+var (
+	launchLogMu  sync.Mutex
+	launchLogOut io.Writer
+	// launchLogNow is swapped in tests to pin event times.
+	launchLogNow = time.Now
+)
+
+// openLaunchLog starts writing launch events to target: stderr for "-",
+// otherwise the file at target, appended to. The returned func stops the log
+// and closes the file.
+func openLaunchLog(target string, stderr io.Writer) (func() error, error) {
+	var out io.Writer = stderr
+	closeFile := func() error { return nil }
+	if target != launchLogStderr {
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open --log-json file: %w", err)
+		}
+		out = f
+		closeFile = f.Close
+	}
+	launchLogMu.Lock()
+	launchLogOut = out
+	launchLogMu.Unlock()
+	return func() error {
+		launchLogMu.Lock()
+		launchLogOut = nil
+		launchLogMu.Unlock()
+		return closeFile()
+	}, nil
+}
+
+// logLaunchEvent writes ev as one JSON line when --log-json is on. Write
+// failures are ignored so logging never fails a launch.
+func logLaunchEvent(ev launchEvent) {
+	launchLogMu.Lock()
+	defer launchLogMu.Unlock()
+	if launchLogOut == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = launchLogNow().UTC()
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	_, _ = launchLogOut.Write(append(line, '\n'))
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// redactLaunchArgs keeps the flag names of a Codex command line and hides
+// everything that can carry user text: positional arguments, everything
+// after "--", and the values of name=value flags.
+func redactLaunchArgs(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			out = append(out, arg)
+			for range args[i+1:] {
+				out = append(out, redactedArg)
+			}
+			return out
+		case strings.HasPrefix(arg, "-"):
+			if name, _, ok := strings.Cut(arg, "="); ok {
+				arg = name + "=" + redactedArg
+			}
+			out = append(out, arg)
+		default:
+			out = append(out, redactedArg)
+		}
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactLaunchArgs(t *testing.T) {
+	got := redactLaunchArgs([]string{"--model", "gpt-5", "-c", "model_provider=secret", "--sandbox=workspace-write", "resume", "abc", "--", "-fix the build"})
+	want := []string{"--model", redactedArg, "-c", redactedArg, "--sandbox=" + redactedArg, redactedArg, redactedArg, "--", redactedArg}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("redactLaunchArgs = %q, want %q", got, want)
+	}
+}
+
+func TestLaunchLogWritesJSONLinesUntilClosed(t *testing.T) {
+	lockCLITestHooks(t)
+	prevNow := launchLogNow
+	t.Cleanup(func() { launchLogNow = prevNow })
+	launchLogNow = func() time.Time { return time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	logLaunchEvent(launchEvent{Event: "dropped"})
+	var stderr bytes.Buffer
+	closeLog, err := openLaunchLog(launchLogStderr, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logLaunchEvent(launchEvent{Event: "session_launched", Cwd: "/work", Args: []string{"--", redactedArg}})
+	if err := closeLog(); err != nil {
+		t.Fatal(err)
+	}
+	logLaunchEvent(launchEvent{Event: "dropped"})
+
+	want := `{"time":"2026-05-01T12:00:00Z","event":"session_launched","cwd":"/work","args":["--","[redacted]"]}` + "\n"
+	if stderr.String() != want {
+		t.Fatalf("log = %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestRootLogJSONFlagRecordsDeletedCaches(t *testing.T) {
+	lockCLITestHooks(t)
+	for _, form := range []string{"--log-json=<file>", "--log-json-file <file>"} {
+		codexDir := t.TempDir()
+		requirements := filepath.Join(codexDir, "cloud-requirements-cache.json")
+		if err := os.WriteFile(requirements, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		logPath := filepath.Join(t.TempDir(), "events.jsonl")
+		logArgs := []string{"--log-json=" + logPath}
+		if form == "--log-json-file <file>" {
+			logArgs = []string{"--log-json-file", logPath}
+		}
+
+		opts := &rootOptions{}
+		cmd := newRootCmdWithOptions(opts)
+		cmd.SetContext(context.Background())
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		args := append([]string{"--config", filepath.Join(t.TempDir(), "config.json")}, logArgs...)
+		cmd.SetArgs(append(args, "clear-cache", "--requirements", "--codex-dir", codexDir))
+		if err := executeRootCmd(cmd, opts); err != nil {
+			t.Fatalf("%s: clear-cache: %v", form, err)
+		}
+		if out.String() != "Removed "+requirements+"\n" {
+			t.Fatalf("%s: output = %q", form, out.String())
+		}
+		data, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ev launchEvent
+		if err := json.Unmarshal(bytes.TrimSpace(data), &ev); err != nil {
+			t.Fatalf("%s: log is not one JSON line: %q: %v", form, data, err)
+		}
+		if ev.Event != "cache_deleted" || ev.Path != requirements || ev.Time.IsZero() {
+			t.Fatalf("%s: event = %+v", form, ev)
+		}
+		logLaunchEvent(launchEvent{Event: "after"})
+		if after, _ := os.ReadFile(logPath); !bytes.Equal(after, data) {
+			t.Fatalf("%s: log kept writing after the command: %q", form, after)
+		}
+	}
+}
+
+func TestRootLogJSONRejectsBothForms(t *testing.T) {
+	lockCLITestHooks(t)
+	opts := &rootOptions{}
+	cmd := newRootCmdWithOptions(opts)
+	cmd.SetContext(context.Background())
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	dir := t.TempDir()
+	cmd.SetArgs([]string{"--config", filepath.Join(dir, "config.json"), "--log-json", "--log-json-file", filepath.Join(dir, "events.jsonl"), "version"})
+	err := executeRootCmd(cmd, opts)
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected --log-json with --log-json-file to fail, got %v", err)
+	}
+}
+
+func TestExecuteRootCmdClosesLaunchLogWhenTheCommandFails(t *testing.T) {
+	lockCLITestHooks(t)
+	logPath := filepath.Join(t.TempDir(), "events.jsonl")
+
+	opts := &rootOptions{}
+	cmd := newRootCmdWithOptions(opts)
+	cmd.SetContext(context.Background())
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--config", filepath.Join(t.TempDir(), "config.json"), "--log-json=" + logPath, "history", "export", "x", "--format", "html"})
+	if err := executeRootCmd(cmd, opts); err == nil {
+		t.Fatal("expected the command to fail")
+	}
+	logLaunchEvent(launchEvent{Event: "after"})
+	if data, err := os.ReadFile(logPath); err != nil || len(data) != 0 {
+		t.Fatalf("log after a failed command = %q (%v), want it closed and empty", data, err)
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+type launchProfileKey struct{}
+
+// withLaunchProfile makes the sessions launched with ctx use the auto-approve
+// and model profile settings of p. Proxy settings are applied by the caller,
+// which picks the proxy profile before launching.
+func withLaunchProfile(ctx context.Context, p config.LaunchProfile) context.Context {
+	return context.WithValue(ctx, launchProfileKey{}, p)
+}
+
+func launchProfileFrom(ctx context.Context) config.LaunchProfile {
+	p, _ := ctx.Value(launchProfileKey{}).(config.LaunchProfile)
+	return p
+}
+
+// launchModelProfile returns the model profile the launch profile in ctx
+// names, or "" for the default one.
+func launchModelProfile(ctx context.Context) string {
+	return launchProfileFrom(ctx).ModelProfile
+}
+
+// findLaunchProfile looks up name, which the TUI took from cfg, so a miss
+// means the profile was removed while the TUI was open.
+func findLaunchProfile(cfg config.Config, name string) (config.LaunchProfile, error) {
+	p, ok := cfg.LaunchProfiles[name]
+	if !ok {
+		return config.LaunchProfile{}, fmt.Errorf("launch profile %q not found", name)
+	}
+	return p, nil
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+// maxResumeCounts bounds the resume counts file; the sessions resumed
+// longest ago are dropped first.
+const maxResumeCounts = 1000
+
+// resumeCountsDoc is the resume counts file: the helper's own bookkeeping,
+// since Codex rollout files do not record resumes.
+type resumeCountsDoc struct {
+	Sessions map[string]resumeCount `json:"sessions"`
+}
+
+type resumeCount struct {
+	Count         int       `json:"count"`
+	LastResumedAt time.Time `json:"lastResumedAt"`
+}
+
+// resumeCountsFile is where resume counts are kept, next to the config file
+// so that resuming does not rewrite the config.
+func resumeCountsFile(store *config.Store) string {
+	return filepath.Join(filepath.Dir(store.Path()), "resume-counts.json")
+}
+
+// loadResumeCounts returns how many times each session was resumed. A
+// missing or unreadable file means no counts.
+func loadResumeCounts(path string) map[string]int {
+	doc, err := readResumeCounts(path)
+	if err != nil || len(doc.Sessions) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(doc.Sessions))
+	for id, entry := range doc.Sessions {
+		counts[id] = entry.Count
+	}
+	return counts
+}
+
+func readResumeCounts(path string) (resumeCountsDoc, error) {
+	var doc resumeCountsDoc
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return doc, err
+	}
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// recordSessionResume counts one more resume of sessionID.
+func recordSessionResume(store *config.Store, sessionID string) error {
+	sessionID = strings.TrimSpace(sessionID)
+	if store == nil || sessionID == "" {
+		return nil
+	}
+	path := resumeCountsFile(store)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("lock resume counts: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	// A corrupt file starts the counts over rather than blocking resumes.
+	doc, err := readResumeCounts(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		doc = resumeCountsDoc{}
+	}
+	if doc.Sessions == nil {
+		doc.Sessions = map[string]resumeCount{}
+	}
+	entry := doc.Sessions[sessionID]
+	entry.Count++
+	entry.LastResumedAt = time.Now().UTC()
+	doc.Sessions[sessionID] = entry
+	pruneResumeCounts(doc.Sessions, maxResumeCounts)
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, append(data, '\n'), 0o600)
+}
+
+// pruneResumeCounts drops the sessions resumed longest ago until at most
+// limit remain.
+func pruneResumeCounts(sessions map[string]resumeCount, limit int) {
+	if len(sessions) <= limit {
+		return
+	}
+	ids := make([]string, 0, len(sessions))
+	for id := range sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return sessions[ids[i]].LastResumedAt.Before(sessions[ids[j]].LastResumedAt)
+	})
+	for _, id := range ids[:len(ids)-limit] {
+		delete(sessions, id)
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+func TestRecordSessionResumeKeepsCountsOutOfTheConfig(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	store, err := config.NewStore(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(config.Config{Version: config.CurrentVersion}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := recordSessionResume(store, " s1 "); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := loadResumeCounts(resumeCountsFile(store)); got["s1"] != 2 || len(got) != 1 {
+		t.Fatalf("counts = %v", got)
+	}
+	if after, err := os.ReadFile(cfgPath); err != nil || string(after) != string(before) {
+		t.Fatalf("resuming rewrote the config:\n%s", after)
+	}
+
+	if err := os.WriteFile(resumeCountsFile(store), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadResumeCounts(resumeCountsFile(store)); got != nil {
+		t.Fatalf("corrupt file counts = %v", got)
+	}
+	if err := recordSessionResume(store, "s2"); err != nil {
+		t.Fatalf("a corrupt file should start the counts over: %v", err)
+	}
+	if got := loadResumeCounts(resumeCountsFile(store)); got["s2"] != 1 || len(got) != 1 {
+		t.Fatalf("counts after corrupt file = %v", got)
+	}
+}
+
+func TestPruneResumeCountsDropsOldestResumes(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sessions := map[string]resumeCount{}
+	for i := range 5 {
+		sessions[fmt.Sprintf("s%d", i)] = resumeCount{Count: 1, LastResumedAt: base.Add(time.Duration(i) * time.Hour)}
+	}
+	pruneResumeCounts(sessions, 3)
+	var kept []string
+	for id := range sessions {
+		kept = append(kept, id)
+	}
+	if len(sessions) != 3 || sessions["s0"].Count != 0 || sessions["s1"].Count != 0 {
+		t.Fatalf("kept %s, want the three most recent", strings.Join(kept, ","))
+	}
+}
@@ -56,6 +56,7 @@ func newRunCmd(root *rootOptions) *cobra.Command {
 	}
 	cmd.Flags().StringVar(&modelProfile, "model-profile", "", "Model profile id or name for Codex launches")
 	cmd.Flags().BoolVar(&agentAutoApprove, "aaa", false, "Automatically approve Codex agent requests for this run")
+	cmd.Flags().Bool("force", false, aaaForceUsage)
 	cmd.Flags().BoolVar(&legacyMode, migration.LegacyRunModeFlagName, false, "")
 	_ = cmd.Flags().MarkHidden(migration.LegacyRunModeFlagName)
 	return cmd
@@ -92,6 +93,7 @@ func runLike(cmd *cobra.Command, root *rootOptions, autoInit bool) error {
 
 	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	ctx = withAAAGuardFromFlags(ctx, cmd)
 
 	store, _, err := newRootStore(root, "")
 	if err != nil {
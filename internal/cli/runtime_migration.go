@@ -74,8 +74,12 @@ func prepareRuntimeMigration(store *config.Store, paths effectivePaths, codexPat
 		}
 		return &migration.RuntimeBlockedError{Blockers: blockers}
 	}
-	if log != nil && len(authReport.Removed)+len(authReport.Restored)+len(report.Preserved)+len(report.Deferred) > 0 {
-		_, _ = fmt.Fprintf(log, "runtime migration prepared (%d authentication artifact(s) removed, %d restored, %d live session-private binary file(s) deferred, %d ambiguous file(s) preserved until activation)\n", len(authReport.Removed), len(authReport.Restored), len(report.Deferred), len(report.Preserved))
+	if len(authReport.Removed)+len(authReport.Restored)+len(report.Preserved)+len(report.Deferred) > 0 {
+		detail := fmt.Sprintf("%d authentication artifact(s) removed, %d restored, %d live session-private binary file(s) deferred, %d ambiguous file(s) preserved until activation", len(authReport.Removed), len(authReport.Restored), len(report.Deferred), len(report.Preserved))
+		logLaunchEvent(launchEvent{Event: "runtime_migration_prepared", Path: codexPath, Detail: detail})
+		if log != nil {
+			_, _ = fmt.Fprintf(log, "runtime migration prepared (%s)\n", detail)
+		}
 	}
 	return nil
 }
@@ -128,7 +132,11 @@ func runtimeMigrationReadyHook(store *config.Store, paths effectivePaths, codexP
 		}
 
 		report, cleanupErr := migration.CleanupLegacyRuntimeAssets(runtimeMigrationCleanupOptions(store, paths, codexPath))
+		for _, path := range report.Removed {
+			logLaunchEvent(launchEvent{Event: "legacy_asset_removed", Path: path})
+		}
 		if cleanupErr != nil || !report.Complete() {
+			logLaunchEvent(launchEvent{Event: "runtime_migration_pending", Path: codexPath, Error: errorText(cleanupErr)})
 			if log != nil {
 				_, _ = fmt.Fprintf(log, "runtime migration cleanup pending: removed=%d restored=%d deferred=%d preserved=%d blockers=%d err=%v\n", len(report.Removed), len(report.Restored), len(report.Deferred), len(report.Preserved), len(report.Blockers), cleanupErr)
 			}
@@ -145,6 +153,7 @@ func runtimeMigrationReadyHook(store *config.Store, paths effectivePaths, codexP
 			}
 			return nil
 		}
+		logLaunchEvent(launchEvent{Event: "runtime_migration_completed", Path: codexPath})
 		if log != nil {
 			_, _ = fmt.Fprintf(log, "runtime migration completed (%d compatibility artifact(s) removed, %d restored, %d deferred, %d ambiguous file(s) preserved)\n", len(report.Removed), len(report.Restored), len(report.Deferred), len(report.Preserved))
 		}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// sessionNotifyModes are the values --notify and tui.notify accept: nothing,
+// a terminal bell, or a desktop notification.
+var sessionNotifyModes = []string{"off", "bell", "desktop"}
+
+const sessionNotifyUsage = "Signal when the Codex session exits: off, bell or desktop (default from tui.notify, else off)"
+
+// normalizeSessionNotify validates a notify mode; empty means off.
+func normalizeSessionNotify(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		return "off", nil
+	}
+	for _, known := range sessionNotifyModes {
+		if mode == known {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid notify mode %q: want one of %s", mode, strings.Join(sessionNotifyModes, ", "))
+}
+
+// sessionNotifyFlag returns the --notify value when it was given, and
+// otherwise the tui.notify setting, validated.
+func sessionNotifyFlag(flagValue string, flagSet bool, setting string) (string, error) {
+	if flagSet {
+		return normalizeSessionNotify(flagValue)
+	}
+	return normalizeSessionNotify(setting)
+}
+
+var (
+	sessionNotifyStdout     io.Writer = os.Stdout
+	sessionNotifyIsTerminal           = func() bool { return isTerminalFile(os.Stdout) }
+	// runDesktopNotifier runs an OS notification command and waits for it,
+	// since the helper usually exits right after the session does.
+	runDesktopNotifier = func(name string, args ...string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return exec.CommandContext(ctx, name, args...).Run()
+	}
+)
+
+// notifySessionExit signals that a launched Codex session has exited and
+// passes runErr through, so it can wrap the run call. Nothing happens when
+// stdout is not a terminal or the user interrupted the session. A desktop
+// notification falls back to the bell when no notifier is available.
+func notifySessionExit(mode string, runErr error) error {
+	if mode == "" || mode == "off" || errors.Is(runErr, context.Canceled) || !sessionNotifyIsTerminal() {
+		return runErr
+	}
+	message := "Codex session finished"
+	if runErr != nil {
+		message = "Codex session exited with an error"
+	}
+	if mode == "desktop" && desktopNotify("codex-proxy", message) == nil {
+		return runErr
+	}
+	_, _ = io.WriteString(sessionNotifyStdout, "\a")
+	return runErr
+}
+
+func desktopNotify(title, message string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		return runDesktopNotifier("osascript", "-e", script)
+	case "windows":
+		return errors.New("desktop notifications are not supported on Windows")
+	default:
+		return runDesktopNotifier("notify-send", title, message)
+	}
+}
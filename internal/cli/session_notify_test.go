@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSessionNotify(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", "off"},
+		{"off", "off"},
+		{" Bell ", "bell"},
+		{"desktop", "desktop"},
+	} {
+		got, err := normalizeSessionNotify(tc.in)
+		if err != nil || got != tc.want {
+			t.Fatalf("normalizeSessionNotify(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := normalizeSessionNotify("email"); err == nil || !strings.Contains(err.Error(), "off, bell, desktop") {
+		t.Fatalf("expected an error listing the modes, got %v", err)
+	}
+	if got, _ := sessionNotifyFlag("off", true, "bell"); got != "off" {
+		t.Fatalf("an explicit --notify should win over tui.notify, got %q", got)
+	}
+	if got, _ := sessionNotifyFlag("", false, "bell"); got != "bell" {
+		t.Fatalf("tui.notify should apply without --notify, got %q", got)
+	}
+}
+
+func TestNotifySessionExit(t *testing.T) {
+	lockCLITestHooks(t)
+	prevStdout, prevIsTerminal, prevNotifier := sessionNotifyStdout, sessionNotifyIsTerminal, runDesktopNotifier
+	t.Cleanup(func() {
+		sessionNotifyStdout, sessionNotifyIsTerminal, runDesktopNotifier = prevStdout, prevIsTerminal, prevNotifier
+	})
+
+	var out strings.Builder
+	var notified []string
+	terminal := true
+	notifierErr := error(nil)
+	sessionNotifyStdout = &out
+	sessionNotifyIsTerminal = func() bool { return terminal }
+	runDesktopNotifier = func(name string, args ...string) error {
+		notified = append(notified, name+" "+strings.Join(args, " "))
+		return notifierErr
+	}
+	reset := func() {
+		out.Reset()
+		notified = nil
+	}
+
+	runErr := errors.New("codex failed")
+	if err := notifySessionExit("bell", runErr); err != runErr {
+		t.Fatalf("expected the run error to pass through, got %v", err)
+	}
+	if out.String() != "\a" {
+		t.Fatalf("bell output = %q", out.String())
+	}
+
+	for _, tc := range []struct {
+		name     string
+		mode     string
+		terminal bool
+		err      error
+	}{
+		{name: "off", mode: "off", terminal: true},
+		{name: "not a terminal", mode: "bell", terminal: false},
+		{name: "interrupted", mode: "bell", terminal: true, err: context.Canceled},
+	} {
+		reset()
+		terminal = tc.terminal
+		_ = notifySessionExit(tc.mode, tc.err)
+		if out.Len() != 0 || len(notified) != 0 {
+			t.Fatalf("%s: expected no signal, got %q %q", tc.name, out.String(), notified)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	reset()
+	terminal = true
+	if err := notifySessionExit("desktop", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 1 || !strings.Contains(notified[0], "Codex session finished") || out.Len() != 0 {
+		t.Fatalf("desktop notification = %q, bell = %q", notified, out.String())
+	}
+
+	reset()
+	notifierErr = errors.New("notify-send: not found")
+	_ = notifySessionExit("desktop", nil)
+	if out.String() != "\a" {
+		t.Fatalf("expected a bell when the desktop notifier fails, got %q", out.String())
+	}
+}
@@ -6,24 +6,64 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// historyTuiFlags holds the picker flags shared by `tui` and `history tui`.
+type historyTuiFlags struct {
+	refreshInterval time.Duration
+	project         string
+	showEmpty       bool
+	noColor         bool
+	theme           string
+	limit           int
+	readOnly        bool
+	rememberView    bool
+	projectOnly     bool
+	hideSubagents   bool
+	resumeLast      bool
+	prompt          string
+	exclude         []string
+	notify          string
+	status          string
+}
+
+// addHistoryTuiFlags declares the picker flags on cmd, bound to flags, so
+// both TUI commands accept the same set.
+func addHistoryTuiFlags(cmd *cobra.Command, flags *historyTuiFlags) {
+	cmd.Flags().DurationVar(&flags.refreshInterval, "refresh-interval", defaultRefreshInterval, "Auto-refresh interval (0 to disable)")
+	cmd.Flags().StringVar(&flags.project, "project", "", "Open with the projects list filtered to this text")
+	cmd.Flags().BoolVar(&flags.showEmpty, "show-empty", false, "Also list empty sessions (toggle in the TUI with x)")
+	cmd.Flags().BoolVar(&flags.noColor, "no-color", false, "Draw without colors or text styles (also set by NO_COLOR)")
+	cmd.Flags().StringVar(&flags.theme, "theme", "", "Color theme: default, high-contrast or solarized (default from tui.theme)")
+	cmd.Flags().IntVar(&flags.limit, "limit", 0, "Only load the N most recent session files (+ in the TUI loads more; 0 for all)")
+	cmd.Flags().BoolVar(&flags.readOnly, "read-only", false, "Browse and preview sessions without being able to open them or launch codex")
+	cmd.Flags().BoolVar(&flags.rememberView, "remember-view", false, "Restore the selection, filters and panes of the last run, and save them while the TUI is open")
+	cmd.Flags().BoolVar(&flags.projectOnly, "project-only", false, "Only load sessions recorded in the current directory (combine with --limit for a fast start)")
+	cmd.Flags().BoolVar(&flags.hideSubagents, "hide-subagents", false, "List only main sessions, without subagents (toggle in the TUI with s)")
+	cmd.Flags().BoolVar(&flags.resumeLast, "resume-last", false, "Start with the most recently modified session selected, so Enter resumes it")
+	cmd.Flags().StringVar(&flags.prompt, "prompt", "", "Send this as the first message when starting a new session (ignored when resuming)")
+	cmd.Flags().StringArrayVar(&flags.exclude, "exclude", nil, historyExcludeUsage)
+	cmd.Flags().StringVar(&flags.notify, "notify", "", sessionNotifyUsage)
+	cmd.Flags().StringVar(&flags.status, "status", "", "Status bar hints: full or minimal (default from tui.status, else full)")
+	cmd.Flags().Bool("force", false, aaaForceUsage)
+}
+
 func newTuiCmd(root *rootOptions) *cobra.Command {
 	var codexDir string
 	var codexPath string
 	var profileRef string
-	var refreshInterval time.Duration
+	var flags historyTuiFlags
 
 	cmd := &cobra.Command{
 		Use:   "tui",
 		Short: "Browse Codex history in a terminal UI",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runHistoryTui(cmd, root, profileRef, codexDir, codexPath, refreshInterval)
+			return runHistoryTui(cmd, root, profileRef, codexDir, codexPath, flags)
 		},
 	}
 
 	cmd.Flags().StringVar(&codexDir, "codex-dir", "", "Override Codex data dir (default: ~/.codex)")
 	cmd.Flags().StringVar(&codexPath, "codex-path", "", "Override Codex CLI path (default: search PATH)")
 	cmd.Flags().StringVar(&profileRef, "profile", "", "Proxy profile id or name")
-	cmd.Flags().DurationVar(&refreshInterval, "refresh-interval", defaultRefreshInterval, "Auto-refresh interval (0 to disable)")
+	addHistoryTuiFlags(cmd, &flags)
 	return cmd
 }
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+	"github.com/baaaaaaaka/codex-helper/internal/tui"
+)
+
+func resolveTUIPreferences(cfg config.Config) config.TUIPreferences {
+	if cfg.TUI == nil {
+		return config.TUIPreferences{}
+	}
+	return *cfg.TUI
+}
+
+// resolveDisplayLocation maps the tui.timeZone setting to a location. Empty
+// returns nil, which shows times as recorded (UTC for rollout timestamps),
+// and "local" means the system zone.
+func resolveDisplayLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	if strings.EqualFold(name, "utc") {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tui.timeZone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// resolveNewAgentAtBottom maps the tui.newAgentPosition setting; empty and
+// "top" keep the new session row first.
+func resolveNewAgentAtBottom(position string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(position)) {
+	case "", "top":
+		return false, nil
+	case "bottom":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid tui.newAgentPosition %q: want \"top\" or \"bottom\"", position)
+}
+
+// resolveMinimalStatus maps a status bar mode, from --status or the
+// tui.status setting; empty and "full" keep every key hint.
+func resolveMinimalStatus(mode string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "full":
+		return false, nil
+	case "minimal":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid status bar mode %q: want \"full\" or \"minimal\"", mode)
+}
+
+// resolveTheme checks a TUI theme name, from --theme or the tui.theme
+// setting, and returns its canonical spelling; empty is the default theme.
+func resolveTheme(name string) (string, error) {
+	theme, ok := tui.LookupTheme(name)
+	if !ok {
+		return "", fmt.Errorf("invalid theme %q: want one of %s", name, strings.Join(tui.ThemeNames(), ", "))
+	}
+	return theme.Name, nil
+}
+
+func updateTUIPreferences(store *config.Store, fn func(*config.TUIPreferences)) error {
+	return store.Update(func(cfg *config.Config) error {
+		prefs := resolveTUIPreferences(*cfg)
+		fn(&prefs)
+		if prefs == (config.TUIPreferences{}) {
+			cfg.TUI = nil
+			return nil
+		}
+		cfg.TUI = &prefs
+		return nil
+	})
+}
+
+func persistPaneWidthBias(store *config.Store, bias int) error {
+	return updateTUIPreferences(store, func(prefs *config.TUIPreferences) {
+		prefs.PaneWidthBias = bias
+	})
+}
+
+func persistShowLastReply(store *config.Store, show bool) error {
+	return updateTUIPreferences(store, func(prefs *config.TUIPreferences) {
+		prefs.ShowLastReply = show
+	})
+}
+
+func persistLayoutMode(store *config.Store, mode string) error {
+	return updateTUIPreferences(store, func(prefs *config.TUIPreferences) {
+		if mode == "auto" {
+			mode = ""
+		}
+		prefs.LayoutMode = mode
+	})
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+)
+
+func TestPersistPaneWidthBiasRoundTrip(t *testing.T) {
+	store, err := config.NewStore(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := persistPaneWidthBias(store, -6); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resolveTUIPreferences(cfg).PaneWidthBias; got != -6 {
+		t.Fatalf("PaneWidthBias = %d, want -6", got)
+	}
+	if err := persistPaneWidthBias(store, 0); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TUI != nil {
+		t.Fatalf("default TUI preferences should be omitted, got %#v", cfg.TUI)
+	}
+}
+
+func TestResolveDisplayLocation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want *time.Location
+	}{
+		{"", nil},
+		{"local", time.Local},
+		{"UTC", time.UTC},
+		{"utc", time.UTC},
+	} {
+		got, err := resolveDisplayLocation(tc.name)
+		if err != nil {
+			t.Fatalf("resolveDisplayLocation(%q): %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("resolveDisplayLocation(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if _, err := resolveDisplayLocation("Not/AZone"); err == nil {
+		t.Fatal("expected an error for an unknown zone")
+	}
+}
+
+func TestResolveNewAgentAtBottom(t *testing.T) {
+	for _, tc := range []struct {
+		position string
+		want     bool
+	}{
+		{"", false},
+		{"top", false},
+		{"Bottom", true},
+		{" bottom ", true},
+	} {
+		got, err := resolveNewAgentAtBottom(tc.position)
+		if err != nil {
+			t.Fatalf("resolveNewAgentAtBottom(%q): %v", tc.position, err)
+		}
+		if got != tc.want {
+			t.Fatalf("resolveNewAgentAtBottom(%q) = %v, want %v", tc.position, got, tc.want)
+		}
+	}
+	if _, err := resolveNewAgentAtBottom("middle"); err == nil {
+		t.Fatal("expected an error for an unknown position")
+	}
+}
+
+func TestResolveMinimalStatus(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		want bool
+	}{
+		{"", false},
+		{"full", false},
+		{"Minimal", true},
+		{" minimal ", true},
+	} {
+		got, err := resolveMinimalStatus(tc.mode)
+		if err != nil {
+			t.Fatalf("resolveMinimalStatus(%q): %v", tc.mode, err)
+		}
+		if got != tc.want {
+			t.Fatalf("resolveMinimalStatus(%q) = %v, want %v", tc.mode, got, tc.want)
+		}
+	}
+	if _, err := resolveMinimalStatus("terse"); err == nil {
+		t.Fatal("expected an error for an unknown status bar mode")
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"", "default"},
+		{"default", "default"},
+		{" High-Contrast ", "high-contrast"},
+		{"solarized", "solarized"},
+	} {
+		got, err := resolveTheme(tc.name)
+		if err != nil {
+			t.Fatalf("resolveTheme(%q): %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("resolveTheme(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if _, err := resolveTheme("neon"); err == nil {
+		t.Fatal("expected an error for an unknown theme")
+	}
+}
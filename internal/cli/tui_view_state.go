@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/baaaaaaaka/codex-helper/internal/config"
+	"github.com/baaaaaaaka/codex-helper/internal/tui"
+)
+
+// tuiViewStateFile is where --remember-view keeps the picker's view, next to
+// the config file.
+func tuiViewStateFile(store *config.Store) string {
+	return filepath.Join(filepath.Dir(store.Path()), "tui-view.json")
+}
+
+// loadTUIViewState reads the saved view. A missing or unreadable file, or one
+// that does not parse, means there is none and the picker starts from its
+// default view.
+func loadTUIViewState(path string) *tui.ViewState {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var view tui.ViewState
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil
+	}
+	return &view
+}
+
+func saveTUIViewState(path string, view tui.ViewState) error {
+	data, err := json.MarshalIndent(view, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomically(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildMetadata describes the running binary for `version`.
+type buildMetadata struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+func newVersionCmd() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the helper version, commit, build date and Go version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			meta := currentBuildMetadata()
+			if asJSON {
+				data, err := json.MarshalIndent(meta, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return nil
+			}
+			printBuildMetadata(cmd.OutOrStdout(), meta)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print JSON")
+	return cmd
+}
+
+// currentBuildMetadata returns what release builds stamp through -ldflags,
+// filling the gaps from the Go build info: a `go install` of a tagged module
+// only knows its module version, and a build from a checkout records the
+// VCS revision and commit time.
+func currentBuildMetadata() buildMetadata {
+	meta := buildMetadata{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	info, ok := readBuildInfo()
+	if !ok {
+		return meta
+	}
+	if commit == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		meta.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if meta.Commit == "" {
+				meta.Commit = setting.Value
+			}
+		case "vcs.time":
+			if meta.Date == "" {
+				meta.Date = setting.Value
+			}
+		case "vcs.modified":
+			meta.Modified = setting.Value == "true"
+		}
+	}
+	return meta
+}
+
+func printBuildMetadata(out io.Writer, meta buildMetadata) {
+	_, _ = fmt.Fprintf(out, "version:  %s\n", meta.Version)
+	if meta.Commit != "" {
+		commitLine := meta.Commit
+		if meta.Modified {
+			commitLine += " (modified)"
+		}
+		_, _ = fmt.Fprintf(out, "commit:   %s\n", commitLine)
+	}
+	if meta.Date != "" {
+		_, _ = fmt.Fprintf(out, "built:    %s\n", meta.Date)
+	}
+	_, _ = fmt.Fprintf(out, "go:       %s\n", meta.GoVersion)
+	_, _ = fmt.Fprintf(out, "platform: %s\n", meta.Platform)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func stubBuildInfo(t *testing.T, v, c, d string, info *debug.BuildInfo) {
+	t.Helper()
+	prevVersion, prevCommit, prevDate, prevRead := version, commit, date, readBuildInfo
+	version, commit, date = v, c, d
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return info, info != nil }
+	t.Cleanup(func() {
+		version, commit, date, readBuildInfo = prevVersion, prevCommit, prevDate, prevRead
+	})
+}
+
+func runVersionCmd(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := newVersionCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	return out.String()
+}
+
+func TestVersionCmdPrintsReleaseStamp(t *testing.T) {
+	stubBuildInfo(t, "v1.2.3", "abc1234", "2026-05-01T10:00:00Z", &debug.BuildInfo{
+		Main:     debug.Module{Version: "v9.9.9"},
+		Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "ffffffff"}, {Key: "vcs.time", Value: "2020-01-01T00:00:00Z"}},
+	})
+
+	out := runVersionCmd(t)
+	for _, want := range []string{
+		"version:  v1.2.3\n",
+		"commit:   abc1234\n",
+		"built:    2026-05-01T10:00:00Z\n",
+		"go:       " + runtime.Version() + "\n",
+		"platform: " + runtime.GOOS + "/" + runtime.GOARCH + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestVersionCmdFallsBackToBuildInfo(t *testing.T) {
+	stubBuildInfo(t, "v0.1.13", "", "", &debug.BuildInfo{
+		Main: debug.Module{Version: "v0.2.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123abcd"},
+			{Key: "vcs.time", Value: "2026-06-01T00:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+
+	var meta buildMetadata
+	if err := json.Unmarshal([]byte(runVersionCmd(t, "--json")), &meta); err != nil {
+		t.Fatalf("decode --json output: %v", err)
+	}
+	if meta.Version != "v0.2.0" || meta.Commit != "0123abcd" || meta.Date != "2026-06-01T00:00:00Z" || !meta.Modified || meta.GoVersion != runtime.Version() {
+		t.Fatalf("metadata = %+v", meta)
+	}
+	if out := runVersionCmd(t); !strings.Contains(out, "commit:   0123abcd (modified)\n") {
+		t.Fatalf("text output:\n%s", out)
+	}
+
+	// A local build reports "(devel)" as its module version; keep the
+	// source default then.
+	stubBuildInfo(t, "v0.1.13", "", "", &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}})
+	if out := runVersionCmd(t); !strings.Contains(out, "version:  v0.1.13\n") || strings.Contains(out, "commit:") {
+		t.Fatalf("devel build output:\n%s", out)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+
+	"github.com/baaaaaaaka/codex-helper/internal/appdirs"
 )
 
 const envCodexHome = "CODEX_HOME"
@@ -66,7 +68,7 @@ func ResolveCodexDirSelection(override string) (CodexDirSelection, error) {
 }
 
 func codexDirSelectionForPath(raw string, source string) CodexDirSelection {
-	dir := filepath.Clean(os.ExpandEnv(strings.TrimSpace(raw)))
+	dir := filepath.Clean(filepath.FromSlash(appdirs.ExpandHome(os.ExpandEnv(strings.TrimSpace(raw)))))
 	selection := CodexDirSelection{
 		Dir:    dir,
 		Source: source,
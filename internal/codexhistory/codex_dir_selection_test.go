@@ -52,6 +52,66 @@ func TestResolveCodexDirSelection_UsesCODEXHOMEEnv(t *testing.T) {
 	}
 }
 
+func TestResolveCodexDirSelection_CleansOverrideAndEnvPaths(t *testing.T) {
+	setResolveCodexDirHooksForTest(t)
+	env := map[string]string{}
+	resolveCodexDirGetenv = func(key string) string { return env[key] }
+
+	tests := []struct {
+		name     string
+		override string
+		env      map[string]string
+		want     string
+		home     string
+	}{
+		{name: "trailing slash", override: "/custom/path/", want: "/custom/path"},
+		{name: "dot segments", override: "/custom/./nested/../path", want: "/custom/path"},
+		{name: "codex home with slash", override: "/home/me/.codex/", want: "/home/me/.codex", home: "/home/me"},
+		{name: "env trailing slash", env: map[string]string{EnvCodexDir: "/env/codex//"}, want: "/env/codex"},
+		{name: "codex home env dots", env: map[string]string{envCodexHome: "/env/./home/"}, want: "/env/home"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env = tt.env
+			got, err := ResolveCodexDirSelection(tt.override)
+			if err != nil {
+				t.Fatalf("ResolveCodexDirSelection: %v", err)
+			}
+			if want := filepath.FromSlash(tt.want); got.Dir != want {
+				t.Fatalf("Dir = %q, want %q", got.Dir, want)
+			}
+			if want := filepath.FromSlash(tt.home); got.Home != want {
+				t.Fatalf("Home = %q, want %q", got.Home, want)
+			}
+			if sessions := filepath.Join(got.Dir, "sessions"); sessions != filepath.Join(filepath.FromSlash(tt.want), "sessions") {
+				t.Fatalf("sessions dir = %q", sessions)
+			}
+		})
+	}
+}
+
+func TestResolveCodexDirSelection_ExpandsTildeOverride(t *testing.T) {
+	setResolveCodexDirHooksForTest(t)
+	resolveCodexDirGetenv = func(string) string { return "" }
+	home := filepath.Join(t.TempDir(), "home")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	for override, want := range map[string]string{
+		"~":          home,
+		"~/my-codex": filepath.Join(home, "my-codex"),
+		"~/.codex/":  filepath.Join(home, ".codex"),
+	} {
+		got, err := ResolveCodexDirSelection(override)
+		if err != nil {
+			t.Fatalf("ResolveCodexDirSelection(%q): %v", override, err)
+		}
+		if got.Dir != want {
+			t.Fatalf("ResolveCodexDirSelection(%q).Dir = %q, want %q", override, got.Dir, want)
+		}
+	}
+}
+
 func TestResolveCodexDirSelection_UsesTrustedUserHomeHintWhenRunningAsRoot(t *testing.T) {
 	setResolveCodexDirHooksForTest(t)
 
@@ -3,9 +3,13 @@ package codexhistory
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -281,6 +285,26 @@ func TestShouldSkipFirstPrompt(t *testing.T) {
 // Session.DisplayTitle — all paths
 // ---------------------------------------------------------------------------
 
+func TestSessionResumable(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", true},
+		{"019A2B3C-4D5E-6F70-8192-A3B4C5D6E7F8", true},
+		{"", false},
+		{"sess-1", false},
+		{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeeZ", false},
+		{"aaaaaaaaabbbb-cccc-dddd-eeeeeeeeeeee", false},
+		{"rollout-2026-01-01T00-00-00", false},
+	}
+	for _, tt := range tests {
+		if got := (Session{SessionID: tt.id}).Resumable(); got != tt.want {
+			t.Errorf("Resumable(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
 func TestSessionDisplayTitle(t *testing.T) {
 	tests := []struct {
 		name string
@@ -558,6 +582,28 @@ func TestMergeSessionMetadata_MessageCountKeepHigher(t *testing.T) {
 	}
 }
 
+func TestMergeSessionMetadata_LastAssistantSnippetFromNewer(t *testing.T) {
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	base := Session{ModifiedAt: t1, LastAssistantSnippet: "old"}
+	other := Session{ModifiedAt: t2, LastAssistantSnippet: "new"}
+	if got := mergeSessionMetadata(base, other).LastAssistantSnippet; got != "new" {
+		t.Errorf("LastAssistantSnippet = %q, want new", got)
+	}
+	if got := mergeSessionMetadata(other, base).LastAssistantSnippet; got != "new" {
+		t.Errorf("LastAssistantSnippet = %q, want new kept", got)
+	}
+}
+
+func TestMergeSessionMetadata_HadErrorFromEither(t *testing.T) {
+	if !mergeSessionMetadata(Session{}, Session{HadError: true}).HadError {
+		t.Error("HadError from other not kept")
+	}
+	if !mergeSessionMetadata(Session{HadError: true}, Session{}).HadError {
+		t.Error("HadError from base not kept")
+	}
+}
+
 func TestMergeSessionMetadata_CreatedAtTakeEarlier(t *testing.T) {
 	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
 	t2 := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
@@ -732,6 +778,37 @@ func TestSessionWorkingDir_Whitespace(t *testing.T) {
 	}
 }
 
+func TestSessionWorkingDirWithFallback_ExistingDir(t *testing.T) {
+	dir := t.TempDir()
+	if got := SessionWorkingDirWithFallback(Session{ProjectPath: dir}, "/home/u"); got != dir {
+		t.Errorf("got %q, want %q", got, dir)
+	}
+}
+
+func TestSessionWorkingDirWithFallback_GitRoot(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s := Session{ProjectPath: filepath.Join(repo, "deleted", "subdir")}
+	if got := SessionWorkingDirWithFallback(s, "/home/u"); got != repo {
+		t.Errorf("got %q, want git root %q", got, repo)
+	}
+}
+
+func TestSessionWorkingDirWithFallback_NoRepo(t *testing.T) {
+	s := Session{ProjectPath: filepath.Join(t.TempDir(), "gone")}
+	if got := SessionWorkingDirWithFallback(s, "/home/u"); got != "/home/u" {
+		t.Errorf("got %q, want fallback", got)
+	}
+	if got := SessionWorkingDirWithFallback(s, ""); got != s.ProjectPath {
+		t.Errorf("empty fallback: got %q, want raw path", got)
+	}
+	if got := SessionWorkingDirWithFallback(Session{}, "/home/u"); got != "" {
+		t.Errorf("empty path: got %q, want empty", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // globRecursive
 // ---------------------------------------------------------------------------
@@ -830,6 +907,26 @@ func TestFindSessionByID_NotFound(t *testing.T) {
 	}
 }
 
+func TestMostRecentSession_SkipsHelperSessions(t *testing.T) {
+	now := time.Now()
+	projects := []Project{
+		{Key: "/a", Path: "/a", Sessions: []Session{
+			{SessionID: "old", FirstPrompt: "old work", ModifiedAt: now.Add(-time.Hour)},
+			{SessionID: "debug", FirstPrompt: "[codex-helper-debug] smoke", ModifiedAt: now.Add(time.Hour)},
+		}},
+		{Key: "/b", Path: "/b", Sessions: []Session{
+			{SessionID: "latest", FirstPrompt: "latest work", ModifiedAt: now},
+		}},
+	}
+	session, project, ok := MostRecentSession(projects)
+	if !ok || session.SessionID != "latest" || project.Path != "/b" {
+		t.Fatalf("MostRecentSession = %q in %q (ok=%v), want latest in /b", session.SessionID, project.Path, ok)
+	}
+	if _, _, ok := MostRecentSession(nil); ok {
+		t.Fatal("MostRecentSession(nil) reported a session")
+	}
+}
+
 func TestFindSessionByID_FoundViaDiscoveryFallback(t *testing.T) {
 	// Place file in a subdirectory so glob pattern might not match directly
 	tmpDir, sessionsDir, projDir := setupCodexDir(t)
@@ -1232,6 +1329,61 @@ func TestCollectSessionFiles_Nested(t *testing.T) {
 	}
 }
 
+func TestCollectSessionFiles_Exclude(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{
+		"2026/01/keep.jsonl",
+		"2026/02/keep.jsonl",
+		"archive/old.jsonl",
+		"2026/archive/old.jsonl",
+		"2025/01/old.jsonl",
+		"2026/01/scratch.bak.jsonl",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := collectSessionFiles(dir, "archive", "2025/*", "*.bak.jsonl")
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+	if want := []string{"2026/01/keep.jsonl", "2026/02/keep.jsonl"}; !slices.Equal(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverProjects_ExcludeSkipsSubtree(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	archiveDir := filepath.Join(sessionsDir, "archive")
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "2026-06-01T10:00:00Z", projDir, `"cli"`, "current work")
+	writeSessionFile(t, archiveDir, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", "2026-06-01T10:00:00Z", projDir, `"cli"`, "archived work")
+
+	projects, err := DiscoverProjects(tmpDir, DiscoverOptions{Exclude: []string{"archive"}})
+	if err != nil {
+		t.Fatalf("DiscoverProjects: %v", err)
+	}
+	if len(projects) != 1 || len(projects[0].Sessions) != 1 || projects[0].Sessions[0].FirstPrompt != "current work" {
+		t.Fatalf("projects = %+v, want only the session outside archive/", projects)
+	}
+
+	if _, err := DiscoverProjects(tmpDir, DiscoverOptions{Exclude: []string{"[archive"}}); err == nil || !strings.Contains(err.Error(), "invalid exclude pattern") {
+		t.Fatalf("expected a bad pattern error, got %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // session cache
 // ---------------------------------------------------------------------------
@@ -1350,6 +1502,9 @@ func TestDiscoverProjects_NoSessionsDir(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for missing sessions dir")
 	}
+	if !errors.Is(err, ErrNoSessionsDir) {
+		t.Fatalf("error = %v, want ErrNoSessionsDir", err)
+	}
 }
 
 func TestDiscoverProjects_EmptySessionsDir(t *testing.T) {
@@ -1404,6 +1559,204 @@ func TestDiscoverProjects_DeduplicateBySessionID(t *testing.T) {
 	}
 }
 
+func TestDiscoverProjects_MergesResumedFiles(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	sessionID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+
+	f1 := filepath.Join(sessionsDir, "rollout-2026-01-01T00-00-00-"+sessionID+".jsonl")
+	f2 := filepath.Join(sessionsDir, "rollout-2026-01-02T00-00-00-"+sessionID+".jsonl")
+	copied := filepath.Join(sessionsDir, "rollout-2026-01-01T12-00-00-"+sessionID+".jsonl")
+	content1 := `{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"` + sessionID + `","cwd":"` + jsonEscapePath(projDir) + `","source":"cli"}}
+{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"original prompt"}]}}
+{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"first reply"}]}}
+`
+	// The resumed file replays the conversation before the new message.
+	content2 := `{"timestamp":"2026-01-02T00:00:00Z","type":"session_meta","payload":{"id":"` + sessionID + `","cwd":"` + jsonEscapePath(projDir) + `","source":"cli"}}
+{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"original prompt"}]}}
+{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"first reply"}]}}
+{"timestamp":"2026-01-02T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"follow-up"}]}}
+`
+	// Write the newer file first so discovery order does not decide the result.
+	if err := os.WriteFile(f2, []byte(content2), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f1, []byte(content1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(copied, []byte(content1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	all := collectAllSessions(projects)
+	if len(all) != 1 {
+		t.Fatalf("expected 1 merged session, got %d", len(all))
+	}
+	got := all[0]
+	if got.MessageCount != 3 {
+		t.Errorf("MessageCount = %d, want 3 with copied and replayed messages counted once", got.MessageCount)
+	}
+	if got.FirstPrompt != "original prompt" {
+		t.Errorf("FirstPrompt = %q, want the original prompt", got.FirstPrompt)
+	}
+	if !got.CreatedAt.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !got.ModifiedAt.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("span = %v..%v", got.CreatedAt, got.ModifiedAt)
+	}
+	if got.FilePath != f2 {
+		t.Errorf("FilePath = %q, want newest file %q", got.FilePath, f2)
+	}
+
+	found, err := FindSessionByID(tmpDir, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.FilePath != f2 || found.MessageCount != 3 {
+		t.Errorf("FindSessionByID = %+v, want merged session", found)
+	}
+}
+
+func TestDiscoverProjects_IncludeEmptyKeepsEmptySessions(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "2026-01-01T00:00:00Z", projDir, `"cli"`, "real prompt")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", "2026-01-01T00:00:00Z", projDir, `"cli"`, "")
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if n := len(collectAllSessions(projects)); n != 1 {
+		t.Fatalf("default discovery returned %d sessions, want 1", n)
+	}
+
+	projects, err = DiscoverProjects(tmpDir, DiscoverOptions{IncludeEmpty: true})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	all := collectAllSessions(projects)
+	if len(all) != 2 {
+		t.Fatalf("IncludeEmpty returned %d sessions, want 2", len(all))
+	}
+}
+
+func TestDiscoverProjects_ContentDigestTracksFileChanges(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	path := writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "2026-01-01T00:00:00Z", projDir, `"cli"`, "prompt")
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if got := collectAllSessions(projects)[0].ContentDigest; got != "" {
+		t.Fatalf("ContentDigest = %q without the option, want empty", got)
+	}
+
+	digestOf := func() string {
+		t.Helper()
+		projects, err := DiscoverProjects(tmpDir, DiscoverOptions{ContentDigest: true})
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+		return collectAllSessions(projects)[0].ContentDigest
+	}
+	first := digestOf()
+	want, err := SessionFileDigest(path)
+	if err != nil {
+		t.Fatalf("SessionFileDigest: %v", err)
+	}
+	if first != want || len(first) != 64 {
+		t.Fatalf("ContentDigest = %q, want %q", first, want)
+	}
+	if again := digestOf(); again != first {
+		t.Fatalf("digest changed without a file change: %q -> %q", first, again)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"reply"}]}}` + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	if changed := digestOf(); changed == first {
+		t.Fatal("digest should change after the rollout file grows")
+	}
+}
+
+func TestDiscoverProjects_ReportsProgress(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "2026-01-01T00:00:00Z", projDir, `"cli"`, "one")
+	other := filepath.Join(sessionsDir, "2026")
+	if err := os.MkdirAll(other, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, other, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", "2026-01-01T00:00:00Z", projDir, `"cli"`, "two")
+
+	var calls [][2]int
+	_, err := DiscoverProjects(tmpDir, DiscoverOptions{Progress: func(processed, total int) {
+		calls = append(calls, [2]int{processed, total})
+	}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(calls) == 0 || calls[len(calls)-1] != [2]int{2, 2} {
+		t.Fatalf("progress calls = %v, want to finish at 2/2", calls)
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i][0] <= calls[i-1][0] {
+			t.Fatalf("progress repeated or went backwards: %v", calls)
+		}
+	}
+}
+
+func TestDiscoverFromFiles_OnlyListedFiles(t *testing.T) {
+	_, sessionsDir, projDir := setupCodexDir(t)
+	listed := writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", "2026-01-01T00:00:00Z", projDir, `"cli"`, "listed")
+	other := filepath.Join(t.TempDir(), "other")
+	if err := os.MkdirAll(other, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, other, "aaaaaaaa-bbbb-cccc-dddd-ffffffffffff", "2026-01-01T00:00:00Z", projDir, `"cli"`, "not listed")
+
+	projects, err := DiscoverFromFiles([]string{"", listed, " " + listed + " ", filepath.Join(sessionsDir, "notes.jsonl")})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	all := collectAllSessions(projects)
+	if len(all) != 1 || all[0].FirstPrompt != "listed" {
+		t.Fatalf("sessions = %+v, want only the listed file", all)
+	}
+
+	projects, err = DiscoverFromFiles(nil)
+	if err != nil || len(projects) != 0 {
+		t.Fatalf("empty list = %v, %v", projects, err)
+	}
+}
+
+func TestDiscoverProjects_ProjectPathKeepsOnlyThatProject(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	otherDir := t.TempDir()
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000001", "2026-01-01T00:00:00Z", projDir, `"cli"`, "mine")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000002", "2026-01-01T00:00:00Z", otherDir, `"cli"`, "other")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000003", "2026-01-01T00:00:00Z", otherDir, `{"subagent":"review"}`, "orphan elsewhere")
+
+	projects, err := DiscoverProjects(tmpDir, DiscoverOptions{ProjectPath: projDir + string(filepath.Separator)})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Path != projDir || len(projects[0].Sessions) != 1 || projects[0].Sessions[0].FirstPrompt != "mine" {
+		t.Fatalf("projects = %+v, want only %s", projects, projDir)
+	}
+
+	projects, err = DiscoverProjects(tmpDir, DiscoverOptions{ProjectPath: t.TempDir()})
+	if err != nil || len(projects) != 0 {
+		t.Fatalf("unrelated dir = %+v, %v", projects, err)
+	}
+}
+
 func TestDiscoverProjects_InvalidSessionIDSkipped(t *testing.T) {
 	tmpDir, sessionsDir, projDir := setupCodexDir(t)
 
@@ -1604,6 +1957,40 @@ func TestDiscoverProjects_UnreadableFileReturnsFirstErr(t *testing.T) {
 	}
 }
 
+func TestProjectsFromSessionFiles_CollectsEveryUnreadableFile(t *testing.T) {
+	_, sessionsDir, projDir := setupCodexDir(t)
+
+	goodID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	good := writeSessionFile(t, sessionsDir, goodID, "2026-01-01T00:00:00Z", projDir, `"cli"`, "good session")
+	missing := []string{
+		filepath.Join(sessionsDir, "rollout-2026-01-01T00-00-00-bbbbbbbb-1111-2222-3333-444444444444.jsonl"),
+		filepath.Join(sessionsDir, "rollout-2026-01-01T00-00-00-cccccccc-1111-2222-3333-444444444444.jsonl"),
+	}
+
+	projects, err := projectsFromSessionFiles(context.Background(), append([]string{good}, missing...), historyIndex{}, DiscoverOptions{})
+	if findSession(collectAllSessions(projects), goodID) == nil {
+		t.Fatal("good session should be found")
+	}
+	var unreadable *UnreadableFilesError
+	if !errors.As(err, &unreadable) {
+		t.Fatalf("err = %v, want *UnreadableFilesError", err)
+	}
+	if len(unreadable.Errs) != len(missing) {
+		t.Fatalf("Errs = %v, want one per missing file", unreadable.Errs)
+	}
+	for i, path := range missing {
+		if !strings.Contains(unreadable.Errs[i].Error(), path) {
+			t.Errorf("Errs[%d] = %v, want it to name %s", i, unreadable.Errs[i], path)
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("errors.Is(err, os.ErrNotExist) = false for %v", err)
+	}
+	if !strings.Contains(err.Error(), "and 1 more unreadable files") {
+		t.Errorf("Error() = %q, want the remaining count", err.Error())
+	}
+}
+
 func TestDiscoverProjects_ModifiedAtFallbackToCreatedAt(t *testing.T) {
 	// Session with CreatedAt from content but no separate ModifiedAt.
 	tmpDir, sessionsDir, projDir := setupCodexDir(t)
@@ -1653,6 +2040,33 @@ func TestProcessMetaLine_ResponseItemNonMessage(t *testing.T) {
 	}
 }
 
+func TestProcessMetaLine_AssistantKeepsLastSnippet(t *testing.T) {
+	var meta sessionFileMeta
+	processMetaLine([]byte(`{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"first"}]}}`), &meta)
+	processMetaLine([]byte(`{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"done:\n  all   green"}]}}`), &meta)
+	if meta.LastAssistantSnippet != "done: all green" {
+		t.Errorf("LastAssistantSnippet = %q", meta.LastAssistantSnippet)
+	}
+
+	long := strings.Repeat("x", lastAssistantSnippetRunes+50)
+	processMetaLine([]byte(`{"timestamp":"2026-01-01T00:02:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"`+long+`"}]}}`), &meta)
+	if n := len([]rune(meta.LastAssistantSnippet)); n != lastAssistantSnippetRunes+1 {
+		t.Errorf("snippet length = %d, want capped", n)
+	}
+}
+
+func TestProcessMetaLine_ErrorEventSetsHadError(t *testing.T) {
+	var meta sessionFileMeta
+	processMetaLine([]byte(`{"timestamp":"2026-01-01T00:00:00Z","type":"event_msg","payload":{"type":"token_count"}}`), &meta)
+	if meta.HadError {
+		t.Fatal("HadError set by a non-error event")
+	}
+	processMetaLine([]byte(`{"timestamp":"2026-01-01T00:01:00Z","type":"event_msg","payload":{"type":"error","message":"stream disconnected before completion"}}`), &meta)
+	if !meta.HadError {
+		t.Fatal("HadError not set by an error event")
+	}
+}
+
 func TestProcessMetaLine_ResponseItemDeveloperRole(t *testing.T) {
 	// role is "developer" → not "user" or "assistant" → returns early.
 	var meta sessionFileMeta
@@ -1805,3 +2219,97 @@ func TestFindSessionByID_HistoryEnrichment(t *testing.T) {
 		t.Errorf("FirstPrompt = %q, want enriched from history", sess.FirstPrompt)
 	}
 }
+
+func TestDiscoverProjects_LimitReadsOnlyLatestFiles(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	ids := []string{
+		"aaaaaaaa-0000-0000-0000-000000000001",
+		"aaaaaaaa-0000-0000-0000-000000000002",
+		"aaaaaaaa-0000-0000-0000-000000000003",
+	}
+	for i, id := range ids {
+		path := writeSessionFile(t, sessionsDir, id, "2026-01-01T00:00:00Z", projDir, `"cli"`, "prompt")
+		renamed := filepath.Join(sessionsDir, fmt.Sprintf("rollout-2026-01-0%dT00-00-00-%s.jsonl", i+1, id))
+		if err := os.Rename(path, renamed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects, err := DiscoverProjects(tmpDir, DiscoverOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	got := map[string]bool{}
+	for _, session := range collectAllSessions(projects) {
+		got[session.SessionID] = true
+	}
+	if len(got) != 2 || !got[ids[1]] || !got[ids[2]] {
+		t.Fatalf("sessions = %v, want only the two newest", got)
+	}
+
+	projects, err = DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if n := len(collectAllSessions(projects)); n != 3 {
+		t.Fatalf("sessions without a limit = %d, want 3", n)
+	}
+}
+
+func TestReadSessionFile_RejectsUnresumableRollouts(t *testing.T) {
+	resetSessionFileCache()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	good := write("copy.jsonl", `{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"11111111-2222-3333-4444-555555555555","cwd":"/work/app","source":"cli"}}`)
+	sess, proj, err := ReadSessionFile(good)
+	if err != nil {
+		t.Fatalf("ReadSessionFile: %v", err)
+	}
+	if sess.SessionID != "11111111-2222-3333-4444-555555555555" || sess.FilePath != good || proj.Path != "/work/app" {
+		t.Fatalf("got session %#v, project %#v", sess, proj)
+	}
+
+	for name, content := range map[string]string{
+		"bad-id.jsonl":   `{"type":"session_meta","payload":{"id":"not-a-uuid","cwd":"/work/app","source":"cli"}}`,
+		"subagent.jsonl": `{"type":"session_meta","payload":{"id":"11111111-2222-3333-4444-666666666666","cwd":"/work/app","source":{"subagent":{"thread_spawn":{"parent_thread_id":"11111111-2222-3333-4444-555555555555","depth":1}}}}}`,
+		"empty.jsonl":    `{"hello":"world"}`,
+	} {
+		if _, _, err := ReadSessionFile(write(name, content)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSessionJSONOmitsUnsetAddedFields(t *testing.T) {
+	data, err := json.Marshal(Session{SessionID: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	got := slices.Sorted(maps.Keys(fields))
+	want := []string{"CreatedAt", "FilePath", "FirstPrompt", "MessageCount", "ModifiedAt", "ProjectPath", "SessionID", "Subagents", "Summary"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Session JSON fields = %v, want %v", got, want)
+	}
+
+	data, err = json.Marshal(Session{SessionID: "s", HadError: true, PromotedSubagent: true, OrphanParentID: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"HadError":true`, `"PromotedSubagent":true`, `"OrphanParentID":"p"`} {
+		if !strings.Contains(string(data), key) {
+			t.Fatalf("Session JSON %s is missing %s", data, key)
+		}
+	}
+}
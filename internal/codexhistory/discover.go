@@ -1,26 +1,134 @@
 package codexhistory
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 )
 
-var ErrSessionsDirNotFound = errors.New("Codex sessions dir not found")
+// ErrNoSessionsDir reports that the Codex data dir has no sessions directory,
+// which usually means Codex has never run against it. An existing but empty
+// sessions directory is not an error: discovery returns no projects instead.
+var ErrNoSessionsDir = errors.New("Codex sessions dir not found")
 
 func IsSessionsDirNotFound(err error) bool {
-	return errors.Is(err, ErrSessionsDirNotFound)
+	return errors.Is(err, ErrNoSessionsDir)
 }
 
-func DiscoverProjects(codexDir string) ([]Project, error) {
-	return DiscoverProjectsContext(context.Background(), codexDir)
+// UnreadableFilesError is returned alongside the discovered projects when
+// some rollout files could not be read, or some session IDs were recorded
+// under several projects. Errs holds one error per unreadable file, in
+// discovery order; Conflicts holds one warning per duplicated session ID.
+type UnreadableFilesError struct {
+	Errs      []error
+	Conflicts []*SessionProjectConflictError
 }
 
-func DiscoverProjectsContext(ctx context.Context, codexDir string) (projects []Project, retErr error) {
+func (e *UnreadableFilesError) Error() string {
+	var parts []string
+	if len(e.Errs) > 0 {
+		msg := e.Errs[0].Error()
+		if len(e.Errs) > 1 {
+			msg += fmt.Sprintf(" (and %d more unreadable files)", len(e.Errs)-1)
+		}
+		parts = append(parts, msg)
+	}
+	if len(e.Conflicts) > 0 {
+		msg := e.Conflicts[0].Error()
+		if len(e.Conflicts) > 1 {
+			msg += fmt.Sprintf(" (and %d more duplicate session IDs)", len(e.Conflicts)-1)
+		}
+		parts = append(parts, msg)
+	}
+	if len(parts) == 0 {
+		return "no unreadable files"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *UnreadableFilesError) Unwrap() []error {
+	errs := slices.Clone(e.Errs)
+	for _, conflict := range e.Conflicts {
+		errs = append(errs, conflict)
+	}
+	return errs
+}
+
+// SessionProjectConflictError warns that the rollout files of one session ID
+// record different project paths, which happens when a rollout file is copied
+// into another project's history. The session is listed once, under Chosen:
+// the path whose files hold the most messages, with the file, message count
+// and times of those files only.
+type SessionProjectConflictError struct {
+	SessionID string
+	// Paths are the conflicting project paths, sorted.
+	Paths  []string
+	Chosen string
+}
+
+func (e *SessionProjectConflictError) Error() string {
+	return fmt.Sprintf("session %s is recorded in projects %s; listing it under %s", e.SessionID, strings.Join(e.Paths, ", "), e.Chosen)
+}
+
+// SubagentSessionError is returned by FindSessionWithProject when the ID
+// belongs to a subagent attached to a main session rather than to a main
+// session itself. Codex resumes such a subagent through its parent.
+type SubagentSessionError struct {
+	Subagent SubagentSession
+	Parent   Session
+	Project  Project
+}
+
+func (e *SubagentSessionError) Error() string {
+	return fmt.Sprintf("session %s is a subagent of %s", e.Subagent.SessionID, e.Parent.SessionID)
+}
+
+// DiscoverOptions tunes a discovery run. The zero value is the default.
+type DiscoverOptions struct {
+	// Progress, when set, is called from the discovering goroutine as rollout
+	// files are read, with the number handled so far and the total. The last
+	// call has processed == total.
+	Progress func(processed, total int)
+	// IncludeEmpty keeps sessions with no prompt, summary or messages, which
+	// are dropped by default.
+	IncludeEmpty bool
+	// ContentDigest hashes every main session's rollout file into
+	// Session.ContentDigest. It is off by default because it reads each file
+	// in full even when the metadata cache is warm.
+	ContentDigest bool
+	// Limit, when positive, reads only the Limit most recent rollout files,
+	// judged by the timestamp in their names, and skips the rest unparsed.
+	Limit int
+	// GroupUnknown splits the UnknownProjectKey project into one project per
+	// orphan subagent type or parent session, e.g. "(unknown: review)".
+	GroupUnknown bool
+	// ProjectPath, when set, keeps only sessions and subagents recorded in
+	// this directory, compared after cleaning and resolving symlinks. Other
+	// rollout files are dropped as soon as their metadata is read. Combine
+	// with Limit for a fast start in a single project.
+	ProjectPath string
+	// Exclude holds glob patterns (path.Match syntax) for paths under the
+	// sessions directory to skip, such as "archive" or "2025/*". They are
+	// matched against the slash-separated path relative to the sessions
+	// directory; a pattern without a slash also matches any single path
+	// element. A matching directory is not descended into. Patterns are
+	// checked with ValidateExcludePatterns before the walk.
+	Exclude []string
+}
+
+func DiscoverProjects(codexDir string, opts ...DiscoverOptions) ([]Project, error) {
+	return DiscoverProjectsContext(context.Background(), codexDir, opts...)
+}
+
+func DiscoverProjectsContext(ctx context.Context, codexDir string, opts ...DiscoverOptions) (projects []Project, retErr error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -33,8 +141,9 @@ func DiscoverProjectsContext(ctx context.Context, codexDir string) (projects []P
 	}
 	sessionsDir := filepath.Join(root, "sessions")
 	if !isDir(sessionsDir) {
-		return nil, fmt.Errorf("%w: %s", ErrSessionsDirNotFound, sessionsDir)
+		return nil, fmt.Errorf("%w: %s", ErrNoSessionsDir, sessionsDir)
 	}
+	maybePrunePersistentCache(ctx)
 
 	ctx, sessionMetaBatch := withSessionMetaPersistentBatch(ctx)
 	defer func() {
@@ -48,31 +157,122 @@ func DiscoverProjectsContext(ctx context.Context, codexDir string) (projects []P
 		}
 	}()
 
+	merged := mergeDiscoverOptions(opts)
+	if err := ValidateExcludePatterns(merged.Exclude); err != nil {
+		return nil, err
+	}
 	historyIdx, err := loadHistoryIndexContext(ctx, root)
 	if err != nil {
 		return nil, err
 	}
 
-	files, err := collectSessionFilesContext(ctx, sessionsDir)
+	files, err := collectSessionFilesContext(ctx, sessionsDir, merged.Exclude)
 	if err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, err
 		}
 		return nil, fmt.Errorf("walk sessions dir: %w", err)
 	}
+	return projectsFromSessionFiles(ctx, files, historyIdx, merged)
+}
+
+func mergeDiscoverOptions(opts []DiscoverOptions) DiscoverOptions {
+	var merged DiscoverOptions
+	for _, opt := range opts {
+		if opt.Progress != nil {
+			merged.Progress = opt.Progress
+		}
+		if opt.IncludeEmpty {
+			merged.IncludeEmpty = true
+		}
+		if opt.ContentDigest {
+			merged.ContentDigest = true
+		}
+		if opt.Limit > 0 {
+			merged.Limit = opt.Limit
+		}
+		if opt.GroupUnknown {
+			merged.GroupUnknown = true
+		}
+		if opt.ProjectPath != "" {
+			merged.ProjectPath = opt.ProjectPath
+		}
+		merged.Exclude = append(merged.Exclude, opt.Exclude...)
+	}
+	return merged
+}
+
+// DiscoverFromFiles builds projects from an explicit list of rollout files
+// instead of walking the sessions directory, so external finders can decide
+// which sessions to show. Paths that are blank or not named like rollout
+// files are skipped; unreadable files are reported like in DiscoverProjects.
+func DiscoverFromFiles(paths []string, opts ...DiscoverOptions) ([]Project, error) {
+	return DiscoverFromFilesContext(context.Background(), paths, opts...)
+}
+
+func DiscoverFromFilesContext(ctx context.Context, paths []string, opts ...DiscoverOptions) (projects []Project, retErr error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+
+	ctx, sessionMetaBatch := withSessionMetaPersistentBatch(ctx)
+	defer func() {
+		if errors.Is(retErr, context.Canceled) || errors.Is(retErr, context.DeadlineExceeded) {
+			return
+		}
+		if err := flushPersistentSessionMetaBatchContext(ctx, sessionMetaBatch); err != nil {
+			if retErr == nil {
+				retErr = err
+			}
+		}
+	}()
+
+	return projectsFromSessionFiles(ctx, files, historyIndex{}, mergeDiscoverOptions(opts))
+}
+
+// projectsFromSessionFiles reads the metadata of each rollout file and groups
+// the sessions into projects, enriching them from historyIdx.
+func projectsFromSessionFiles(ctx context.Context, files []string, historyIdx historyIndex, opts DiscoverOptions) ([]Project, error) {
+	files = latestSessionFiles(files, opts.Limit)
 	if len(files) == 0 {
 		return nil, nil
 	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(int, int) {}
+	}
 
-	var firstErr error
+	var fileErrs []error
 	sessionIndex := map[string]int{}
 	sessions := make([]Session, 0, len(files))
 	var pendingSubagents []SubagentSession
+	// projectSessions merges, per session ID, the files recorded under
+	// each project path separately, to resolve copied sessions.
+	projectSessions := map[string]map[string]Session{}
+	inProject := projectPathMatcher(opts.ProjectPath)
 
-	for _, filePath := range files {
+	for i, filePath := range files {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
+		progress(i, len(files))
 		name := filepath.Base(filePath)
 		sessionID := parseSessionIDFromFilename(name)
 		if sessionID == "" {
@@ -84,38 +284,14 @@ func DiscoverProjectsContext(ctx context.Context, codexDir string) (projects []P
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return nil, err
 			}
-			if firstErr == nil {
-				firstErr = fmt.Errorf("read session %s: %w", filePath, err)
-			}
+			fileErrs = append(fileErrs, fmt.Errorf("read session %s: %w", filePath, err))
 			continue
 		}
 
-		if meta.SessionID == "" {
-			meta.SessionID = sessionID
-		}
-
-		// Enrich from history.jsonl
-		if info, ok := historyIdx.lookup(sessionID); ok {
-			if meta.FirstPrompt == "" && info.FirstPrompt != "" {
-				meta.FirstPrompt = info.FirstPrompt
-			}
-			if meta.CreatedAt.IsZero() && !info.FirstPromptTime.IsZero() {
-				meta.CreatedAt = info.FirstPromptTime
-			}
-			if meta.ModifiedAt.IsZero() && !info.FirstPromptTime.IsZero() {
-				meta.ModifiedAt = info.FirstPromptTime
-			}
-		}
-
-		// Fallback timestamps from filename
-		if meta.CreatedAt.IsZero() {
-			if ts := parseTimestampFromFilename(name); !ts.IsZero() {
-				meta.CreatedAt = ts
-			}
-		}
-		if meta.ModifiedAt.IsZero() {
-			meta.ModifiedAt = meta.CreatedAt
+		if !inProject(meta.ProjectPath) {
+			continue
 		}
+		meta = enrichSessionFileMeta(meta, sessionID, name, historyIdx)
 
 		// Separate subagents from main sessions.
 		if meta.IsSubagent {
@@ -133,48 +309,162 @@ func DiscoverProjectsContext(ctx context.Context, codexDir string) (projects []P
 			continue
 		}
 
-		sess := Session{
-			SessionID:    sessionID,
-			FirstPrompt:  meta.FirstPrompt,
-			MessageCount: meta.MessageCount,
-			CreatedAt:    meta.CreatedAt,
-			ModifiedAt:   meta.ModifiedAt,
-			ProjectPath:  strings.TrimSpace(meta.ProjectPath),
-			FilePath:     filePath,
+		sess := sessionFromFileMeta(sessionID, filePath, meta)
+		if opts.ContentDigest {
+			digest, err := SessionFileDigest(filePath)
+			if err != nil {
+				fileErrs = append(fileErrs, fmt.Errorf("digest session %s: %w", filePath, err))
+			}
+			sess.ContentDigest = digest
 		}
 
-		// Deduplicate by session ID, keep the more recent
-		if existingIdx, ok := sessionIndex[sessionID]; ok {
-			if sess.ModifiedAt.After(sessions[existingIdx].ModifiedAt) {
-				sessions[existingIdx] = mergeSessionMetadata(sessions[existingIdx], sess)
+		if path := strings.TrimSpace(sess.ProjectPath); path != "" {
+			path = filepath.Clean(path)
+			byProject := projectSessions[sessionID]
+			if byProject == nil {
+				byProject = map[string]Session{}
+				projectSessions[sessionID] = byProject
 			}
+			if prev, ok := byProject[path]; ok {
+				byProject[path] = mergeResumedSession(prev, sess)
+			} else {
+				byProject[path] = sess
+			}
+		}
+
+		// A resumed conversation can span several rollout files that share
+		// the session ID; fold them into one session.
+		if existingIdx, ok := sessionIndex[sessionID]; ok {
+			sessions[existingIdx] = mergeResumedSession(sessions[existingIdx], sess)
 			continue
 		}
 		sessionIndex[sessionID] = len(sessions)
 		sessions = append(sessions, sess)
 	}
 
+	progress(len(files), len(files))
+	conflicts := resolveProjectConflicts(sessions, projectSessions)
+
 	// Associate subagents with parent sessions; orphans become top-level.
 	sessions = attachSubagents(sessions, sessionIndex, pendingSubagents)
 
-	sessions = filterEmptySessions(sessions)
+	if !opts.IncludeEmpty {
+		sessions = filterEmptySessions(sessions)
+	}
 
 	sort.Slice(sessions, func(i, j int) bool {
 		return sessions[i].ModifiedAt.After(sessions[j].ModifiedAt)
 	})
 
-	projects = groupByProject(sessions)
+	projects := groupByProject(sessions)
+	if opts.GroupUnknown {
+		projects = splitUnknownProjects(projects)
+	}
 
 	sort.Slice(projects, func(i, j int) bool {
-		return strings.ToLower(projects[i].Path) < strings.ToLower(projects[j].Path)
+		return projectPathLess(projects[i], projects[j])
 	})
 
-	if firstErr != nil {
-		return projects, firstErr
+	if len(fileErrs) > 0 || len(conflicts) > 0 {
+		return projects, &UnreadableFilesError{Errs: fileErrs, Conflicts: conflicts}
 	}
 	return projects, nil
 }
 
+// resolveProjectConflicts replaces each session whose files record more than
+// one project path with the files of the path holding the most messages,
+// breaking ties by the lexically smallest path so the choice does not depend
+// on file order, and returns a warning per such session.
+func resolveProjectConflicts(sessions []Session, projectSessions map[string]map[string]Session) []*SessionProjectConflictError {
+	var warnings []*SessionProjectConflictError
+	for i := range sessions {
+		byProject := projectSessions[sessions[i].SessionID]
+		if len(byProject) < 2 {
+			continue
+		}
+		paths := slices.Sorted(maps.Keys(byProject))
+		chosen := paths[0]
+		for _, path := range paths[1:] {
+			if byProject[path].MessageCount > byProject[chosen].MessageCount {
+				chosen = path
+			}
+		}
+		sessions[i] = byProject[chosen]
+		sessions[i].ProjectPath = chosen
+		warnings = append(warnings, &SessionProjectConflictError{
+			SessionID: sessions[i].SessionID,
+			Paths:     paths,
+			Chosen:    chosen,
+		})
+	}
+	return warnings
+}
+
+// projectPathMatcher reports whether a recorded project path is project.
+// Every path matches when project is empty. Resolved paths are remembered,
+// since most sessions share a handful of directories.
+func projectPathMatcher(project string) func(string) bool {
+	if strings.TrimSpace(project) == "" {
+		return func(string) bool { return true }
+	}
+	want := comparablePath(project)
+	resolved := map[string]bool{}
+	return func(path string) bool {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return false
+		}
+		match, ok := resolved[path]
+		if !ok {
+			match = comparablePath(path) == want
+			resolved[path] = match
+		}
+		return match
+	}
+}
+
+// enrichSessionFileMeta fills what the rollout file named name did not record
+// from history.jsonl and, for timestamps, from the file name.
+func enrichSessionFileMeta(meta sessionFileMeta, sessionID string, name string, historyIdx historyIndex) sessionFileMeta {
+	if meta.SessionID == "" {
+		meta.SessionID = sessionID
+	}
+	if info, ok := historyIdx.lookup(sessionID); ok {
+		if meta.FirstPrompt == "" && info.FirstPrompt != "" {
+			meta.FirstPrompt = info.FirstPrompt
+		}
+		if meta.CreatedAt.IsZero() && !info.FirstPromptTime.IsZero() {
+			meta.CreatedAt = info.FirstPromptTime
+		}
+		if meta.ModifiedAt.IsZero() && !info.FirstPromptTime.IsZero() {
+			meta.ModifiedAt = info.FirstPromptTime
+		}
+	}
+	if meta.CreatedAt.IsZero() {
+		if ts := parseTimestampFromFilename(name); !ts.IsZero() {
+			meta.CreatedAt = ts
+		}
+	}
+	if meta.ModifiedAt.IsZero() {
+		meta.ModifiedAt = meta.CreatedAt
+	}
+	return meta
+}
+
+func sessionFromFileMeta(sessionID string, filePath string, meta sessionFileMeta) Session {
+	return Session{
+		SessionID:            sessionID,
+		FirstPrompt:          meta.FirstPrompt,
+		MessageCount:         meta.MessageCount,
+		CreatedAt:            meta.CreatedAt,
+		ModifiedAt:           meta.ModifiedAt,
+		ProjectPath:          strings.TrimSpace(meta.ProjectPath),
+		FilePath:             filePath,
+		LastAssistantSnippet: meta.LastAssistantSnippet,
+		HadError:             meta.HadError,
+	}
+}
+
 // attachSubagents associates pending subagents with their parent sessions.
 // Subagents with a ParentSessionID that matches a known session are attached
 // to that session's Subagents slice. Orphan subagents (no parent or parent
@@ -228,6 +518,10 @@ func attachSubagents(sessions []Session, sessionIndex map[string]int, pending []
 			CreatedAt:    orphan.CreatedAt,
 			ModifiedAt:   orphan.ModifiedAt,
 			FilePath:     orphan.FilePath,
+
+			PromotedSubagent: true,
+			OrphanAgentID:    orphan.AgentID,
+			OrphanParentID:   orphan.ParentSessionID,
 		}
 		sessionIndex[orphan.SessionID] = len(sessions)
 		sessions = append(sessions, sess)
@@ -242,7 +536,7 @@ func groupByProject(sessions []Session) []Project {
 	for _, sess := range sessions {
 		key := strings.TrimSpace(sess.ProjectPath)
 		if key == "" {
-			key = "(unknown)"
+			key = UnknownProjectKey
 		}
 		if _, ok := groups[key]; !ok {
 			keys = append(keys, key)
@@ -251,24 +545,67 @@ func groupByProject(sessions []Session) []Project {
 	}
 	projects := make([]Project, 0, len(groups))
 	for _, key := range keys {
-		sessionsForKey := groups[key]
 		path := key
-		if path == "(unknown)" {
+		if path == UnknownProjectKey {
 			path = ""
 		}
-		projectKey := path
-		if projectKey == "" {
-			projectKey = "(unknown)"
-		}
 		projects = append(projects, Project{
-			Key:      projectKey,
+			Key:      key,
 			Path:     path,
-			Sessions: sessionsForKey,
+			Sessions: groups[key],
 		})
 	}
 	return projects
 }
 
+// splitUnknownProjects replaces the UnknownProjectKey project with one
+// project per orphan subagent type or parent. Path-less sessions that are
+// not orphan subagents stay under UnknownProjectKey.
+func splitUnknownProjects(projects []Project) []Project {
+	out := make([]Project, 0, len(projects))
+	for _, project := range projects {
+		if project.Key != UnknownProjectKey {
+			out = append(out, project)
+			continue
+		}
+		groups := map[string][]Session{}
+		var keys []string
+		for _, sess := range project.Sessions {
+			key := unknownSubgroupKey(sess)
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], sess)
+		}
+		for _, key := range keys {
+			out = append(out, Project{Key: key, Sessions: groups[key]})
+		}
+	}
+	return out
+}
+
+func unknownSubgroupKey(sess Session) string {
+	if agent := strings.TrimSpace(sess.OrphanAgentID); agent != "" {
+		return "(unknown: " + agent + ")"
+	}
+	if parent := strings.TrimSpace(sess.OrphanParentID); parent != "" {
+		return "(unknown: parent " + parent + ")"
+	}
+	return UnknownProjectKey
+}
+
+// projectPathLess orders projects by path, case-insensitively, with the
+// path-less unknown projects last.
+func projectPathLess(left, right Project) bool {
+	if left.Unknown() != right.Unknown() {
+		return right.Unknown()
+	}
+	if left.Unknown() {
+		return left.Key < right.Key
+	}
+	return strings.ToLower(left.Path) < strings.ToLower(right.Path)
+}
+
 func mergeSessionMetadata(base Session, other Session) Session {
 	if base.Summary == "" && other.Summary != "" {
 		base.Summary = other.Summary
@@ -280,6 +617,11 @@ func mergeSessionMetadata(base Session, other Session) Session {
 		base.MessageCount = other.MessageCount
 	}
 
+	if other.LastAssistantSnippet != "" && (base.LastAssistantSnippet == "" || other.ModifiedAt.After(base.ModifiedAt)) {
+		base.LastAssistantSnippet = other.LastAssistantSnippet
+	}
+	base.HadError = base.HadError || other.HadError
+
 	if base.CreatedAt.IsZero() {
 		base.CreatedAt = other.CreatedAt
 	} else if !other.CreatedAt.IsZero() && other.CreatedAt.Before(base.CreatedAt) {
@@ -307,6 +649,45 @@ func mergeSessionMetadata(base Session, other Session) Session {
 	return base
 }
 
+// mergeResumedSession combines two rollout files of the same conversation:
+// the time span covers both files, the first prompt comes from the older
+// file, and the newest file stays the resume target. Files that share a
+// session ID are copies of the conversation or replay it before going on, so
+// the message count is the largest one rather than the sum.
+func mergeResumedSession(a Session, b Session) Session {
+	older, newer := a, b
+	if !a.CreatedAt.IsZero() && !b.CreatedAt.IsZero() && b.CreatedAt.Before(a.CreatedAt) {
+		older, newer = b, a
+	}
+	merged := mergeSessionMetadata(older, newer)
+	merged.MessageCount = max(a.MessageCount, b.MessageCount)
+	merged.ContentDigest = combineContentDigests(older.ContentDigest, newer.ContentDigest)
+
+	latest := a
+	if b.ModifiedAt.After(a.ModifiedAt) {
+		latest = b
+	}
+	if path := strings.TrimSpace(latest.FilePath); path != "" {
+		merged.FilePath = path
+	}
+	if strings.TrimSpace(latest.ProjectPath) != "" {
+		merged.ProjectPath = latest.ProjectPath
+	}
+
+	merged.Subagents = nil
+	seen := map[string]bool{}
+	for _, sub := range append(append([]SubagentSession(nil), a.Subagents...), b.Subagents...) {
+		if sub.SessionID != "" {
+			if seen[sub.SessionID] {
+				continue
+			}
+			seen[sub.SessionID] = true
+		}
+		merged.Subagents = append(merged.Subagents, sub)
+	}
+	return merged
+}
+
 func FindSessionByID(codexDir, sessionID string) (*Session, error) {
 	if strings.TrimSpace(sessionID) == "" {
 		return nil, fmt.Errorf("empty session ID")
@@ -325,6 +706,11 @@ func FindSessionByID(codexDir, sessionID string) (*Session, error) {
 		// Try without nested glob (filepath.Glob doesn't support **)
 		matches = globRecursive(sessionsDir, sessionID)
 	}
+	var found *Session
+	var historyIdx historyIndex
+	if len(matches) > 0 {
+		historyIdx = loadHistoryIndex(root)
+	}
 	for _, filePath := range matches {
 		meta, err := readSessionFileMetaCached(filePath)
 		if err != nil {
@@ -333,24 +719,23 @@ func FindSessionByID(codexDir, sessionID string) (*Session, error) {
 		name := filepath.Base(filePath)
 		fileSessionID := parseSessionIDFromFilename(name)
 		if fileSessionID == sessionID || meta.SessionID == sessionID {
-			historyIdx := loadHistoryIndex(root)
 			if info, ok := historyIdx.lookup(sessionID); ok {
 				if meta.FirstPrompt == "" && info.FirstPrompt != "" {
 					meta.FirstPrompt = info.FirstPrompt
 				}
 			}
-			sess := &Session{
-				SessionID:    sessionID,
-				FirstPrompt:  meta.FirstPrompt,
-				MessageCount: meta.MessageCount,
-				CreatedAt:    meta.CreatedAt,
-				ModifiedAt:   meta.ModifiedAt,
-				ProjectPath:  strings.TrimSpace(meta.ProjectPath),
-				FilePath:     filePath,
+			sess := sessionFromFileMeta(sessionID, filePath, meta)
+			if found == nil {
+				found = &sess
+			} else {
+				merged := mergeResumedSession(*found, sess)
+				found = &merged
 			}
-			return sess, nil
 		}
 	}
+	if found != nil {
+		return found, nil
+	}
 
 	// Fallback: full discovery
 	projects, err := DiscoverProjects(codexDir)
@@ -386,9 +771,160 @@ func FindSessionWithProject(codexDir, sessionID string) (*Session, *Project, err
 			}
 		}
 	}
+	if sub, parent, proj, ok := findSubagent(projects, sessionID); ok {
+		return nil, nil, &SubagentSessionError{Subagent: *sub, Parent: *parent, Project: *proj}
+	}
 	return nil, nil, fmt.Errorf("session not found: %s", sessionID)
 }
 
+// ReadSessionFile builds a session and its project from one rollout file,
+// which need not live under the sessions directory, for example a copied
+// rollout. The session ID comes from the session_meta line, or else from the
+// file name, and must be one Codex can resume. Subagent rollouts are
+// rejected, since they are resumed through their parent.
+func ReadSessionFile(filePath string) (*Session, *Project, error) {
+	filePath = strings.TrimSpace(filePath)
+	if filePath == "" {
+		return nil, nil, fmt.Errorf("empty session file path")
+	}
+	meta, err := readSessionFileMeta(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	sessionID := strings.TrimSpace(meta.SessionID)
+	if sessionID == "" {
+		sessionID = parseSessionIDFromFilename(filepath.Base(filePath))
+	}
+	if sessionID == "" {
+		return nil, nil, fmt.Errorf("%s has no session ID; is it a Codex rollout file?", filePath)
+	}
+	if !IsValidSessionID(sessionID) {
+		return nil, nil, fmt.Errorf("%s has session ID %q, which Codex cannot resume", filePath, sessionID)
+	}
+	if meta.IsSubagent {
+		return nil, nil, fmt.Errorf("%s is a subagent rollout; open its parent session instead", filePath)
+	}
+	sess := sessionFromFileMeta(sessionID, filePath, meta)
+	proj := groupByProject([]Session{sess})[0]
+	return &sess, &proj, nil
+}
+
+// StageSessionFile makes session, read from a rollout by ReadSessionFile,
+// resumable by Codex, which looks sessions up by ID in the file names under
+// its own sessions dir. A rollout outside that dir is copied into the dated
+// directory of its start time and the copy's path is returned. When the dir
+// already holds a rollout with the session's ID, that is used if it is the
+// same file or has the same contents, since Codex would resume it anyway;
+// a different one is an error.
+func StageSessionFile(codexDir string, session Session) (string, error) {
+	root, err := ResolveCodexDir(codexDir)
+	if err != nil {
+		return "", err
+	}
+	sessionsDir := filepath.Join(root, "sessions")
+	data, err := os.ReadFile(session.FilePath)
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range globRecursive(sessionsDir, session.SessionID) {
+		if parseSessionIDFromFilename(filepath.Base(existing)) != session.SessionID {
+			continue
+		}
+		if sameRollout(existing, session.FilePath, data) {
+			return existing, nil
+		}
+		return "", fmt.Errorf("session %s is already recorded in %s, which Codex would resume instead of %s; open it by ID, or move that file out of %s first", session.SessionID, existing, session.FilePath, sessionsDir)
+	}
+	started := session.CreatedAt
+	if started.IsZero() {
+		started = time.Now()
+	}
+	name := filepath.Base(session.FilePath)
+	if !strings.HasPrefix(name, "rollout-") || parseSessionIDFromFilename(name) != session.SessionID {
+		name = "rollout-" + started.UTC().Format("2006-01-02T15-04-05") + "-" + session.SessionID + ".jsonl"
+	}
+	dir := filepath.Join(sessionsDir, started.UTC().Format("2006"), started.UTC().Format("01"), started.UTC().Format("02"))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, name)
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(target)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(target)
+		return "", err
+	}
+	return target, nil
+}
+
+func sameRollout(existing, filePath string, data []byte) bool {
+	a, errA := os.Stat(existing)
+	b, errB := os.Stat(filePath)
+	if errA == nil && errB == nil && os.SameFile(a, b) {
+		return true
+	}
+	other, err := os.ReadFile(existing)
+	return err == nil && bytes.Equal(other, data)
+}
+
+// FindSubagentWithParent resolves a subagent's session ID to the subagent and
+// the main session and project it is attached to. It uses the same discovery
+// and attachment as DiscoverProjects, so orphaned subagents, which are listed
+// as sessions of their own, are found by FindSessionWithProject instead.
+func FindSubagentWithParent(codexDir, subagentID string) (*SubagentSession, *Session, *Project, error) {
+	subagentID = strings.TrimSpace(subagentID)
+	if subagentID == "" {
+		return nil, nil, nil, fmt.Errorf("empty session ID")
+	}
+
+	projects, err := DiscoverProjects(codexDir)
+	if err != nil && len(projects) == 0 {
+		return nil, nil, nil, err
+	}
+	if sub, parent, proj, ok := findSubagent(projects, subagentID); ok {
+		return sub, parent, proj, nil
+	}
+	return nil, nil, nil, fmt.Errorf("subagent not found: %s", subagentID)
+}
+
+// findSubagent looks subagentID up among the subagents attached to the main
+// sessions of projects.
+func findSubagent(projects []Project, subagentID string) (*SubagentSession, *Session, *Project, bool) {
+	for i := range projects {
+		for j := range projects[i].Sessions {
+			parent := projects[i].Sessions[j]
+			for _, sub := range parent.Subagents {
+				if sub.SessionID == subagentID {
+					proj := projects[i]
+					return &sub, &parent, &proj, true
+				}
+			}
+		}
+	}
+	return nil, nil, nil, false
+}
+
+// MostRecentSession returns the user-visible session with the latest
+// ModifiedAt across projects, and the project it is listed under. Helper
+// sessions are skipped. ok is false when there is no session at all.
+func MostRecentSession(projects []Project) (session Session, project Project, ok bool) {
+	for _, p := range FilterUserVisibleProjects(projects) {
+		for _, s := range FilterUserVisibleSessions(p.Sessions) {
+			if !ok || s.ModifiedAt.After(session.ModifiedAt) {
+				session, project, ok = s, p, true
+			}
+		}
+	}
+	return session, project, ok
+}
+
 func SessionWorkingDir(s Session) string {
 	path := strings.TrimSpace(s.ProjectPath)
 	if isDir(path) {
@@ -400,6 +936,23 @@ func SessionWorkingDir(s Session) string {
 	return ""
 }
 
+// SessionWorkingDirWithFallback is SessionWorkingDir for launches: when the
+// recorded directory no longer exists it returns the closest ancestor that is
+// a git work tree root, else fallback (typically the home directory). With an
+// empty fallback it behaves like SessionWorkingDir.
+func SessionWorkingDirWithFallback(s Session, fallback string) string {
+	path := SessionWorkingDir(s)
+	if path == "" || isDir(path) || strings.TrimSpace(fallback) == "" {
+		return path
+	}
+	for dir := filepath.Dir(filepath.Clean(path)); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil && isDir(dir) {
+			return dir
+		}
+	}
+	return fallback
+}
+
 // globRecursive walks sessionsDir and returns files whose name contains sessionID.
 func globRecursive(sessionsDir, sessionID string) []string {
 	var matches []string
@@ -418,7 +971,8 @@ func globRecursive(sessionsDir, sessionID string) []string {
 	return matches
 }
 
-// ResetCache clears the session file cache. Useful for testing.
+// ResetCache clears the in-process session file cache. Useful for testing.
+// The on-disk caches shared with other processes are left alone.
 func ResetCache() {
 	resetSessionFileCache()
 }
@@ -1,9 +1,13 @@
 package codexhistory
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDiscoverProjects_SortsProjectsAlphabetically(t *testing.T) {
@@ -34,3 +38,180 @@ func TestDiscoverProjects_SortsProjectsAlphabetically(t *testing.T) {
 		t.Fatalf("expected zulu second, got %q", projects[1].Path)
 	}
 }
+
+func TestDiscoverProjects_SortsUnknownProjectLast(t *testing.T) {
+	tmpDir, sessionsDir, _ := setupCodexDir(t)
+	project := filepath.Join(tmpDir, "alpha")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-1111-2222-3333-444444444444", "2026-01-01T05:00:00Z", "", `"cli"`, "no cwd")
+	writeSessionFile(t, sessionsDir, "bbbbbbbb-1111-2222-3333-444444444444", "2026-01-01T01:00:00Z", project, `"cli"`, "alpha")
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("DiscoverProjects: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	if projects[0].Path != project {
+		t.Fatalf("expected %q first, got %q", project, projects[0].Key)
+	}
+	if projects[1].Key != UnknownProjectKey || !projects[1].Unknown() {
+		t.Fatalf("expected unknown project last, got %+v", projects[1])
+	}
+}
+
+func TestDiscoverProjects_GroupUnknownSplitsOrphansByType(t *testing.T) {
+	tmpDir, sessionsDir, _ := setupCodexDir(t)
+
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-1111-2222-3333-444444444444", "2026-01-01T05:00:00Z", "", `"cli"`, "no cwd")
+	writeSessionFile(t, sessionsDir, "bbbbbbbb-1111-2222-3333-444444444444", "2026-01-01T04:00:00Z", "", `{"subagent":"review"}`, "review work")
+	writeSessionFile(t, sessionsDir, "cccccccc-1111-2222-3333-444444444444", "2026-01-01T03:00:00Z", "", `{"subagent":"review"}`, "more review")
+
+	projects, err := DiscoverProjects(tmpDir, DiscoverOptions{GroupUnknown: true})
+	if err != nil {
+		t.Fatalf("DiscoverProjects: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", projects)
+	}
+	if projects[0].Key != UnknownProjectKey || len(projects[0].Sessions) != 1 {
+		t.Fatalf("expected plain unknown project first, got %+v", projects[0])
+	}
+	if projects[1].Key != "(unknown: review)" || len(projects[1].Sessions) != 2 {
+		t.Fatalf("expected review sub-group, got %+v", projects[1])
+	}
+	if projects[1].Path != "" {
+		t.Fatalf("sub-group path = %q, want empty", projects[1].Path)
+	}
+}
+
+func TestDiscoverProjects_NestedDateFolders(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	jsonDir := strings.ReplaceAll(projDir, `\`, `\\`)
+	write := func(rel, id, ts, source, prompt string) string {
+		t.Helper()
+		path := filepath.Join(sessionsDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := `{"timestamp":"` + ts + `","type":"session_meta","payload":{"id":"` + id + `","cwd":"` + jsonDir + `","source":` + source + `}}` + "\n" +
+			`{"timestamp":"` + ts + `","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"` + prompt + `"}]}}` + "\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	const (
+		yearEnd  = "aaaaaaaa-0000-0000-0000-000000000001"
+		archived = "aaaaaaaa-0000-0000-0000-000000000002"
+		child    = "aaaaaaaa-0000-0000-0000-000000000003"
+		newest   = "aaaaaaaa-0000-0000-0000-000000000004"
+	)
+	write("2025/12/31/rollout-2025-12-31T09-00-00-"+yearEnd+".jsonl", yearEnd, "2025-12-31T09:00:00Z", `"cli"`, "year end")
+	// Moved by hand into a deeper archive tree.
+	archivedPath := write("archive/old/2024/06/15/rollout-2024-06-15T08-30-00-"+archived+".jsonl", archived, "2024-06-15T08:30:00Z", `"cli"`, "archived")
+	// The subagent lives in another day's folder than its parent.
+	write("2026/01/02/rollout-2026-01-02T10-00-00-"+child+".jsonl", child, "2026-01-02T10:00:00Z",
+		`{"subagent":{"thread_spawn":{"parent_thread_id":"`+yearEnd+`","depth":1}}}`, "child task")
+	write("2026/01/05/rollout-2026-01-05T12-00-00-"+newest+".jsonl", newest, "2026-01-05T12:00:00Z", `"cli"`, "newest")
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("DiscoverProjects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Path != projDir {
+		t.Fatalf("projects = %+v, want one project for %s", projects, projDir)
+	}
+	sessions := projects[0].Sessions
+	if len(sessions) != 3 {
+		t.Fatalf("sessions = %+v, want 3 main sessions", sessions)
+	}
+	if sessions[0].SessionID != newest || sessions[len(sessions)-1].SessionID != archived {
+		t.Fatalf("session order = %s, %s, %s; want newest first and the archived one last", sessions[0].SessionID, sessions[1].SessionID, sessions[2].SessionID)
+	}
+	parent := findSession(sessions, yearEnd)
+	if parent == nil || len(parent.Subagents) != 1 || parent.Subagents[0].SessionID != child {
+		t.Fatalf("year-end session = %+v, want the subagent from another folder attached", parent)
+	}
+	old := findSession(sessions, archived)
+	if want := time.Date(2024, 6, 15, 8, 30, 0, 0, time.UTC); old == nil || !old.CreatedAt.Equal(want) || !old.ModifiedAt.Equal(want) {
+		t.Fatalf("archived session = %+v, want created and modified at %v", old, want)
+	}
+
+	found, err := FindSessionByID(tmpDir, archived)
+	if err != nil || found == nil || found.FilePath != archivedPath {
+		t.Fatalf("FindSessionByID(archived) = %+v, %v; want %s", found, err, archivedPath)
+	}
+
+	// Limit picks files by the timestamp in their names, whatever folder
+	// they are in.
+	ResetCache()
+	limited, err := DiscoverProjects(tmpDir, DiscoverOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("DiscoverProjects with Limit: %v", err)
+	}
+	if got := collectAllSessions(limited); len(got) != 1 || got[0].SessionID != newest {
+		t.Fatalf("Limit 1 sessions = %+v, want only the newest file across folders", got)
+	}
+}
+
+func TestDiscoverProjects_SessionIDInTwoProjectsPrefersMoreMessages(t *testing.T) {
+	sessionID := "aaaaaaaa-1111-2222-3333-444444444444"
+	for _, busyFirst := range []bool{true, false} {
+		tmpDir, sessionsDir, _ := setupCodexDir(t)
+		busy := filepath.Join(tmpDir, "busy")
+		copied := filepath.Join(tmpDir, "copied")
+		busyDir, copiedDir := filepath.Join(sessionsDir, "a"), filepath.Join(sessionsDir, "b")
+		if !busyFirst {
+			busyDir, copiedDir = copiedDir, busyDir
+		}
+		for _, dir := range []string{busy, copied, busyDir, copiedDir} {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		busyFile := writeSessionFile(t, busyDir, sessionID, "2026-01-01T01:00:00Z", busy, `"cli"`, "first")
+		more := `{"timestamp":"2026-01-01T01:01:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"second"}]}}` + "\n"
+		f, err := os.OpenFile(busyFile, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(more); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		writeSessionFile(t, copiedDir, sessionID, "2026-01-01T02:00:00Z", copied, `"cli"`, "copy")
+
+		projects, err := DiscoverProjects(tmpDir)
+		if len(projects) != 1 || projects[0].Path != busy || len(projects[0].Sessions) != 1 {
+			t.Fatalf("busyFirst=%v: expected the session under %q only, got %+v", busyFirst, busy, projects)
+		}
+		got := projects[0].Sessions[0]
+		if got.ProjectPath != busy {
+			t.Fatalf("busyFirst=%v: session project = %q, want %q", busyFirst, got.ProjectPath, busy)
+		}
+		// The newer copy must not leak its file or messages into the
+		// chosen project's session.
+		if got.FilePath != busyFile || got.MessageCount != 2 || !got.CreatedAt.Equal(time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)) {
+			t.Fatalf("busyFirst=%v: session = file %q, %d messages, created %v; want the busy project's file only", busyFirst, got.FilePath, got.MessageCount, got.CreatedAt)
+		}
+		var unreadable *UnreadableFilesError
+		if !errors.As(err, &unreadable) || len(unreadable.Errs) != 0 || len(unreadable.Conflicts) != 1 {
+			t.Fatalf("busyFirst=%v: conflicts must not count as unreadable files: %#v", busyFirst, unreadable)
+		}
+		var conflict *SessionProjectConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("busyFirst=%v: expected a project conflict warning, got %v", busyFirst, err)
+		}
+		if conflict.SessionID != sessionID || conflict.Chosen != busy || !slices.Equal(conflict.Paths, []string{busy, copied}) {
+			t.Fatalf("busyFirst=%v: conflict = %+v", busyFirst, conflict)
+		}
+	}
+}
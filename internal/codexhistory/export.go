@@ -0,0 +1,101 @@
+package codexhistory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SessionExport is a session with its full transcript and those of its
+// subagents, for sharing a whole agentic run as one record.
+type SessionExport struct {
+	Session
+	Messages []Message
+	// Subagents replaces Session.Subagents with the transcripts added.
+	Subagents []SubagentExport
+}
+
+// SubagentExport is a subagent session with its full transcript.
+type SubagentExport struct {
+	SubagentSession
+	Messages []Message
+}
+
+// ExportSession reads the transcripts of s and of every subagent in
+// s.Subagents. A subagent whose rollout cannot be read fails the export,
+// since the record would silently miss part of the run.
+func ExportSession(s Session) (SessionExport, error) {
+	export := SessionExport{Session: s}
+	export.Session.Subagents = nil
+	if s.FilePath != "" {
+		msgs, err := ReadSessionMessages(s.FilePath, 0)
+		if err != nil {
+			return SessionExport{}, err
+		}
+		export.Messages = msgs
+	}
+	for _, sub := range s.Subagents {
+		subExport := SubagentExport{SubagentSession: sub}
+		if sub.FilePath != "" {
+			msgs, err := ReadSessionMessages(sub.FilePath, 0)
+			if err != nil {
+				return SessionExport{}, fmt.Errorf("subagent %s: %w", sub.SessionID, err)
+			}
+			subExport.Messages = msgs
+		}
+		export.Subagents = append(export.Subagents, subExport)
+	}
+	return export, nil
+}
+
+// FormatSessionMarkdown renders an export as Markdown: the session's
+// details and transcript, then one section per subagent with its parent,
+// agent ID, first prompt and transcript.
+func FormatSessionMarkdown(e SessionExport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Session %s\n\n", e.SessionID)
+	writeMarkdownField(&b, "Project", e.ProjectPath)
+	writeMarkdownField(&b, "Summary", e.Summary)
+	writeMarkdownField(&b, "First prompt", e.FirstPrompt)
+	writeMarkdownTime(&b, "Created", e.CreatedAt)
+	writeMarkdownTime(&b, "Modified", e.ModifiedAt)
+	writeMarkdownMessages(&b, "##", e.Messages)
+	for _, sub := range e.Subagents {
+		fmt.Fprintf(&b, "\n## Subagent %s\n\n", sub.SessionID)
+		writeMarkdownField(&b, "Parent session", sub.ParentSessionID)
+		writeMarkdownField(&b, "Agent", sub.AgentID)
+		writeMarkdownField(&b, "First prompt", sub.FirstPrompt)
+		writeMarkdownTime(&b, "Created", sub.CreatedAt)
+		writeMarkdownTime(&b, "Modified", sub.ModifiedAt)
+		writeMarkdownMessages(&b, "###", sub.Messages)
+	}
+	return b.String()
+}
+
+func writeMarkdownField(b *strings.Builder, name, value string) {
+	value = strings.Join(strings.Fields(SanitizeTerminalText(value)), " ")
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "- %s: %s\n", name, value)
+}
+
+func writeMarkdownTime(b *strings.Builder, name string, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	fmt.Fprintf(b, "- %s: %s\n", name, t.Format(time.RFC3339))
+}
+
+// writeMarkdownMessages writes each message under a heading of the given
+// level naming its role.
+func writeMarkdownMessages(b *strings.Builder, heading string, msgs []Message) {
+	for _, msg := range msgs {
+		msg.Content = SanitizeTerminalText(msg.Content)
+		text := strings.TrimSpace(formatMessageText(msg))
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(b, "\n%s %s\n\n%s\n", heading, roleLabel(msg.Role), text)
+	}
+}
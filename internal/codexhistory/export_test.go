@@ -0,0 +1,110 @@
+package codexhistory
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func discoverExportFixture(t *testing.T) (Session, string) {
+	t.Helper()
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	parentID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	childID := "11111111-2222-3333-4444-555555555555"
+	ts := "2026-06-01T10:00:00Z"
+	writeSessionFile(t, sessionsDir, parentID, ts, projDir, `"cli"`, "split the work")
+	writeSessionFile(t, sessionsDir, childID, ts, projDir,
+		`{"subagent":{"thread_spawn":{"parent_thread_id":"`+parentID+`","depth":1}}}`, "check the tests")
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("DiscoverProjects: %v", err)
+	}
+	parent := findSession(collectAllSessions(projects), parentID)
+	if parent == nil || len(parent.Subagents) != 1 {
+		t.Fatalf("parent with one subagent not found: %+v", parent)
+	}
+	return *parent, childID
+}
+
+func TestExportSessionIncludesSubagentTranscripts(t *testing.T) {
+	parent, childID := discoverExportFixture(t)
+
+	export, err := ExportSession(parent)
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+	if len(export.Messages) != 1 || export.Messages[0].Content != "split the work" {
+		t.Fatalf("parent messages = %+v", export.Messages)
+	}
+	if len(export.Subagents) != 1 {
+		t.Fatalf("subagents = %+v", export.Subagents)
+	}
+	sub := export.Subagents[0]
+	if sub.SessionID != childID || sub.ParentSessionID != parent.SessionID || sub.AgentID != "thread_spawn" || sub.FirstPrompt != "check the tests" {
+		t.Fatalf("subagent = %+v", sub.SubagentSession)
+	}
+	if len(sub.Messages) != 1 || sub.Messages[0].Content != "check the tests" {
+		t.Fatalf("subagent messages = %+v", sub.Messages)
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		SessionID string
+		Subagents []struct {
+			ParentSessionID string
+			AgentID         string
+			Messages        []struct{ Role, Content string }
+		}
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SessionID != parent.SessionID || len(decoded.Subagents) != 1 || decoded.Subagents[0].ParentSessionID != parent.SessionID ||
+		len(decoded.Subagents[0].Messages) != 1 || decoded.Subagents[0].Messages[0].Content != "check the tests" {
+		t.Fatalf("JSON export = %s", data)
+	}
+}
+
+func TestFormatSessionMarkdownNestsSubagents(t *testing.T) {
+	parent, childID := discoverExportFixture(t)
+	export, err := ExportSession(parent)
+	if err != nil {
+		t.Fatalf("ExportSession: %v", err)
+	}
+
+	md := FormatSessionMarkdown(export)
+	for _, want := range []string{
+		"# Session " + parent.SessionID + "\n",
+		"- First prompt: split the work\n",
+		"\n## User\n\nsplit the work\n",
+		"\n## Subagent " + childID + "\n\n- Parent session: " + parent.SessionID + "\n- Agent: thread_spawn\n- First prompt: check the tests\n",
+		"\n### User\n\ncheck the tests\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "## Subagent") < strings.Index(md, "## User") {
+		t.Fatalf("subagent section should follow the parent transcript:\n%s", md)
+	}
+}
+
+func TestExportSessionFailsOnUnreadableSubagent(t *testing.T) {
+	parent, childID := discoverExportFixture(t)
+	if err := os.Remove(parent.Subagents[0].FilePath); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ExportSession(parent)
+	if err == nil || !strings.Contains(err.Error(), childID) {
+		t.Fatalf("expected error naming subagent %s, got %v", childID, err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a not-exist error, got %v", err)
+	}
+}
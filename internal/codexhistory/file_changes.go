@@ -0,0 +1,227 @@
+package codexhistory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FileChange is one file operation from an apply_patch call recorded in a
+// rollout. Diff holds the patch lines for the file as Codex wrote them: the
+// "+" lines of an added file, or the hunks of an update.
+type FileChange struct {
+	Kind      string // "add", "update" or "delete"
+	Path      string
+	MovePath  string // set when an update also renames the file
+	Diff      string
+	Timestamp time.Time
+}
+
+const (
+	patchBeginMarker = "*** Begin Patch"
+	patchEndMarker   = "*** End Patch"
+)
+
+// ReadSessionFileChanges returns the file operations of every apply_patch
+// call in a rollout, in file order. Calls are read from the tool-call
+// arguments, so a patch Codex proposed but failed to apply is listed too.
+// Reading the whole file is slower than a preview, so callers load it on
+// request rather than during discovery.
+func ReadSessionFileChanges(filePath string) ([]FileChange, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var changes []FileChange
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		// Most lines are messages or reasoning; skip them before decoding.
+		if len(line) > 0 && bytes.Contains(line, []byte(patchBeginMarker)) {
+			changes = append(changes, parseLineFileChanges(line)...)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return changes, nil
+}
+
+// parseLineFileChanges extracts file changes from a function_call or
+// custom_tool_call line. apply_patch arrives as a custom tool whose input is
+// the patch, or as a function call whose arguments carry it, possibly inside
+// a shell command.
+func parseLineFileChanges(line []byte) []FileChange {
+	var env codexEnvelope
+	if json.Unmarshal(line, &env) != nil || env.Type != "response_item" {
+		return nil
+	}
+	var call struct {
+		Type      string          `json:"type"`
+		Input     json.RawMessage `json:"input"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if json.Unmarshal(env.Payload, &call) != nil {
+		return nil
+	}
+	var raw json.RawMessage
+	switch call.Type {
+	case "custom_tool_call":
+		raw = call.Input
+	case "function_call":
+		raw = call.Arguments
+	default:
+		return nil
+	}
+	ts := parseTimestamp(env.Timestamp)
+	var changes []FileChange
+	for _, text := range jsonStrings(raw) {
+		for _, patch := range patchBlocks(text) {
+			for _, change := range parsePatchFileChanges(patch) {
+				change.Timestamp = ts
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
+
+// jsonStrings returns every string in raw. A string that is itself JSON, as
+// function-call arguments are, is searched too.
+func jsonStrings(raw json.RawMessage) []string {
+	var value any
+	if json.Unmarshal(raw, &value) != nil {
+		return nil
+	}
+	var out []string
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			var nested any
+			if trimmed := strings.TrimSpace(v); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+				if json.Unmarshal([]byte(trimmed), &nested) == nil {
+					walk(nested)
+					return
+				}
+			}
+			out = append(out, v)
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				walk(v[key])
+			}
+		}
+	}
+	walk(value)
+	return out
+}
+
+// patchBlocks returns the text between each Begin Patch / End Patch pair. A
+// block without an end marker runs to the end of text.
+func patchBlocks(text string) []string {
+	var blocks []string
+	for {
+		start := strings.Index(text, patchBeginMarker)
+		if start < 0 {
+			return blocks
+		}
+		text = text[start+len(patchBeginMarker):]
+		end := strings.Index(text, patchEndMarker)
+		if end < 0 {
+			return append(blocks, text)
+		}
+		blocks = append(blocks, text[:end])
+		text = text[end+len(patchEndMarker):]
+	}
+}
+
+// parsePatchFileChanges splits one apply_patch block into its file
+// operations.
+func parsePatchFileChanges(patch string) []FileChange {
+	var changes []FileChange
+	var diff []string
+	flush := func() {
+		if len(changes) > 0 {
+			changes[len(changes)-1].Diff = strings.Join(diff, "\n")
+		}
+		diff = nil
+	}
+	for _, line := range strings.Split(patch, "\n") {
+		line = strings.TrimRight(line, "\r")
+		kind, path := "", ""
+		switch {
+		case strings.HasPrefix(line, "*** Add File: "):
+			kind, path = "add", strings.TrimPrefix(line, "*** Add File: ")
+		case strings.HasPrefix(line, "*** Update File: "):
+			kind, path = "update", strings.TrimPrefix(line, "*** Update File: ")
+		case strings.HasPrefix(line, "*** Delete File: "):
+			kind, path = "delete", strings.TrimPrefix(line, "*** Delete File: ")
+		case strings.HasPrefix(line, "*** Move to: "):
+			if len(changes) > 0 {
+				changes[len(changes)-1].MovePath = strings.TrimSpace(strings.TrimPrefix(line, "*** Move to: "))
+			}
+			continue
+		case strings.HasPrefix(line, "*** End of File"):
+			continue
+		}
+		if kind != "" {
+			flush()
+			changes = append(changes, FileChange{Kind: kind, Path: strings.TrimSpace(path)})
+			continue
+		}
+		if len(changes) > 0 && line != "" {
+			diff = append(diff, line)
+		}
+	}
+	flush()
+	return changes
+}
+
+// FormatFileChanges renders changes as a list of files followed by each
+// file's diff.
+func FormatFileChanges(changes []FileChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Files changed:\n")
+	for _, change := range changes {
+		b.WriteString("  ")
+		b.WriteString(fileChangeHeader(change))
+		b.WriteString("\n")
+	}
+	for _, change := range changes {
+		b.WriteString("\n")
+		b.WriteString(fileChangeHeader(change))
+		b.WriteString("\n")
+		if diff := SanitizeTerminalText(change.Diff); diff != "" {
+			b.WriteString(diff)
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func fileChangeHeader(change FileChange) string {
+	header := change.Kind + " " + SanitizeTerminalText(change.Path)
+	if change.MovePath != "" {
+		header += " -> " + SanitizeTerminalText(change.MovePath)
+	}
+	return header
+}
@@ -0,0 +1,85 @@
+package codexhistory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRolloutLines(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("write rollout: %v", err)
+	}
+	return path
+}
+
+func jsonString(t *testing.T, s string) string {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReadSessionFileChanges_CustomToolAndShellPatches(t *testing.T) {
+	customPatch := "*** Begin Patch\n*** Add File: docs/new.md\n+# New\n+body\n*** Update File: main.go\n*** Move to: cmd/main.go\n@@ func main() {\n-\told()\n+\tnew()\n*** End Patch"
+	shellPatch := "*** Begin Patch\n*** Delete File: old.txt\n*** End Patch\n"
+	shellArgs := jsonString(t, `{"command":["apply_patch",`+jsonString(t, shellPatch)+`],"workdir":"/repo"}`)
+	path := writeRolloutLines(t,
+		`{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"s1","cwd":"/repo","source":"cli"}}`,
+		`{"timestamp":"2026-01-01T00:00:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"please apply *** Begin Patch from my notes"}]}}`,
+		`{"timestamp":"2026-01-01T00:00:02Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","call_id":"c1","input":`+jsonString(t, customPatch)+`}}`,
+		`{"timestamp":"2026-01-01T00:00:03Z","type":"response_item","payload":{"type":"function_call","name":"shell","call_id":"c2","arguments":`+shellArgs+`}}`,
+	)
+
+	changes, err := ReadSessionFileChanges(path)
+	if err != nil {
+		t.Fatalf("ReadSessionFileChanges: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %#v", changes)
+	}
+	if changes[0].Kind != "add" || changes[0].Path != "docs/new.md" || changes[0].Diff != "+# New\n+body" {
+		t.Fatalf("add change = %#v", changes[0])
+	}
+	if changes[1].Kind != "update" || changes[1].Path != "main.go" || changes[1].MovePath != "cmd/main.go" {
+		t.Fatalf("update change = %#v", changes[1])
+	}
+	if changes[1].Diff != "@@ func main() {\n-\told()\n+\tnew()" {
+		t.Fatalf("update diff = %q", changes[1].Diff)
+	}
+	if changes[2].Kind != "delete" || changes[2].Path != "old.txt" || changes[2].Diff != "" {
+		t.Fatalf("delete change = %#v", changes[2])
+	}
+	if changes[2].Timestamp.IsZero() {
+		t.Fatalf("expected the call's timestamp on the change")
+	}
+
+	text := FormatFileChanges(changes)
+	for _, want := range []string{"Files changed:\n  add docs/new.md\n  update main.go -> cmd/main.go\n  delete old.txt", "\nupdate main.go -> cmd/main.go\n@@ func main() {"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("formatted changes missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestReadSessionFileChanges_NoPatches(t *testing.T) {
+	path := writeRolloutLines(t,
+		`{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"ls\"]}"}}`,
+	)
+	changes, err := ReadSessionFileChanges(path)
+	if err != nil {
+		t.Fatalf("ReadSessionFileChanges: %v", err)
+	}
+	if len(changes) != 0 || FormatFileChanges(changes) != "" {
+		t.Fatalf("expected no changes, got %#v", changes)
+	}
+	if _, err := ReadSessionFileChanges(filepath.Join(t.TempDir(), "missing.jsonl")); !os.IsNotExist(err) {
+		t.Fatalf("expected a not-exist error, got %v", err)
+	}
+}
@@ -1,11 +1,38 @@
 package codexhistory
 
 import (
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func FormatSession(s Session) string {
+	var msgs []Message
+	if s.FilePath != "" {
+		msgs, _ = ReadSessionMessages(s.FilePath, 50)
+	}
+	return formatSession(s, msgs, "")
+}
+
+// FormatSessionRange is FormatSession for the count messages starting at
+// index start. When more messages follow it ends with a note naming the next
+// start index.
+func FormatSessionRange(s Session, start int, count int) string {
+	var msgs []Message
+	footer := ""
+	if s.FilePath != "" {
+		var more bool
+		var err error
+		msgs, more, err = ReadSessionMessagesRange(s.FilePath, start, count)
+		if err == nil && more {
+			footer = fmt.Sprintf("(more messages follow; next offset %d)", max(0, start)+len(msgs))
+		}
+	}
+	return formatSession(s, msgs, footer)
+}
+
+func formatSession(s Session, msgs []Message, footer string) string {
 	var b strings.Builder
 	b.WriteString("Session: ")
 	b.WriteString(s.SessionID)
@@ -32,16 +59,20 @@ func FormatSession(s Session) string {
 	}
 	b.WriteString("\n")
 
-	if s.FilePath != "" {
-		msgs, err := ReadSessionMessages(s.FilePath, 50)
-		if err == nil && len(msgs) > 0 {
-			b.WriteString(FormatMessages(msgs, 0))
-			b.WriteString("\n")
-		}
+	if len(msgs) > 0 {
+		b.WriteString(FormatMessages(msgs, 0))
+		b.WriteString("\n")
+	}
+	if footer != "" {
+		b.WriteString("\n")
+		b.WriteString(footer)
+		b.WriteString("\n")
 	}
 	return b.String()
 }
 
+// FormatMessages renders msgs as role-labelled blocks. Each message's text
+// comes from the first PreviewFormatter that accepts it.
 func FormatMessages(msgs []Message, maxLen int) string {
 	var b strings.Builder
 	for i, msg := range msgs {
@@ -51,7 +82,8 @@ func FormatMessages(msgs []Message, maxLen int) string {
 		role := roleLabel(msg.Role)
 		b.WriteString(role)
 		b.WriteString(":\n")
-		text := strings.TrimSpace(msg.Content)
+		msg.Content = SanitizeTerminalText(msg.Content)
+		text := formatMessageText(msg)
 		if maxLen > 0 {
 			text = truncateRunes(text, maxLen)
 		}
@@ -62,14 +94,26 @@ func FormatMessages(msgs []Message, maxLen int) string {
 }
 
 func FormatPreviewMessages(msgs []Message, maxLen int) string {
+	return formatLabeledMessages(msgs, maxLen, previewRoleLabel)
+}
+
+// FormatConversationMessages is FormatPreviewMessages that also shows the
+// user's prompts, for messages read with ConversationRoles.
+func FormatConversationMessages(msgs []Message, maxLen int) string {
+	return formatLabeledMessages(msgs, maxLen, conversationRoleLabel)
+}
+
+// formatLabeledMessages writes each message under its label, skipping
+// messages label maps to "".
+func formatLabeledMessages(msgs []Message, maxLen int, label func(role string) string) string {
 	var b strings.Builder
 	wrote := false
 	for _, msg := range msgs {
-		label := previewRoleLabel(msg.Role)
+		label := label(msg.Role)
 		if label == "" {
 			continue
 		}
-		text := strings.TrimSpace(msg.Content)
+		text := formatMessageText(msg)
 		if text == "" {
 			continue
 		}
@@ -117,6 +161,13 @@ func previewRoleLabel(role string) string {
 	}
 }
 
+func conversationRoleLabel(role string) string {
+	if role == "user" {
+		return "User prompt"
+	}
+	return previewRoleLabel(role)
+}
+
 func truncateRunes(s string, maxRunes int) string {
 	if maxRunes <= 0 {
 		return ""
@@ -127,3 +178,86 @@ func truncateRunes(s string, maxRunes int) string {
 	}
 	return string(runes[:maxRunes]) + "…"
 }
+
+// SanitizeTerminalText removes escape sequences and control characters that
+// would be interpreted by a terminal, keeping newlines and tabs. Tool output
+// recorded in rollouts often carries ANSI colors, cursor movement, or OSC
+// titles that would otherwise corrupt whatever is drawing the text. Bytes
+// that are not valid UTF-8, such as a binary paste, become U+FFFD, one per
+// byte, so width calculations agree with what is drawn.
+func SanitizeTerminalText(s string) string {
+	if utf8.ValidString(s) && !strings.ContainsFunc(s, isTerminalControl) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == 0x1b:
+			i = skipEscapeSequence(runes, i)
+		case r == 0x9b:
+			i = skipCSIParams(runes, i+1)
+		case r == 0x9d:
+			i = skipOSCString(runes, i+1)
+		case isTerminalControl(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isTerminalControl(r rune) bool {
+	if r == '\n' || r == '\t' {
+		return false
+	}
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f)
+}
+
+// skipEscapeSequence returns the index of the last rune of the escape
+// sequence starting at runes[start] (an ESC).
+func skipEscapeSequence(runes []rune, start int) int {
+	next := start + 1
+	if next >= len(runes) {
+		return start
+	}
+	switch runes[next] {
+	case '[':
+		return skipCSIParams(runes, next+1)
+	case ']', 'P', '_', '^', 'X':
+		return skipOSCString(runes, next+1)
+	default:
+		// Two-rune sequences such as ESC c or ESC =; intermediates (0x20-0x2f)
+		// run until the final byte.
+		for next < len(runes) && runes[next] >= 0x20 && runes[next] <= 0x2f {
+			next++
+		}
+		return min(next, len(runes)-1)
+	}
+}
+
+func skipCSIParams(runes []rune, i int) int {
+	for ; i < len(runes); i++ {
+		if runes[i] >= 0x40 && runes[i] <= 0x7e {
+			return i
+		}
+	}
+	return len(runes) - 1
+}
+
+// skipOSCString skips a string terminated by BEL or ST (ESC \).
+func skipOSCString(runes []rune, i int) int {
+	for ; i < len(runes); i++ {
+		switch runes[i] {
+		case 0x07, 0x9c:
+			return i
+		case 0x1b:
+			if i+1 < len(runes) && runes[i+1] == '\\' {
+				return i + 1
+			}
+		}
+	}
+	return len(runes) - 1
+}
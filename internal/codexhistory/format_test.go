@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // ---------------------------------------------------------------------------
@@ -262,3 +263,142 @@ func TestFormatSession_NonexistentFilePath(t *testing.T) {
 		t.Errorf("should still format header: %q", got)
 	}
 }
+
+func TestSanitizeTerminalText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "hello\n\tworld", want: "hello\n\tworld"},
+		{name: "sgr", in: "\x1b[31mred\x1b[0m text", want: "red text"},
+		{name: "cursor", in: "a\x1b[2K\x1b[1Gb", want: "ab"},
+		{name: "osc title bel", in: "\x1b]0;title\x07after", want: "after"},
+		{name: "osc title st", in: "\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\", want: "link"},
+		{name: "carriage return and bell", in: "progress\r\x07done\r\n", want: "progressdone\n"},
+		{name: "c1 csi", in: "\u009b1mbold", want: "bold"},
+		{name: "trailing escape", in: "tail\x1b", want: "tail"},
+		{name: "charset", in: "\x1b(Bok", want: "ok"},
+		{name: "unicode", in: "日本語 ✓", want: "日本語 ✓"},
+		{name: "invalid utf8", in: "caf\xe9 \xe4\xb8", want: "caf\uFFFD \uFFFD\uFFFD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeTerminalText(tt.in); got != tt.want {
+				t.Fatalf("SanitizeTerminalText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSessionPreviewHandlesInvalidUTF8(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout-2026-01-01T00-00-00-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee.jsonl")
+	bad := "binary \xff\xfe paste \xe4\xb8"
+	data := `{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"` + bad + `"}]}}` + "\n" +
+		`{"timestamp":"2026-01-01T00:00:01Z","type":"response_item","payload":{"type":"function_call_output","output":{"stdout":"` + bad + `"}}}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := ReadSessionPreviewText(path, 10, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionPreviewText: %v", err)
+	}
+	msgs, err := ReadSessionMessages(path, 10)
+	if err != nil {
+		t.Fatalf("ReadSessionMessages: %v", err)
+	}
+	formatted := FormatMessages(msgs, 0)
+	for _, s := range []string{text, formatted} {
+		if !utf8.ValidString(s) || !strings.Contains(s, "binary \uFFFD") {
+			t.Fatalf("rendered text = %q, want valid UTF-8 with replacement characters", s)
+		}
+	}
+}
+
+func TestFormatMessagesStripsEscapeSequences(t *testing.T) {
+	got := FormatMessages([]Message{{Role: "tool_result", Content: "\x1b[32mPASS\x1b[0m ok"}}, 0)
+	if strings.Contains(got, "\x1b") || !strings.Contains(got, "PASS ok") {
+		t.Fatalf("FormatMessages = %q", got)
+	}
+}
+
+func TestFormatSessionRange_NotesNextOffset(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "session.jsonl")
+	content := `{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"first question"}]}}
+{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"first answer"}]}}
+{"timestamp":"2026-01-01T00:02:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"second question"}]}}
+`
+	if err := os.WriteFile(f, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := Session{SessionID: "paged", FilePath: f}
+
+	got := FormatSessionRange(s, 0, 2)
+	if !strings.Contains(got, "first answer") || strings.Contains(got, "second question") {
+		t.Fatalf("first page = %q", got)
+	}
+	if !strings.Contains(got, "next offset 2") {
+		t.Fatalf("first page should point at the next offset: %q", got)
+	}
+
+	got = FormatSessionRange(s, 2, 2)
+	if !strings.Contains(got, "second question") || strings.Contains(got, "next offset") {
+		t.Fatalf("last page = %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// PreviewFormatter
+// ---------------------------------------------------------------------------
+
+func TestFormatMessagesIndentsJSONToolContent(t *testing.T) {
+	got := FormatMessages([]Message{{Role: "tool", Content: `Tool: lookup
+{"query":"a<b","limit":2}`}}, 0)
+	want := "Tool:\nTool: lookup\n{\n  \"limit\": 2,\n  \"query\": \"a<b\"\n}"
+	if got != want {
+		t.Fatalf("FormatMessages = %q, want %q", got, want)
+	}
+}
+
+func TestFormatMessagesKeepsDiffsAsIs(t *testing.T) {
+	diff := "diff --git a/x b/x\n@@ -1,2 +1,2 @@\n keep\n-old\n+new"
+	got := FormatMessages([]Message{{Role: "tool_result", Content: "\n" + diff + "\n\n"}}, 0)
+	if got != "Tool Result:\n"+diff {
+		t.Fatalf("FormatMessages = %q", got)
+	}
+}
+
+func TestFormatMessagesDefaultLeavesProseAlone(t *testing.T) {
+	got := FormatMessages([]Message{{Role: "assistant", Content: `  {"not":"indented"}  `}}, 0)
+	if got != `Assistant:
+{"not":"indented"}` {
+		t.Fatalf("FormatMessages = %q", got)
+	}
+}
+
+type upperToolFormatter struct{}
+
+func (upperToolFormatter) FormatMessage(msg Message) (string, bool) {
+	if msg.Role != "tool_result" {
+		return "", false
+	}
+	return strings.ToUpper(strings.TrimSpace(msg.Content)), true
+}
+
+func TestRegisterPreviewFormatterTakesPrecedence(t *testing.T) {
+	msgs := []Message{
+		{Role: "tool_result", Content: "done"},
+		{Role: "assistant", Content: "all good"},
+	}
+	unregister := RegisterPreviewFormatter(upperToolFormatter{})
+	got := FormatMessages(msgs, 0)
+	unregister()
+	if got != "Tool Result:\nDONE\n\nAssistant:\nall good" {
+		t.Fatalf("FormatMessages with custom formatter = %q", got)
+	}
+	if got := FormatMessages(msgs, 0); !strings.Contains(got, "done") {
+		t.Fatalf("custom formatter still applied after unregister: %q", got)
+	}
+}
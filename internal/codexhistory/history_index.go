@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
@@ -15,6 +17,9 @@ import (
 
 type historyIndex struct {
 	sessions map[string]*historySessionInfo
+	// tailDigest is the historyTailDigest of the indexed file, set when it
+	// ended on a complete line so a later load can resume after it.
+	tailDigest string
 }
 
 type historySessionInfo struct {
@@ -33,11 +38,24 @@ type codexHistoryEntry struct {
 
 var openHistoryIndexFile = os.Open
 
+const (
+	// historyIndexReadBufferSize is the read size for history.jsonl, which
+	// can grow to hundreds of megabytes for long-time users.
+	historyIndexReadBufferSize = 256 << 10
+	// historyTailDigestBytes is how much of the already indexed end of
+	// history.jsonl must be unchanged to resume indexing after it.
+	historyTailDigestBytes = 4 << 10
+)
+
 func loadHistoryIndex(root string) historyIndex {
 	idx, _ := loadHistoryIndexContext(context.Background(), root)
 	return idx
 }
 
+// loadHistoryIndexContext indexes ~/.codex/history.jsonl. An unchanged file
+// is served from the persistent cache. Codex only appends to it, so when
+// the cached entry covers an unchanged prefix of the file, only the lines
+// added since are parsed, on top of the cached sessions.
 func loadHistoryIndexContext(ctx context.Context, root string) (historyIndex, error) {
 	idx := historyIndex{sessions: map[string]*historySessionInfo{}}
 	if err := ctx.Err(); err != nil {
@@ -66,7 +84,20 @@ func loadHistoryIndexContext(ctx context.Context, root string) (historyIndex, er
 	}
 	defer f.Close()
 
-	reader := bufio.NewReader(f)
+	var offset int64
+	if entry, ok, err := readStalePersistentHistoryIndexContext(ctx, path); err != nil {
+		return idx, err
+	} else if ok && canResumeHistoryIndex(f, path, info, entry) {
+		idx.sessions = cloneHistorySessions(entry.Sessions)
+		offset = entry.FileCacheKey.Size
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return historyIndex{sessions: map[string]*historySessionInfo{}}, nil
+		}
+	}
+
+	// Lines appended while this runs are left for the next load, which
+	// resumes at info.Size().
+	reader := bufio.NewReaderSize(io.LimitReader(f, info.Size()-offset), historyIndexReadBufferSize)
 	for {
 		if err := ctx.Err(); err != nil {
 			return idx, err
@@ -75,39 +106,87 @@ func loadHistoryIndexContext(ctx context.Context, root string) (historyIndex, er
 		if err != nil && err != io.EOF {
 			return idx, nil
 		}
-		line = bytes.TrimSpace(line)
-		if len(line) > 0 {
-			var entry codexHistoryEntry
-			if json.Unmarshal(line, &entry) == nil && entry.SessionID != "" {
-				info := idx.sessions[entry.SessionID]
-				if info == nil {
-					info = &historySessionInfo{}
-					idx.sessions[entry.SessionID] = info
-				}
-				text := strings.TrimSpace(entry.Text)
-				if text != "" && !shouldSkipFirstPrompt(text) {
-					text = firstPromptTitleText(text)
-					if text == "" {
-						continue
-					}
-					ts := time.Unix(entry.Ts, 0)
-					if info.FirstPrompt == "" || (!ts.IsZero() && (info.FirstPromptTime.IsZero() || ts.Before(info.FirstPromptTime))) {
-						info.FirstPrompt = text
-						info.FirstPromptTime = ts
-					}
-				}
-			}
-		}
+		addHistoryIndexLine(idx, line)
 		if err == io.EOF {
 			break
 		}
 	}
+	idx.tailDigest = historyTailDigest(f, info.Size())
 	if err := writePersistentHistoryIndexContext(ctx, path, info, idx); err != nil {
 		return idx, err
 	}
 	return idx, nil
 }
 
+// addHistoryIndexLine records line's prompt as its session's first prompt
+// when it is the earliest real one seen so far. Injected system text is
+// skipped.
+func addHistoryIndexLine(idx historyIndex, line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	var entry codexHistoryEntry
+	if json.Unmarshal(line, &entry) != nil || entry.SessionID == "" {
+		return
+	}
+	info := idx.sessions[entry.SessionID]
+	if info == nil {
+		info = &historySessionInfo{}
+		idx.sessions[entry.SessionID] = info
+	}
+	text := strings.TrimSpace(entry.Text)
+	if text == "" || shouldSkipFirstPrompt(text) {
+		return
+	}
+	text = firstPromptTitleText(text)
+	if text == "" {
+		return
+	}
+	ts := time.Unix(entry.Ts, 0)
+	if info.FirstPrompt == "" || (!ts.IsZero() && (info.FirstPromptTime.IsZero() || ts.Before(info.FirstPromptTime))) {
+		info.FirstPrompt = text
+		info.FirstPromptTime = ts
+	}
+}
+
+// canResumeHistoryIndex reports whether f is entry's file with lines
+// appended: the same file, grown, and with the bytes before the cached
+// size unchanged.
+func canResumeHistoryIndex(f *os.File, path string, info os.FileInfo, entry persistentHistoryIndexEntry) bool {
+	cached := entry.FileCacheKey
+	if entry.TailDigest == "" || cached.Size <= 0 || info.Size() <= cached.Size {
+		return false
+	}
+	current := newFileCacheKey(path, info)
+	if current.Mode != cached.Mode {
+		return false
+	}
+	if cached.HasFileID && (!current.HasFileID || current.Dev != cached.Dev || current.Ino != cached.Ino) {
+		return false
+	}
+	return historyTailDigest(f, cached.Size) == entry.TailDigest
+}
+
+// historyTailDigest hashes the last historyTailDigestBytes before size. It
+// is empty when that part of f does not end with a newline, since a load
+// resuming in the middle of a line would misparse it.
+func historyTailDigest(f *os.File, size int64) string {
+	if size <= 0 {
+		return ""
+	}
+	start := max(0, size-historyTailDigestBytes)
+	buf := make([]byte, size-start)
+	if _, err := f.ReadAt(buf, start); err != nil {
+		return ""
+	}
+	if buf[len(buf)-1] != '\n' {
+		return ""
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
 func (idx historyIndex) lookup(sessionID string) (historySessionInfo, bool) {
 	if sessionID == "" || idx.sessions == nil {
 		return historySessionInfo{}, false
@@ -14,7 +14,7 @@ import (
 	"github.com/gofrs/flock"
 )
 
-const persistentCacheVersion = 3
+const persistentCacheVersion = 5
 
 type fileCacheKey struct {
 	Size          int64  `json:"size"`
@@ -45,6 +45,10 @@ type persistentHistoryIndexCache struct {
 type persistentHistoryIndexEntry struct {
 	FileCacheKey fileCacheKey                   `json:"fileCacheKey"`
 	Sessions     map[string]*historySessionInfo `json:"sessions"`
+	// TailDigest fingerprints the bytes just before FileCacheKey.Size. When
+	// set, a history.jsonl that has only been appended to since can be
+	// indexed from that offset instead of from the start.
+	TailDigest string `json:"tailDigest,omitempty"`
 }
 
 type sessionMetaPersistentState struct {
@@ -254,6 +258,27 @@ func readPersistentHistoryIndexContext(ctx context.Context, path string, info os
 	return historyIndex{}, false, nil
 }
 
+// readStalePersistentHistoryIndexContext returns the local cache entry for
+// path whatever the file looks like now, for loadHistoryIndexContext to
+// decide whether it can resume from it.
+func readStalePersistentHistoryIndexContext(ctx context.Context, path string) (persistentHistoryIndexEntry, bool, error) {
+	cachePath, err := historyIndexCacheFile()
+	if err != nil {
+		return persistentHistoryIndexEntry{}, false, nil
+	}
+	persistentHistoryIndexState.mu.Lock()
+	cache, loadErr := loadHistoryIndexPersistentStateLockedContext(ctx, cachePath)
+	persistentHistoryIndexState.mu.Unlock()
+	if loadErr != nil {
+		if isContextError(loadErr) {
+			return persistentHistoryIndexEntry{}, false, loadErr
+		}
+		return persistentHistoryIndexEntry{}, false, nil
+	}
+	entry, ok := cache.Entries[filepath.Clean(path)]
+	return entry, ok, nil
+}
+
 func writePersistentHistoryIndex(path string, info os.FileInfo, idx historyIndex) {
 	_ = writePersistentHistoryIndexContext(context.Background(), path, info, idx)
 }
@@ -264,6 +289,7 @@ func writePersistentHistoryIndexContext(ctx context.Context, path string, info o
 	entry := persistentHistoryIndexEntry{
 		FileCacheKey: newFileCacheKey(path, info),
 		Sessions:     cloneHistorySessions(idx.sessions),
+		TailDigest:   idx.tailDigest,
 	}
 
 	if err == nil {
@@ -699,6 +725,7 @@ func clonePersistentHistoryIndexCache(src persistentHistoryIndexCache) persisten
 		out.Entries[key] = persistentHistoryIndexEntry{
 			FileCacheKey: entry.FileCacheKey,
 			Sessions:     cloneHistorySessions(entry.Sessions),
+			TailDigest:   entry.TailDigest,
 		}
 	}
 	return out
@@ -0,0 +1,258 @@
+package codexhistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// persistentCachePruneInterval is how often discovery also drops local cache
+// entries for rollout files that were deleted or rewritten since they were
+// cached. Without it the caches only ever grow.
+const persistentCachePruneInterval = 24 * time.Hour
+
+var persistentCacheNow = time.Now
+
+// persistentCachePruneMarker records when the local caches were last pruned.
+// It lives in its own small file so the daily check does not have to decode
+// the metadata cache on every discovery.
+type persistentCachePruneMarker struct {
+	PrunedAtUnixNano int64 `json:"prunedAtUnixNano"`
+}
+
+func persistentCachePruneMarkerFile() (string, error) {
+	dir, err := persistentCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache_pruned_at.json"), nil
+}
+
+func writePersistentCachePruneMarker() error {
+	path, err := persistentCachePruneMarkerFile()
+	if err != nil {
+		return err
+	}
+	return writeJSONAtomically(path, persistentCachePruneMarker{PrunedAtUnixNano: persistentCacheNow().UnixNano()})
+}
+
+// CachePruneStats counts the entries PrunePersistentCache kept and removed
+// across all the caches it visited.
+type CachePruneStats struct {
+	Kept    int
+	Removed int
+}
+
+func (s *CachePruneStats) add(kept, removed int) {
+	s.Kept += kept
+	s.Removed += removed
+}
+
+// PrunePersistentCache drops cached session metadata, history indexes and
+// previews whose source file no longer exists, and metadata and indexes whose
+// file no longer matches the recorded size and mtime. It prunes the per-user
+// caches and, when codexDir is set, this writer's shared shards under it.
+func PrunePersistentCache(ctx context.Context, codexDir string) (CachePruneStats, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var stats CachePruneStats
+	if err := pruneLocalPersistentCaches(ctx, &stats); err != nil {
+		return stats, err
+	}
+	if previewPath, err := sessionPreviewCacheFile(); err == nil && isFile(previewPath) {
+		if err := updatePersistentSessionPreviewCache(previewPath, func(cache *persistentSessionPreviewCache) {
+			stats.add(pruneCacheEntries(cache.Entries, sessionPreviewEntryExists))
+		}); err != nil {
+			return stats, err
+		}
+	}
+	if codexDir == "" {
+		return stats, nil
+	}
+	root, err := ResolveCodexDir(codexDir)
+	if err != nil {
+		return stats, err
+	}
+	writerID, err := persistentCacheWriterID(ctx)
+	if err != nil || writerID == "" {
+		return stats, err
+	}
+	base := sharedPersistentCacheBase(root)
+	if shard := filepath.Join(base, "session-meta", writerID+".json"); isFile(shard) {
+		if err := updateSharedSessionMetaShardContext(ctx, shard, func(cache *persistentSessionMetaCache) {
+			stats.add(pruneCacheEntries(cache.Entries, sessionMetaEntryMatches))
+		}); err != nil {
+			return stats, err
+		}
+		invalidateSharedSessionMetaState(filepath.Dir(shard))
+	}
+	if shard := filepath.Join(base, "history-index", writerID+".json"); isFile(shard) {
+		if err := updateSharedHistoryIndexShardContext(ctx, shard, func(cache *persistentHistoryIndexCache) {
+			stats.add(pruneCacheEntries(cache.Entries, historyIndexEntryMatches))
+		}); err != nil {
+			return stats, err
+		}
+		invalidateSharedHistoryIndexState(filepath.Dir(shard))
+	}
+	return stats, nil
+}
+
+// ClearPersistentCache deletes the per-user session metadata, history index
+// and preview caches and, when codexDir is set, this writer's shared shards
+// under it, so the next discovery rereads every rollout file. It returns the
+// files it removed; caches that do not exist are skipped.
+func ClearPersistentCache(ctx context.Context, codexDir string) ([]string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var paths []string
+	for _, cacheFile := range []func() (string, error){sessionMetaCacheFile, historyIndexCacheFile, sessionPreviewCacheFile} {
+		path, err := cacheFile()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	var sharedDirs []string
+	if codexDir != "" {
+		root, err := ResolveCodexDir(codexDir)
+		if err != nil {
+			return nil, err
+		}
+		writerID, err := persistentCacheWriterID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if writerID != "" {
+			base := sharedPersistentCacheBase(root)
+			sharedDirs = []string{filepath.Join(base, "session-meta"), filepath.Join(base, "history-index")}
+			for _, dir := range sharedDirs {
+				paths = append(paths, filepath.Join(dir, writerID+".json"))
+			}
+		}
+	}
+
+	var removed []string
+	var firstErr error
+	for _, path := range paths {
+		err := os.Remove(path)
+		switch {
+		case err == nil:
+			removed = append(removed, path)
+		case !errors.Is(err, os.ErrNotExist) && firstErr == nil:
+			firstErr = err
+		}
+	}
+
+	persistentSessionMetaState.mu.Lock()
+	persistentSessionMetaState.loaded = false
+	persistentSessionMetaState.mu.Unlock()
+	persistentHistoryIndexState.mu.Lock()
+	persistentHistoryIndexState.loaded = false
+	persistentHistoryIndexState.mu.Unlock()
+	persistentSessionPreviewState.mu.Lock()
+	persistentSessionPreviewState.loaded = false
+	persistentSessionPreviewState.mu.Unlock()
+	if len(sharedDirs) == 2 {
+		invalidateSharedSessionMetaState(sharedDirs[0])
+		invalidateSharedHistoryIndexState(sharedDirs[1])
+	}
+	ResetCache()
+	return removed, firstErr
+}
+
+// maybePrunePersistentCache prunes the per-user metadata and history index
+// caches when the last prune is older than persistentCachePruneInterval. A
+// cache without a prune marker counts as freshly pruned. Failures are
+// ignored: a stale entry never matches a lookup anyway.
+func maybePrunePersistentCache(ctx context.Context) {
+	cachePath, err := sessionMetaCacheFile()
+	if err != nil || !isFile(cachePath) {
+		return
+	}
+	markerPath, err := persistentCachePruneMarkerFile()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(markerPath)
+	if errors.Is(err, os.ErrNotExist) {
+		_ = writePersistentCachePruneMarker()
+		return
+	}
+	var marker persistentCachePruneMarker
+	if err == nil {
+		err = json.Unmarshal(data, &marker)
+	}
+	if err == nil && persistentCacheNow().Sub(time.Unix(0, marker.PrunedAtUnixNano)) < persistentCachePruneInterval {
+		return
+	}
+	_ = pruneLocalPersistentCaches(ctx, &CachePruneStats{})
+}
+
+func pruneLocalPersistentCaches(ctx context.Context, stats *CachePruneStats) error {
+	if cachePath, err := sessionMetaCacheFile(); err == nil && isFile(cachePath) {
+		if err := updatePersistentSessionMetaCacheContext(ctx, cachePath, func(cache *persistentSessionMetaCache) {
+			stats.add(pruneCacheEntries(cache.Entries, sessionMetaEntryMatches))
+		}); err != nil {
+			return err
+		}
+		persistentSessionMetaState.mu.Lock()
+		persistentSessionMetaState.loaded = false
+		persistentSessionMetaState.mu.Unlock()
+	}
+	if cachePath, err := historyIndexCacheFile(); err == nil && isFile(cachePath) {
+		if err := updatePersistentHistoryIndexCacheContext(ctx, cachePath, func(cache *persistentHistoryIndexCache) {
+			stats.add(pruneCacheEntries(cache.Entries, historyIndexEntryMatches))
+		}); err != nil {
+			return err
+		}
+		persistentHistoryIndexState.mu.Lock()
+		persistentHistoryIndexState.loaded = false
+		persistentHistoryIndexState.mu.Unlock()
+	}
+	return writePersistentCachePruneMarker()
+}
+
+func sessionMetaEntryMatches(path string, entry persistentSessionMetaEntry) bool {
+	return fileMatchesCacheKey(path, entry.FileCacheKey)
+}
+
+func historyIndexEntryMatches(path string, entry persistentHistoryIndexEntry) bool {
+	return fileMatchesCacheKey(path, entry.FileCacheKey)
+}
+
+// sessionPreviewEntryExists only checks that the file is still there: a
+// rollout file that grew since its preview was cached extends the entry
+// rather than invalidating it.
+func sessionPreviewEntryExists(path string, _ persistentSessionPreviewEntry) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+func fileMatchesCacheKey(path string, key fileCacheKey) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Only a missing file is known to be stale; keep entries on other
+		// errors, which may be transient.
+		return !errors.Is(err, os.ErrNotExist)
+	}
+	return matchesFileInfo(path, info, key)
+}
+
+// pruneCacheEntries deletes the entries keep rejects and returns how many
+// were kept and removed.
+func pruneCacheEntries[E any](entries map[string]E, keep func(path string, entry E) bool) (kept, removed int) {
+	for path, entry := range entries {
+		if keep(path, entry) {
+			kept++
+			continue
+		}
+		delete(entries, path)
+		removed++
+	}
+	return kept, removed
+}
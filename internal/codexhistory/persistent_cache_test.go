@@ -8,7 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -380,6 +382,70 @@ func TestSessionFilePersistentCache_PersistsAcrossReset(t *testing.T) {
 	}
 }
 
+func TestDiscoverProjects_ConcurrentInstancesKeepSharedCacheReadable(t *testing.T) {
+	lockCodexHistoryTestHooks(t)
+	setTestUserCacheDir(t)
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+
+	const instances, rounds = 2, 8
+	var wg sync.WaitGroup
+	errs := make(chan error, instances*rounds)
+	for i := 0; i < instances; i++ {
+		wg.Add(1)
+		go func(instance int) {
+			defer wg.Done()
+			for round := 0; round < rounds; round++ {
+				sessionID := fmt.Sprintf("55555555-5555-5555-5555-%04d%08d", instance, round)
+				filePath := filepath.Join(sessionsDir, "rollout-2026-01-01T00-00-05-"+sessionID+".jsonl")
+				content := `{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"` + sessionID + `","cwd":"` + jsonEscapePath(projDir) + `","source":"cli"}}` + "\n" +
+					`{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"concurrent prompt"}]}}` + "\n"
+				if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := DiscoverProjects(tmpDir); err != nil {
+					errs <- fmt.Errorf("instance %d round %d: %w", instance, round, err)
+					return
+				}
+				// Drop the in-memory cache like a freshly started TUI would.
+				ResetCache()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	cachePath, err := sessionMetaCacheFile()
+	if err != nil {
+		t.Fatalf("sessionMetaCacheFile: %v", err)
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("read session meta cache: %v", err)
+	}
+	var cache persistentSessionMetaCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		t.Fatalf("session meta cache is not valid JSON after concurrent writes: %v", err)
+	}
+	leftovers, _ := filepath.Glob(cachePath + ".tmp-*")
+	if len(leftovers) != 0 {
+		t.Fatalf("temporary cache files left behind: %v", leftovers)
+	}
+
+	resetPersistentCacheStatesForTest()
+	ResetCache()
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("DiscoverProjects after concurrent writes: %v", err)
+	}
+	if got := len(collectAllSessions(projects)); got != instances*rounds {
+		t.Fatalf("sessions = %d, want %d", got, instances*rounds)
+	}
+}
+
 func TestReadSessionFileMetaCachedContext_CanceledWhileWaitingOnPersistentCacheLock(t *testing.T) {
 	setTestUserCacheDir(t)
 	resetSessionFileCache()
@@ -1783,3 +1849,373 @@ func TestHistoryIndexPersistentCache_ReloadsChangedCacheFileInProcess(t *testing
 		t.Fatalf("FirstPrompt = %q, want second prompt", got)
 	}
 }
+
+func TestHistoryIndexPersistentCache_ResumesAfterAppend(t *testing.T) {
+	setTestUserCacheDir(t)
+
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	initial := strings.Join([]string{
+		`{"session_id":"s1","ts":200,"text":"later prompt"}`,
+		`{"session_id":"s2","ts":200,"text":"second session"}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(historyPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("write history file: %v", err)
+	}
+	loadHistoryIndex(dir)
+
+	// Mark s2 in the cache, so the result shows whether the cached sessions
+	// were reused or the file was parsed again from the start.
+	entry, ok, err := readStalePersistentHistoryIndexContext(context.Background(), historyPath)
+	if err != nil || !ok || entry.TailDigest == "" {
+		t.Fatalf("cached entry = %+v, ok=%v, err=%v; want one with a tail digest", entry, ok, err)
+	}
+	entry.Sessions["s2"].FirstPrompt = "from cache"
+	info, err := os.Stat(historyPath)
+	if err != nil {
+		t.Fatalf("stat history file: %v", err)
+	}
+	if err := writePersistentHistoryIndexContext(context.Background(), historyPath, info, historyIndex{sessions: entry.Sessions, tailDigest: entry.TailDigest}); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+
+	appended := strings.Join([]string{
+		`{"session_id":"s1","ts":50,"text":"# AGENTS.md\nskill instructions"}`,
+		`{"session_id":"s1","ts":100,"text":"earlier prompt"}`,
+		`{"session_id":"s3","ts":300,"text":"new session"}`,
+	}, "\n") + "\n"
+	f, err := os.OpenFile(historyPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open history file: %v", err)
+	}
+	if _, err := f.WriteString(appended); err != nil {
+		t.Fatalf("append history: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close history file: %v", err)
+	}
+
+	idx := loadHistoryIndex(dir)
+	for id, want := range map[string]string{"s1": "earlier prompt", "s2": "from cache", "s3": "new session"} {
+		if got, _ := idx.lookup(id); got.FirstPrompt != want {
+			t.Fatalf("%s FirstPrompt = %q, want %q", id, got.FirstPrompt, want)
+		}
+	}
+
+	// A full parse of the grown file, copied where no cache knows it,
+	// agrees on everything the cache was not tampered with.
+	copyDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(copyDir, "history.jsonl"), []byte(initial+appended), 0o644); err != nil {
+		t.Fatalf("write history copy: %v", err)
+	}
+	full := loadHistoryIndex(copyDir)
+	for id, want := range map[string]string{"s1": "earlier prompt", "s2": "second session", "s3": "new session"} {
+		if got, _ := full.lookup(id); got.FirstPrompt != want {
+			t.Fatalf("full parse %s FirstPrompt = %q, want %q", id, got.FirstPrompt, want)
+		}
+	}
+}
+
+func TestHistoryIndexPersistentCache_ReparsesRewrittenHistory(t *testing.T) {
+	setTestUserCacheDir(t)
+
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	if err := os.WriteFile(historyPath, []byte(`{"session_id":"s1","ts":100,"text":"old prompt"}`+"\n"), 0o644); err != nil {
+		t.Fatalf("write history file: %v", err)
+	}
+	loadHistoryIndex(dir)
+
+	// Same length up to the cached size, but different bytes: resuming
+	// would keep the old prompt.
+	rewritten := `{"session_id":"s1","ts":100,"text":"new prompt"}` + "\n" + `{"session_id":"s2","ts":200,"text":"other"}` + "\n"
+	if err := os.WriteFile(historyPath, []byte(rewritten), 0o644); err != nil {
+		t.Fatalf("rewrite history file: %v", err)
+	}
+	idx := loadHistoryIndex(dir)
+	if got, _ := idx.lookup("s1"); got.FirstPrompt != "new prompt" {
+		t.Fatalf("s1 FirstPrompt = %q, want new prompt", got.FirstPrompt)
+	}
+	if _, ok := idx.lookup("s2"); !ok {
+		t.Fatal("s2 not indexed")
+	}
+}
+
+func TestHistoryTailDigestRequiresCompleteLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, []byte(`{"session_id":"s1","ts":1,"text":"partial`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := historyTailDigest(f, info.Size()); got != "" {
+		t.Fatalf("historyTailDigest of a partial line = %q, want empty", got)
+	}
+}
+
+func writeLargeHistoryFile(b *testing.B, path string, size int) {
+	b.Helper()
+	var body strings.Builder
+	for i := 0; body.Len() < size; i++ {
+		fmt.Fprintf(&body, `{"session_id":"session-%d","ts":%d,"text":"prompt %d with some ordinary length text to index"}`+"\n", i%5000, 1770777540+i, i)
+		if i%100 == 0 {
+			fmt.Fprintf(&body, `{"session_id":"session-%d","ts":%d,"text":"# AGENTS.md\ninjected instructions"}`+"\n", i%5000, 1770777540+i)
+		}
+	}
+	if err := os.WriteFile(path, []byte(body.String()), 0o644); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func setBenchmarkUserCacheDir(b *testing.B) {
+	b.Helper()
+	cacheDir := b.TempDir()
+	b.Setenv("XDG_CACHE_HOME", cacheDir)
+	b.Setenv("HOME", cacheDir)
+	b.Setenv("LOCALAPPDATA", cacheDir)
+	resetPersistentCacheStatesForTest()
+}
+
+func BenchmarkLoadHistoryIndexFullParse(b *testing.B) {
+	setBenchmarkUserCacheDir(b)
+	dir := b.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	writeLargeHistoryFile(b, historyPath, 16<<20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		deletePersistentHistoryIndex(historyPath)
+		b.StartTimer()
+		if idx := loadHistoryIndex(dir); len(idx.sessions) == 0 {
+			b.Fatal("empty index")
+		}
+	}
+}
+
+func BenchmarkLoadHistoryIndexAfterAppend(b *testing.B) {
+	setBenchmarkUserCacheDir(b)
+	dir := b.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	writeLargeHistoryFile(b, historyPath, 16<<20)
+	loadHistoryIndex(dir)
+	f, err := os.OpenFile(historyPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		if _, err := fmt.Fprintf(f, `{"session_id":"appended-%d","ts":1870777540,"text":"appended prompt"}`+"\n", i); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if _, ok := loadHistoryIndex(dir).lookup(fmt.Sprintf("appended-%d", i)); !ok {
+			b.Fatal("appended session not indexed")
+		}
+	}
+}
+
+func TestPrunePersistentCacheDropsMissingAndChangedFiles(t *testing.T) {
+	setTestUserCacheDir(t)
+	dir := t.TempDir()
+	paths := map[string]string{}
+	for _, name := range []string{"kept", "deleted", "changed"} {
+		path := filepath.Join(dir, name+".jsonl")
+		if err := os.WriteFile(path, []byte(name+"\n"), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat %s: %v", name, err)
+		}
+		writePersistentSessionMeta(path, info, sessionFileMeta{})
+		paths[name] = path
+	}
+	if err := os.Remove(paths["deleted"]); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := os.WriteFile(paths["changed"], []byte("changed and longer\n"), 0o600); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+
+	stats, err := PrunePersistentCache(context.Background(), "")
+	if err != nil {
+		t.Fatalf("PrunePersistentCache: %v", err)
+	}
+	if stats.Kept != 1 || stats.Removed != 2 {
+		t.Fatalf("stats = %+v, want 1 kept and 2 removed", stats)
+	}
+	cachePath, err := sessionMetaCacheFile()
+	if err != nil {
+		t.Fatalf("sessionMetaCacheFile: %v", err)
+	}
+	cache, err := loadPersistentSessionMetaCache(cachePath)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	if _, ok := cache.Entries[filepath.Clean(paths["kept"])]; !ok || len(cache.Entries) != 1 {
+		t.Fatalf("entries after prune = %v", cache.Entries)
+	}
+}
+
+func TestClearPersistentCacheRemovesLocalAndOwnSharedCaches(t *testing.T) {
+	setTestUserCacheDir(t)
+	codexDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatalf("write rollout: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	writePersistentSessionMeta(path, info, sessionFileMeta{FirstPrompt: "cached"})
+	writerID, err := persistentCacheWriterID(context.Background())
+	if err != nil || writerID == "" {
+		t.Fatalf("writer id = %q, %v", writerID, err)
+	}
+	shard := filepath.Join(sharedPersistentCacheBase(codexDir), "session-meta", writerID+".json")
+	other := filepath.Join(filepath.Dir(shard), "someone-else.json")
+	for _, file := range []string{shard, other} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := ClearPersistentCache(context.Background(), codexDir)
+	if err != nil {
+		t.Fatalf("ClearPersistentCache: %v", err)
+	}
+	cachePath, err := sessionMetaCacheFile()
+	if err != nil {
+		t.Fatalf("sessionMetaCacheFile: %v", err)
+	}
+	if len(removed) != 2 || !slices.Contains(removed, cachePath) || !slices.Contains(removed, shard) {
+		t.Fatalf("removed = %q, want %s and %s", removed, cachePath, shard)
+	}
+	if _, ok := readPersistentSessionMeta(path, info); ok {
+		t.Fatal("session meta still cached after clearing")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("another writer's shard should be kept: %v", err)
+	}
+
+	removed, err = ClearPersistentCache(context.Background(), codexDir)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("second clear = %q, %v; want nothing removed", removed, err)
+	}
+}
+
+func TestMaybePrunePersistentCacheHonorsInterval(t *testing.T) {
+	setTestUserCacheDir(t)
+	prevNow := persistentCacheNow
+	t.Cleanup(func() { persistentCacheNow = prevNow })
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	persistentCacheNow = func() time.Time { return now }
+
+	cachePath, err := sessionMetaCacheFile()
+	if err != nil {
+		t.Fatalf("sessionMetaCacheFile: %v", err)
+	}
+	gone := filepath.Join(t.TempDir(), "gone.jsonl")
+	if err := updatePersistentSessionMetaCache(cachePath, func(cache *persistentSessionMetaCache) {
+		cache.Entries[gone] = persistentSessionMetaEntry{}
+	}); err != nil {
+		t.Fatalf("seed cache: %v", err)
+	}
+	entries := func() int {
+		cache, err := loadPersistentSessionMetaCache(cachePath)
+		if err != nil {
+			t.Fatalf("load cache: %v", err)
+		}
+		return len(cache.Entries)
+	}
+
+	maybePrunePersistentCache(context.Background())
+	if got := entries(); got != 1 {
+		t.Fatalf("a cache without a prune marker should count as freshly pruned: %d entries", got)
+	}
+	markerPath, err := persistentCachePruneMarkerFile()
+	if err != nil {
+		t.Fatalf("persistentCachePruneMarkerFile: %v", err)
+	}
+	if !isFile(markerPath) {
+		t.Fatal("expected the prune marker to be written")
+	}
+
+	now = now.Add(persistentCachePruneInterval - time.Minute)
+	maybePrunePersistentCache(context.Background())
+	if got := entries(); got != 1 {
+		t.Fatalf("pruned before the interval elapsed: %d entries", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	maybePrunePersistentCache(context.Background())
+	if got := entries(); got != 0 {
+		t.Fatalf("expected the stale entry to be pruned, %d entries left", got)
+	}
+}
+
+func TestReloadSessionContext_PicksUpAppendedMessages(t *testing.T) {
+	setTestUserCacheDir(t)
+	resetSessionFileCache()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "reload.jsonl")
+	writeSessionMetaFile(t, filePath, "reload-1", dir, "first prompt")
+	meta, err := readSessionFileMetaCached(filePath)
+	if err != nil {
+		t.Fatalf("initial read: %v", err)
+	}
+	session := Session{SessionID: "reload-1", FilePath: filePath, FirstPrompt: meta.FirstPrompt, MessageCount: meta.MessageCount, ModifiedAt: meta.ModifiedAt}
+
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open for append: %v", err)
+	}
+	_, err = f.WriteString(`{"timestamp":"2026-01-01T00:02:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"follow-up"}]}}` + "\n")
+	_ = f.Close()
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	later := meta.ModifiedAt.Add(time.Minute)
+	if err := os.Chtimes(filePath, later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	got, err := ReloadSessionContext(context.Background(), session)
+	if err != nil {
+		t.Fatalf("ReloadSessionContext: %v", err)
+	}
+	if got.MessageCount != meta.MessageCount+1 {
+		t.Fatalf("MessageCount = %d, want %d", got.MessageCount, meta.MessageCount+1)
+	}
+	if !got.ModifiedAt.After(session.ModifiedAt) {
+		t.Fatalf("ModifiedAt = %v, want later than %v", got.ModifiedAt, session.ModifiedAt)
+	}
+	if got.FirstPrompt != "first prompt" {
+		t.Fatalf("FirstPrompt = %q", got.FirstPrompt)
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := ReloadSessionContext(context.Background(), got); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected os.ErrNotExist for a removed file, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package codexhistory
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// PreviewFormatter renders the text of one message for FormatMessages and
+// FormatPreviewMessages. FormatMessage returns ok=false when the message is
+// not a kind it handles, and the next formatter is tried.
+type PreviewFormatter interface {
+	FormatMessage(msg Message) (text string, ok bool)
+}
+
+// DefaultPreviewFormatter is tried after every other formatter and accepts
+// any message: it shows the content with surrounding whitespace trimmed.
+var DefaultPreviewFormatter PreviewFormatter = plainPreviewFormatter{}
+
+var (
+	previewFormattersMu sync.RWMutex
+	// customPreviewFormatters run before the built-in ones, most recently
+	// registered first.
+	customPreviewFormatters  []PreviewFormatter
+	builtinPreviewFormatters = []PreviewFormatter{
+		diffPreviewFormatter{},
+		jsonPreviewFormatter{},
+	}
+)
+
+// RegisterPreviewFormatter adds f ahead of the built-in formatters and
+// returns a function that removes it again.
+func RegisterPreviewFormatter(f PreviewFormatter) (unregister func()) {
+	previewFormattersMu.Lock()
+	defer previewFormattersMu.Unlock()
+	customPreviewFormatters = append([]PreviewFormatter{f}, customPreviewFormatters...)
+	return func() {
+		previewFormattersMu.Lock()
+		defer previewFormattersMu.Unlock()
+		for i, registered := range customPreviewFormatters {
+			if registered == f {
+				customPreviewFormatters = append(customPreviewFormatters[:i:i], customPreviewFormatters[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// formatMessageText returns msg's text as rendered by the first formatter
+// that accepts it.
+func formatMessageText(msg Message) string {
+	previewFormattersMu.RLock()
+	custom := customPreviewFormatters
+	previewFormattersMu.RUnlock()
+	for _, formatters := range [][]PreviewFormatter{custom, builtinPreviewFormatters} {
+		for _, f := range formatters {
+			if text, ok := f.FormatMessage(msg); ok {
+				return text
+			}
+		}
+	}
+	text, _ := DefaultPreviewFormatter.FormatMessage(msg)
+	return text
+}
+
+type plainPreviewFormatter struct{}
+
+func (plainPreviewFormatter) FormatMessage(msg Message) (string, bool) {
+	return strings.TrimSpace(msg.Content), true
+}
+
+// diffPreviewFormatter keeps patches exactly as recorded, including the
+// leading space of context lines, so nothing reflows them.
+type diffPreviewFormatter struct{}
+
+func (diffPreviewFormatter) FormatMessage(msg Message) (string, bool) {
+	if msg.Role != "tool" && msg.Role != "tool_result" {
+		return "", false
+	}
+	for _, line := range strings.Split(msg.Content, "\n") {
+		if strings.HasPrefix(line, "diff --git ") || strings.HasPrefix(line, "@@ ") || strings.HasPrefix(line, "*** Begin Patch") {
+			return strings.TrimRight(strings.TrimLeft(msg.Content, "\r\n"), " \t\r\n"), true
+		}
+	}
+	return "", false
+}
+
+// jsonPreviewFormatter indents tool calls and results whose body is a JSON
+// object or array, keeping a leading "Tool: name" line.
+type jsonPreviewFormatter struct{}
+
+func (jsonPreviewFormatter) FormatMessage(msg Message) (string, bool) {
+	if msg.Role != "tool" && msg.Role != "tool_result" {
+		return "", false
+	}
+	text := strings.TrimSpace(msg.Content)
+	header := ""
+	if strings.HasPrefix(text, "Tool: ") {
+		if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+			header, text = text[:idx+1], strings.TrimSpace(text[idx+1:])
+		}
+	}
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return "", false
+	}
+	var parsed any
+	if json.Unmarshal([]byte(text), &parsed) != nil {
+		return "", false
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if enc.Encode(parsed) != nil {
+		return "", false
+	}
+	return header + strings.TrimRight(buf.String(), "\n"), true
+}
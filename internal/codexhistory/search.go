@@ -0,0 +1,112 @@
+package codexhistory
+
+import (
+	"context"
+	"strings"
+)
+
+// searchSnippetRadius is how many runes of context a SearchMatch snippet keeps
+// on each side of the hit.
+const searchSnippetRadius = 40
+
+// SearchMatch is a session whose conversation contains a search query.
+type SearchMatch struct {
+	Session Session
+	// Snippet is the text around the first hit, on one line.
+	Snippet string
+}
+
+// SearchSessionFile reports whether one of the prompts or replies in the
+// rollout at filePath contains query, ignoring case, and returns a one-line
+// snippet around the first hit. Tool calls, tool output and reasoning are
+// not searched.
+func SearchSessionFile(filePath string, query string) (string, bool, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", false, nil
+	}
+	msgs, err := ReadSessionMessagesWithRoles(filePath, 0, ConversationRoles...)
+	if err != nil {
+		return "", false, err
+	}
+	needle := []rune(strings.ToLower(query))
+	for _, msg := range msgs {
+		if snippet, ok := searchSnippet(msg.Content, needle); ok {
+			return snippet, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// SearchSessions searches the conversations of the main sessions in projects,
+// in order, and returns the ones containing query. Files that cannot be read
+// are skipped, as discovery does; the first such error is returned alongside
+// the matches. Subagent transcripts are not searched.
+func SearchSessions(ctx context.Context, projects []Project, query string) ([]SearchMatch, error) {
+	var matches []SearchMatch
+	var firstErr error
+	for _, project := range projects {
+		for _, session := range project.Sessions {
+			if err := ctx.Err(); err != nil {
+				return matches, err
+			}
+			if session.FilePath == "" {
+				continue
+			}
+			snippet, ok, err := SearchSessionFile(session.FilePath, query)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if ok {
+				matches = append(matches, SearchMatch{Session: session, Snippet: snippet})
+			}
+		}
+	}
+	return matches, firstErr
+}
+
+// searchSnippet finds needle, already lowercased, in text ignoring case and
+// returns the hit with searchSnippetRadius runes around it, whitespace runs
+// folded to single spaces.
+func searchSnippet(text string, needle []rune) (string, bool) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		// Lowercasing changed the length, so indexes would not line up;
+		// fall back to the lowered text.
+		runes = lower
+	}
+	at := indexRunes(lower, needle)
+	if at < 0 {
+		return "", false
+	}
+	start := max(0, at-searchSnippetRadius)
+	end := min(len(runes), at+len(needle)+searchSnippetRadius)
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet, true
+}
+
+func indexRunes(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j, r := range needle {
+			if haystack[i+j] != r {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,68 @@
+package codexhistory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearchSessionsMatchesConversationText(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, lines ...string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	reply := write("reply.jsonl",
+		`{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"fix the build"}]}}`,
+		`{"timestamp":"2026-01-01T00:00:01Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"The   Flaky\nTest was in parser_test.go and is now fixed."}]}}`,
+	)
+	tool := write("tool.jsonl",
+		`{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"run the tests"}]}}`,
+		`{"timestamp":"2026-01-01T00:00:01Z","type":"response_item","payload":{"type":"function_call_output","call_id":"c1","output":"flaky test output"}}`,
+	)
+	projects := []Project{{Sessions: []Session{
+		{SessionID: "reply", FilePath: reply},
+		{SessionID: "tool", FilePath: tool},
+		{SessionID: "gone", FilePath: filepath.Join(dir, "missing.jsonl")},
+	}}}
+
+	matches, err := SearchSessions(context.Background(), projects, "  flaky TEST ")
+	if err == nil {
+		t.Fatal("expected the missing file's error")
+	}
+	if len(matches) != 1 || matches[0].Session.SessionID != "reply" {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if got, want := matches[0].Snippet, "The Flaky Test was in parser_test.go and is now fixed."; got != want {
+		t.Fatalf("snippet = %q, want %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if matches, err := SearchSessions(ctx, projects, "flaky"); err == nil || len(matches) != 0 {
+		t.Fatalf("cancelled search: matches=%+v err=%v", matches, err)
+	}
+}
+
+func TestSearchSnippetTrimsLongText(t *testing.T) {
+	text := strings.Repeat("a", 100) + " needle " + strings.Repeat("b", 100)
+	snippet, ok := searchSnippet(text, []rune("needle"))
+	if !ok {
+		t.Fatal("expected a hit")
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, " needle ") {
+		t.Fatalf("snippet = %q", snippet)
+	}
+	if n := len([]rune(snippet)); n != len("needle")+2*searchSnippetRadius+2 {
+		t.Fatalf("snippet has %d runes: %q", n, snippet)
+	}
+	if _, ok := searchSnippet(text, []rune("missing")); ok {
+		t.Fatal("unexpected hit")
+	}
+}
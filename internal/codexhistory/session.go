@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
@@ -39,6 +40,65 @@ func ReadSessionMessages(filePath string, maxMessages int) ([]Message, error) {
 	return readSessionMessages(filePath, maxMessages, nil)
 }
 
+// ConversationRoles are the message roles of the conversation itself: the
+// user's prompts and Codex's replies, without tool calls, tool output or
+// reasoning.
+var ConversationRoles = []string{"user", "assistant", "assistant_commentary"}
+
+// ReadSessionMessagesWithRoles is ReadSessionMessages keeping only messages
+// whose Role is one of roles; maxMessages counts the kept messages.
+func ReadSessionMessagesWithRoles(filePath string, maxMessages int, roles ...string) ([]Message, error) {
+	return readSessionMessages(filePath, maxMessages, func(msg Message) bool {
+		return slices.Contains(roles, msg.Role)
+	})
+}
+
+// ReadSessionMessagesRange returns up to count messages starting at the
+// zero-based message index start, in file order, and reports whether more
+// messages follow. A count <= 0 returns everything from start on. Reading
+// stops as soon as the page is known to be followed by another message, so
+// paging near the top of a long session does not scan the whole file.
+func ReadSessionMessagesRange(filePath string, start int, count int) ([]Message, bool, error) {
+	if start < 0 {
+		start = 0
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	var page []Message
+	index := 0
+	reader := bufio.NewReaderSize(f, 64*1024)
+	seenMessages := newMessageSeenState()
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, false, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			for _, msg := range parseLineMessages(line) {
+				if !markMessageSeen(msg, seenMessages) {
+					continue
+				}
+				if count > 0 && index >= start+count {
+					return page, true, nil
+				}
+				if index >= start {
+					page = append(page, msg)
+				}
+				index++
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return page, false, nil
+}
+
 func ReadSessionPreviewMessages(filePath string, maxMessages int) ([]Message, error) {
 	if maxMessages > 0 {
 		return readRecentSessionMessages(filePath, maxMessages, isPreviewMessage)
@@ -46,15 +106,23 @@ func ReadSessionPreviewMessages(filePath string, maxMessages int) ([]Message, er
 	return readSessionPreviewMessagesCached(filePath)
 }
 
+// ReadSessionPreviewText formats the recent Codex replies of a session. A
+// session without any, such as one that only ran tools, gets its outline
+// instead so the preview does not look empty.
 func ReadSessionPreviewText(filePath string, maxMessages int, maxLen int) (string, error) {
+	var text string
+	var err error
 	if maxMessages > 0 || maxLen > 0 {
-		msgs, err := ReadSessionPreviewMessages(filePath, maxMessages)
-		if err != nil {
-			return "", err
-		}
-		return FormatPreviewMessages(msgs, maxLen), nil
+		var msgs []Message
+		msgs, err = ReadSessionPreviewMessages(filePath, maxMessages)
+		text = FormatPreviewMessages(msgs, maxLen)
+	} else {
+		text, err = readSessionPreviewTextCached(filePath)
+	}
+	if err != nil || text != "" {
+		return text, err
 	}
-	return readSessionPreviewTextCached(filePath)
+	return ReadSessionOutlineText(filePath)
 }
 
 func readSessionMessages(filePath string, maxMessages int, keep func(Message) bool) ([]Message, error) {
@@ -730,7 +798,7 @@ func formatJSONFieldText(raw json.RawMessage) string {
 			return string(formatted)
 		}
 	}
-	return string(raw)
+	return strings.ToValidUTF8(string(raw), "\uFFFD")
 }
 
 func formatMaybeJSONText(text string) string {
@@ -2,7 +2,9 @@ package codexhistory
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -93,6 +95,46 @@ func readSessionFileMetaCachedContext(ctx context.Context, filePath string) (ses
 	return meta, nil
 }
 
+// ReloadSessionContext re-reads the rollout file of s, skipping the
+// in-process cache, and returns s updated with what was added to it since
+// discovery: messages, the modification time and the last reply. Fields
+// that come from older rollout files of a resumed session are kept. A
+// missing file is returned as an error wrapping os.ErrNotExist.
+func ReloadSessionContext(ctx context.Context, s Session) (Session, error) {
+	filePath := strings.TrimSpace(s.FilePath)
+	if filePath == "" {
+		return s, fmt.Errorf("session %s has no rollout file", s.SessionID)
+	}
+	sessionFileCache.mu.Lock()
+	prev, hadPrev := sessionFileCache.entries[filePath]
+	delete(sessionFileCache.entries, filePath)
+	sessionFileCache.mu.Unlock()
+
+	meta, err := readSessionFileMetaCachedContext(ctx, filePath)
+	if err != nil {
+		return s, err
+	}
+	if hadPrev && prev.hasMeta {
+		s.MessageCount = max(0, s.MessageCount+meta.MessageCount-prev.meta.MessageCount)
+	} else if meta.MessageCount > s.MessageCount {
+		s.MessageCount = meta.MessageCount
+	}
+	if meta.ModifiedAt.After(s.ModifiedAt) {
+		s.ModifiedAt = meta.ModifiedAt
+	}
+	if meta.LastAssistantSnippet != "" {
+		s.LastAssistantSnippet = meta.LastAssistantSnippet
+	}
+	s.HadError = s.HadError || meta.HadError
+	if s.FirstPrompt == "" {
+		s.FirstPrompt = meta.FirstPrompt
+	}
+	if strings.TrimSpace(s.ProjectPath) == "" {
+		s.ProjectPath = strings.TrimSpace(meta.ProjectPath)
+	}
+	return s, nil
+}
+
 func SessionFileIsSubagentContext(ctx context.Context, filePath string) (bool, error) {
 	meta, err := readSessionFileMetaCachedContext(ctx, filePath)
 	if err != nil {
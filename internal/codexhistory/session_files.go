@@ -2,18 +2,24 @@ package codexhistory
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"io/fs"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // collectSessionFiles walks sessionsDir (e.g. ~/.codex/sessions/) recursively
-// and returns all .jsonl file paths.
-func collectSessionFiles(sessionsDir string) ([]string, error) {
-	return collectSessionFilesContext(context.Background(), sessionsDir)
+// and returns all .jsonl file paths, skipping those matched by exclude (see
+// DiscoverOptions.Exclude).
+func collectSessionFiles(sessionsDir string, exclude ...string) ([]string, error) {
+	return collectSessionFilesContext(context.Background(), sessionsDir, exclude)
 }
 
-func collectSessionFilesContext(ctx context.Context, sessionsDir string) ([]string, error) {
+func collectSessionFilesContext(ctx context.Context, sessionsDir string, exclude []string) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(sessionsDir, func(path string, d fs.DirEntry, err error) error {
 		if err := ctx.Err(); err != nil {
@@ -22,6 +28,14 @@ func collectSessionFilesContext(ctx context.Context, sessionsDir string) ([]stri
 		if err != nil {
 			return nil
 		}
+		if len(exclude) > 0 && path != sessionsDir {
+			if rel, relErr := filepath.Rel(sessionsDir, path); relErr == nil && excludedSessionPath(rel, exclude) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
 		if d.IsDir() {
 			return nil
 		}
@@ -35,3 +49,92 @@ func collectSessionFilesContext(ctx context.Context, sessionsDir string) ([]stri
 	}
 	return files, nil
 }
+
+// ValidateExcludePatterns reports the first malformed DiscoverOptions.Exclude
+// pattern.
+func ValidateExcludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(filepath.FromSlash(pattern), ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// excludedSessionPath reports whether rel, a path relative to the sessions
+// directory, matches one of patterns, which use forward slashes everywhere.
+func excludedSessionPath(rel string, patterns []string) bool {
+	base := filepath.Base(rel)
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(filepath.FromSlash(pattern), rel); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := filepath.Match(pattern, base); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// latestSessionFiles keeps the limit most recent files by the timestamp in
+// their names, in their original order. Files without a timestamp count as
+// oldest. A limit of zero or less keeps every file.
+func latestSessionFiles(files []string, limit int) []string {
+	if limit <= 0 || len(files) <= limit {
+		return files
+	}
+	order := make([]int, len(files))
+	stamps := make([]int64, len(files))
+	for i, path := range files {
+		order[i] = i
+		if ts := parseTimestampFromFilename(filepath.Base(path)); !ts.IsZero() {
+			stamps[i] = ts.UnixNano()
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return stamps[order[a]] > stamps[order[b]]
+	})
+	keep := make([]bool, len(files))
+	for _, i := range order[:limit] {
+		keep[i] = true
+	}
+	latest := make([]string, 0, limit)
+	for i, path := range files {
+		if keep[i] {
+			latest = append(latest, path)
+		}
+	}
+	return latest
+}
+
+// SessionFileDigest returns the hex SHA-256 of a rollout file's bytes. It is
+// what DiscoverOptions.ContentDigest fills Session.ContentDigest with, and can
+// be called directly to check one file without rediscovering.
+func SessionFileDigest(filePath string) (string, error) {
+	f, err := openSessionMetaFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// combineContentDigests folds the digests of a session's rollout files,
+// oldest first, into one digest that changes when any of them does.
+func combineContentDigests(older, newer string) string {
+	if older == "" || newer == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(older + "\n" + newer))
+	return hex.EncodeToString(sum[:])
+}
@@ -16,6 +16,12 @@ func filterEmptySessions(sessions []Session) []Session {
 	return out
 }
 
+// IsEmptySession reports whether a session has no messages, first prompt or
+// summary, such as a rollout that was started and abandoned.
+func IsEmptySession(session Session) bool {
+	return isEmptySession(session)
+}
+
 func isEmptySession(session Session) bool {
 	if session.MessageCount > 0 {
 		return false
@@ -28,3 +34,59 @@ func isEmptySession(session Session) bool {
 	}
 	return true
 }
+
+// FilterMainSessions drops subagent sessions that were promoted to the top
+// level because their parent was not found, and any project left without
+// sessions. With dropAttached it also clears each session's Subagents, so
+// only main conversations remain.
+func FilterMainSessions(projects []Project, dropAttached bool) []Project {
+	out := make([]Project, 0, len(projects))
+	for _, project := range projects {
+		sessions := make([]Session, 0, len(project.Sessions))
+		for _, sess := range project.Sessions {
+			if sess.PromotedSubagent {
+				continue
+			}
+			if dropAttached {
+				sess.Subagents = nil
+			}
+			sessions = append(sessions, sess)
+		}
+		if len(sessions) == 0 {
+			continue
+		}
+		project.Sessions = sessions
+		out = append(out, project)
+	}
+	return out
+}
+
+// FilterFailedSessions keeps only the sessions with HadError set, and the
+// projects that still have one.
+func FilterFailedSessions(projects []Project) []Project {
+	return filterProjectSessions(projects, func(sess Session) bool { return sess.HadError })
+}
+
+// FilterResumableSessions keeps only the sessions Codex can resume, and the
+// projects that still have one.
+func FilterResumableSessions(projects []Project) []Project {
+	return filterProjectSessions(projects, Session.Resumable)
+}
+
+func filterProjectSessions(projects []Project, keep func(Session) bool) []Project {
+	out := make([]Project, 0, len(projects))
+	for _, project := range projects {
+		sessions := make([]Session, 0, len(project.Sessions))
+		for _, sess := range project.Sessions {
+			if keep(sess) {
+				sessions = append(sessions, sess)
+			}
+		}
+		if len(sessions) == 0 {
+			continue
+		}
+		project.Sessions = sessions
+		out = append(out, project)
+	}
+	return out
+}
@@ -22,8 +22,15 @@ type sessionFileMeta struct {
 	IsSubagent     bool
 	SubagentType   string // "thread_spawn", "review", "compact"
 	ParentThreadID string // only set for thread_spawn
+	// LastAssistantSnippet is the start of the latest assistant message,
+	// flattened to one line and capped at lastAssistantSnippetRunes.
+	LastAssistantSnippet string
+	// HadError is set when the rollout records an error event.
+	HadError bool
 }
 
+const lastAssistantSnippetRunes = 160
+
 // codexEnvelope is the outer JSON structure of every line in a Codex JSONL file.
 // Payload is kept as RawMessage to avoid parsing heavy fields (reasoning, turn_context).
 type codexEnvelope struct {
@@ -237,12 +244,35 @@ func processMetaLine(line []byte, meta *sessionFileMeta) {
 			}
 		} else {
 			meta.MessageCount++
+			var payload struct {
+				Content json.RawMessage `json:"content"`
+			}
+			if json.Unmarshal(env.Payload, &payload) == nil {
+				if snippet := assistantSnippet(extractContentText(payload.Content)); snippet != "" {
+					meta.LastAssistantSnippet = snippet
+				}
+			}
 		}
 
 	case "event_msg":
 		// Could extract user_message for first prompt fallback,
 		// but response_item/user is the canonical source.
+		var header struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(env.Payload, &header) == nil && header.Type == "error" {
+			meta.HadError = true
+		}
+	}
+}
+
+// assistantSnippet flattens text to a single line and caps its length.
+func assistantSnippet(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return ""
 	}
+	return truncateRunes(text, lastAssistantSnippetRunes)
 }
 
 // parseSessionIDFromFilename extracts the session UUID from a Codex session filename.
@@ -299,11 +329,13 @@ func parseTimestamp(raw string) time.Time {
 	if raw == "" {
 		return time.Time{}
 	}
+	// Rollout timestamps end in "Z"; normalize any other offset to UTC too
+	// so display code only has to convert from one zone.
 	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
-		return t
+		return t.UTC()
 	}
 	if t, err := time.Parse(time.RFC3339, raw); err == nil {
-		return t
+		return t.UTC()
 	}
 	return time.Time{}
 }
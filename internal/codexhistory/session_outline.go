@@ -0,0 +1,105 @@
+package codexhistory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EventCount is how often one kind of rollout line occurs in a session.
+type EventCount struct {
+	Name  string
+	Count int
+}
+
+// ReadSessionOutline counts the kinds of lines in a rollout, in order of
+// first appearance. Tool calls are named by tool, e.g. "tool shell"; other
+// lines by their item or event type. It gives a session without message
+// text something to show.
+func ReadSessionOutline(filePath string) ([]EventCount, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var counts []EventCount
+	index := map[string]int{}
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			if name := outlineLineName(line); name != "" {
+				if i, ok := index[name]; ok {
+					counts[i].Count++
+				} else {
+					index[name] = len(counts)
+					counts = append(counts, EventCount{Name: name, Count: 1})
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return counts, nil
+}
+
+func outlineLineName(line []byte) string {
+	var env codexEnvelope
+	if json.Unmarshal(line, &env) != nil {
+		return ""
+	}
+	var payload struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(env.Payload, &payload)
+	switch env.Type {
+	case "response_item", "event_msg":
+		switch payload.Type {
+		case "function_call", "custom_tool_call":
+			if name := strings.TrimSpace(payload.Name); name != "" {
+				return "tool " + name
+			}
+		}
+		if payload.Type != "" {
+			return payload.Type
+		}
+	case "":
+		return strings.TrimSpace(env.Method)
+	}
+	return env.Type
+}
+
+// FormatSessionOutline renders counts as a note that the session has no
+// message text followed by one line per kind.
+func FormatSessionOutline(counts []EventCount) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("No message text in this session. It contains:")
+	for _, c := range counts {
+		fmt.Fprintf(&b, "\n  %s x%d", SanitizeTerminalText(c.Name), c.Count)
+	}
+	return b.String()
+}
+
+// ReadSessionOutlineText is ReadSessionOutline formatted with
+// FormatSessionOutline.
+func ReadSessionOutlineText(filePath string) (string, error) {
+	counts, err := ReadSessionOutline(filePath)
+	if err != nil {
+		return "", err
+	}
+	return FormatSessionOutline(counts), nil
+}
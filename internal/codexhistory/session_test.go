@@ -3,6 +3,7 @@ package codexhistory
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -755,6 +756,47 @@ func TestReadSessionMessages_MixedTypes(t *testing.T) {
 	}
 }
 
+func TestReadSessionMessagesWithRoles_KeepsConversation(t *testing.T) {
+	lines := []string{
+		`{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"hello"}]}}`,
+		`{"timestamp":"2026-01-01T00:02:00Z","type":"response_item","payload":{"type":"function_call","name":"read","arguments":"{}"}}`,
+		`{"timestamp":"2026-01-01T00:03:00Z","type":"response_item","payload":{"type":"function_call_output","output":"file data"}}`,
+		`{"timestamp":"2026-01-01T00:04:00Z","type":"response_item","payload":{"type":"reasoning","summary":[{"type":"summary_text","text":"thinking"}]}}`,
+		`{"timestamp":"2026-01-01T00:05:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hi there"}]}}`,
+		`{"timestamp":"2026-01-01T00:06:00Z","type":"event_msg","payload":{"type":"user_message","content":"thanks"}}`,
+	}
+	f := filepath.Join(t.TempDir(), "conversation.jsonl")
+	if err := os.WriteFile(f, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, err := ReadSessionMessagesWithRoles(f, 0, ConversationRoles...)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	var got []string
+	for _, msg := range msgs {
+		got = append(got, msg.Role+":"+msg.Content)
+	}
+	if want := []string{"user:hello", "assistant:hi there", "user:thanks"}; !slices.Equal(got, want) {
+		t.Fatalf("messages = %q, want %q", got, want)
+	}
+
+	// maxMessages counts kept messages only.
+	msgs, err = ReadSessionMessagesWithRoles(f, 2, ConversationRoles...)
+	if err != nil || len(msgs) != 2 || msgs[0].Content != "hi there" {
+		t.Fatalf("last two = %+v, %v", msgs, err)
+	}
+
+	text := FormatConversationMessages(msgs, 0)
+	if text != "Codex answer:\nhi there\n\nUser prompt:\nthanks" {
+		t.Fatalf("FormatConversationMessages = %q", text)
+	}
+	if msgs, err := ReadSessionMessagesWithRoles(f, 0, "tool"); err != nil || len(msgs) != 1 || msgs[0].Role != "tool" {
+		t.Fatalf("tool messages = %+v, %v", msgs, err)
+	}
+}
+
 func TestReadSessionPreviewMessagesFiltersToCodexStatusAndAnswer(t *testing.T) {
 	lines := []string{
 		`{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"s1","cwd":"/tmp","source":"cli"}}`,
@@ -1502,3 +1544,74 @@ func TestReadSessionMessages_CompletedEventShapes(t *testing.T) {
 		}
 	}
 }
+
+func TestReadSessionMessagesRangePages(t *testing.T) {
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, `{"timestamp":"2026-01-01T00:00:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"msg`+string(rune('A'+i))+`"}]}}`)
+	}
+	f := filepath.Join(t.TempDir(), "range.jsonl")
+	if err := os.WriteFile(f, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		start, count int
+		want         string
+		more         bool
+	}{
+		{start: 0, count: 3, want: "msgA,msgB,msgC", more: true},
+		{start: 3, count: 3, want: "msgD,msgE,msgF", more: true},
+		{start: 8, count: 3, want: "msgI,msgJ", more: false},
+		{start: 7, count: 3, want: "msgH,msgI,msgJ", more: false},
+		{start: 12, count: 3, want: "", more: false},
+		{start: -1, count: 1, want: "msgA", more: true},
+		{start: 9, count: 0, want: "msgJ", more: false},
+	} {
+		msgs, more, err := ReadSessionMessagesRange(f, tc.start, tc.count)
+		if err != nil {
+			t.Fatalf("range(%d,%d): %v", tc.start, tc.count, err)
+		}
+		var got []string
+		for _, msg := range msgs {
+			got = append(got, msg.Content)
+		}
+		if strings.Join(got, ",") != tc.want || more != tc.more {
+			t.Fatalf("range(%d,%d) = %v more=%t, want %s more=%t", tc.start, tc.count, got, more, tc.want, tc.more)
+		}
+	}
+
+	if _, _, err := ReadSessionMessagesRange(filepath.Join(t.TempDir(), "missing.jsonl"), 0, 1); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestReadSessionPreviewText_FallsBackToOutline(t *testing.T) {
+	path := writeRolloutLines(t,
+		`{"timestamp":"2026-01-01T00:00:00Z","type":"session_meta","payload":{"id":"s1","cwd":"/repo"}}`,
+		`{"timestamp":"2026-01-01T00:00:01Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"ls\"]}"}}`,
+		`{"timestamp":"2026-01-01T00:00:02Z","type":"response_item","payload":{"type":"function_call_output","output":"a.txt"}}`,
+		`{"timestamp":"2026-01-01T00:00:03Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"pwd\"]}"}}`,
+		`{"timestamp":"2026-01-01T00:00:04Z","type":"event_msg","payload":{"type":"token_count"}}`,
+	)
+
+	counts, err := ReadSessionOutline(path)
+	if err != nil {
+		t.Fatalf("ReadSessionOutline: %v", err)
+	}
+	want := []EventCount{{"session_meta", 1}, {"tool shell", 2}, {"function_call_output", 1}, {"token_count", 1}}
+	if !slices.Equal(counts, want) {
+		t.Fatalf("outline = %#v, want %#v", counts, want)
+	}
+
+	text, err := ReadSessionPreviewText(path, 10, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionPreviewText: %v", err)
+	}
+	if !strings.HasPrefix(text, "No message text in this session.") || !strings.Contains(text, "\n  tool shell x2") {
+		t.Fatalf("preview text = %q", text)
+	}
+	if FormatSessionOutline(nil) != "" {
+		t.Fatal("an empty outline should format to nothing")
+	}
+}
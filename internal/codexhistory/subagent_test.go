@@ -3,6 +3,7 @@ package codexhistory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -703,6 +704,37 @@ func TestDiscoverProjects_SubagentAssociation(t *testing.T) {
 	}
 }
 
+func TestFindSubagentWithParent(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+
+	parentID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	childID := "11111111-2222-3333-4444-555555555555"
+	ts := "2026-06-01T10:00:00Z"
+	writeSessionFile(t, sessionsDir, parentID, ts, projDir, `"cli"`, "hello parent")
+	writeSessionFile(t, sessionsDir, childID, ts, projDir,
+		`{"subagent":{"thread_spawn":{"parent_thread_id":"`+parentID+`","depth":1}}}`, "child task")
+
+	sub, parent, project, err := FindSubagentWithParent(tmpDir, childID)
+	if err != nil {
+		t.Fatalf("FindSubagentWithParent: %v", err)
+	}
+	if sub.SessionID != childID || sub.FirstPrompt != "child task" {
+		t.Fatalf("subagent = %+v", sub)
+	}
+	if parent.SessionID != parentID || project == nil || len(project.Sessions) == 0 {
+		t.Fatalf("parent = %+v, project = %+v", parent, project)
+	}
+
+	if _, _, _, err := FindSubagentWithParent(tmpDir, parentID); err == nil {
+		t.Fatal("a main session ID resolved as a subagent")
+	}
+
+	var subErr *SubagentSessionError
+	if _, _, err := FindSessionWithProject(tmpDir, childID); !errors.As(err, &subErr) || subErr.Parent.SessionID != parentID {
+		t.Fatalf("FindSessionWithProject(subagent) error = %v, want a SubagentSessionError naming the parent", err)
+	}
+}
+
 func TestDiscoverProjects_SubagentSortOrder(t *testing.T) {
 	tmpDir, sessionsDir, projDir := setupCodexDir(t)
 
@@ -1152,3 +1184,70 @@ func TestOrphanSession_DisplayTitle(t *testing.T) {
 		t.Errorf("DisplayTitle() = %q, want [review subagent]", got)
 	}
 }
+
+func TestFilterMainSessions_DropsPromotedOrphans(t *testing.T) {
+	tmpDir, sessionsDir, _ := setupCodexDir(t)
+	project := filepath.Join(tmpDir, "proj")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-1111-2222-3333-444444444444", "2026-01-01T05:00:00Z", project, `"cli"`, "main work")
+	writeSessionFile(t, sessionsDir, "bbbbbbbb-1111-2222-3333-444444444444", "2026-01-01T04:00:00Z", "", `{"subagent":"review"}`, "orphan review")
+
+	projects, err := DiscoverProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("DiscoverProjects: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected the project and the unknown orphan project, got %d", len(projects))
+	}
+
+	filtered := FilterMainSessions(projects, false)
+	if len(filtered) != 1 || filtered[0].Path != project {
+		t.Fatalf("filtered = %+v, want only the main project", filtered)
+	}
+	if len(filtered[0].Sessions) != 1 || filtered[0].Sessions[0].PromotedSubagent {
+		t.Fatalf("filtered sessions = %+v", filtered[0].Sessions)
+	}
+}
+
+func TestFilterFailedSessions(t *testing.T) {
+	projects := []Project{
+		{Key: "/a", Path: "/a", Sessions: []Session{{SessionID: "ok"}, {SessionID: "bad", HadError: true}}},
+		{Key: "/b", Path: "/b", Sessions: []Session{{SessionID: "ok2"}}},
+	}
+	got := FilterFailedSessions(projects)
+	if len(got) != 1 || got[0].Path != "/a" || len(got[0].Sessions) != 1 || got[0].Sessions[0].SessionID != "bad" {
+		t.Fatalf("FilterFailedSessions = %+v, want only the failed session", got)
+	}
+	if len(projects[0].Sessions) != 2 {
+		t.Fatal("FilterFailedSessions modified its input")
+	}
+}
+
+func TestFilterResumableSessions(t *testing.T) {
+	projects := []Project{
+		{Key: "/a", Path: "/a", Sessions: []Session{{SessionID: "11111111-1111-1111-1111-111111111111"}, {SessionID: "legacy"}}},
+		{Key: "/b", Path: "/b", Sessions: []Session{{SessionID: ""}}},
+	}
+	got := FilterResumableSessions(projects)
+	if len(got) != 1 || got[0].Path != "/a" || len(got[0].Sessions) != 1 || !got[0].Sessions[0].Resumable() {
+		t.Fatalf("FilterResumableSessions = %+v, want only the resumable session", got)
+	}
+}
+
+func TestFilterMainSessions_DropAttachedClearsSubagents(t *testing.T) {
+	projects := []Project{{Key: "/p", Path: "/p", Sessions: []Session{{
+		SessionID: "main",
+		Subagents: []SubagentSession{{SessionID: "sub"}},
+	}}}}
+	if got := FilterMainSessions(projects, false); len(got[0].Sessions[0].Subagents) != 1 {
+		t.Fatalf("attached subagents dropped without dropAttached: %+v", got)
+	}
+	if got := FilterMainSessions(projects, true); got[0].Sessions[0].Subagents != nil {
+		t.Fatalf("attached subagents kept with dropAttached: %+v", got)
+	}
+	if len(projects[0].Sessions[0].Subagents) != 1 {
+		t.Fatal("FilterMainSessions modified its input")
+	}
+}
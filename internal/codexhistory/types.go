@@ -1,17 +1,28 @@
 package codexhistory
 
 import (
+	"strings"
 	"time"
 )
 
 const EnvCodexDir = "CODEX_DIR"
 
+// UnknownProjectKey is the Project.Key of sessions recorded without a
+// working directory. Such projects have an empty Path.
+const UnknownProjectKey = "(unknown)"
+
 type Project struct {
 	Key      string
 	Path     string
 	Sessions []Session
 }
 
+// Unknown reports whether the project groups sessions that have no project
+// path, either under UnknownProjectKey or one of its sub-groups.
+func (p Project) Unknown() bool {
+	return strings.TrimSpace(p.Path) == ""
+}
+
 type Session struct {
 	SessionID    string
 	Summary      string
@@ -22,6 +33,22 @@ type Session struct {
 	ProjectPath  string
 	FilePath     string
 	Subagents    []SubagentSession
+	// LastAssistantSnippet is a one-line excerpt of the latest assistant
+	// message, showing where the conversation ended up.
+	LastAssistantSnippet string `json:",omitempty"`
+	// HadError reports that Codex recorded an error event in the session,
+	// e.g. a failed model request. Rollouts without such events leave it
+	// false.
+	HadError bool `json:",omitempty"`
+	// ContentDigest is a hex SHA-256 over the session's rollout file(s). It
+	// is only computed when DiscoverOptions.ContentDigest is set.
+	ContentDigest string `json:",omitempty"`
+	// PromotedSubagent marks a subagent session whose parent was not found
+	// and that is listed at the top level instead. OrphanAgentID and
+	// OrphanParentID are only set on such sessions.
+	PromotedSubagent bool   `json:",omitempty"`
+	OrphanAgentID    string `json:",omitempty"`
+	OrphanParentID   string `json:",omitempty"`
 }
 
 type SubagentSession struct {
@@ -36,6 +63,38 @@ type SubagentSession struct {
 	FilePath        string
 }
 
+// Resumable reports whether the session has a well-formed UUID session ID,
+// which `codex resume` needs. Sessions known only by a file name or without
+// an ID are listed but cannot be resumed.
+func (s Session) Resumable() bool {
+	return IsValidSessionID(s.SessionID)
+}
+
+// IsValidSessionID reports whether id is a canonical 8-4-4-4-12 hex UUID.
+func IsValidSessionID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
 func (s Session) DisplayTitle() string {
 	kind := HelperSessionKind(s)
 	if s.Summary != "" {
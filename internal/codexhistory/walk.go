@@ -0,0 +1,100 @@
+package codexhistory
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkSessions calls fn for each main session under codexDir, reading one
+// rollout file at a time and keeping none of the sessions, so histories too
+// large for DiscoverProjects can be streamed in constant memory.
+//
+// Walk mode trades grouping for memory: subagent rollouts are skipped and
+// Session.Subagents is always nil, a conversation resumed across several
+// rollout files is reported once per file, and sessions come in directory
+// order (oldest first) rather than by modification time. Empty sessions are
+// skipped as in DiscoverProjects. Cached metadata is used when present, but
+// nothing is added to the caches.
+//
+// An error from fn stops the walk and is returned; fn may return
+// filepath.SkipAll to stop early without one. Unreadable rollout files are
+// skipped and the first such error is returned after the walk.
+func WalkSessions(codexDir string, fn func(Session) error) error {
+	return WalkSessionsContext(context.Background(), codexDir, fn)
+}
+
+func WalkSessionsContext(ctx context.Context, codexDir string, fn func(Session) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	root, err := ResolveCodexDir(codexDir)
+	if err != nil {
+		return err
+	}
+	sessionsDir := filepath.Join(root, "sessions")
+	if !isDir(sessionsDir) {
+		return fmt.Errorf("%w: %s", ErrNoSessionsDir, sessionsDir)
+	}
+	historyIdx, err := loadHistoryIndexContext(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	err = filepath.WalkDir(sessionsDir, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+			return nil
+		}
+		sessionID := parseSessionIDFromFilename(d.Name())
+		if sessionID == "" {
+			return nil
+		}
+		meta, err := readSessionFileMetaForWalk(ctx, path)
+		if err != nil {
+			if isContextError(err) {
+				return err
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("read session %s: %w", path, err)
+			}
+			return nil
+		}
+		if meta.IsSubagent {
+			return nil
+		}
+		sess := sessionFromFileMeta(sessionID, path, enrichSessionFileMeta(meta, sessionID, d.Name(), historyIdx))
+		if isEmptySession(sess) {
+			return nil
+		}
+		return fn(sess)
+	})
+	if err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// readSessionFileMetaForWalk is readSessionFileMetaCachedContext without the
+// writes: the in-process cache would grow with every file walked.
+func readSessionFileMetaForWalk(ctx context.Context, filePath string) (sessionFileMeta, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return sessionFileMeta{}, err
+	}
+	if meta, ok, err := readPersistentSessionMetaContext(ctx, filePath, info); isContextError(err) {
+		return sessionFileMeta{}, err
+	} else if ok {
+		return meta, nil
+	}
+	return readSessionFileMetaContext(ctx, filePath)
+}
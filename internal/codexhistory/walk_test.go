@@ -0,0 +1,60 @@
+package codexhistory
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestWalkSessionsYieldsMainSessionsOnly(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000001", "2026-01-01T00:00:00Z", projDir, `"cli"`, "first")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000002", "2026-01-02T00:00:00Z", projDir, `"cli"`, "second")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000003", "2026-01-03T00:00:00Z", projDir, `{"subagent":"review"}`, "subagent")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000004", "2026-01-04T00:00:00Z", projDir, `"cli"`, "")
+
+	var prompts []string
+	if err := WalkSessions(tmpDir, func(s Session) error {
+		if s.ProjectPath != projDir || s.Subagents != nil {
+			t.Fatalf("walked session = %+v", s)
+		}
+		prompts = append(prompts, s.FirstPrompt)
+		return nil
+	}); err != nil {
+		t.Fatalf("WalkSessions: %v", err)
+	}
+	sort.Strings(prompts)
+	if len(prompts) != 2 || prompts[0] != "first" || prompts[1] != "second" {
+		t.Fatalf("walked prompts = %v", prompts)
+	}
+}
+
+func TestWalkSessionsStopsOnCallbackError(t *testing.T) {
+	tmpDir, sessionsDir, projDir := setupCodexDir(t)
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000001", "2026-01-01T00:00:00Z", projDir, `"cli"`, "first")
+	writeSessionFile(t, sessionsDir, "aaaaaaaa-bbbb-cccc-dddd-000000000002", "2026-01-02T00:00:00Z", projDir, `"cli"`, "second")
+
+	calls := 0
+	if err := WalkSessions(tmpDir, func(Session) error {
+		calls++
+		return filepath.SkipAll
+	}); err != nil || calls != 1 {
+		t.Fatalf("SkipAll: err=%v calls=%d", err, calls)
+	}
+
+	boom := errors.New("boom")
+	calls = 0
+	if err := WalkSessions(tmpDir, func(Session) error {
+		calls++
+		return boom
+	}); !errors.Is(err, boom) || calls != 1 {
+		t.Fatalf("callback error: err=%v calls=%d", err, calls)
+	}
+}
+
+func TestWalkSessionsMissingSessionsDir(t *testing.T) {
+	if err := WalkSessions(t.TempDir(), func(Session) error { return nil }); !IsSessionsDirNotFound(err) {
+		t.Fatalf("err = %v, want ErrNoSessionsDir", err)
+	}
+}
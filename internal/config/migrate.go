@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// migrations[v] upgrades a generation v document to v+1, working on the raw
+// JSON object so it can see fields Config no longer declares. Additive
+// generations need no step and leave their entry nil; add one whenever a field
+// is renamed, removed or changes meaning. Entry 0 covers files written before
+// the version field existed.
+var migrations = [CurrentVersion]func(doc map[string]json.RawMessage) error{
+	3: dropLegacyRunMode,
+}
+
+// dropLegacyRunMode removes the yoloEnabled toggle that generation 4 replaced
+// with agentAutoApproveEnabled. The old value is deliberately not carried
+// over: auto-approval has to be opted into again.
+func dropLegacyRunMode(doc map[string]json.RawMessage) error {
+	delete(doc, "yoloEnabled")
+	return nil
+}
+
+// migrateDocument runs the migration chain on b, a document of generation
+// from, and returns the upgraded document, or b and false when no step
+// applies. Newer generations are never touched here; the reader floor decides
+// whether they can be read at all.
+func migrateDocument(b []byte, from int) ([]byte, bool, error) {
+	var doc map[string]json.RawMessage
+	for v := max(from, 0); v < CurrentVersion; v++ {
+		step := migrations[v]
+		if step == nil {
+			continue
+		}
+		if doc == nil {
+			if err := json.Unmarshal(b, &doc); err != nil {
+				return nil, false, fmt.Errorf("parse config: %w", err)
+			}
+		}
+		if err := step(doc); err != nil {
+			return nil, false, fmt.Errorf("migrate config from version %d: %w", v, err)
+		}
+	}
+	if doc == nil {
+		return b, false, nil
+	}
+	migrated, err := json.Marshal(doc)
+	return migrated, err == nil, err
+}
+
+// migrateConfig brings cfg, decoded from b, up to CurrentVersion.
+func migrateConfig(b []byte, cfg Config) (Config, error) {
+	if cfg.Version >= CurrentVersion {
+		return cfg, nil
+	}
+	migrated, changed, err := migrateDocument(b, cfg.Version)
+	if err != nil {
+		return Config{}, err
+	}
+	if changed {
+		cfg = Config{}
+		if err := json.Unmarshal(migrated, &cfg); err != nil {
+			return Config{}, fmt.Errorf("parse migrated config: %w", err)
+		}
+	}
+	cfg.Version = CurrentVersion
+	return cfg, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestLoadMigratesVersionOneFileAndUpdateWritesItBack(t *testing.T) {
+	doc := `{"version":1,"yoloEnabled":true,"proxyEnabled":true,"profiles":[{"id":"p1","name":"n1","host":"h","port":22}]}`
+	s := writeConfigFile(t, doc)
+
+	cfg, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Version != CurrentVersion || len(cfg.Profiles) != 1 || cfg.ProxyEnabled == nil || !*cfg.ProxyEnabled {
+		t.Fatalf("migrated config = %#v", cfg)
+	}
+	raw, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if string(raw) != doc {
+		t.Fatalf("Load wrote the migration back: %s", raw)
+	}
+
+	if err := s.Update(func(*Config) error { return nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	raw, err = os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if bytes.Contains(raw, []byte("yoloEnabled")) {
+		t.Fatalf("retired field written back: %s", raw)
+	}
+	var header struct {
+		Version   int `json:"version"`
+		MinReader int `json:"minReader"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		t.Fatalf("unmarshal config: %v", err)
+	}
+	if header.Version != CurrentVersion || header.MinReader != MinReaderVersion {
+		t.Fatalf("on-disk header = %+v, want version %d minReader %d", header, CurrentVersion, MinReaderVersion)
+	}
+}
+
+func TestLoadDoesNotRewriteCurrentFile(t *testing.T) {
+	doc := fmt.Sprintf(`{"version":%d,"profiles":[]}`, CurrentVersion)
+	s := writeConfigFile(t, doc)
+	if _, err := s.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	raw, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if string(raw) != doc {
+		t.Fatalf("current config rewritten on load: %s", raw)
+	}
+}
+
+func TestMigrateDocumentRunsStepsFromFileVersion(t *testing.T) {
+	prev := migrations
+	t.Cleanup(func() { migrations = prev })
+	var ran []int
+	for v := range migrations {
+		migrations[v] = func(doc map[string]json.RawMessage) error {
+			ran = append(ran, v)
+			doc["steps"] = json.RawMessage(fmt.Sprint(len(ran)))
+			return nil
+		}
+	}
+
+	out, changed, err := migrateDocument([]byte(`{"version":4}`), 4)
+	if err != nil || !changed {
+		t.Fatalf("migrateDocument = %s, %v, %v", out, changed, err)
+	}
+	var want []int
+	for v := 4; v < CurrentVersion; v++ {
+		want = append(want, v)
+	}
+	if fmt.Sprint(ran) != fmt.Sprint(want) || !bytes.Contains(out, []byte(fmt.Sprintf(`"steps":%d`, len(want)))) {
+		t.Fatalf("ran %v, got %s", ran, out)
+	}
+
+	migrations[5] = func(map[string]json.RawMessage) error { return errors.New("boom") }
+	if _, err := Decode([]byte(`{"version":5}`)); err == nil {
+		t.Fatal("Decode ignored a failing migration")
+	}
+}
@@ -1,6 +1,12 @@
 package config
 
-import "strings"
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
 
 const DefaultModelProfileName = "default"
 
@@ -115,3 +121,145 @@ func (c *Config) RemoveModelProfile(name string) bool {
 	}
 	return false
 }
+
+// ProjectCodexPath returns the Codex binary configured for dir or its nearest
+// configured ancestor, or "" when there is none.
+func (c Config) ProjectCodexPath(dir string) string {
+	if len(c.ProjectCodexPaths) == 0 || strings.TrimSpace(dir) == "" {
+		return ""
+	}
+	dir = filepath.Clean(dir)
+	for {
+		if path := strings.TrimSpace(c.ProjectCodexPaths[dir]); path != "" {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SetProjectCodexPath launches sessions in project with codexPath from now
+// on. An empty codexPath goes back to the normal Codex resolution.
+func (c *Config) SetProjectCodexPath(project, codexPath string) {
+	project = filepath.Clean(project)
+	codexPath = strings.TrimSpace(codexPath)
+	if codexPath == "" {
+		delete(c.ProjectCodexPaths, project)
+		if len(c.ProjectCodexPaths) == 0 {
+			c.ProjectCodexPaths = nil
+		}
+		return
+	}
+	if c.ProjectCodexPaths == nil {
+		c.ProjectCodexPaths = map[string]string{}
+	}
+	c.ProjectCodexPaths[project] = codexPath
+}
+
+// ProjectEnvFor returns the environment variables configured for dir or its
+// nearest configured ancestor, and that project directory. Variables of
+// further ancestors are not merged in.
+func (c Config) ProjectEnvFor(dir string) (string, map[string]string) {
+	if len(c.ProjectEnv) == 0 || strings.TrimSpace(dir) == "" {
+		return "", nil
+	}
+	dir = filepath.Clean(dir)
+	for {
+		if env := c.ProjectEnv[dir]; len(env) > 0 {
+			return dir, env
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// SetProjectEnvVar sets name to value for sessions launched in project.
+func (c *Config) SetProjectEnvVar(project, name, value string) error {
+	if err := ValidateEnvName(name); err != nil {
+		return err
+	}
+	project = filepath.Clean(project)
+	if c.ProjectEnv == nil {
+		c.ProjectEnv = map[string]map[string]string{}
+	}
+	if c.ProjectEnv[project] == nil {
+		c.ProjectEnv[project] = map[string]string{}
+	}
+	c.ProjectEnv[project][name] = value
+	return nil
+}
+
+// UnsetProjectEnvVar removes name from project, or every variable of the
+// project when name is empty.
+func (c *Config) UnsetProjectEnvVar(project, name string) {
+	project = filepath.Clean(project)
+	if name == "" {
+		delete(c.ProjectEnv, project)
+	} else if env := c.ProjectEnv[project]; env != nil {
+		delete(env, name)
+		if len(env) == 0 {
+			delete(c.ProjectEnv, project)
+		}
+	}
+	if len(c.ProjectEnv) == 0 {
+		c.ProjectEnv = nil
+	}
+}
+
+// ValidateEnvName reports whether name can be passed to a child process as
+// an environment variable: a letter or underscore followed by letters,
+// digits and underscores.
+func ValidateEnvName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty environment variable name")
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return fmt.Errorf("invalid environment variable name %q: use letters, digits and underscores, not starting with a digit", name)
+		}
+	}
+	return nil
+}
+
+// LaunchProfileNames returns the names of the launch profiles, sorted.
+func (c Config) LaunchProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.LaunchProfiles))
+}
+
+// SetLaunchProfile stores p under name, replacing a profile of that name.
+func (c *Config) SetLaunchProfile(name string, p LaunchProfile) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("empty launch profile name")
+	}
+	p.ProxyProfile = strings.TrimSpace(p.ProxyProfile)
+	p.ModelProfile = strings.TrimSpace(p.ModelProfile)
+	if c.LaunchProfiles == nil {
+		c.LaunchProfiles = map[string]LaunchProfile{}
+	}
+	c.LaunchProfiles[name] = p
+	return nil
+}
+
+// RemoveLaunchProfile deletes the launch profile name and reports whether it
+// existed.
+func (c *Config) RemoveLaunchProfile(name string) bool {
+	name = strings.TrimSpace(name)
+	if _, ok := c.LaunchProfiles[name]; !ok {
+		return false
+	}
+	delete(c.LaunchProfiles, name)
+	if len(c.LaunchProfiles) == 0 {
+		c.LaunchProfiles = nil
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -121,3 +122,96 @@ func TestConfigModelProfileOps(t *testing.T) {
 		t.Fatalf("removed model profile still found")
 	}
 }
+
+func TestConfigProjectCodexPathOps(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "repo")
+	cfg := Config{Version: CurrentVersion}
+
+	if got := cfg.ProjectCodexPath(project); got != "" {
+		t.Fatalf("ProjectCodexPath without entries = %q", got)
+	}
+	cfg.SetProjectCodexPath(project+string(filepath.Separator), "/opt/codex-patched/bin/codex")
+	if got := cfg.ProjectCodexPath(project); got != "/opt/codex-patched/bin/codex" {
+		t.Fatalf("ProjectCodexPath(project) = %q", got)
+	}
+	if got := cfg.ProjectCodexPath(filepath.Join(project, "cmd", "tool")); got != "/opt/codex-patched/bin/codex" {
+		t.Fatalf("ProjectCodexPath(subdir) = %q", got)
+	}
+	if got := cfg.ProjectCodexPath(filepath.Dir(project)); got != "" {
+		t.Fatalf("ProjectCodexPath(parent) = %q", got)
+	}
+
+	cfg.SetProjectCodexPath(project, " ")
+	if cfg.ProjectCodexPaths != nil || cfg.ProjectCodexPath(project) != "" {
+		t.Fatalf("clearing the only entry left %#v", cfg.ProjectCodexPaths)
+	}
+}
+
+func TestConfigProjectEnvOps(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "repo")
+	cfg := Config{Version: CurrentVersion}
+
+	if dir, env := cfg.ProjectEnvFor(project); dir != "" || env != nil {
+		t.Fatalf("ProjectEnvFor without entries = %q, %#v", dir, env)
+	}
+	if err := cfg.SetProjectEnvVar(project+string(filepath.Separator), "DATABASE_URL", "postgres://localhost/dev"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetProjectEnvVar(project, "FEATURE_X", "1"); err != nil {
+		t.Fatal(err)
+	}
+	dir, env := cfg.ProjectEnvFor(filepath.Join(project, "cmd", "tool"))
+	if dir != project || len(env) != 2 || env["DATABASE_URL"] != "postgres://localhost/dev" {
+		t.Fatalf("ProjectEnvFor(subdir) = %q, %#v", dir, env)
+	}
+	if dir, _ := cfg.ProjectEnvFor(filepath.Dir(project)); dir != "" {
+		t.Fatalf("ProjectEnvFor(parent) = %q", dir)
+	}
+
+	for _, name := range []string{"", "1ABC", "A-B", "A=B"} {
+		if err := cfg.SetProjectEnvVar(project, name, "x"); err == nil {
+			t.Fatalf("SetProjectEnvVar accepted name %q", name)
+		}
+	}
+
+	cfg.UnsetProjectEnvVar(project, "FEATURE_X")
+	if _, env := cfg.ProjectEnvFor(project); len(env) != 1 {
+		t.Fatalf("after unset = %#v", env)
+	}
+	cfg.UnsetProjectEnvVar(project, "DATABASE_URL")
+	if cfg.ProjectEnv != nil {
+		t.Fatalf("removing the last variable left %#v", cfg.ProjectEnv)
+	}
+	_ = cfg.SetProjectEnvVar(project, "A", "1")
+	cfg.UnsetProjectEnvVar(project, "")
+	if cfg.ProjectEnv != nil {
+		t.Fatalf("clearing the project left %#v", cfg.ProjectEnv)
+	}
+}
+
+func TestConfigLaunchProfileOps(t *testing.T) {
+	cfg := Config{Version: CurrentVersion}
+	on := true
+	if err := cfg.SetLaunchProfile(" ", LaunchProfile{}); err == nil {
+		t.Fatal("SetLaunchProfile accepted an empty name")
+	}
+	if err := cfg.SetLaunchProfile(" yolo ", LaunchProfile{AutoApprove: &on, ModelProfile: " ds "}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetLaunchProfile("safe", LaunchProfile{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.LaunchProfileNames(); len(got) != 2 || got[0] != "safe" || got[1] != "yolo" {
+		t.Fatalf("LaunchProfileNames = %#v", got)
+	}
+	if p := cfg.LaunchProfiles["yolo"]; p.AutoApprove == nil || !*p.AutoApprove || p.ModelProfile != "ds" {
+		t.Fatalf("yolo = %#v", p)
+	}
+
+	if cfg.RemoveLaunchProfile("missing") {
+		t.Fatal("RemoveLaunchProfile reported removing a missing profile")
+	}
+	if !cfg.RemoveLaunchProfile("safe") || !cfg.RemoveLaunchProfile("yolo") || cfg.LaunchProfiles != nil {
+		t.Fatalf("after removing all = %#v", cfg.LaunchProfiles)
+	}
+}
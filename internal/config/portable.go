@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Portable returns the parts of c that make sense on another machine: proxy
+// and model profiles, the default model profile, launch profiles and the
+// preferences.
+// Instances, runtime migration state and per-project Codex binaries and
+// environment variables describe this installation only, and
+// the variables may hold secrets, so they are left out.
+func (c Config) Portable() Config {
+	out := Config{
+		Version:                      CurrentVersion,
+		MinReader:                    MinReaderVersion,
+		ProxyEnabled:                 c.ProxyEnabled,
+		AgentAutoApproveEnabled:      c.AgentAutoApproveEnabled,
+		AgentAutoApproveRequireClean: c.AgentAutoApproveRequireClean,
+		Profiles:                     append([]Profile(nil), c.Profiles...),
+		DefaultModelProfile:          c.DefaultModelProfile,
+		TUI:                          c.TUI,
+	}
+	if len(c.ModelProfiles) > 0 {
+		out.ModelProfiles = make(map[string]ModelProfile, len(c.ModelProfiles))
+		for name, profile := range c.ModelProfiles {
+			out.ModelProfiles[name] = profile
+		}
+	}
+	if len(c.LaunchProfiles) > 0 {
+		out.LaunchProfiles = maps.Clone(c.LaunchProfiles)
+	}
+	return out
+}
+
+// DecodePortable parses an exported config for import. Unlike Decode it
+// rejects unknown fields and files written by a newer generation, since
+// either could silently lose settings, and it validates the profiles.
+func DecodePortable(b []byte) (Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config: %w", err)
+	}
+	cfg, err := checkReaderVersion(cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	if cfg, err = migrateConfig(b, cfg); err != nil {
+		return Config{}, err
+	}
+	if cfg.Version > CurrentVersion {
+		return Config{}, fmt.Errorf(
+			"refuse to import config version %d > this build %d; upgrade codex-helper",
+			cfg.Version, CurrentVersion,
+		)
+	}
+	if problems := cfg.problems(); len(problems) > 0 {
+		return Config{}, fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return cfg.Portable(), nil
+}
+
+// MergePortable folds an imported config into c. Profiles, model profiles and
+// launch profiles are added or replaced by ID and name; an imported model
+// profile without an API key reference keeps the one already configured. Unset preferences in in
+// leave c's as they are.
+func (c *Config) MergePortable(in Config) {
+	for _, p := range in.Profiles {
+		c.UpsertProfile(p)
+	}
+	for name, p := range in.ModelProfiles {
+		if existing, ok := c.FindModelProfile(name); ok {
+			if p.APIKeyRef == "" {
+				p.APIKeyRef = existing.APIKeyRef
+			}
+			if p != existing {
+				p.Revision = max(p.Revision, existing.Revision+1)
+			}
+		}
+		c.UpsertModelProfile(name, p)
+	}
+	if in.DefaultModelProfile != "" {
+		c.DefaultModelProfile = in.DefaultModelProfile
+	}
+	if in.ProxyEnabled != nil {
+		c.ProxyEnabled = in.ProxyEnabled
+	}
+	if in.AgentAutoApproveEnabled != nil {
+		c.AgentAutoApproveEnabled = in.AgentAutoApproveEnabled
+	}
+	if in.AgentAutoApproveRequireClean != nil {
+		c.AgentAutoApproveRequireClean = in.AgentAutoApproveRequireClean
+	}
+	for name, p := range in.LaunchProfiles {
+		_ = c.SetLaunchProfile(name, p)
+	}
+	if in.TUI != nil {
+		c.TUI = in.TUI
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPortableDropsMachineLocalState(t *testing.T) {
+	enabled := true
+	cfg := Config{
+		Version:               CurrentVersion,
+		RuntimeGeneration:     1,
+		RuntimeCleanupPending: true,
+		ProxyEnabled:          &enabled,
+		Profiles:              []Profile{{ID: "p1", Name: "work", Host: "h", Port: 22}},
+		Instances:             []Instance{{ID: "i1", ProfileID: "p1"}},
+		ModelProfiles:         map[string]ModelProfile{"ds": {Provider: "deepseek", Revision: 2}},
+		TUI:                   &TUIPreferences{TabWidth: 8},
+		LaunchProfiles:        map[string]LaunchProfile{"remote": {Proxy: &enabled, ProxyProfile: "work"}},
+	}
+	out := cfg.Portable()
+	if out.Instances != nil || out.RuntimeGeneration != 0 || out.RuntimeCleanupPending {
+		t.Fatalf("portable config kept local state: %+v", out)
+	}
+	if len(out.Profiles) != 1 || out.ModelProfiles["ds"].Provider != "deepseek" || out.TUI.TabWidth != 8 || out.ProxyEnabled == nil || out.LaunchProfiles["remote"].ProxyProfile != "work" {
+		t.Fatalf("portable config lost settings: %+v", out)
+	}
+	out.ModelProfiles["ds"] = ModelProfile{}
+	if cfg.ModelProfiles["ds"].Provider != "deepseek" {
+		t.Fatal("Portable shares its model profile map with the source")
+	}
+}
+
+func TestDecodePortableValidates(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"unknown field", `{"version":6,"profiles":[],"bogus":1}`, "unknown field"},
+		{"newer version", `{"version":99,"profiles":[]}`, "refuse to import"},
+		{"stale reader", `{"version":6,"minReader":99,"profiles":[]}`, "upgrade codex-helper"},
+		{"profile without host", `{"version":6,"profiles":[{"id":"p1"}]}`, "missing host"},
+		{"model profile without provider", `{"version":6,"profiles":[],"modelProfiles":{"x":{}}}`, "missing provider"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodePortable([]byte(tc.doc))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("DecodePortable error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergePortableUpsertsAndKeepsKeys(t *testing.T) {
+	cfg := Config{
+		Profiles: []Profile{{ID: "p1", Host: "old"}, {ID: "p2", Host: "keep"}},
+		ModelProfiles: map[string]ModelProfile{
+			"ds": {Provider: "deepseek", Model: "v3", APIKeyRef: "secret:model-profile/ds/api-key", Revision: 3},
+		},
+	}
+	cfg.MergePortable(Config{
+		Profiles:       []Profile{{ID: "p1", Host: "new"}, {ID: "p3", Host: "added"}},
+		ModelProfiles:  map[string]ModelProfile{"ds": {Provider: "deepseek", Model: "v4", Revision: 1}},
+		TUI:            &TUIPreferences{LayoutMode: "2col"},
+		LaunchProfiles: map[string]LaunchProfile{"safe": {ModelProfile: "ds"}},
+	})
+
+	hosts := map[string]string{}
+	for _, p := range cfg.Profiles {
+		hosts[p.ID] = p.Host
+	}
+	if hosts["p1"] != "new" || hosts["p2"] != "keep" || hosts["p3"] != "added" {
+		t.Fatalf("profiles after merge = %+v", cfg.Profiles)
+	}
+	ds := cfg.ModelProfiles["ds"]
+	if ds.Model != "v4" || ds.APIKeyRef != "secret:model-profile/ds/api-key" || ds.Revision != 4 {
+		t.Fatalf("model profile after merge = %+v", ds)
+	}
+	if cfg.TUI == nil || cfg.TUI.LayoutMode != "2col" || cfg.LaunchProfiles["safe"].ModelProfile != "ds" {
+		t.Fatalf("merge lost or skipped settings: %+v", cfg)
+	}
+}
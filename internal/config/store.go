@@ -9,8 +9,11 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gofrs/flock"
+
+	"github.com/baaaaaaaka/codex-helper/internal/appdirs"
 )
 
 // ErrStaleReader indicates the on-disk config requires a newer codex-helper
@@ -70,7 +73,7 @@ func DefaultPathForHome(home string) (string, error) {
 }
 
 func NewStore(pathOverride string) (*Store, error) {
-	path := pathOverride
+	path := appdirs.ExpandHome(pathOverride)
 	if path == "" {
 		p, err := DefaultPath()
 		if err != nil {
@@ -146,11 +149,35 @@ func (s *Store) loadUnlocked() (Config, error) {
 		return Config{}, fmt.Errorf("read config: %w", err)
 	}
 
+	// An older file is migrated in memory only; Save and Update write the
+	// upgrade, so a plain Load never touches the file.
+	return s.decodeFile(b)
+}
+
+// fileVersion returns the version stamped in a config document.
+func fileVersion(b []byte) int {
+	var header struct {
+		Version int `json:"version"`
+	}
+	_ = json.Unmarshal(b, &header)
+	return header.Version
+}
+
+// Decode parses a config document and applies the same reader gate and
+// migrations as Load.
+func Decode(b []byte) (Config, error) {
 	var cfg Config
 	if err := json.Unmarshal(b, &cfg); err != nil {
-		return Config{}, fmt.Errorf("parse config: %w", err)
+		return Config{}, fmt.Errorf("parse config: %w", describeJSONError(b, err))
+	}
+	cfg, err := checkReaderVersion(cfg)
+	if err != nil {
+		return Config{}, err
 	}
+	return migrateConfig(b, cfg)
+}
 
+func checkReaderVersion(cfg Config) (Config, error) {
 	// Three-state gate. Compatibility is decided by the reader floor, not by
 	// version equality, so an additive future config (higher version, same
 	// floor) reads fine instead of bricking an older binary.
@@ -169,13 +196,6 @@ func (s *Store) loadUnlocked() (Config, error) {
 			WriterVersion: cfg.Version,
 		}
 	}
-
-	// Migrate legacy write-generation stamps in memory. Newer additive
-	// generations (Version > CurrentVersion) are left as-is and read tolerantly.
-	if cfg.Version == 0 || cfg.Version == 1 {
-		cfg.Version = CurrentVersion
-	}
-
 	return cfg, nil
 }
 
@@ -205,11 +225,10 @@ func (s *Store) saveUnlocked(cfg Config) error {
 		return fmt.Errorf("create config dir: %w", err)
 	}
 
-	b, err := json.MarshalIndent(cfg, "", "  ")
+	b, err := Encode(cfg)
 	if err != nil {
-		return fmt.Errorf("marshal config: %w", err)
+		return err
 	}
-	b = append(b, '\n')
 
 	if err := atomicWriteFile(s.path, b, 0o600); err != nil {
 		return fmt.Errorf("atomic write config: %w", err)
@@ -217,3 +236,35 @@ func (s *Store) saveUnlocked(cfg Config) error {
 
 	return nil
 }
+
+// Encode renders cfg in the on-disk format: indented JSON with a trailing
+// newline.
+func Encode(cfg Config) ([]byte, error) {
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// Reset moves the config file aside, to a name with a timestamp suffix, so
+// the next Load starts from defaults. It returns the backup path, or "" when
+// there was no file.
+func (s *Store) Reset(now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.lock.Lock(); err != nil {
+		return "", fmt.Errorf("lock config: %w", err)
+	}
+	defer func() { _ = s.lock.Unlock() }()
+
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	backup := s.path + ".bak-" + now.Format("20060102-150405")
+	if err := os.Rename(s.path, backup); err != nil {
+		return "", fmt.Errorf("move config aside: %w", err)
+	}
+	return backup, nil
+}
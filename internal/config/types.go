@@ -3,10 +3,15 @@ package config
 import "time"
 
 // CurrentVersion is the schema generation this binary stamps into configs it
-// writes. Generation 4 adds the agent-auto-approve preference. The field is
-// additive and keeps the reader floor unchanged, while the newer write
-// generation prevents an older helper from silently dropping the preference.
-const CurrentVersion = 4
+// writes. Generation 4 adds the agent-auto-approve preference, generation 5
+// adds the history TUI preferences, generation 6 adds per-project Codex
+// binaries, generation 7 adds per-project environment variables, generation 8
+// adds launch profiles and generation 9 adds the auto-approve clean-tree
+// guard. All are additive and keep the reader floor unchanged, while the newer
+// write generation prevents an older helper from silently dropping them.
+// Older files are upgraded through migrations on load; the next Save or
+// Update writes the upgrade back.
+const CurrentVersion = 9
 
 // MinReaderVersion is the minimum reader generation required to SAFELY read a
 // config written by this binary. Raise it ONLY for breaking schema changes
@@ -29,16 +34,86 @@ type Config struct {
 	// initialized the generation-1 broker runtime. RuntimeCleanupPending keeps
 	// post-commit compatibility cleanup retryable without making an activated
 	// installation fall back to the retired runner.
-	RuntimeGeneration       int                     `json:"runtimeGeneration,omitempty"`
-	RuntimeMigrationID      string                  `json:"runtimeMigrationId,omitempty"`
-	RuntimeMigratedAt       time.Time               `json:"runtimeMigratedAt,omitempty"`
-	RuntimeCleanupPending   bool                    `json:"runtimeCleanupPending,omitempty"`
-	ProxyEnabled            *bool                   `json:"proxyEnabled,omitempty"`
-	AgentAutoApproveEnabled *bool                   `json:"agentAutoApproveEnabled,omitempty"`
-	Profiles                []Profile               `json:"profiles"`
-	Instances               []Instance              `json:"instances,omitempty"`
-	DefaultModelProfile     string                  `json:"defaultModelProfile,omitempty"`
-	ModelProfiles           map[string]ModelProfile `json:"modelProfiles,omitempty"`
+	RuntimeGeneration       int       `json:"runtimeGeneration,omitempty"`
+	RuntimeMigrationID      string    `json:"runtimeMigrationId,omitempty"`
+	RuntimeMigratedAt       time.Time `json:"runtimeMigratedAt,omitempty"`
+	RuntimeCleanupPending   bool      `json:"runtimeCleanupPending,omitempty"`
+	ProxyEnabled            *bool     `json:"proxyEnabled,omitempty"`
+	AgentAutoApproveEnabled *bool     `json:"agentAutoApproveEnabled,omitempty"`
+	// AgentAutoApproveRequireClean makes launches with auto-approve on ask
+	// for confirmation, or refuse without a terminal, when the working
+	// directory is a git repo with uncommitted changes.
+	AgentAutoApproveRequireClean *bool                   `json:"agentAutoApproveRequireClean,omitempty"`
+	Profiles                     []Profile               `json:"profiles"`
+	Instances                    []Instance              `json:"instances,omitempty"`
+	DefaultModelProfile          string                  `json:"defaultModelProfile,omitempty"`
+	ModelProfiles                map[string]ModelProfile `json:"modelProfiles,omitempty"`
+	TUI                          *TUIPreferences         `json:"tui,omitempty"`
+	// ProjectCodexPaths maps a project directory to the Codex binary sessions
+	// in it (and its subdirectories) are launched with.
+	ProjectCodexPaths map[string]string `json:"projectCodexPaths,omitempty"`
+	// ProjectEnv maps a project directory to environment variables set for
+	// the Codex sessions launched in it (and its subdirectories).
+	ProjectEnv map[string]map[string]string `json:"projectEnv,omitempty"`
+	// LaunchProfiles are named launch settings picked in the history TUI.
+	LaunchProfiles map[string]LaunchProfile `json:"launchProfiles,omitempty"`
+}
+
+// LaunchProfile is a named preset of the settings a session launches with.
+// Unset fields keep the current proxy and auto-approve toggles and the
+// default profiles.
+type LaunchProfile struct {
+	Proxy        *bool  `json:"proxy,omitempty"`
+	ProxyProfile string `json:"proxyProfile,omitempty"`
+	AutoApprove  *bool  `json:"autoApprove,omitempty"`
+	ModelProfile string `json:"modelProfile,omitempty"`
+}
+
+// TUIPreferences holds layout and display choices made inside the history TUI
+// so they survive restarts. Zero values mean "use the built-in default".
+type TUIPreferences struct {
+	// PaneWidthBias shifts the projects pane border, in columns, relative to
+	// the default split. Negative values give more room to the panes on the
+	// right.
+	PaneWidthBias int `json:"paneWidthBias,omitempty"`
+	// LayoutMode pins the pane arrangement ("3col", "2col", "1col",
+	// "compact"). Empty picks one from the terminal size.
+	LayoutMode string `json:"layoutMode,omitempty"`
+	// TabWidth is the tab stop distance in the preview. Zero means 4.
+	TabWidth int `json:"tabWidth,omitempty"`
+	// PreviewMaxWidth caps the preview text width in columns. Zero wraps at
+	// the pane width.
+	PreviewMaxWidth int `json:"previewMaxWidth,omitempty"`
+	// TimeZone is the zone session timestamps are shown in: "local", "UTC",
+	// or an IANA name such as "Europe/Berlin". Empty shows them as recorded.
+	TimeZone string `json:"timeZone,omitempty"`
+	// ShowLastReply adds a dim second line under each session with the
+	// start of its last assistant message.
+	ShowLastReply bool `json:"showLastReply,omitempty"`
+	// LargeSessionMB and LargeSessionMessages are the rollout size, in MiB,
+	// and message count from which the preview is only loaded on request.
+	// Zero means 8 MiB and 2000 messages; negative always loads it.
+	LargeSessionMB       int `json:"largeSessionMB,omitempty"`
+	LargeSessionMessages int `json:"largeSessionMessages,omitempty"`
+	// NewAgentLabel replaces the "(New Agent)" row label.
+	NewAgentLabel string `json:"newAgentLabel,omitempty"`
+	// NewAgentPosition is where that row is listed: "top" (or empty) or
+	// "bottom".
+	NewAgentPosition string `json:"newAgentPosition,omitempty"`
+	// ResumeLast starts the picker on the most recently modified session,
+	// like --resume-last.
+	ResumeLast bool `json:"resumeLast,omitempty"`
+	// AgentsMarker marks projects whose directory has an AGENTS.md.
+	AgentsMarker bool `json:"agentsMarker,omitempty"`
+	// Status is how much the status bar shows: "full" (or empty), every key
+	// hint, or "minimal", the focused pane and essential keys.
+	Status string `json:"status,omitempty"`
+	// Notify signals when a Codex session launched from the history
+	// commands exits: "off" (or empty), "bell" or "desktop".
+	Notify string `json:"notify,omitempty"`
+	// Theme is the TUI color palette: "default" (or empty),
+	// "high-contrast" or "solarized".
+	Theme string `json:"theme,omitempty"`
 }
 
 type Profile struct {
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FileError is returned by Load when the config file cannot be parsed. It
+// names the file so the user knows what to fix.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("config file %s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error { return e.Err }
+
+// ValidationError lists the problems Validate found in a config file that
+// otherwise loads.
+type ValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config file %s: %s", e.Path, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the config file more strictly than Load: it reports keys
+// this build does not know (unless the file comes from a newer one) and
+// profiles missing required fields. It returns a *FileError when the file
+// does not load at all, a *ValidationError listing the problems, or nil.
+func (s *Store) Validate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.lock.Lock(); err != nil {
+		return fmt.Errorf("lock config: %w", err)
+	}
+	defer func() { _ = s.lock.Unlock() }()
+
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	cfg, err := s.decodeFile(b)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	if cfg.Version <= CurrentVersion {
+		// Keys a pending migration removes are not the user's problem.
+		if migrated, _, err := migrateDocument(b, fileVersion(b)); err == nil {
+			b = migrated
+		}
+		var raw any
+		if json.Unmarshal(b, &raw) == nil {
+			for _, key := range unknownJSONKeys(raw, reflect.TypeOf(Config{}), "") {
+				problems = append(problems, fmt.Sprintf("unknown key %q", key))
+			}
+		}
+	}
+	problems = append(problems, cfg.problems()...)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Path: s.path, Problems: problems}
+}
+
+// decodeFile is Decode with parse errors wrapped in a *FileError. A config
+// from a newer, incompatible build is not a broken file and is returned as
+// is.
+func (s *Store) decodeFile(b []byte) (Config, error) {
+	cfg, err := Decode(b)
+	if err != nil && !errors.Is(err, ErrStaleReader) {
+		return Config{}, &FileError{Path: s.path, Err: err}
+	}
+	return cfg, err
+}
+
+// problems lists missing or invalid required fields.
+func (c Config) problems() []string {
+	var out []string
+	seen := map[string]bool{}
+	for i, p := range c.Profiles {
+		id := strings.TrimSpace(p.ID)
+		switch {
+		case id == "":
+			out = append(out, fmt.Sprintf("profiles.%d: missing id", i))
+		case seen[id]:
+			out = append(out, fmt.Sprintf("profiles.%d: duplicate id %q", i, id))
+		}
+		seen[id] = true
+		if strings.TrimSpace(p.Host) == "" {
+			out = append(out, fmt.Sprintf("profiles.%d: missing host", i))
+		}
+		if p.Port < 0 || p.Port > 65535 {
+			out = append(out, fmt.Sprintf("profiles.%d: invalid port %d", i, p.Port))
+		}
+	}
+	names := make([]string, 0, len(c.ModelProfiles))
+	for name := range c.ModelProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.TrimSpace(c.ModelProfiles[name].Provider) == "" {
+			out = append(out, fmt.Sprintf("modelProfiles.%s: missing provider", name))
+		}
+	}
+	return out
+}
+
+// describeJSONError adds the line and column of a syntax error, or the field
+// of a type mismatch, to a json.Unmarshal error.
+func describeJSONError(b []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := lineAndColumn(b, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Errorf("key %q: expected %s, got JSON %s: %w", typeErr.Field, typeErr.Type, typeErr.Value, err)
+	}
+	return err
+}
+
+func lineAndColumn(b []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(b)))
+	before := b[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, max(col-1, 1)
+}
+
+// unknownJSONKeys returns the dotted paths of object keys in raw that have no
+// matching json field in t.
+func unknownJSONKeys(raw any, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var out []string
+	switch v := raw.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				field, ok := fields[strings.ToLower(key)]
+				if !ok {
+					out = append(out, prefix+key)
+					continue
+				}
+				out = append(out, unknownJSONKeys(v[key], field, prefix+key+".")...)
+			}
+		case reflect.Map:
+			for key, value := range v {
+				out = append(out, unknownJSONKeys(value, t.Elem(), prefix+key+".")...)
+			}
+			sort.Strings(out)
+		}
+	case []any:
+		if t.Kind() == reflect.Slice {
+			for i, value := range v {
+				out = append(out, unknownJSONKeys(value, t.Elem(), fmt.Sprintf("%s%d.", prefix, i))...)
+			}
+		}
+	}
+	return out
+}
+
+// jsonFields maps the lower-cased json names of t's fields to their types,
+// matching encoding/json's case-insensitive decoding.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
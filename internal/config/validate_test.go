@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, doc string) *Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return s
+}
+
+func TestLoadReportsSyntaxErrorLocation(t *testing.T) {
+	s := writeConfigFile(t, "{\n  \"version\": 1,\n  \"profiles\": [,]\n}\n")
+	_, err := s.Load()
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != s.Path() {
+		t.Fatalf("expected *FileError for %s, got %v", s.Path(), err)
+	}
+	if !strings.Contains(err.Error(), "line 3, column 16") {
+		t.Fatalf("expected line and column in %q", err)
+	}
+}
+
+func TestLoadReportsTypeErrorKey(t *testing.T) {
+	s := writeConfigFile(t, `{"version":1,"profiles":[{"id":"p","port":"22"}]}`)
+	_, err := s.Load()
+	if err == nil || !strings.Contains(err.Error(), `key "profiles.0.port"`) {
+		t.Fatalf("expected the offending key in %v", err)
+	}
+}
+
+func TestValidateListsProblems(t *testing.T) {
+	s := writeConfigFile(t, `{
+  "version": `+strconv.Itoa(CurrentVersion)+`,
+  "profiles": [
+    {"id": "p1", "host": "h", "port": 22, "colour": "red"},
+    {"id": "p1", "port": 70000}
+  ],
+  "modelProfiles": {"ds": {"model": "x"}},
+  "legacy": 1
+}`)
+	if _, err := s.Load(); err != nil {
+		t.Fatalf("Load should tolerate validation problems: %v", err)
+	}
+	err := s.Validate()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	want := []string{
+		`unknown key "legacy"`,
+		`unknown key "profiles.0.colour"`,
+		`profiles.1: duplicate id "p1"`,
+		"profiles.1: missing host",
+		"profiles.1: invalid port 70000",
+		"modelProfiles.ds: missing provider",
+	}
+	if strings.Join(invalid.Problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("problems = %q, want %q", invalid.Problems, want)
+	}
+}
+
+func TestValidateAcceptsMissingAndValidFiles(t *testing.T) {
+	s, err := NewStore(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+	if err := s.Update(func(cfg *Config) error {
+		cfg.UpsertProfile(Profile{ID: "p1", Name: "work", Host: "h", Port: 22})
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("saved file: %v", err)
+	}
+}
+
+func TestResetMovesFileAside(t *testing.T) {
+	s := writeConfigFile(t, "not json")
+	backup, err := s.Reset(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if backup != s.Path()+".bak-20240506-070809" {
+		t.Fatalf("backup = %q", backup)
+	}
+	if b, err := os.ReadFile(backup); err != nil || string(b) != "not json" {
+		t.Fatalf("backup content = %q, %v", b, err)
+	}
+	if _, err := s.Load(); err != nil {
+		t.Fatalf("Load after reset: %v", err)
+	}
+	if backup, err := s.Reset(time.Now()); err != nil || backup != "" {
+		t.Fatalf("second Reset = %q, %v", backup, err)
+	}
+}
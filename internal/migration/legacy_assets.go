@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -570,6 +571,22 @@ type legacyCacheEnvelope struct {
 	SignedPayload legacyCachePayload `json:"signed_payload"`
 }
 
+// RemoveCloudRequirementsCache deletes the cloud requirements cache in
+// codexHome whoever wrote it, for clearing stale state by hand; Codex fetches
+// the requirements again on its next start. Unlike the migration cleanup it
+// does not check that a legacy build wrote the file. It returns the path and
+// whether a file was removed.
+func RemoveCloudRequirementsCache(codexHome string) (string, bool, error) {
+	path := filepath.Join(codexHome, legacyCacheFile)
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return path, false, nil
+		}
+		return path, false, err
+	}
+	return path, true, nil
+}
+
 func cleanupLegacyCloudCache(report *CleanupReport, codexHome string, binaryPath string) {
 	path := filepath.Join(codexHome, legacyCacheFile)
 	data, err := os.ReadFile(path)
@@ -259,3 +259,22 @@ func writeTestFile(t *testing.T, path string, data []byte) {
 		t.Fatal(err)
 	}
 }
+
+func TestRemoveCloudRequirementsCacheRemovesAnyCache(t *testing.T) {
+	codexHome := t.TempDir()
+	cachePath := filepath.Join(codexHome, legacyCacheFile)
+	// Unlike the migration cleanup, a cache Codex wrote itself goes too.
+	if err := os.WriteFile(cachePath, []byte(`{"signature":"x","signed_payload":{"contents":{"rules":[]}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path, removed, err := RemoveCloudRequirementsCache(codexHome)
+	if err != nil || !removed || path != cachePath {
+		t.Fatalf("RemoveCloudRequirementsCache = %q, %v, %v", path, removed, err)
+	}
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Fatalf("cache still present: %v", err)
+	}
+	if _, removed, err := RemoveCloudRequirementsCache(codexHome); err != nil || removed {
+		t.Fatalf("missing cache = %v, %v; want a clean no-op", removed, err)
+	}
+}
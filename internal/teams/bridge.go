@@ -83,7 +83,9 @@ const (
 )
 
 var ownerMentionLongTurnThreshold = time.Minute
-var discoverCodexProjectsForTeams = codexhistory.DiscoverProjectsContext
+var discoverCodexProjectsForTeams = func(ctx context.Context, codexDir string) ([]codexhistory.Project, error) {
+	return codexhistory.DiscoverProjectsContext(ctx, codexDir)
+}
 var helperRestartDelay = 3 * time.Second
 var helperReloadDrainStaleAfter = 6 * time.Minute
 var codexIdleStatusInitialDelay = 2 * time.Minute
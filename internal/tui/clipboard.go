@@ -0,0 +1,10 @@
+package tui
+
+import "github.com/gdamore/tcell/v2"
+
+// copyToClipboard hands text to the terminal clipboard. tcell sends it with
+// OSC 52, so it works over SSH on terminals that allow it and is silently
+// ignored elsewhere.
+var copyToClipboard = func(screen tcell.Screen, text string) {
+	screen.SetClipboard([]byte(text))
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// loadProgress is updated by the goroutine loading projects and read when
+// drawing the loading screen.
+type loadProgress struct {
+	processed atomic.Int64
+	total     atomic.Int64
+}
+
+type loadProgressKey struct{}
+
+type showEmptySessionsKey struct{}
+
+type sessionLimitKey struct{}
+
+// ShowEmptySessions reports whether the TUI currently lists empty sessions,
+// so Options.LoadProjects can ask discovery to keep them.
+func ShowEmptySessions(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	show, _ := ctx.Value(showEmptySessionsKey{}).(bool)
+	return show
+}
+
+func withShowEmptySessions(ctx context.Context, show bool) context.Context {
+	return context.WithValue(ctx, showEmptySessionsKey{}, show)
+}
+
+// SessionLimit returns how many of the most recent rollout files the TUI
+// currently wants loaded, or 0 for all of them. Pass it to
+// codexhistory.DiscoverOptions.Limit from Options.LoadProjects.
+func SessionLimit(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	limit, _ := ctx.Value(sessionLimitKey{}).(int)
+	return limit
+}
+
+// withLoadSettings carries the TUI's current load choices to LoadProjects.
+func withLoadSettings(ctx context.Context, state *uiState) context.Context {
+	ctx = withShowEmptySessions(ctx, state.showEmptySessions)
+	return context.WithValue(ctx, sessionLimitKey{}, state.sessionLimit)
+}
+
+// LoadProgressReporter returns a callback that feeds the loading screen's
+// progress bar, or nil when ctx does not come from the TUI's initial load.
+// Pass it to codexhistory.DiscoverOptions.Progress from Options.LoadProjects.
+func LoadProgressReporter(ctx context.Context) func(processed, total int) {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(loadProgressKey{}).(*loadProgress)
+	if p == nil {
+		return nil
+	}
+	return p.report
+}
+
+func (p *loadProgress) report(processed, total int) {
+	p.total.Store(int64(total))
+	p.processed.Store(int64(processed))
+}
+
+func (p *loadProgress) snapshot() (processed, total int) {
+	if p == nil {
+		return 0, 0
+	}
+	return int(p.processed.Load()), int(p.total.Load())
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// startFileManager launches the file manager without waiting for it; the
+// process is reaped in the background once it exits.
+var startFileManager = func(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// revealInFileManager opens the folder containing path in the OS file
+// manager. On macOS and Windows the file itself is selected.
+func revealInFileManager(path string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return startFileManager("open", "-R", path)
+	case "windows":
+		return startFileManager("explorer", "/select,"+path)
+	default:
+		return startFileManager("xdg-open", filepath.Dir(path))
+	}
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ErrInterrupted is returned by SelectSession when SIGINT or SIGTERM stops
+// the picker. It wraps context.Canceled, so callers that already treat a
+// cancelled context as a quiet exit need no extra case.
+var ErrInterrupted = fmt.Errorf("session picker interrupted: %w", context.Canceled)
+
+// shutdownSignalGrace is how long the main loop gets to return on its own
+// after a shutdown signal before the terminal is restored from the watcher.
+const shutdownSignalGrace = 2 * time.Second
+
+var notifyShutdownSignals = func(c chan<- os.Signal) (stop func()) {
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	return func() { signal.Stop(c) }
+}
+
+var exitProcess = os.Exit
+
+// watchShutdownSignals keeps a signal sent from outside the terminal, such
+// as kill -INT or kill -TERM, from leaving the terminal in raw mode. The
+// first signal calls quit so the main loop returns and its deferred cleanup
+// runs as usual. If done is not closed within grace, or a second signal
+// arrives, restore is called here and the process exits with the
+// conventional 128+signal status.
+func watchShutdownSignals(signals <-chan os.Signal, done <-chan struct{}, grace time.Duration, quit func(), restore func()) {
+	var sig os.Signal
+	select {
+	case sig = <-signals:
+	case <-done:
+		return
+	}
+	quit()
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return
+	case sig = <-signals:
+	case <-timer.C:
+	}
+	restore()
+	exitProcess(signalExitCode(sig))
+}
+
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+type finiCountingScreen struct {
+	tcell.Screen
+	initDone chan struct{}
+	finis    atomic.Int32
+}
+
+func (s *finiCountingScreen) Init() error {
+	if err := s.Screen.Init(); err != nil {
+		return err
+	}
+	s.Screen.SetSize(80, 24)
+	close(s.initDone)
+	return nil
+}
+
+func (s *finiCountingScreen) Fini() {
+	s.finis.Add(1)
+	s.Screen.Fini()
+}
+
+func stubShutdownSignals(t *testing.T) <-chan chan<- os.Signal {
+	t.Helper()
+	registered := make(chan chan<- os.Signal, 1)
+	prevNotify := notifyShutdownSignals
+	notifyShutdownSignals = func(c chan<- os.Signal) func() {
+		registered <- c
+		return func() {}
+	}
+	t.Cleanup(func() { notifyShutdownSignals = prevNotify })
+	return registered
+}
+
+func stubExitProcess(t *testing.T) <-chan int {
+	t.Helper()
+	codes := make(chan int, 1)
+	prevExit := exitProcess
+	exitProcess = func(code int) { codes <- code }
+	t.Cleanup(func() { exitProcess = prevExit })
+	return codes
+}
+
+func TestSelectSessionSignalRestoresTerminal(t *testing.T) {
+	registered := stubShutdownSignals(t)
+	codes := stubExitProcess(t)
+	screen := &finiCountingScreen{Screen: tcell.NewSimulationScreen("UTF-8"), initDone: make(chan struct{})}
+	prevNewScreen := newScreen
+	newScreen = func() (tcell.Screen, error) { return screen, nil }
+	t.Cleanup(func() { newScreen = prevNewScreen })
+
+	go func() {
+		<-screen.initDone
+		waitForScreenContains(t, screen, "No sessions yet.")
+		(<-registered) <- syscall.SIGINT
+	}()
+	_, err := SelectSession(context.Background(), Options{
+		LoadProjects: func(context.Context) ([]codexhistory.Project, error) { return nil, nil },
+	})
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("SelectSession error = %v, want ErrInterrupted wrapping context.Canceled", err)
+	}
+	// The main loop returned on its own, so the deferred Fini restored the
+	// terminal once and the watcher neither restored it again nor exited.
+	if n := screen.finis.Load(); n != 1 {
+		t.Fatalf("Fini called %d times, want 1", n)
+	}
+	select {
+	case code := <-codes:
+		t.Fatalf("process exited with %d after a clean shutdown", code)
+	default:
+	}
+}
+
+func TestWatchShutdownSignalsForcesRestoreWhenLoopIsStuck(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		second os.Signal
+		grace  time.Duration
+		want   int
+	}{
+		{name: "second signal", second: syscall.SIGINT, grace: time.Hour, want: 130},
+		{name: "grace expired", grace: 10 * time.Millisecond, want: 143},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			codes := stubExitProcess(t)
+			signals := make(chan os.Signal, 2)
+			signals <- syscall.SIGTERM
+			if tc.second != nil {
+				signals <- tc.second
+			}
+			var quits, restores int
+			watchShutdownSignals(signals, make(chan struct{}), tc.grace, func() { quits++ }, func() { restores++ })
+			if quits != 1 || restores != 1 {
+				t.Fatalf("quit called %d times, restore %d times; want 1 and 1", quits, restores)
+			}
+			if code := <-codes; code != tc.want {
+				t.Fatalf("exit code = %d, want %d", code, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme is the set of styles the panes and the status bar are drawn with.
+// Options.Monochrome still drops all of them.
+type Theme struct {
+	Name string
+	// Text is list rows, preview lines and the blank cells around them;
+	// Dim is secondary rows and file paths in the preview.
+	Text tcell.Style
+	Dim  tcell.Style
+	// Selected is the selected row of the focused pane, SelectedBlur that
+	// of the other panes.
+	Selected     tcell.Style
+	SelectedBlur tcell.Style
+	// Border and Title frame unfocused panes, the Focus variants the
+	// focused one, and the Warn variants replace them while AAA mode is on.
+	Border          tcell.Style
+	FocusBorder     tcell.Style
+	Title           tcell.Style
+	FocusTitle      tcell.Style
+	WarnBorder      tcell.Style
+	WarnFocusBorder tcell.Style
+	WarnTitle       tcell.Style
+	WarnFocusTitle  tcell.Style
+	// Status is the status bar and StatusWarn its AAA mode label.
+	Status     tcell.Style
+	StatusWarn tcell.Style
+	// Match is the preview line of the current search match.
+	Match tcell.Style
+}
+
+// DefaultThemeName is the theme used when none is configured: the terminal's
+// own colors with reverse video for selections and the status bar.
+const DefaultThemeName = "default"
+
+var defaultTheme = Theme{
+	Name:            DefaultThemeName,
+	Text:            tcell.StyleDefault,
+	Dim:             tcell.StyleDefault.Dim(true),
+	Selected:        tcell.StyleDefault.Reverse(true).Bold(true),
+	SelectedBlur:    tcell.StyleDefault.Reverse(true).Dim(true),
+	Border:          tcell.StyleDefault.Dim(true),
+	FocusBorder:     tcell.StyleDefault.Bold(true),
+	Title:           tcell.StyleDefault.Reverse(true),
+	FocusTitle:      tcell.StyleDefault.Reverse(true).Bold(true),
+	WarnBorder:      tcell.StyleDefault.Foreground(tcell.ColorYellow).Dim(true),
+	WarnFocusBorder: tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true),
+	WarnTitle:       tcell.StyleDefault.Reverse(true).Foreground(tcell.ColorYellow),
+	WarnFocusTitle:  tcell.StyleDefault.Reverse(true).Bold(true).Foreground(tcell.ColorRed),
+	Status:          tcell.StyleDefault.Reverse(true),
+	StatusWarn:      tcell.StyleDefault.Reverse(true).Foreground(tcell.ColorYellow),
+	Match:           tcell.StyleDefault.Reverse(true),
+}
+
+// highContrastTheme draws white on black with bright selections and no dim
+// text, for terminals where dim and reverse video are hard to read.
+var highContrastTheme = func() Theme {
+	text := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+	bar := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
+	return Theme{
+		Name:            "high-contrast",
+		Text:            text,
+		Dim:             text.Foreground(tcell.ColorSilver),
+		Selected:        bar.Background(tcell.ColorYellow).Bold(true),
+		SelectedBlur:    bar,
+		Border:          text,
+		FocusBorder:     text.Foreground(tcell.ColorYellow).Bold(true),
+		Title:           bar,
+		FocusTitle:      bar.Background(tcell.ColorYellow).Bold(true),
+		WarnBorder:      text.Foreground(tcell.ColorRed),
+		WarnFocusBorder: text.Foreground(tcell.ColorRed).Bold(true),
+		WarnTitle:       bar.Background(tcell.ColorRed),
+		WarnFocusTitle:  bar.Foreground(tcell.ColorWhite).Background(tcell.ColorRed).Bold(true),
+		Status:          bar,
+		StatusWarn:      bar.Foreground(tcell.ColorWhite).Background(tcell.ColorRed).Bold(true),
+		Match:           bar.Background(tcell.ColorAqua).Bold(true),
+	}
+}()
+
+// solarizedTheme uses the Solarized dark palette.
+var solarizedTheme = func() Theme {
+	var (
+		base03 = tcell.NewHexColor(0x002b36)
+		base02 = tcell.NewHexColor(0x073642)
+		base01 = tcell.NewHexColor(0x586e75)
+		base0  = tcell.NewHexColor(0x839496)
+		base1  = tcell.NewHexColor(0x93a1a1)
+		base3  = tcell.NewHexColor(0xfdf6e3)
+		yellow = tcell.NewHexColor(0xb58900)
+		orange = tcell.NewHexColor(0xcb4b16)
+		red    = tcell.NewHexColor(0xdc322f)
+		blue   = tcell.NewHexColor(0x268bd2)
+		cyan   = tcell.NewHexColor(0x2aa198)
+	)
+	text := tcell.StyleDefault.Foreground(base0).Background(base03)
+	bar := tcell.StyleDefault.Foreground(base1).Background(base02)
+	return Theme{
+		Name:            "solarized",
+		Text:            text,
+		Dim:             text.Foreground(base01),
+		Selected:        text.Foreground(base3).Background(blue).Bold(true),
+		SelectedBlur:    bar,
+		Border:          text.Foreground(base01),
+		FocusBorder:     text.Foreground(blue).Bold(true),
+		Title:           bar,
+		FocusTitle:      text.Foreground(base3).Background(blue).Bold(true),
+		WarnBorder:      text.Foreground(yellow),
+		WarnFocusBorder: text.Foreground(orange).Bold(true),
+		WarnTitle:       text.Foreground(base03).Background(yellow),
+		WarnFocusTitle:  text.Foreground(base3).Background(red).Bold(true),
+		Status:          bar,
+		StatusWarn:      text.Foreground(base03).Background(yellow).Bold(true),
+		Match:           text.Foreground(base03).Background(cyan),
+	}
+}()
+
+var themes = []*Theme{&defaultTheme, &highContrastTheme, &solarizedTheme}
+
+// ThemeNames lists the built-in themes, default first.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for _, theme := range themes {
+		names = append(names, theme.Name)
+	}
+	return names
+}
+
+// LookupTheme returns the built-in theme called name, ignoring case. Empty
+// is the default theme.
+func LookupTheme(name string) (*Theme, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return &defaultTheme, true
+	}
+	for _, theme := range themes {
+		if strings.EqualFold(theme.Name, name) {
+			return theme, true
+		}
+	}
+	return nil, false
+}
+
+// activeTheme is the theme to draw with; states built without one use the
+// default.
+func (s *uiState) activeTheme() *Theme {
+	if s.theme == nil {
+		return &defaultTheme
+	}
+	return s.theme
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -35,11 +39,70 @@ func (ProxyToggleRequested) Error() string { return "proxy toggle requested" }
 
 var errQuit = errors.New("quit")
 
+// ErrNotTerminal is returned by SelectSession when there is no terminal to
+// draw on, e.g. when running in CI or under a daemon.
+var ErrNotTerminal = errors.New("the session picker needs an interactive terminal")
+
 const updateErrorDisplayDuration = 4 * time.Second
+const flashMessageDuration = 3 * time.Second
 const previewLinesCacheMaxEntries = 6
 const previewLinesCacheMaxBytes = 24 * 1024 * 1024
 
-var newScreen = tcell.NewScreen
+// previewDebounceDelay is how long the selection must stay put before the
+// preview for it is read, so that scrolling through the list does not start a
+// read for every session passed over.
+const previewDebounceDelay = 150 * time.Millisecond
+
+// maxConcurrentPreviewReads caps how many session files are read for previews
+// at the same time.
+const maxConcurrentPreviewReads = 4
+
+// previewEventBuffer sizes the channel finished previews are handed back on.
+// The main loop drains it on every "preview" event, so it only has to absorb
+// a few rounds of concurrent reads while a slow draw or key handler runs;
+// past that, readers wait on it (or on shutdown) instead of blocking forever.
+const previewEventBuffer = 4 * maxConcurrentPreviewReads
+
+const defaultTabWidth = 4
+
+var newScreen = newTerminalScreen
+
+// isTerminal reports whether tcell can open the controlling terminal. tcell
+// draws on /dev/tty rather than stdin and stdout, so redirecting those is
+// fine. On Windows tcell opens the console itself and reports its own error.
+var isTerminal = func() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	_ = tty.Close()
+	return true
+}
+
+var readSessionPreviewText = codexhistory.ReadSessionPreviewText
+var readSessionConversationText = func(filePath string, maxMessages int, maxLen int) (string, error) {
+	msgs, err := codexhistory.ReadSessionMessagesWithRoles(filePath, maxMessages, codexhistory.ConversationRoles...)
+	if err != nil {
+		return "", err
+	}
+	if text := codexhistory.FormatConversationMessages(msgs, maxLen); text != "" {
+		return text, nil
+	}
+	return codexhistory.ReadSessionOutlineText(filePath)
+}
+var readSessionDiffText = func(filePath string, _ int, _ int) (string, error) {
+	changes, err := codexhistory.ReadSessionFileChanges(filePath)
+	if err != nil {
+		return "", err
+	}
+	if len(changes) == 0 {
+		return "No file changes: this session made no apply_patch calls.", nil
+	}
+	return codexhistory.FormatFileChanges(changes), nil
+}
 var loadingFrames = []string{"-", "\\", "|", "/"}
 
 type Selection struct {
@@ -48,6 +111,9 @@ type Selection struct {
 	Cwd      string
 	UseProxy bool
 	UseAAA   bool
+	// LaunchProfile is the launch profile picked with 'w', or "" to launch
+	// with the proxy and AAA toggles as they are.
+	LaunchProfile string
 }
 
 type Options struct {
@@ -60,9 +126,136 @@ type Options struct {
 	AAAEnabled      bool
 	RefreshInterval time.Duration
 	PersistAAA      func(bool) error
-	DefaultCwd      string
+	// PersistProxy saves a Ctrl+P toggle so the TUI can flip proxy mode in
+	// place. Without it, or when enabling needs a profile set up first, the
+	// toggle leaves the TUI with ProxyToggleRequested.
+	PersistProxy func(bool) error
+	DefaultCwd   string
+	// PaneWidthBias is the persisted projects pane border shift, in columns.
+	PaneWidthBias        int
+	PersistPaneWidthBias func(int) error
+	// LayoutMode is the persisted layout mode: "auto" (or empty), "3col",
+	// "2col", "1col" or "compact".
+	LayoutMode        string
+	PersistLayoutMode func(string) error
+	// TabWidth is the tab stop distance used when expanding tabs in the
+	// preview. Zero uses defaultTabWidth.
+	TabWidth int
+	// PreviewMaxWidth caps the column at which preview text wraps, so prose
+	// stays readable in a very wide pane. Zero wraps at the pane width.
+	PreviewMaxWidth int
+	// Location is the time zone session timestamps are shown in, in the list
+	// and the preview. Nil shows them as recorded, which is UTC for rollout
+	// timestamps.
+	Location *time.Location
+	// ResumeCounts maps a session ID to how many times it was resumed, shown
+	// in the preview.
+	ResumeCounts map[string]int
+	// ProjectCodexPath returns the custom Codex binary configured for a
+	// project directory, or "". The preview mentions it so a project that
+	// launches a patched build is easy to spot. Nil means none are configured.
+	ProjectCodexPath func(dir string) string
+	// ShowLastReply renders a second line per session with the start of the
+	// last assistant message.
+	ShowLastReply        bool
+	PersistShowLastReply func(bool) error
+	// ShowAgentsMarker puts agentsFileMarker in front of projects whose
+	// directory has an AGENTS.md. Each directory is checked once per load.
+	ShowAgentsMarker bool
+	// ProjectFilter pre-fills the projects filter; the first matching project
+	// is selected once history has loaded.
+	ProjectFilter string
+	// SelectMostRecent selects the most recently modified session, and its
+	// project, once history has loaded, with the sessions pane focused so
+	// Enter resumes it. It wins over InitialView's selection; ProjectFilter
+	// wins over it.
+	SelectMostRecent bool
+	// ShowEmptySessions starts with empty sessions listed; see
+	// ShowEmptySessions for how LoadProjects learns about it.
+	ShowEmptySessions bool
+	// HideSubagents starts with subagent sessions left out: promoted orphans
+	// are not listed and sessions have nothing to expand. 's' toggles it.
+	HideSubagents bool
+	// NewAgentLabel replaces the "(New Agent)" label of the row that starts
+	// a new session. Empty keeps the default.
+	NewAgentLabel string
+	// NewAgentAtBottom lists that row after the sessions instead of first,
+	// so moving into a project selects its newest session. The row stays
+	// visible whatever the filter.
+	NewAgentAtBottom bool
+	// MinimalStatus replaces the hint list in the status bar with the
+	// focused pane and a few essential keys, so it rarely wraps. Search,
+	// loading, flash and error messages are unchanged.
+	MinimalStatus bool
+	// SessionLimit starts with only the latest SessionLimit rollout files
+	// loaded; '+' doubles it. Zero loads everything. See SessionLimit for how
+	// LoadProjects learns about it.
+	SessionLimit int
+	// LaunchProfiles are the names of the configured launch profiles, in the
+	// order 'w' cycles through them. The picked one is returned on Selection.
+	LaunchProfiles []string
+	// CodexVersion is the version of the codex binary sessions launch with,
+	// shown next to Version in the status bar. Empty hides it.
+	CodexVersion string
+	// LoadCodexVersion, when set and CodexVersion is empty, detects the
+	// version in the background; the status bar shows it once it returns.
+	LoadCodexVersion func(context.Context) string
+	// Monochrome draws without colors or attributes (NO_COLOR / --no-color);
+	// selected rows get a "> " marker instead of reverse video.
+	Monochrome bool
+	// Theme names the built-in palette to draw with (see ThemeNames). Empty
+	// or unknown names use the default theme.
+	Theme string
+	// ReadOnly is an inspect mode for auditing: sessions can be browsed and
+	// previewed, but Enter and Ctrl+N never return a Selection, and the
+	// proxy, AAA, update and skills keys do nothing, 'o' starts no file
+	// manager, and the layout, pane width and last-reply toggles apply to
+	// this run without being persisted. SelectSession then only returns on
+	// quit.
+	ReadOnly bool
+	// RecentSessions adds a "Recent sessions" entry to the projects list
+	// holding the RecentSessions most recently modified sessions across all
+	// projects. It sits right after the current directory's project, or at
+	// the top when there is none. Zero leaves it out.
+	RecentSessions int
+	// ProjectOnly tells the picker that LoadProjects only returns the
+	// DefaultCwd project (--project-only), so an empty history is reported
+	// as "no sessions for this project" rather than as no history at all.
+	ProjectOnly bool
+	// InitialView restores a view saved through PersistView: its filters and
+	// toggles right away, its selection once history has loaded. A
+	// ProjectFilter given here wins over the saved filter and selection.
+	InitialView *ViewState
+	// LargeSessionBytes and LargeSessionMessages are the rollout file size
+	// and message count from which a session's preview is only read when
+	// asked for ('v'), so selecting a huge rollout keeps navigation
+	// responsive. Zero uses defaultLargeSessionBytes and
+	// defaultLargeSessionMessages; negative always loads the preview.
+	LargeSessionBytes    int64
+	LargeSessionMessages int
+	// PersistView, when set, is handed the current view every
+	// viewAutoSaveInterval while it changes, and once more when
+	// SelectSession returns. Errors are ignored: losing the view only means
+	// starting from the default one next time.
+	PersistView func(ViewState) error
 }
 
+// DefaultRecentSessions is how many sessions the "Recent sessions" entry
+// lists when the caller does not choose.
+const DefaultRecentSessions = 10
+
+// recentProjectKey is the Key of the "Recent sessions" pseudo project.
+const recentProjectKey = "(recent)"
+
+const projectOnlyEmptyText = "No sessions for this project."
+
+const readOnlyHint = "Read-only mode: nothing can be launched from here."
+
+const (
+	defaultLargeSessionBytes    = 8 << 20
+	defaultLargeSessionMessages = 2000
+)
+
 type uiEvent struct {
 	when time.Time
 	kind string
@@ -103,6 +296,14 @@ type previewEvent struct {
 
 const previewFilterVersion = "status-answer-v2"
 
+// previewConversationFilterVersion marks previews read in conversation mode,
+// which adds the user's prompts, so toggling the mode reloads them.
+const previewConversationFilterVersion = "conversation-v1"
+
+// previewDiffFilterVersion marks previews read in diff mode, which lists the
+// files apply_patch changed instead of messages.
+const previewDiffFilterVersion = "diff-v1"
+
 type previewCacheMeta struct {
 	path          string
 	size          int64
@@ -160,6 +361,32 @@ type layout struct {
 	mode     string
 }
 
+// layoutOptions carries the user-adjustable parts of the layout.
+type layoutOptions struct {
+	widthBias    int
+	mode         string
+	hideProjects bool
+}
+
+// Layout modes. layoutModeAuto picks one of the others from the terminal
+// size; an explicit mode that does not fit the terminal falls back to auto.
+const (
+	layoutModeAuto    = "auto"
+	layoutMode3Col    = "3col"
+	layoutMode2Col    = "2col"
+	layoutMode1Col    = "1col"
+	layoutModeCompact = "compact"
+)
+
+var layoutModeCycle = []string{layoutModeAuto, layoutMode3Col, layoutMode2Col, layoutMode1Col, layoutModeCompact}
+
+const (
+	paneWidthBiasStep = 2
+	maxPaneWidthBias  = 40
+	minListPaneWidth  = 16
+	minPreviewWidth   = 20
+)
+
 type listState struct {
 	selected int
 	scroll   int
@@ -174,6 +401,7 @@ type projectItem struct {
 	project       codexhistory.Project
 	isCurrent     bool
 	alwaysVisible bool
+	hasAgentsFile bool
 }
 
 type sessionItem struct {
@@ -194,32 +422,76 @@ const (
 )
 
 type uiState struct {
-	projects         []codexhistory.Project
-	loadError        error
-	loadingProjects  bool
-	loadingStartedAt time.Time
-	focus            string
-	lastListFocus    string
-	inputMode        string
-	inputBuffer      string
-	projectFilter    string
-	sessionFilter    string
-	projectState     listState
-	sessionState     listState
-	previewState     previewState
-	updateStatus     *update.Status
-	updateChecking   bool
-	updateErrorUntil time.Time
-	updateErrorTimer *time.Timer
+	projects        []codexhistory.Project
+	loadError       error
+	unreadableFiles []string
+	// duplicateSessions are the warnings about session IDs recorded under
+	// several projects; '!' lists them after the unreadable files.
+	duplicateSessions []string
+	showUnreadable    bool
+	// previewConversation shows the user's prompts in the preview next to
+	// Codex's replies; tool calls and their output stay hidden either way.
+	previewConversation bool
+	loadingProjects     bool
+	loadingStartedAt    time.Time
+	loadProgress        *loadProgress
+	focus               string
+	lastListFocus       string
+	inputMode           string
+	inputBuffer         string
+	inputOriginal       string
+	projectFilter       string
+	sessionFilter       string
+	projectState        listState
+	sessionState        listState
+	previewState        previewState
+	updateStatus        *update.Status
+	updateChecking      bool
+	updateErrorUntil    time.Time
+	updateErrorTimer    *time.Timer
+	flashMessage        string
+	flashUntil          time.Time
+	flashTimer          *time.Timer
 
 	proxyEnabled    bool
 	proxyConfigured bool
 	aaaEnabled      bool
-
-	expandedSessions  map[string]bool
-	previewCache      map[string]previewCacheEntry
-	previewError      map[string]previewErrorEntry
-	previewLoading    map[string]previewCacheMeta
+	launchProfile   string
+
+	// agentsFiles caches whether a project directory has an AGENTS.md; it
+	// is dropped whenever history is loaded again.
+	agentsFiles map[string]bool
+
+	// previewDiff shows the files apply_patch changed in the selected
+	// session or subagent instead of its messages. It reads the whole
+	// rollout, so it is only on when asked for and is not remembered.
+	previewDiff bool
+	// compactPreviewHeader folds the project and session details above the
+	// preview into one line of counts and times ('b').
+	compactPreviewHeader bool
+	// metadataOnly shows just the details of the selected session or
+	// subagent and never reads its transcript ('z'), for browsing quickly.
+	metadataOnly bool
+	// bodySearch makes the session filter also match the prompts and replies
+	// of main sessions (Ctrl+F). bodyMatches caches, per bodyMatchKey,
+	// whether a session's conversation contains the filter; the search runs
+	// in the background and hands its results over on bodySearchCh.
+	bodySearch        bool
+	bodyMatches       map[string]bool
+	bodySearchRunning bool
+	bodySearchCh      chan bodySearchResult
+
+	// codexVersion is the version LoadCodexVersion detected after startup.
+	codexVersion string
+
+	expandedSessions map[string]bool
+	previewCache     map[string]previewCacheEntry
+	previewError     map[string]previewErrorEntry
+	previewLoading   map[string]previewCacheMeta
+	// previewDeferred holds the large sessions whose preview waits for 'v',
+	// and previewRequested the ones it was pressed for.
+	previewDeferred   map[string]previewCacheMeta
+	previewRequested  map[string]bool
 	previewLines      previewLinesCacheEntry
 	previewLinesCache map[string]previewLinesCacheEntry
 	previewLinesOrder []string
@@ -230,6 +502,41 @@ type uiState struct {
 	previewMatchIdx   int
 	previewSearchKey  string
 	statusHeight      int
+	paneWidthBias     int
+	layoutMode        string
+	showLastReply     bool
+	showEmptySessions bool
+	hideSubagents     bool
+	failedOnly        bool
+	newAgentLabel     string
+	newAgentAtBottom  bool
+	sessionLimit      int
+	showFilePaths     bool
+	hideProjects      bool
+	flatSessions      bool
+	monochrome        bool
+	theme             *Theme
+	sessionTimeMode   string
+	location          *time.Location
+
+	previewDebounce      time.Duration
+	previewPendingKey    string
+	previewPendingSince  time.Time
+	previewDebounceTimer *time.Timer
+	previewReadSlots     chan struct{}
+	// done is closed when SelectSession returns, so preview readers give up
+	// instead of blocking on a channel nobody drains any more.
+	done <-chan struct{}
+}
+
+// projectsHidden reports whether the projects pane is out of view, either
+// collapsed with 'p' or replaced by the flat session list.
+func (s *uiState) projectsHidden() bool {
+	return s.hideProjects || s.flatSessions
+}
+
+func (s *uiState) layoutOptions() layoutOptions {
+	return layoutOptions{widthBias: s.paneWidthBias, mode: s.layoutMode, hideProjects: s.projectsHidden()}
 }
 
 func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
@@ -237,9 +544,11 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 		return nil, errors.New("LoadProjects is required")
 	}
 
+	theme, _ := LookupTheme(opts.Theme)
 	state := &uiState{
 		loadingProjects:   true,
 		loadingStartedAt:  time.Now(),
+		loadProgress:      &loadProgress{},
 		focus:             "projects",
 		lastListFocus:     "projects",
 		proxyEnabled:      opts.ProxyEnabled,
@@ -249,8 +558,27 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 		previewCache:      map[string]previewCacheEntry{},
 		previewError:      map[string]previewErrorEntry{},
 		previewLoading:    map[string]previewCacheMeta{},
+		previewDeferred:   map[string]previewCacheMeta{},
+		previewRequested:  map[string]bool{},
 		previewLinesCache: map[string]previewLinesCacheEntry{},
 		statusHeight:      1,
+		paneWidthBias:     clamp(opts.PaneWidthBias, -maxPaneWidthBias, maxPaneWidthBias),
+		layoutMode:        normalizeLayoutMode(opts.LayoutMode),
+		showLastReply:     opts.ShowLastReply,
+		showEmptySessions: opts.ShowEmptySessions,
+		hideSubagents:     opts.HideSubagents,
+		newAgentLabel:     opts.NewAgentLabel,
+		newAgentAtBottom:  opts.NewAgentAtBottom,
+		sessionLimit:      max(0, opts.SessionLimit),
+		monochrome:        opts.Monochrome,
+		theme:             theme,
+		location:          opts.Location,
+		projectFilter:     strings.TrimSpace(opts.ProjectFilter),
+		previewDebounce:   previewDebounceDelay,
+		previewReadSlots:  make(chan struct{}, maxConcurrentPreviewReads),
+	}
+	if opts.InitialView != nil {
+		applyViewSettings(state, *opts.InitialView)
 	}
 
 	screen, err := newScreen()
@@ -260,18 +588,35 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 	if err := screen.Init(); err != nil {
 		return nil, err
 	}
-	defer screen.Fini()
+	// Deferred first so it runs last, once everything below that draws or
+	// posts events has stopped. The signal watcher may call it earlier when
+	// the main loop does not return in time.
+	restoreTerminal := sync.OnceFunc(screen.Fini)
+	defer restoreTerminal()
 
 	done := make(chan struct{})
 	defer close(done)
+	state.done = done
+	defer func() {
+		if state.previewDebounceTimer != nil {
+			state.previewDebounceTimer.Stop()
+		}
+	}()
+
+	signals := make(chan os.Signal, 2)
+	defer notifyShutdownSignals(signals)()
+	go watchShutdownSignals(signals, done, shutdownSignalGrace, func() {
+		postUIEventWithRetry(context.Background(), done, screen, &uiEvent{when: time.Now(), kind: "signal"})
+	}, restoreTerminal)
 	loadCtx, cancelLoad := context.WithCancel(ctx)
 	defer cancelLoad()
 	loadingTickerCtx, cancelLoadingTicker := context.WithCancel(ctx)
 	defer cancelLoadingTicker()
 
 	projectLoadCh := make(chan projectLoadEvent, 1)
+	progressCtx := context.WithValue(withLoadSettings(loadCtx, state), loadProgressKey{}, state.loadProgress)
 	go func() {
-		projects, err := opts.LoadProjects(loadCtx)
+		projects, err := opts.LoadProjects(progressCtx)
 		select {
 		case <-done:
 			return
@@ -331,7 +676,15 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 		}()
 	}
 
-	previewCh := make(chan previewEvent, 8)
+	codexVersionCh := make(chan string, 1)
+	if opts.LoadCodexVersion != nil && strings.TrimSpace(opts.CodexVersion) == "" {
+		go func() {
+			codexVersionCh <- opts.LoadCodexVersion(ctx)
+			postUIEventWithRetry(ctx, done, screen, &uiEvent{when: time.Now(), kind: "codexVersion"})
+		}()
+	}
+
+	previewCh := make(chan previewEvent, previewEventBuffer)
 
 	if opts.RefreshInterval > 0 {
 		interval := opts.RefreshInterval
@@ -351,6 +704,39 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 		}()
 	}
 
+	var savedView *ViewState
+	saveView := func() {
+		// Until history has loaded the selection is not known yet; keep
+		// whatever was saved before.
+		if opts.PersistView == nil || state.loadingProjects || state.loadError != nil {
+			return
+		}
+		view := captureView(state, opts)
+		if savedView != nil && savedView.equal(view) {
+			return
+		}
+		if opts.PersistView(view) == nil {
+			savedView = &view
+		}
+	}
+	defer saveView()
+	if opts.PersistView != nil {
+		go func() {
+			ticker := time.NewTicker(viewAutoSaveInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					screen.PostEvent(&uiEvent{when: time.Now(), kind: "autosave"})
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
 	go func() {
 		<-ctx.Done()
 		postUIEventWithRetry(ctx, done, screen, &uiEvent{when: time.Now(), kind: "quit"})
@@ -367,6 +753,8 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 			switch tev.kind {
 			case "quit":
 				return nil, ctx.Err()
+			case "signal":
+				return nil, ErrInterrupted
 			case "load":
 				for {
 					select {
@@ -374,7 +762,15 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 						cancelLoadingTicker()
 						state.loadingProjects = false
 						state.projects = ev.projects
-						state.loadError = ev.err
+						state.agentsFiles = nil
+						state.unreadableFiles, state.duplicateSessions, state.loadError = splitUnreadableFiles(ev.err)
+						selectFirstFilteredProject(state, opts)
+						if opts.InitialView != nil && strings.TrimSpace(opts.ProjectFilter) == "" {
+							restoreViewSelection(state, opts, *opts.InitialView)
+						}
+						if opts.SelectMostRecent && strings.TrimSpace(opts.ProjectFilter) == "" {
+							selectMostRecentSession(state, opts)
+						}
 					default:
 						goto nextEvent
 					}
@@ -410,6 +806,13 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 						goto nextEvent
 					}
 				}
+			case "codexVersion":
+				select {
+				case state.codexVersion = <-codexVersionCh:
+				default:
+				}
+			case "autosave":
+				saveView()
 			case "refresh":
 				if !state.loadingProjects {
 					refreshStatePreserveSelection(ctx, state, opts)
@@ -423,6 +826,15 @@ func SelectSession(ctx context.Context, opts Options) (*Selection, error) {
 						goto nextEvent
 					}
 				}
+			case "search":
+				for {
+					select {
+					case res := <-state.bodySearchCh:
+						applyBodySearchResult(state, res)
+					default:
+						goto nextEvent
+					}
+				}
 			}
 		nextEvent:
 			continue
@@ -451,22 +863,24 @@ func handleKey(
 	opts Options,
 	ev *tcell.EventKey,
 ) (*Selection, error) {
+	if state.showUnreadable {
+		state.showUnreadable = false
+		return nil, nil
+	}
 	if state.inputMode != "" {
 		switch ev.Key() {
 		case tcell.KeyESC:
 			if state.inputMode == "preview" {
 				state.previewSearchBuf = state.previewSearch
+			} else {
+				state.inputBuffer = state.inputOriginal
+				applyInputFilter(state)
 			}
 			state.inputMode = ""
 			state.inputBuffer = ""
+			state.inputOriginal = ""
 			return nil, nil
 		case tcell.KeyEnter:
-			if state.inputMode == "projects" {
-				state.projectFilter = strings.TrimSpace(state.inputBuffer)
-			}
-			if state.inputMode == "sessions" {
-				state.sessionFilter = strings.TrimSpace(state.inputBuffer)
-			}
 			if state.inputMode == "preview" {
 				state.previewSearch = strings.TrimSpace(state.previewSearchBuf)
 				state.previewSearchBuf = state.previewSearch
@@ -475,10 +889,15 @@ func handleKey(
 			}
 			state.inputMode = ""
 			state.inputBuffer = ""
+			state.inputOriginal = ""
+			return nil, nil
+		case tcell.KeyCtrlF:
+			toggleBodySearch(screen, state)
 			return nil, nil
 		case tcell.KeyBackspace, tcell.KeyBackspace2:
 			if len(state.inputBuffer) > 0 {
 				state.inputBuffer = state.inputBuffer[:len(state.inputBuffer)-1]
+				applyInputFilter(state)
 			}
 			if state.inputMode == "preview" && len(state.previewSearchBuf) > 0 {
 				state.previewSearchBuf = state.previewSearchBuf[:len(state.previewSearchBuf)-1]
@@ -491,6 +910,7 @@ func handleKey(
 				if state.inputMode == "preview" {
 					state.previewSearchBuf += string(ch)
 				}
+				applyInputFilter(state)
 			}
 			return nil, nil
 		default:
@@ -498,6 +918,14 @@ func handleKey(
 		}
 	}
 
+	if opts.ReadOnly {
+		switch ev.Key() {
+		case tcell.KeyCtrlU, tcell.KeyCtrlK, tcell.KeyCtrlP, tcell.KeyCtrlA:
+			showFlash(screen, state, readOnlyHint)
+			return nil, nil
+		}
+	}
+
 	switch ev.Key() {
 	case tcell.KeyCtrlU:
 		if state.updateStatus != nil && state.updateStatus.Supported && state.updateStatus.UpdateAvailable {
@@ -511,7 +939,14 @@ func handleKey(
 		if enable && !state.proxyConfigured {
 			return nil, ProxyToggleRequested{Enable: true, RequireConfig: true}
 		}
-		return nil, ProxyToggleRequested{Enable: enable}
+		if opts.PersistProxy == nil {
+			return nil, ProxyToggleRequested{Enable: enable}
+		}
+		if err := opts.PersistProxy(enable); err != nil {
+			return nil, err
+		}
+		state.proxyEnabled = enable
+		return nil, nil
 	case tcell.KeyCtrlA:
 		enable := !state.aaaEnabled
 		if opts.PersistAAA != nil {
@@ -527,6 +962,9 @@ func handleKey(
 		}
 		refreshState(ctx, state, opts)
 		return nil, nil
+	case tcell.KeyCtrlF:
+		toggleBodySearch(screen, state)
+		return nil, nil
 	case tcell.KeyCtrlC:
 		return nil, errQuit
 	case tcell.KeyESC:
@@ -545,9 +983,11 @@ func handleKey(
 			if state.focus == "projects" {
 				state.inputMode = "projects"
 				state.inputBuffer = state.projectFilter
+				state.inputOriginal = state.projectFilter
 			} else if state.focus == "sessions" {
 				state.inputMode = "sessions"
 				state.inputBuffer = state.sessionFilter
+				state.inputOriginal = state.sessionFilter
 			} else if state.focus == "preview" {
 				state.inputMode = "preview"
 				state.previewSearchBuf = state.previewSearch
@@ -557,8 +997,7 @@ func handleKey(
 			if state.focus == "preview" {
 				state.focus = state.lastListFocus
 			} else {
-				state.focus = "projects"
-				state.lastListFocus = "projects"
+				focusProjects(state)
 			}
 			return nil, nil
 		case 'l', 'L':
@@ -573,16 +1012,189 @@ func handleKey(
 			return nil, nil
 		case 'n', 'N':
 			if state.focus == "preview" && len(state.previewMatches) > 0 {
-				layoutMode := computeLayout(screen, max(1, state.statusHeight))
+				layoutMode := computeLayout(screen, max(1, state.statusHeight), state.layoutOptions())
+				last := len(state.previewMatches) - 1
+				wrapped := ""
 				if ev.Rune() == 'n' {
+					if state.previewMatchIdx >= last {
+						wrapped = "top"
+					}
 					state.previewMatchIdx = (state.previewMatchIdx + 1) % len(state.previewMatches)
 				} else {
+					if state.previewMatchIdx <= 0 {
+						wrapped = "bottom"
+					}
 					state.previewMatchIdx = (state.previewMatchIdx - 1 + len(state.previewMatches)) % len(state.previewMatches)
 				}
 				matchLine := state.previewMatches[state.previewMatchIdx]
 				state.previewState.scroll = previewScrollToMatch(matchLine, max(0, layoutMode.preview.h-2))
+				if wrapped != "" {
+					showFlash(screen, state, "Search wrapped to "+wrapped+": "+previewMatchCounter(state))
+				}
+				return nil, nil
+			}
+		case 'y', 'Y':
+			if state.focus == "preview" {
+				copyPreviewText(screen, state, opts)
 				return nil, nil
 			}
+		case 'o', 'O':
+			revealSelectedFile(screen, state, opts)
+			return nil, nil
+		case '<', '>':
+			delta := paneWidthBiasStep
+			if ev.Rune() == '<' {
+				delta = -delta
+			}
+			return nil, adjustPaneWidthBias(state, opts, delta)
+		case 'e', 'E':
+			show := !state.showLastReply
+			if opts.PersistShowLastReply != nil && !opts.ReadOnly {
+				if err := opts.PersistShowLastReply(show); err != nil {
+					return nil, err
+				}
+			}
+			state.showLastReply = show
+			return nil, nil
+		case 'x', 'X':
+			if state.loadingProjects {
+				return nil, nil
+			}
+			state.showEmptySessions = !state.showEmptySessions
+			refreshStatePreserveSelection(ctx, state, opts)
+			if state.showEmptySessions {
+				showFlash(screen, state, "Showing empty sessions")
+			} else {
+				showFlash(screen, state, "Hiding empty sessions")
+			}
+			return nil, nil
+		case 's', 'S':
+			state.hideSubagents = !state.hideSubagents
+			state.projectState = listState{}
+			state.sessionState = listState{}
+			state.previewState.scroll = 0
+			if state.hideSubagents {
+				showFlash(screen, state, "Hiding subagent sessions")
+			} else {
+				showFlash(screen, state, "Showing subagent sessions")
+			}
+			return nil, nil
+		case 'u', 'U':
+			state.failedOnly = !state.failedOnly
+			state.projectState = listState{}
+			state.sessionState = listState{}
+			state.previewState.scroll = 0
+			if state.failedOnly {
+				showFlash(screen, state, "Showing only sessions with errors")
+			} else {
+				showFlash(screen, state, "Showing all sessions")
+			}
+			return nil, nil
+		case '+':
+			if state.loadingProjects || state.sessionLimit <= 0 {
+				return nil, nil
+			}
+			state.sessionLimit *= 2
+			refreshStatePreserveSelection(ctx, state, opts)
+			showFlash(screen, state, fmt.Sprintf("Showing the latest %d session files", state.sessionLimit))
+			return nil, nil
+		case 'f', 'F':
+			state.showFilePaths = !state.showFilePaths
+			if state.showFilePaths {
+				showFlash(screen, state, "Showing rollout file paths in the preview")
+			} else {
+				showFlash(screen, state, "Hiding rollout file paths in the preview")
+			}
+			return nil, nil
+		case 'b', 'B':
+			state.compactPreviewHeader = !state.compactPreviewHeader
+			state.previewState.scroll = 0
+			if state.compactPreviewHeader {
+				showFlash(screen, state, "Preview header: one line")
+			} else {
+				showFlash(screen, state, "Preview header: details")
+			}
+			return nil, nil
+		case 'z', 'Z':
+			state.metadataOnly = !state.metadataOnly
+			state.previewState.scroll = 0
+			if state.metadataOnly {
+				showFlash(screen, state, "Preview: details only, transcripts are not read")
+			} else {
+				showFlash(screen, state, "Preview: details and transcript")
+			}
+			return nil, nil
+		case 'p', 'P':
+			state.hideProjects = !state.hideProjects
+			if state.hideProjects {
+				if state.focus == "projects" {
+					state.focus = "sessions"
+				}
+				if state.lastListFocus == "projects" {
+					state.lastListFocus = "sessions"
+				}
+				showFlash(screen, state, "Projects pane hidden (p or h to show)")
+			} else {
+				showFlash(screen, state, "Projects pane shown")
+			}
+			return nil, nil
+		case 'a', 'A':
+			state.flatSessions = !state.flatSessions
+			state.projectState = listState{}
+			state.sessionState = listState{}
+			state.previewState.scroll = 0
+			if state.flatSessions {
+				state.focus = "sessions"
+				state.lastListFocus = "sessions"
+				showFlash(screen, state, "All sessions, newest first (a for projects)")
+			} else {
+				showFlash(screen, state, "Sessions grouped by project")
+			}
+			return nil, nil
+		case '!':
+			state.showUnreadable = len(state.unreadableFiles) > 0 || len(state.duplicateSessions) > 0
+			return nil, nil
+		case 'v', 'V':
+			loadDeferredPreview(state, opts)
+			return nil, nil
+		case 'i', 'I':
+			if state.loadingProjects {
+				return nil, nil
+			}
+			reloadSelectedSession(ctx, screen, state, opts)
+			return nil, nil
+		case 'c', 'C':
+			state.previewConversation = !state.previewConversation
+			if state.previewConversation {
+				showFlash(screen, state, "Preview: conversation (prompts and replies)")
+			} else {
+				showFlash(screen, state, "Preview: Codex replies")
+			}
+			return nil, nil
+		case 'd', 'D':
+			state.previewDiff = !state.previewDiff
+			if state.previewDiff {
+				showFlash(screen, state, "Preview: file changes")
+			} else {
+				showFlash(screen, state, "Preview: messages")
+			}
+			return nil, nil
+		case 'w', 'W':
+			cycleLaunchProfile(screen, state, opts)
+			return nil, nil
+		case 't', 'T':
+			state.sessionTimeMode = nextSessionTimeMode(state.sessionTimeMode)
+			showFlash(screen, state, "Session times: "+state.sessionTimeMode)
+			return nil, nil
+		case 'm', 'M':
+			mode := nextLayoutMode(state.layoutMode)
+			if opts.PersistLayoutMode != nil && !opts.ReadOnly {
+				if err := opts.PersistLayoutMode(mode); err != nil {
+					return nil, err
+				}
+			}
+			state.layoutMode = mode
+			return nil, nil
 		}
 	case tcell.KeyTab:
 		if state.focus == "projects" {
@@ -590,6 +1202,9 @@ func handleKey(
 			state.lastListFocus = "sessions"
 		} else if state.focus == "sessions" {
 			state.focus = "preview"
+		} else if state.projectsHidden() {
+			state.focus = "sessions"
+			state.lastListFocus = "sessions"
 		} else {
 			state.focus = "projects"
 			state.lastListFocus = "projects"
@@ -599,8 +1214,7 @@ func handleKey(
 		if state.focus == "preview" {
 			state.focus = state.lastListFocus
 		} else {
-			state.focus = "projects"
-			state.lastListFocus = "projects"
+			focusProjects(state)
 		}
 		return nil, nil
 	case tcell.KeyRight:
@@ -615,19 +1229,19 @@ func handleKey(
 		return nil, nil
 	}
 
-	layoutMode := computeLayout(screen, max(1, state.statusHeight))
+	layoutMode := computeLayout(screen, max(1, state.statusHeight), state.layoutOptions())
 	listFocus := state.focus
-	if layoutMode.mode == "1col" && state.focus == "preview" {
+	if layoutMode.singleColumn() && state.focus == "preview" {
 		listFocus = state.lastListFocus
 	}
 
-	projects := buildProjectItems(state.projects, opts.DefaultCwd)
+	projects := visibleProjectItems(state, opts)
 	filteredProjects := filterProjects(projects, state.projectFilter)
 	state.projectState.clamp(len(filteredProjects))
 	selectedProject := selectedProject(filteredProjects, state.projectState.selected)
 
-	sessions := buildSessionItems(selectedProject, state.expandedSessions)
-	filteredSessions := filterSessions(sessions, state.sessionFilter)
+	sessions := visibleSessionItems(state, selectedProject)
+	filteredSessions := filterSessionItems(state, sessions)
 	state.sessionState.clamp(len(filteredSessions))
 	selectedItem, selectedOk := selectedSessionItem(filteredSessions, state.sessionState.selected)
 	selectedSession, selectedSubagent, selectedIsNew := sessionSelection(selectedItem)
@@ -636,6 +1250,9 @@ func handleKey(
 		selectedSubagent = nil
 		selectedIsNew = false
 	}
+	if mergedProject(state, selectedProject) && selectedSession != nil {
+		selectedProject = sessionOriginProject(state.projects, *selectedSession, selectedProject)
+	}
 
 	enterPressed := ev.Key() == tcell.KeyEnter || ev.Key() == tcell.KeyCtrlJ || ev.Key() == tcell.KeyCtrlM
 	if ev.Key() == tcell.KeyRune {
@@ -646,6 +1263,10 @@ func handleKey(
 	if state.loadingProjects && (ev.Key() == tcell.KeyCtrlN || enterPressed) {
 		return nil, nil
 	}
+	if opts.ReadOnly && (ev.Key() == tcell.KeyCtrlN || enterPressed) {
+		showFlash(screen, state, readOnlyHint)
+		return nil, nil
+	}
 
 	if ev.Key() == tcell.KeyCtrlO {
 		if listFocus != "sessions" {
@@ -659,30 +1280,34 @@ func handleKey(
 			return nil, nil
 		}
 		state.expandedSessions[parentID] = !state.expandedSessions[parentID]
-		sessions = buildSessionItems(selectedProject, state.expandedSessions)
-		filteredSessions = filterSessions(sessions, state.sessionFilter)
+		sessions = visibleSessionItems(state, selectedProject)
+		filteredSessions = filterSessionItems(state, sessions)
 		state.sessionState.clamp(len(filteredSessions))
 		if idx := findSessionIndex(filteredSessions, parentID); idx >= 0 {
 			state.sessionState.selected = idx
-			state.sessionState.ensureVisible(layoutMode.sessions.h-2, len(filteredSessions))
+			state.sessionState.ensureVisible(sessionListCapacity(layoutMode.sessions.h-2, state.showLastReply), len(filteredSessions))
 		}
 		return nil, nil
 	}
 
 	if ev.Key() == tcell.KeyCtrlN {
 		if cwd := newSessionCwd(selectedProject, opts.DefaultCwd); cwd != "" {
-			return &Selection{Project: selectedProject, Cwd: cwd, UseProxy: state.proxyEnabled, UseAAA: state.aaaEnabled}, nil
+			return &Selection{Project: selectedProject, Cwd: cwd, UseProxy: state.proxyEnabled, UseAAA: state.aaaEnabled, LaunchProfile: state.launchProfile}, nil
 		}
 		return nil, nil
 	}
 
 	if enterPressed {
+		if selectedSession != nil && !selectedSession.Resumable() {
+			showFlash(screen, state, "Can't resume: this session has no valid session ID.")
+			return nil, nil
+		}
 		if selectedSession != nil {
-			return &Selection{Project: selectedProject, Session: *selectedSession, UseProxy: state.proxyEnabled, UseAAA: state.aaaEnabled}, nil
+			return &Selection{Project: selectedProject, Session: *selectedSession, UseProxy: state.proxyEnabled, UseAAA: state.aaaEnabled, LaunchProfile: state.launchProfile}, nil
 		}
 		if selectedIsNew {
 			if cwd := newSessionCwd(selectedProject, opts.DefaultCwd); cwd != "" {
-				return &Selection{Project: selectedProject, Cwd: cwd, UseProxy: state.proxyEnabled, UseAAA: state.aaaEnabled}, nil
+				return &Selection{Project: selectedProject, Cwd: cwd, UseProxy: state.proxyEnabled, UseAAA: state.aaaEnabled, LaunchProfile: state.launchProfile}, nil
 			}
 		}
 	}
@@ -706,7 +1331,7 @@ func handleKey(
 
 	if listFocus == "sessions" {
 		prev := state.sessionState.selected
-		applyListNavigation(&state.sessionState, len(filteredSessions), layoutMode.sessions.h-2, ev)
+		applyListNavigation(&state.sessionState, len(filteredSessions), sessionListCapacity(layoutMode.sessions.h-2, state.showLastReply), ev)
 		if state.sessionState.selected != prev {
 			state.previewState.scroll = 0
 		}
@@ -723,29 +1348,252 @@ func handleKey(
 }
 
 func refreshState(ctx context.Context, state *uiState, opts Options) {
-	projects, err := opts.LoadProjects(ctx)
+	projects, err := opts.LoadProjects(withLoadSettings(ctx, state))
+	unreadable, duplicates, err := splitUnreadableFiles(err)
 	if err != nil {
 		state.loadError = err
 		return
 	}
 	state.loadError = nil
+	state.unreadableFiles = unreadable
+	state.duplicateSessions = duplicates
 	state.projects = projects
+	state.agentsFiles = nil
 	state.projectState = listState{}
 	state.sessionState = listState{}
 	state.previewState = previewState{}
 }
 
 func refreshStatePreserveSelection(ctx context.Context, state *uiState, opts Options) {
-	projects, err := opts.LoadProjects(ctx)
+	projects, err := opts.LoadProjects(withLoadSettings(ctx, state))
+	unreadable, duplicates, err := splitUnreadableFiles(err)
 	if err != nil {
 		state.loadError = err
 		return
 	}
 	state.loadError = nil
+	state.unreadableFiles = unreadable
+	state.duplicateSessions = duplicates
 	state.projects = projects
+	state.agentsFiles = nil
+}
+
+// splitUnreadableFiles separates rollout files discovery could not read,
+// which still leave the other projects to show, from an error that stopped
+// the load. The files are returned as one message each.
+func splitUnreadableFiles(err error) ([]string, []string, error) {
+	var unreadable *codexhistory.UnreadableFilesError
+	if !errors.As(err, &unreadable) {
+		return nil, nil, err
+	}
+	files := make([]string, 0, len(unreadable.Errs))
+	for _, fileErr := range unreadable.Errs {
+		files = append(files, codexhistory.SanitizeTerminalText(fileErr.Error()))
+	}
+	var duplicates []string
+	for _, conflict := range unreadable.Conflicts {
+		duplicates = append(duplicates, codexhistory.SanitizeTerminalText(conflict.Error()))
+	}
+	return files, duplicates, nil
+}
+
+// selectFirstFilteredProject moves the selection to the first project that
+// matches the filter itself, skipping a pinned [current] entry that is only
+// listed because it is always visible.
+func selectFirstFilteredProject(state *uiState, opts Options) {
+	if state.projectFilter == "" {
+		return
+	}
+	needle := strings.ToLower(state.projectFilter)
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	for i, item := range projects {
+		if strings.Contains(strings.ToLower(item.label), needle) {
+			state.projectState = listState{selected: i}
+			return
+		}
+	}
+}
+
+// selectMostRecentSession selects the session modified last in the project
+// it belongs to and focuses the sessions pane. The "Recent sessions" entry
+// is skipped so the session is shown alongside its own project.
+func selectMostRecentSession(state *uiState, opts Options) {
+	latest, _, ok := codexhistory.MostRecentSession(state.projects)
+	if !ok {
+		return
+	}
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	for i, item := range projects {
+		if item.project.Key == recentProjectKey {
+			continue
+		}
+		sessions := filterSessionItems(state, visibleSessionItems(state, item.project))
+		idx := slices.IndexFunc(sessions, func(item sessionItem) bool {
+			return item.kind == sessionItemMain && item.session.SessionID == latest.SessionID
+		})
+		if idx < 0 {
+			continue
+		}
+		state.projectState = listState{selected: i}
+		state.sessionState = listState{selected: idx}
+		state.focus = "sessions"
+		state.lastListFocus = "sessions"
+		return
+	}
 }
 
-func computeLayout(screen tcell.Screen, statusHeight int) layout {
+// revealSelectedFile shows the rollout file of the selected session or
+// subagent in the OS file manager.
+func revealSelectedFile(screen tcell.Screen, state *uiState, opts Options) {
+	if opts.ReadOnly {
+		showFlash(screen, state, readOnlyHint)
+		return
+	}
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	project := selectedProject(projects, state.projectState.selected)
+	sessions := filterSessionItems(state, visibleSessionItems(state, project))
+	item, ok := selectedSessionItem(sessions, state.sessionState.selected)
+	if !ok {
+		showFlash(screen, state, "No session file to show.")
+		return
+	}
+	session, subagent, _ := sessionSelection(item)
+	path := ""
+	if subagent != nil {
+		path = subagent.FilePath
+	} else if session != nil {
+		path = session.FilePath
+	}
+	if strings.TrimSpace(path) == "" {
+		showFlash(screen, state, "No session file to show.")
+		return
+	}
+	if err := revealInFileManager(path); err != nil {
+		showFlash(screen, state, fmt.Sprintf("Can't open file manager: %v", err))
+		return
+	}
+	showFlash(screen, state, "Opened "+filepath.Dir(path))
+}
+
+// loadDeferredPreview lets the next draw load the preview of the selected
+// session even when it is large.
+func loadDeferredPreview(state *uiState, opts Options) {
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	project := selectedProject(projects, state.projectState.selected)
+	sessions := filterSessionItems(state, visibleSessionItems(state, project))
+	item, ok := selectedSessionItem(sessions, state.sessionState.selected)
+	if !ok {
+		return
+	}
+	session, subagent, _ := sessionSelection(item)
+	if cacheKey := previewCacheKey(session, subagent); cacheKey != "" {
+		state.previewRequested[cacheKey] = true
+	}
+}
+
+var reloadSessionMeta = codexhistory.ReloadSessionContext
+
+// reloadSelectedSession re-reads only the selected session's rollout file
+// and updates that session in place, a cheap alternative to a full refresh
+// after Codex added to it. If the file is gone, history is reloaded.
+func reloadSelectedSession(ctx context.Context, screen tcell.Screen, state *uiState, opts Options) {
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	project := selectedProject(projects, state.projectState.selected)
+	sessions := filterSessionItems(state, visibleSessionItems(state, project))
+	item, ok := selectedSessionItem(sessions, state.sessionState.selected)
+	if !ok || item.kind != sessionItemMain {
+		showFlash(screen, state, "Select a session to reload.")
+		return
+	}
+	updated, err := reloadSessionMeta(ctx, item.session)
+	if errors.Is(err, os.ErrNotExist) {
+		refreshStatePreserveSelection(ctx, state, opts)
+		showFlash(screen, state, "Session file is gone; reloaded history.")
+		return
+	}
+	if err != nil {
+		showFlash(screen, state, "Reload failed: "+err.Error())
+		return
+	}
+	for i := range state.projects {
+		for j := range state.projects[i].Sessions {
+			if state.projects[i].Sessions[j].SessionID == updated.SessionID {
+				current := &state.projects[i].Sessions[j]
+				current.MessageCount = updated.MessageCount
+				current.ModifiedAt = updated.ModifiedAt
+				current.LastAssistantSnippet = updated.LastAssistantSnippet
+				current.HadError = updated.HadError
+				current.FirstPrompt = updated.FirstPrompt
+			}
+		}
+	}
+	if cacheKey := previewCacheKey(&updated, nil); cacheKey != "" {
+		delete(state.previewCache, cacheKey)
+		delete(state.previewError, cacheKey)
+	}
+	showFlash(screen, state, fmt.Sprintf("Reloaded session (%d messages)", updated.MessageCount))
+}
+
+// copyPreviewText copies the full preview text of the selected item.
+func copyPreviewText(screen tcell.Screen, state *uiState, opts Options) {
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	project := selectedProject(projects, state.projectState.selected)
+	sessions := filterSessionItems(state, visibleSessionItems(state, project))
+	item, ok := selectedSessionItem(sessions, state.sessionState.selected)
+	if !ok {
+		showFlash(screen, state, "Nothing to copy.")
+		return
+	}
+	session, subagent, _ := sessionSelection(item)
+	text := strings.TrimRight(previewTextForItem(state, session, subagent), "\n")
+	if text == "" {
+		showFlash(screen, state, "Nothing to copy.")
+		return
+	}
+	copyToClipboard(screen, text)
+	lines := strings.Count(text, "\n") + 1
+	if lines == 1 {
+		showFlash(screen, state, "Copied 1 line")
+		return
+	}
+	showFlash(screen, state, fmt.Sprintf("Copied %d lines", lines))
+}
+
+// showFlash shows msg in the status bar for a few seconds. A timer posts an
+// event when it expires so the status bar is redrawn without it.
+func showFlash(screen tcell.Screen, state *uiState, msg string) {
+	state.flashMessage = msg
+	state.flashUntil = time.Now().Add(flashMessageDuration)
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	state.flashTimer = time.AfterFunc(flashMessageDuration, func() {
+		screen.PostEvent(&uiEvent{when: time.Now(), kind: "flash"})
+	})
+}
+
+func flashActive(state *uiState) bool {
+	return state.flashMessage != "" && time.Now().Before(state.flashUntil)
+}
+
+// adjustPaneWidthBias moves the projects pane border by delta columns and
+// persists the new bias when the caller provided a way to do so, outside
+// read-only mode.
+func adjustPaneWidthBias(state *uiState, opts Options, delta int) error {
+	bias := clamp(state.paneWidthBias+delta, -maxPaneWidthBias, maxPaneWidthBias)
+	if bias == state.paneWidthBias {
+		return nil
+	}
+	if opts.PersistPaneWidthBias != nil && !opts.ReadOnly {
+		if err := opts.PersistPaneWidthBias(bias); err != nil {
+			return err
+		}
+	}
+	state.paneWidthBias = bias
+	return nil
+}
+
+func computeLayout(screen tcell.Screen, statusHeight int, opts layoutOptions) layout {
 	maxX, maxY := screen.Size()
 	if statusHeight <= 0 {
 		statusHeight = 1
@@ -755,28 +1603,59 @@ func computeLayout(screen tcell.Screen, statusHeight int) layout {
 	}
 	usableH := max(1, maxY-statusHeight)
 
-	if maxX >= 120 && usableH >= 10 {
+	mode := opts.mode
+	switch {
+	case mode == layoutMode3Col && maxX >= minListPaneWidth+32+minPreviewWidth && usableH >= 3:
+	case mode == layoutMode2Col && maxX >= 3*minListPaneWidth && usableH >= 9:
+	case mode == layoutMode1Col || mode == layoutModeCompact:
+	case maxX >= 120 && usableH >= 10:
+		mode = layoutMode3Col
+	case maxX >= 80 && usableH >= 10:
+		mode = layoutMode2Col
+	default:
+		mode = layoutMode1Col
+	}
+
+	if opts.hideProjects && (mode == layoutMode3Col || mode == layoutMode2Col) {
+		return hiddenProjectsLayout(maxX, usableH, opts.widthBias, mode)
+	}
+
+	switch mode {
+	case layoutMode3Col:
 		leftW := min(40, max(24, maxX/4))
 		midW := min(60, max(32, maxX/3))
-		rightW := max(20, maxX-leftW-midW)
+		leftW = clamp(leftW+opts.widthBias, minListPaneWidth, max(minListPaneWidth, maxX-midW-minPreviewWidth))
+		rightW := max(minPreviewWidth, maxX-leftW-midW)
 		return layout{
 			projects: rect{y: 0, x: 0, h: usableH, w: leftW},
 			sessions: rect{y: 0, x: leftW, h: usableH, w: midW},
 			preview:  rect{y: 0, x: leftW + midW, h: usableH, w: rightW},
-			mode:     "3col",
+			mode:     layoutMode3Col,
 		}
-	}
-
-	if maxX >= 80 && usableH >= 10 {
+	case layoutMode2Col:
 		leftW := min(40, max(24, maxX/3))
+		leftW = clamp(leftW+opts.widthBias, minListPaneWidth, max(minListPaneWidth, maxX-2*minListPaneWidth))
 		rightW := maxX - leftW
-		convH := max(6, int(float64(usableH)*0.6))
-		prevH := max(3, usableH-convH)
+		convH, prevH := splitListPreviewHeight(usableH)
 		return layout{
 			projects: rect{y: 0, x: 0, h: usableH, w: leftW},
 			sessions: rect{y: 0, x: leftW, h: convH, w: rightW},
 			preview:  rect{y: convH, x: leftW, h: prevH, w: rightW},
-			mode:     "2col",
+			mode:     layoutMode2Col,
+		}
+	case layoutModeCompact:
+		// Same list/preview split as the right-hand side of 2col, stacked in a
+		// single column. The preview strip keeps room for its border and at
+		// least one line even when that squeezes the list.
+		listH, prevH := splitListPreviewHeight(usableH)
+		if listH+prevH > usableH {
+			listH = max(0, usableH-prevH)
+		}
+		return layout{
+			projects: rect{y: 0, x: 0, h: listH, w: maxX},
+			sessions: rect{y: 0, x: 0, h: listH, w: maxX},
+			preview:  rect{y: listH, x: 0, h: usableH - listH, w: maxX},
+			mode:     layoutModeCompact,
 		}
 	}
 
@@ -788,21 +1667,112 @@ func computeLayout(screen tcell.Screen, statusHeight int) layout {
 		projects: rect{y: 0, x: 0, h: listH, w: maxX},
 		sessions: rect{y: 0, x: 0, h: listH, w: maxX},
 		preview:  rect{y: listH, x: 0, h: usableH - listH, w: maxX},
-		mode:     "1col",
+		mode:     layoutMode1Col,
+	}
+}
+
+// applyInputFilter makes the in-progress filter text the live project or
+// session filter, so the list narrows as the user types. The selection goes
+// back to the top because the old index points into the unfiltered list.
+func applyInputFilter(state *uiState) {
+	filter := strings.TrimSpace(state.inputBuffer)
+	switch state.inputMode {
+	case "projects":
+		if filter == state.projectFilter {
+			return
+		}
+		state.projectFilter = filter
+		state.projectState = listState{}
+		state.sessionState = listState{}
+	case "sessions":
+		if filter == state.sessionFilter {
+			return
+		}
+		state.sessionFilter = filter
+		state.sessionState = listState{}
+	default:
+		return
+	}
+	state.previewState.scroll = 0
+}
+
+// hiddenProjectsLayout gives the projects pane's columns to the sessions list
+// and preview: side by side in 3col, stacked full width in 2col. The width
+// bias moves the sessions/preview divider instead.
+func hiddenProjectsLayout(maxX int, usableH int, widthBias int, mode string) layout {
+	if mode == layoutMode2Col {
+		listH, prevH := splitListPreviewHeight(usableH)
+		return layout{
+			sessions: rect{y: 0, x: 0, h: listH, w: maxX},
+			preview:  rect{y: listH, x: 0, h: prevH, w: maxX},
+			mode:     layoutMode2Col,
+		}
+	}
+	listW := min(80, max(32, maxX*2/5))
+	listW = clamp(listW+widthBias, minListPaneWidth, max(minListPaneWidth, maxX-minPreviewWidth))
+	return layout{
+		sessions: rect{y: 0, x: 0, h: usableH, w: listW},
+		preview:  rect{y: 0, x: listW, h: usableH, w: max(minPreviewWidth, maxX-listW)},
+		mode:     layoutMode3Col,
+	}
+}
+
+// focusProjects moves focus to the projects list, bringing the pane back if
+// it was hidden with 'p' or by the flat session list.
+func focusProjects(state *uiState) {
+	state.hideProjects = false
+	state.flatSessions = false
+	state.focus = "projects"
+	state.lastListFocus = "projects"
+}
+
+func normalizeLayoutMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	for _, known := range layoutModeCycle {
+		if mode == known {
+			return mode
+		}
 	}
+	return layoutModeAuto
+}
+
+func splitListPreviewHeight(usableH int) (int, int) {
+	listH := max(6, int(float64(usableH)*0.6))
+	prevH := max(3, usableH-listH)
+	return listH, prevH
+}
+
+// singleColumn reports whether the lists share one pane, so that only one of
+// projects or sessions is visible at a time.
+func (l layout) singleColumn() bool {
+	return l.mode == layoutMode1Col || l.mode == layoutModeCompact
+}
+
+// nextLayoutMode returns the mode after current in the cycle bound to 'm'.
+func nextLayoutMode(current string) string {
+	for i, mode := range layoutModeCycle {
+		if mode == current {
+			return layoutModeCycle[(i+1)%len(layoutModeCycle)]
+		}
+	}
+	return layoutModeCycle[0]
 }
 
 func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- previewEvent) error {
+	if state.monochrome {
+		screen = monochromeScreen{Screen: screen}
+	}
 	screen.Clear()
 
-	projects := buildProjectItems(state.projects, opts.DefaultCwd)
+	projects := visibleProjectItems(state, opts)
 	filteredProjects := filterProjects(projects, state.projectFilter)
 	state.projectState.clamp(len(filteredProjects))
 
 	selectedProject := selectedProject(filteredProjects, state.projectState.selected)
 
-	sessions := buildSessionItems(selectedProject, state.expandedSessions)
-	filteredSessions := filterSessions(sessions, state.sessionFilter)
+	sessions := visibleSessionItems(state, selectedProject)
+	ensureBodySearch(screen, state, sessions)
+	filteredSessions := filterSessionItems(state, sessions)
 	state.sessionState.clamp(len(filteredSessions))
 
 	selectedItem, selectedOk := selectedSessionItem(filteredSessions, state.sessionState.selected)
@@ -812,6 +1782,9 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 		selectedSubagent = nil
 		selectedIsNew = false
 	}
+	if mergedProject(state, selectedProject) && selectedSession != nil {
+		selectedProject = sessionOriginProject(state.projects, *selectedSession, selectedProject)
+	}
 
 	projectFilter := state.projectFilter
 	sessionFilter := state.sessionFilter
@@ -822,7 +1795,7 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 		sessionFilter = state.inputBuffer
 	}
 
-	if selectedSession != nil || selectedSubagent != nil {
+	if (selectedSession != nil || selectedSubagent != nil) && !state.metadataOnly {
 		ensurePreview(screen, state, opts, selectedSession, selectedSubagent, previewCh)
 	}
 
@@ -830,13 +1803,14 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 	if state.proxyEnabled {
 		proxyLabel = "Proxy mode (Ctrl+P): on"
 	}
+	theme := state.activeTheme()
 	aaaLabel := "AAA mode (Ctrl+A): off"
-	aaaStyle := tcell.StyleDefault.Reverse(true)
+	aaaStyle := theme.Status
 	if state.aaaEnabled {
 		aaaLabel = "[!] AAA mode (Ctrl+A): on"
-		aaaStyle = aaaStyle.Foreground(tcell.ColorYellow)
+		aaaStyle = theme.StatusWarn
 	}
-	baseStatusStyle := tcell.StyleDefault.Reverse(true)
+	baseStatusStyle := theme.Status
 	newSessionPath := newSessionCwd(selectedProject, opts.DefaultCwd)
 	openLabel := "Enter: open"
 	newHint := ""
@@ -846,40 +1820,68 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 			openLabel = "Enter: new"
 		}
 	}
+	if opts.ReadOnly {
+		openLabel = "Read-only"
+		newHint = ""
+		proxyLabel = ""
+		aaaLabel = ""
+		aaaStyle = baseStatusStyle
+	}
+	// compactStatus is the single-line stand-in for statusSegments on
+	// terminals too short to spare several status rows.
+	compactStatus := openLabel + "  Tab: switch  q: quit"
 	statusSegments := []statusSegment{
 		{text: "Tab/Left/Right: switch  /: search  Ctrl+O: subagents  " + openLabel + "  r: refresh" + newHint + "  " + proxyLabel + "  ", style: baseStatusStyle},
 		{text: aaaLabel + "  ", style: aaaStyle},
 		{text: "  q: quit", style: baseStatusStyle},
 	}
+	if state.sessionLimit > 0 {
+		statusSegments = append(statusSegments, statusSegment{text: fmt.Sprintf("  Latest %d files (+: more)", state.sessionLimit), style: baseStatusStyle})
+	}
 	if state.loadingProjects {
+		compactStatus = loadingStatusText(state) + "  q: quit"
 		statusSegments = []statusSegment{
 			{text: loadingStatusText(state) + "  " + proxyLabel + "  ", style: baseStatusStyle},
 			{text: aaaLabel + "  ", style: aaaStyle},
 			{text: "  q: quit", style: baseStatusStyle},
 		}
 	} else if state.inputMode != "" {
+		compactStatus = "Enter: apply  Esc: cancel"
 		statusSegments = []statusSegment{
 			{text: "Type to search. Enter: apply  Esc: cancel  " + proxyLabel + "  ", style: baseStatusStyle},
 			{text: aaaLabel + "  ", style: aaaStyle},
 		}
 	} else if state.focus == "preview" {
+		compactStatus = "PgUp/PgDn: scroll  Tab: switch  q: quit"
 		statusSegments = []statusSegment{
-			{text: "PgUp/PgDn Home/End: scroll  /: search  Ctrl+O: subagents  " + openLabel + "  Tab/Left/Right: switch" + newHint + "  " + proxyLabel + "  ", style: baseStatusStyle},
+			{text: "PgUp/PgDn Home/End: scroll  /: search  y: copy  Ctrl+O: subagents  " + openLabel + "  Tab/Left/Right: switch" + newHint + "  " + proxyLabel + "  ", style: baseStatusStyle},
 			{text: aaaLabel + "  ", style: aaaStyle},
 			{text: "  q: quit", style: baseStatusStyle},
 		}
-		if state.previewSearch != "" && len(state.previewMatches) > 0 {
-			statusSegments = append(statusSegments, statusSegment{text: "  n/N: next/prev", style: baseStatusStyle})
+		if counter := previewMatchCounter(state); counter != "" {
+			compactStatus = counter + "  " + compactStatus
+			statusSegments = append(statusSegments, statusSegment{text: "  " + counter + "  n/N: next/prev", style: baseStatusStyle})
 		}
 	}
+	if opts.MinimalStatus && !state.loadingProjects && state.inputMode == "" {
+		statusSegments = minimalStatusSegments(state, opts, openLabel, baseStatusStyle, aaaStyle)
+	}
 	if state.loadError != nil {
+		compactStatus = noHistoryText(state.loadError)
 		if len(state.projects) == 0 && newSessionPath != "" {
 			statusSegments = []statusSegment{
-				{text: "No history found. " + openLabel + "  " + proxyLabel + "  ", style: baseStatusStyle},
+				{text: noHistoryText(state.loadError) + " " + openLabel + "  " + proxyLabel + "  ", style: baseStatusStyle},
+				{text: aaaLabel + "  ", style: aaaStyle},
+				{text: "  q: quit", style: baseStatusStyle},
+			}
+		} else if codexhistory.IsSessionsDirNotFound(state.loadError) {
+			statusSegments = []statusSegment{
+				{text: noHistoryText(state.loadError) + "  ", style: baseStatusStyle},
 				{text: aaaLabel + "  ", style: aaaStyle},
 				{text: "  q: quit", style: baseStatusStyle},
 			}
 		} else {
+			compactStatus = fmt.Sprintf("Load error: %v", state.loadError)
 			statusSegments = []statusSegment{
 				{text: fmt.Sprintf("Load error: %v", state.loadError), style: baseStatusStyle},
 				{text: aaaLabel, style: aaaStyle},
@@ -887,6 +1889,23 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 		}
 	}
 
+	if state.launchProfile != "" && state.loadError == nil && !state.loadingProjects && state.inputMode == "" {
+		statusSegments = append(statusSegments, statusSegment{text: "  Launch profile (w): " + state.launchProfile, style: baseStatusStyle})
+	}
+	if state.failedOnly && state.loadError == nil && !state.loadingProjects && state.inputMode == "" {
+		statusSegments = append(statusSegments, statusSegment{text: "  Errors only (u: all)", style: baseStatusStyle})
+	}
+	if n := len(state.unreadableFiles); n > 0 && state.loadError == nil && !state.loadingProjects && state.inputMode == "" {
+		statusSegments = append(statusSegments, statusSegment{text: fmt.Sprintf("  ⚠ %d unreadable %s (!: list)", n, pluralFiles(n)), style: baseStatusStyle.Dim(true)})
+	}
+	if n := len(state.duplicateSessions); n > 0 && state.loadError == nil && !state.loadingProjects && state.inputMode == "" {
+		label := "IDs"
+		if n == 1 {
+			label = "ID"
+		}
+		statusSegments = append(statusSegments, statusSegment{text: fmt.Sprintf("  ⚠ %d duplicate session %s (!: list)", n, label), style: baseStatusStyle.Dim(true)})
+	}
+
 	showUpdateError := state.updateStatus != nil &&
 		!state.updateStatus.Supported &&
 		state.updateStatus.Error != "" &&
@@ -894,13 +1913,29 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 		time.Now().Before(state.updateErrorUntil)
 
 	if state.loadError == nil && showUpdateError && state.inputMode == "" {
+		compactStatus = fmt.Sprintf("Update check failed: %s", state.updateStatus.Error)
 		statusSegments = []statusSegment{
 			{text: fmt.Sprintf("Update check failed: %s", state.updateStatus.Error), style: baseStatusStyle},
 			{text: aaaLabel, style: aaaStyle},
 		}
 	}
 
+	if state.loadError == nil && state.inputMode == "" && flashActive(state) {
+		compactStatus = state.flashMessage
+		statusSegments = []statusSegment{
+			{text: state.flashMessage, style: baseStatusStyle},
+			{text: aaaLabel, style: aaaStyle},
+		}
+	}
+
 	updateRight := versionLabel(opts.Version)
+	codexVersion := strings.TrimSpace(opts.CodexVersion)
+	if codexVersion == "" {
+		codexVersion = strings.TrimSpace(state.codexVersion)
+	}
+	if codexVersion != "" {
+		updateRight = "codex " + versionLabel(codexVersion) + "  " + updateRight
+	}
 	updateBold := false
 	if state.updateStatus == nil && state.updateChecking {
 		updateRight = updateRight + " checking"
@@ -920,23 +1955,26 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 
 	maxX, maxY := screen.Size()
 	statusLines := buildStatusLines(maxX, statusSegments, updateRight, updateBold)
+	if len(statusLines) > 1 && maxY-len(statusLines) < minPaneHeightForFullStatus {
+		statusLines = buildCompactStatusLine(maxX, compactStatus, baseStatusStyle, state.aaaEnabled, aaaStyle, updateRight, updateBold)
+	}
 	if maxY > 0 && len(statusLines) > maxY {
 		statusLines = statusLines[len(statusLines)-maxY:]
 	}
 	state.statusHeight = max(1, len(statusLines))
 
-	layoutMode := computeLayout(screen, state.statusHeight)
+	layoutMode := computeLayout(screen, state.statusHeight, state.layoutOptions())
 	state.projectState.ensureVisible(layoutMode.projects.h-2, len(filteredProjects))
-	state.sessionState.ensureVisible(layoutMode.sessions.h-2, len(filteredSessions))
+	state.sessionState.ensureVisible(sessionListCapacity(layoutMode.sessions.h-2, state.showLastReply), len(filteredSessions))
 
 	listFocus := state.focus
-	if layoutMode.mode == "1col" && state.focus == "preview" {
+	if layoutMode.singleColumn() && state.focus == "preview" {
 		listFocus = state.lastListFocus
 	}
 
-	if layoutMode.mode == "1col" {
+	if layoutMode.singleColumn() {
 		projectRows := renderProjectRows(filteredProjects, listFocus == "projects", state.projectState, layoutMode.projects.w-2, layoutMode.projects.h-2)
-		sessionRows := renderSessionRows(filteredSessions, listFocus == "sessions", state.sessionState, layoutMode.projects.h-2)
+		sessionRows := renderSessionRows(filteredSessions, listFocus == "sessions", state.sessionState, layoutMode.projects.h-2, state.showLastReply)
 		if shouldShowLoadingRows(state) {
 			projectRows = loadingRows(state, layoutMode.projects.h-2)
 			sessionRows = loadingRows(state, layoutMode.projects.h-2)
@@ -948,39 +1986,47 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 			title = "Sessions"
 			listFilter = sessionFilter
 		}
-		drawBox(screen, layoutMode.projects, title, listFocus != "preview", listFilter)
+		drawBox(screen, theme, layoutMode.projects, title, listFocus != "preview", listFilter, state.aaaEnabled)
 		drawList(
 			screen,
+			theme,
 			layoutMode.projects,
 			projectRows,
+			state.monochrome,
 		)
 		if listFocus == "sessions" {
 			drawList(
 				screen,
+				theme,
 				layoutMode.projects,
 				sessionRows,
+				state.monochrome,
 			)
 		}
 	} else {
 		projectRows := renderProjectRows(filteredProjects, state.focus == "projects", state.projectState, layoutMode.projects.w-2, layoutMode.projects.h-2)
-		sessionRows := renderSessionRows(filteredSessions, state.focus == "sessions", state.sessionState, layoutMode.sessions.h-2)
+		sessionRows := renderSessionRows(filteredSessions, state.focus == "sessions", state.sessionState, layoutMode.sessions.h-2, state.showLastReply)
 		if shouldShowLoadingRows(state) {
 			projectRows = loadingRows(state, layoutMode.projects.h-2)
 			sessionRows = loadingRows(state, layoutMode.sessions.h-2)
 		}
 
-		drawBox(screen, layoutMode.projects, "Projects", state.focus == "projects", projectFilter)
+		drawBox(screen, theme, layoutMode.projects, "Projects", state.focus == "projects", projectFilter, state.aaaEnabled)
 		drawList(
 			screen,
+			theme,
 			layoutMode.projects,
 			projectRows,
+			state.monochrome,
 		)
 
-		drawBox(screen, layoutMode.sessions, "Sessions", state.focus == "sessions", sessionFilter)
+		drawBox(screen, theme, layoutMode.sessions, "Sessions", state.focus == "sessions", sessionFilter, state.aaaEnabled)
 		drawList(
 			screen,
+			theme,
 			layoutMode.sessions,
 			sessionRows,
+			state.monochrome,
 		)
 	}
 
@@ -990,7 +2036,7 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 	} else if state.previewSearch != "" {
 		previewFilter = state.previewSearch
 	}
-	drawBox(screen, layoutMode.preview, "Preview", state.focus == "preview", previewFilter)
+	drawBox(screen, theme, layoutMode.preview, "Preview", state.focus == "preview", previewFilter, state.aaaEnabled)
 	lines := wrappedPreviewLinesForSelection(state, selectedProject, selectedSession, selectedSubagent, selectedIsNew, opts, max(0, layoutMode.preview.w-2))
 	viewH := max(0, layoutMode.preview.h-2)
 	state.previewState.scroll = clamp(state.previewState.scroll, 0, max(0, len(lines)-viewH))
@@ -998,6 +2044,7 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 	previewKey := state.previewLines.key + "|search:" + state.previewSearch
 	if previewKey != state.previewSearchKey {
 		state.previewSearchKey = previewKey
+		matchCount := len(state.previewMatches)
 		if state.previewSearch != "" {
 			state.previewMatches = previewFindMatches(lines, state.previewSearch)
 			state.previewMatchIdx = clamp(state.previewMatchIdx, 0, max(0, len(state.previewMatches)-1))
@@ -1009,17 +2056,31 @@ func draw(screen tcell.Screen, state *uiState, opts Options, previewCh chan<- pr
 			state.previewMatches = nil
 			state.previewMatchIdx = 0
 		}
+		// The status bar was laid out before the matches were known, so
+		// redraw it with the new match counter.
+		if len(state.previewMatches) != matchCount {
+			screen.PostEvent(&uiEvent{when: time.Now(), kind: "preview"})
+		}
 	}
 
 	lineAttrs := map[int]tcell.Style{}
+	if state.showFilePaths {
+		for _, idx := range previewFilePathLines(lines) {
+			lineAttrs[idx] = theme.Dim
+		}
+	}
 	if len(state.previewMatches) > 0 {
 		matchLine := state.previewMatches[state.previewMatchIdx]
-		lineAttrs[matchLine] = tcell.StyleDefault.Reverse(true)
+		lineAttrs[matchLine] = theme.Match
 	}
 
-	drawPreview(screen, layoutMode.preview, lines, state.previewState.scroll, lineAttrs)
+	drawPreview(screen, theme, layoutMode.preview, lines, state.previewState.scroll, lineAttrs)
+
+	if state.showUnreadable {
+		drawUnreadableFiles(screen, theme, state.unreadableFiles, state.duplicateSessions, maxX, maxY-state.statusHeight)
+	}
 
-	drawStatusLines(screen, statusLines)
+	drawStatusLines(screen, theme, statusLines)
 	screen.Show()
 	return nil
 }
@@ -1039,6 +2100,12 @@ func ensurePreview(
 	}
 	maxMessages := opts.PreviewMessages
 	meta, err := previewCacheMetaFor(filePath, maxMessages)
+	switch {
+	case state.previewDiff:
+		meta.filterVersion = previewDiffFilterVersion
+	case state.previewConversation:
+		meta.filterVersion = previewConversationFilterVersion
+	}
 	if err != nil {
 		state.previewError[cacheKey] = previewErrorEntry{message: err.Error(), meta: meta}
 		delete(state.previewCache, cacheKey)
@@ -1057,16 +2124,143 @@ func ensurePreview(
 		}
 		delete(state.previewError, cacheKey)
 	}
+	if !state.previewRequested[cacheKey] && isLargeSession(opts, meta.size, previewMessageCount(session, subagent)) {
+		state.previewDeferred[cacheKey] = meta
+		return
+	}
+	delete(state.previewDeferred, cacheKey)
+	if !previewSelectionSettled(screen, state, cacheKey) {
+		return
+	}
 	delete(state.previewError, cacheKey)
 	state.previewLoading[cacheKey] = meta
 
+	slots := state.previewReadSlots
+	done := state.done
+	read := readSessionPreviewText
+	switch {
+	case state.previewDiff:
+		read = readSessionDiffText
+	case state.previewConversation:
+		read = readSessionConversationText
+	}
 	go func(key string, path string, meta previewCacheMeta) {
-		text, err := codexhistory.ReadSessionPreviewText(path, meta.maxMessages, 0)
-		previewCh <- previewEvent{cacheKey: key, meta: meta, text: text, err: err}
-		screen.PostEvent(&uiEvent{when: time.Now(), kind: "preview"})
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+		}
+		text, err := read(path, meta.maxMessages, 0)
+		if slots != nil {
+			<-slots
+		}
+		select {
+		case previewCh <- previewEvent{cacheKey: key, meta: meta, text: text, err: err}:
+		case <-done:
+			return
+		}
+		postUIEventWithRetry(context.Background(), done, screen, &uiEvent{when: time.Now(), kind: "preview"})
 	}(cacheKey, filePath, meta)
 }
 
+// cycleLaunchProfile picks the next launch profile, wrapping back to none
+// after the last one.
+func cycleLaunchProfile(screen tcell.Screen, state *uiState, opts Options) {
+	if opts.ReadOnly {
+		showFlash(screen, state, readOnlyHint)
+		return
+	}
+	if len(opts.LaunchProfiles) == 0 {
+		showFlash(screen, state, "No launch profiles configured (codex-proxy config launch-profile)")
+		return
+	}
+	next := 0
+	if i := slices.Index(opts.LaunchProfiles, state.launchProfile); i >= 0 {
+		next = i + 1
+	}
+	if next >= len(opts.LaunchProfiles) {
+		state.launchProfile = ""
+		showFlash(screen, state, "Launch profile: none (proxy and AAA toggles apply)")
+		return
+	}
+	state.launchProfile = opts.LaunchProfiles[next]
+	showFlash(screen, state, "Launch profile: "+state.launchProfile)
+}
+
+// previewMatchCounter returns the position of the current preview search
+// match as "3/12", or "" when the preview is not focused or nothing matches.
+func previewMatchCounter(state *uiState) string {
+	if state.focus != "preview" || state.previewSearch == "" || len(state.previewMatches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", state.previewMatchIdx+1, len(state.previewMatches))
+}
+
+// previewSelectionSettled reports whether cacheKey has been the selected
+// preview for at least the debounce delay. While it has not, a timer posts a
+// "preview" event so the next draw retries once the delay has passed. The
+// very first selection loads immediately.
+func previewSelectionSettled(screen tcell.Screen, state *uiState, cacheKey string) bool {
+	if state.previewDebounce <= 0 {
+		return true
+	}
+	now := time.Now()
+	if state.previewPendingKey != cacheKey {
+		first := state.previewPendingKey == ""
+		state.previewPendingKey = cacheKey
+		state.previewPendingSince = now
+		if first {
+			return true
+		}
+	}
+	wait := state.previewDebounce - now.Sub(state.previewPendingSince)
+	if wait <= 0 {
+		return true
+	}
+	if state.previewDebounceTimer != nil {
+		state.previewDebounceTimer.Stop()
+	}
+	done := state.done
+	state.previewDebounceTimer = time.AfterFunc(wait, func() {
+		postUIEventWithRetry(context.Background(), done, screen, &uiEvent{when: time.Now(), kind: "preview"})
+	})
+	return false
+}
+
+// isLargeSession reports whether a rollout of size bytes holding messages
+// messages is big enough that its preview waits until it is asked for.
+func isLargeSession(opts Options, size int64, messages int) bool {
+	maxBytes := opts.LargeSessionBytes
+	if maxBytes == 0 {
+		maxBytes = defaultLargeSessionBytes
+	}
+	maxMessages := opts.LargeSessionMessages
+	if maxMessages == 0 {
+		maxMessages = defaultLargeSessionMessages
+	}
+	return (maxBytes > 0 && size >= maxBytes) || (maxMessages > 0 && messages >= maxMessages)
+}
+
+func previewMessageCount(session *codexhistory.Session, subagent *codexhistory.SubagentSession) int {
+	if subagent != nil {
+		return subagent.MessageCount
+	}
+	if session != nil {
+		return session.MessageCount
+	}
+	return 0
+}
+
+func largeSessionPromptText(size int64, messages int) string {
+	details := fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	if messages > 0 {
+		details += fmt.Sprintf(", %d messages", messages)
+	}
+	return "Large session (" + details + ") - press v to load the preview."
+}
+
 func previewCacheMetaFor(filePath string, maxMessages int) (previewCacheMeta, error) {
 	meta := previewCacheMeta{
 		path:          strings.TrimSpace(filePath),
@@ -1077,26 +2271,140 @@ func previewCacheMetaFor(filePath string, maxMessages int) (previewCacheMeta, er
 	if err != nil {
 		return meta, err
 	}
-	meta.size = info.Size()
-	meta.modTime = info.ModTime()
-	return meta, nil
+	meta.size = info.Size()
+	meta.modTime = info.ModTime()
+	return meta, nil
+}
+
+func applyPreviewEvent(state *uiState, ev previewEvent) {
+	if state == nil || ev.cacheKey == "" {
+		return
+	}
+	loadingMeta, ok := state.previewLoading[ev.cacheKey]
+	if !ok || !loadingMeta.equal(ev.meta) {
+		return
+	}
+	delete(state.previewLoading, ev.cacheKey)
+	if ev.err != nil {
+		state.previewError[ev.cacheKey] = previewErrorEntry{message: ev.err.Error(), meta: ev.meta}
+		return
+	}
+	state.previewCache[ev.cacheKey] = previewCacheEntry{text: ev.text, meta: ev.meta, revision: previewMetaRevision(ev.meta)}
+	delete(state.previewError, ev.cacheKey)
+}
+
+// newTerminalScreen checks for a terminal before handing off to tcell, whose
+// own failure on a pipe does not say what went wrong.
+func newTerminalScreen() (tcell.Screen, error) {
+	if !isTerminal() {
+		return nil, ErrNotTerminal
+	}
+	return tcell.NewScreen()
+}
+
+// visibleProjectItems returns the project list for the current view. In flat
+// mode that is a single entry holding every session, so the rest of the UI can
+// keep working on "the selected project". Subagent sessions are left out
+// first when they are hidden, and sessions without errors when only failed
+// ones are shown.
+func visibleProjectItems(state *uiState, opts Options) []projectItem {
+	projects := state.projects
+	if state.hideSubagents {
+		projects = codexhistory.FilterMainSessions(projects, true)
+	}
+	if state.failedOnly {
+		projects = codexhistory.FilterFailedSessions(projects)
+	}
+	if !state.flatSessions {
+		items := buildProjectItems(projects, opts.DefaultCwd)
+		if opts.ShowAgentsMarker {
+			markAgentsFiles(state, items)
+		}
+		return withRecentProjectItem(items, projects, opts.RecentSessions)
+	}
+	return []projectItem{{
+		label:         "All sessions",
+		project:       flatProject(projects),
+		alwaysVisible: true,
+	}}
+}
+
+// withRecentProjectItem inserts the "Recent sessions" pseudo project holding
+// the limit newest sessions of all projects, after the current directory's
+// project if items starts with one.
+func withRecentProjectItem(items []projectItem, projects []codexhistory.Project, limit int) []projectItem {
+	if limit <= 0 {
+		return items
+	}
+	recent := flatProject(projects)
+	if len(recent.Sessions) == 0 {
+		return items
+	}
+	if len(recent.Sessions) > limit {
+		recent.Sessions = recent.Sessions[:limit]
+	}
+	recent.Key = recentProjectKey
+	at := 0
+	if len(items) > 0 && items[0].isCurrent {
+		at = 1
+	}
+	item := projectItem{label: "Recent sessions", project: recent}
+	return append(items[:at:at], append([]projectItem{item}, items[at:]...)...)
+}
+
+// mergedProject reports whether project is a pseudo project gathering
+// sessions from several real ones: the flat list or "Recent sessions".
+func mergedProject(state *uiState, project codexhistory.Project) bool {
+	return state.flatSessions || project.Key == recentProjectKey
+}
+
+// flatProject merges the visible sessions of all projects into one pseudo
+// project, most recently modified first. It has no path, so a new session
+// started from it uses the default cwd.
+func flatProject(projects []codexhistory.Project) codexhistory.Project {
+	var sessions []codexhistory.Session
+	for _, project := range codexhistory.FilterUserVisibleProjects(projects) {
+		for _, session := range codexhistory.FilterUserVisibleSessions(project.Sessions) {
+			if strings.TrimSpace(session.ProjectPath) == "" {
+				session.ProjectPath = project.Path
+			}
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].ModifiedAt.After(sessions[j].ModifiedAt)
+	})
+	return codexhistory.Project{Sessions: sessions}
 }
 
-func applyPreviewEvent(state *uiState, ev previewEvent) {
-	if state == nil || ev.cacheKey == "" {
-		return
+// visibleSessionItems builds the session rows for project. Merged lists add
+// each session's project path, which also makes it searchable with '/'.
+func visibleSessionItems(state *uiState, project codexhistory.Project) []sessionItem {
+	items := arrangeNewAgentItem(buildSessionItemsIn(project, state.expandedSessions, state.sessionTimeMode, state.location), state.newAgentLabel, state.newAgentAtBottom)
+	if !mergedProject(state, project) {
+		return items
 	}
-	loadingMeta, ok := state.previewLoading[ev.cacheKey]
-	if !ok || !loadingMeta.equal(ev.meta) {
-		return
+	for i := range items {
+		if items[i].kind == sessionItemMain {
+			if path := listLabelText(strings.TrimSpace(items[i].session.ProjectPath)); path != "" {
+				items[i].label += "  " + path
+			}
+		}
 	}
-	delete(state.previewLoading, ev.cacheKey)
-	if ev.err != nil {
-		state.previewError[ev.cacheKey] = previewErrorEntry{message: ev.err.Error(), meta: ev.meta}
-		return
+	return items
+}
+
+// sessionOriginProject finds the project that session was listed under, so
+// that resuming from a merged list runs in the right directory.
+func sessionOriginProject(projects []codexhistory.Project, session codexhistory.Session, fallback codexhistory.Project) codexhistory.Project {
+	for _, project := range projects {
+		for _, candidate := range project.Sessions {
+			if candidate.SessionID == session.SessionID && candidate.FilePath == session.FilePath {
+				return project
+			}
+		}
 	}
-	state.previewCache[ev.cacheKey] = previewCacheEntry{text: ev.text, meta: ev.meta, revision: previewMetaRevision(ev.meta)}
-	delete(state.previewError, ev.cacheKey)
+	return fallback
 }
 
 func buildProjectItems(projects []codexhistory.Project, defaultCwd string) []projectItem {
@@ -1143,18 +2451,71 @@ func buildProjectItems(projects []codexhistory.Project, defaultCwd string) []pro
 	return items
 }
 
-func buildSessionItems(project codexhistory.Project, expanded map[string]bool) []sessionItem {
+// Session time modes pick which timestamps session rows show; see
+// sessionTimeLabel. The empty mode is treated as sessionTimeModified.
+const (
+	sessionTimeModified = "modified"
+	sessionTimeCreated  = "created"
+	sessionTimeBoth     = "both"
+)
+
+var sessionTimeModeCycle = []string{sessionTimeModified, sessionTimeCreated, sessionTimeBoth}
+
+func nextSessionTimeMode(mode string) string {
+	for i, known := range sessionTimeModeCycle {
+		if mode == known {
+			return sessionTimeModeCycle[(i+1)%len(sessionTimeModeCycle)]
+		}
+	}
+	return sessionTimeCreated
+}
+
+// displayTime converts t to the zone timestamps are shown in; nil loc keeps
+// t as recorded.
+func displayTime(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// sessionTimeLabel formats the timestamp shown in parentheses after a
+// session or subagent title.
+func sessionTimeLabel(created, modified time.Time, mode string, loc *time.Location) string {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return "unknown"
+		}
+		return displayTime(t, loc).Format("2006-01-02 15:04")
+	}
+	switch mode {
+	case sessionTimeCreated:
+		return "started " + format(created)
+	case sessionTimeBoth:
+		return "started " + format(created) + ", modified " + format(modified)
+	default:
+		return format(modified)
+	}
+}
+
+func buildSessionItems(project codexhistory.Project, expanded map[string]bool, timeMode string) []sessionItem {
+	return buildSessionItemsIn(project, expanded, timeMode, nil)
+}
+
+// buildSessionItemsIn is buildSessionItems with the row timestamps shown in
+// loc.
+func buildSessionItemsIn(project codexhistory.Project, expanded map[string]bool, timeMode string, loc *time.Location) []sessionItem {
 	items := []sessionItem{{
 		label:         "(New Agent)",
 		kind:          sessionItemNew,
 		alwaysVisible: true,
 	}}
 	for _, session := range codexhistory.FilterUserVisibleSessions(project.Sessions) {
-		title := session.DisplayTitle()
-		ts := "unknown"
-		if !session.ModifiedAt.IsZero() {
-			ts = session.ModifiedAt.Format("2006-01-02 15:04")
+		title := listLabelText(session.DisplayTitle())
+		if codexhistory.IsEmptySession(session) {
+			title += " (empty session)"
 		}
+		ts := sessionTimeLabel(session.CreatedAt, session.ModifiedAt, timeMode, loc)
 		marker := "   "
 		if len(session.Subagents) > 0 {
 			if expanded != nil && expanded[session.SessionID] {
@@ -1163,7 +2524,13 @@ func buildSessionItems(project codexhistory.Project, expanded map[string]bool) [
 				marker = "[+]"
 			}
 		}
+		if session.HadError {
+			title = sessionErrorMarker + title
+		}
 		label := fmt.Sprintf("%s %s  (%s)", marker, title, ts)
+		if !session.Resumable() {
+			label += "  [no resume]"
+		}
 		items = append(items, sessionItem{
 			label:   label,
 			session: session,
@@ -1171,12 +2538,13 @@ func buildSessionItems(project codexhistory.Project, expanded map[string]bool) [
 		})
 		if expanded != nil && expanded[session.SessionID] {
 			for _, sub := range session.Subagents {
-				subTitle := sub.DisplayTitle()
-				subTS := "unknown"
-				if !sub.ModifiedAt.IsZero() {
-					subTS = sub.ModifiedAt.Format("2006-01-02 15:04")
+				subTitle := listLabelText(sub.DisplayTitle())
+				subTS := sessionTimeLabel(sub.CreatedAt, sub.ModifiedAt, timeMode, loc)
+				subKind := "subagent"
+				if agentType := listLabelText(strings.TrimSpace(sub.AgentID)); agentType != "" {
+					subKind = "[" + agentType + "]"
 				}
-				subLabel := fmt.Sprintf("  |- subagent %s  (%s)", subTitle, subTS)
+				subLabel := fmt.Sprintf("  |- %s %s  (%s)", subKind, subTitle, subTS)
 				items = append(items, sessionItem{
 					label:         subLabel,
 					subagent:      sub,
@@ -1189,6 +2557,54 @@ func buildSessionItems(project codexhistory.Project, expanded map[string]bool) [
 	return items
 }
 
+// sessionErrorMarker is shown before sessions in which Codex recorded an
+// error.
+const sessionErrorMarker = "! "
+
+// arrangeNewAgentItem applies the custom label and position of the new
+// session row, which buildSessionItems puts first as "(New Agent)".
+func arrangeNewAgentItem(items []sessionItem, label string, atBottom bool) []sessionItem {
+	if len(items) == 0 || items[0].kind != sessionItemNew {
+		return items
+	}
+	if label = listLabelText(strings.TrimSpace(label)); label != "" {
+		items[0].label = label
+	}
+	if atBottom {
+		items = append(items[1:], items[0])
+	}
+	return items
+}
+
+// agentsFileMarker is shown before projects that have an AGENTS.md.
+const agentsFileMarker = "⚙ "
+
+var agentsFileExists = func(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "AGENTS.md"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// markAgentsFiles sets hasAgentsFile on items whose project directory has an
+// AGENTS.md. The current directory entry added without sessions and the
+// unknown-path groups are not checked.
+func markAgentsFiles(state *uiState, items []projectItem) {
+	for i := range items {
+		project := items[i].project
+		if project.Unknown() || (items[i].isCurrent && len(project.Sessions) == 0) {
+			continue
+		}
+		if state.agentsFiles == nil {
+			state.agentsFiles = map[string]bool{}
+		}
+		has, ok := state.agentsFiles[project.Path]
+		if !ok {
+			has = agentsFileExists(project.Path)
+			state.agentsFiles[project.Path] = has
+		}
+		items[i].hasAgentsFile = has
+	}
+}
+
 func projectSearchLabel(project codexhistory.Project) string {
 	label := strings.TrimSpace(project.Path)
 	if label == "" {
@@ -1201,6 +2617,9 @@ func projectSearchLabel(project codexhistory.Project) string {
 }
 
 func projectLess(left, right codexhistory.Project) bool {
+	if left.Unknown() != right.Unknown() {
+		return right.Unknown()
+	}
 	leftTime := projectModifiedAt(left)
 	rightTime := projectModifiedAt(right)
 	switch {
@@ -1246,6 +2665,9 @@ func formatProjectRow(item projectItem, width int) string {
 	if item.isCurrent {
 		prefix = "[current] "
 	}
+	if item.hasAgentsFile {
+		prefix += agentsFileMarker
+	}
 
 	suffix := ""
 	if count := len(item.project.Sessions); count > 0 {
@@ -1396,7 +2818,7 @@ func headByDisplayWidth(s string, width int) string {
 	var buf strings.Builder
 	curWidth := 0
 	for _, ch := range s {
-		chWidth := runewidth.RuneWidth(ch)
+		chWidth := runeCells(ch)
 		if chWidth == 0 {
 			buf.WriteRune(ch)
 			continue
@@ -1419,7 +2841,7 @@ func tailByDisplayWidth(s string, width int) string {
 	curWidth := 0
 	for start > 0 {
 		ch := runes[start-1]
-		chWidth := runewidth.RuneWidth(ch)
+		chWidth := runeCells(ch)
 		if chWidth == 0 {
 			start--
 			continue
@@ -1496,6 +2918,19 @@ func sessionSelection(item sessionItem) (*codexhistory.Session, *codexhistory.Su
 	}
 }
 
+// sessionItemResumable reports whether Enter can resume the item; subagent
+// rows resume their parent session.
+func sessionItemResumable(item sessionItem) bool {
+	switch item.kind {
+	case sessionItemMain:
+		return item.session.Resumable()
+	case sessionItemSubagent:
+		return item.parentSession.Resumable()
+	default:
+		return true
+	}
+}
+
 func sessionItemParentID(item sessionItem) string {
 	switch item.kind {
 	case sessionItemSubagent:
@@ -1563,20 +2998,38 @@ func buildPreviewLines(
 	previewText string,
 	opts Options,
 ) []string {
+	if codexhistory.IsSessionsDirNotFound(state.loadError) {
+		return []string{noHistoryText(state.loadError), "Start a new session to create it."}
+	}
 	if state.loadError != nil {
 		return []string{fmt.Sprintf("Load error: %v", state.loadError)}
 	}
 	if shouldShowLoadingRows(state) {
 		return loadingPreviewLines(state)
 	}
+	if opts.ProjectOnly && len(state.projects) == 0 {
+		lines := []string{projectOnlyEmptyText}
+		if cwd := newSessionCwd(project, opts.DefaultCwd); cwd != "" && selectedIsNew {
+			lines = append(lines, "", "Start a new Codex session in:", "  "+cwd)
+		}
+		return lines
+	}
 	if project.Path == "" && len(state.projects) == 0 {
-		return []string{"No Codex sessions found.", "Run Codex to create a session first."}
+		return []string{noHistoryText(nil), "Run Codex to create a session first."}
+	}
+	if state.compactPreviewHeader && session != nil && !selectedIsNew {
+		return compactPreviewLines(session, subagent, state, previewText, opts)
 	}
 
 	lines := []string{}
 	if project.Path != "" {
 		lines = append(lines, "Project:")
 		lines = append(lines, "  "+project.Path)
+		if opts.ProjectCodexPath != nil {
+			if path := opts.ProjectCodexPath(project.Path); path != "" {
+				lines = append(lines, "  Custom Codex: "+path)
+			}
+		}
 	}
 	if selectedIsNew {
 		cwd := newSessionCwd(project, opts.DefaultCwd)
@@ -1600,7 +3053,7 @@ func buildPreviewLines(
 		lines = append(lines, "")
 		lines = append(lines, "Subagent:")
 		if subagent.AgentID != "" {
-			lines = append(lines, "  ID: "+subagent.AgentID)
+			lines = append(lines, "  Type: "+subagent.AgentID)
 		}
 		if session.SessionID != "" {
 			lines = append(lines, "  Parent: "+session.SessionID)
@@ -1612,10 +3065,13 @@ func buildPreviewLines(
 			lines = append(lines, fmt.Sprintf("  Messages: %d", subagent.MessageCount))
 		}
 		if !subagent.CreatedAt.IsZero() {
-			lines = append(lines, "  Created: "+subagent.CreatedAt.Format(time.RFC3339))
+			lines = append(lines, "  Created: "+displayTime(subagent.CreatedAt, opts.Location).Format(time.RFC3339))
 		}
 		if !subagent.ModifiedAt.IsZero() {
-			lines = append(lines, "  Modified: "+subagent.ModifiedAt.Format(time.RFC3339))
+			lines = append(lines, "  Modified: "+displayTime(subagent.ModifiedAt, opts.Location).Format(time.RFC3339))
+		}
+		if state.showFilePaths && subagent.FilePath != "" {
+			lines = append(lines, previewFileLinePrefix+subagent.FilePath)
 		}
 		if previewText != "" {
 			lines = append(lines, "")
@@ -1640,11 +3096,17 @@ func buildPreviewLines(
 	if len(session.Subagents) > 0 {
 		lines = append(lines, fmt.Sprintf("  Subagents: %d", len(session.Subagents)))
 	}
+	if n := opts.ResumeCounts[session.SessionID]; n > 0 {
+		lines = append(lines, "  "+resumedTimesText(n))
+	}
 	if !session.CreatedAt.IsZero() {
-		lines = append(lines, "  Created: "+session.CreatedAt.Format(time.RFC3339))
+		lines = append(lines, "  Created: "+displayTime(session.CreatedAt, opts.Location).Format(time.RFC3339))
 	}
 	if !session.ModifiedAt.IsZero() {
-		lines = append(lines, "  Modified: "+session.ModifiedAt.Format(time.RFC3339))
+		lines = append(lines, "  Modified: "+displayTime(session.ModifiedAt, opts.Location).Format(time.RFC3339))
+	}
+	if state.showFilePaths && session.FilePath != "" {
+		lines = append(lines, previewFileLinePrefix+session.FilePath)
 	}
 
 	if previewText != "" {
@@ -1655,8 +3117,106 @@ func buildPreviewLines(
 	return lines
 }
 
+// compactPreviewLines is buildPreviewLines with the detail block folded
+// into previewHeaderLine, leaving the rest of the pane to the transcript.
+func compactPreviewLines(
+	session *codexhistory.Session,
+	subagent *codexhistory.SubagentSession,
+	state *uiState,
+	previewText string,
+	opts Options,
+) []string {
+	var lines []string
+	filePath := session.FilePath
+	if subagent != nil {
+		lines = append(lines, previewHeaderLine("subagent "+subagent.AgentID, subagent.MessageCount, 0, subagent.CreatedAt, subagent.ModifiedAt, opts.Location))
+		filePath = subagent.FilePath
+	} else {
+		lines = append(lines, previewHeaderLine("", session.MessageCount, len(session.Subagents), session.CreatedAt, session.ModifiedAt, opts.Location))
+	}
+	if state.showFilePaths && filePath != "" {
+		lines = append(lines, previewFileLinePrefix+filePath)
+	}
+	if previewText != "" {
+		lines = append(lines, "", previewText)
+	}
+	return lines
+}
+
+// previewHeaderLine summarizes a session as
+// "12 msgs · 2 subagents · created 2026-03-01 09:00 · modified …", leaving
+// out the parts that are unknown or zero.
+func previewHeaderLine(label string, messages, subagents int, created, modified time.Time, loc *time.Location) string {
+	var parts []string
+	if label = strings.TrimSpace(label); label != "" {
+		parts = append(parts, label)
+	}
+	parts = append(parts, countLabel(messages, "msg"))
+	if subagents > 0 {
+		parts = append(parts, countLabel(subagents, "subagent"))
+	}
+	if !created.IsZero() {
+		parts = append(parts, "created "+displayTime(created, loc).Format("2006-01-02 15:04"))
+	}
+	if !modified.IsZero() {
+		parts = append(parts, "modified "+displayTime(modified, loc).Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func resumedTimesText(n int) string {
+	if n == 1 {
+		return "Resumed 1 time"
+	}
+	return fmt.Sprintf("Resumed %d times", n)
+}
+
+// previewFileLinePrefix starts the rollout file line buildPreviewLines adds
+// when file paths are toggled on; previewFilePathLines finds it again to dim.
+const previewFileLinePrefix = "  File: "
+
+// previewFilePathLines returns the wrapped preview lines that hold the
+// rollout file path, including its wrapped continuation, so draw can dim
+// them. Only the metadata block above "Preview:" is searched.
+func previewFilePathLines(lines []string) []int {
+	var out []int
+	for i, line := range lines {
+		if line == "Preview:" {
+			break
+		}
+		if !strings.HasPrefix(line, previewFileLinePrefix) {
+			continue
+		}
+		for j := i; j < len(lines) && lines[j] != ""; j++ {
+			out = append(out, j)
+		}
+		break
+	}
+	return out
+}
+
+// noHistoryText explains why there is no history to show. A missing sessions
+// directory means Codex has never run against this data dir, which is worth
+// telling apart from a sessions directory that simply has nothing in it yet.
+func noHistoryText(err error) string {
+	if codexhistory.IsSessionsDirNotFound(err) {
+		return "Codex has never run here (no sessions directory)."
+	}
+	if err != nil {
+		return "No history found."
+	}
+	return "No sessions yet."
+}
+
 func renderProjectRows(items []projectItem, focused bool, state listState, viewW, viewH int) []row {
-	rows := make([]row, 0, min(len(items), viewH))
+	rows := make([]row, 0, min(len(items), max(0, viewH)))
 	start := clamp(state.scroll, 0, max(0, len(items)))
 	end := min(len(items), start+max(0, viewH))
 	for i := start; i < end; i++ {
@@ -1668,19 +3228,61 @@ func renderProjectRows(items []projectItem, focused bool, state listState, viewW
 	return applySelection(rows, focused, listState{selected: state.selected - start})
 }
 
-func renderSessionRows(items []sessionItem, focused bool, state listState, viewH int) []row {
-	rows := make([]row, 0, min(len(items), viewH))
+func renderSessionRows(items []sessionItem, focused bool, state listState, viewH int, showLastReply bool) []row {
+	if !showLastReply {
+		rows := make([]row, 0, min(len(items), viewH))
+		start := clamp(state.scroll, 0, max(0, len(items)))
+		end := min(len(items), start+max(0, viewH))
+		for i := start; i < end; i++ {
+			item := items[i]
+			rowItem := row{label: item.label}
+			if item.kind == sessionItemSubagent || !sessionItemResumable(item) {
+				rowItem.dim = true
+			}
+			rows = append(rows, rowItem)
+		}
+		return applySelection(rows, focused, listState{selected: state.selected - start})
+	}
+
+	// Two rows per item: the label, then a dim reply line (blank for items
+	// without one) so every item keeps the same height for scrolling.
+	capacity := sessionListCapacity(viewH, true)
+	rows := make([]row, 0, min(len(items), capacity)*2)
 	start := clamp(state.scroll, 0, max(0, len(items)))
-	end := min(len(items), start+max(0, viewH))
+	end := min(len(items), start+capacity)
 	for i := start; i < end; i++ {
 		item := items[i]
-		rowItem := row{label: item.label}
-		if item.kind == sessionItemSubagent {
-			rowItem.dim = true
+		first := row{label: item.label, dim: item.kind == sessionItemSubagent || !sessionItemResumable(item)}
+		second := row{label: lastReplyLabel(item), dim: true}
+		if i == state.selected {
+			first.selected, first.focused, first.dim = true, focused, false
+			second.selected, second.focused = true, focused
 		}
-		rows = append(rows, rowItem)
+		rows = append(rows, first, second)
 	}
-	return applySelection(rows, focused, listState{selected: state.selected - start})
+	if len(rows) > viewH {
+		rows = rows[:max(0, viewH)]
+	}
+	return rows
+}
+
+// sessionListCapacity reports how many session items fit in viewH rows.
+func sessionListCapacity(viewH int, showLastReply bool) int {
+	if !showLastReply || viewH <= 1 {
+		return viewH
+	}
+	return viewH / 2
+}
+
+func lastReplyLabel(item sessionItem) string {
+	if item.kind != sessionItemMain {
+		return ""
+	}
+	snippet := listLabelText(item.session.LastAssistantSnippet)
+	if snippet == "" {
+		return ""
+	}
+	return "      ↳ " + snippet
 }
 
 func loadingRows(state *uiState, viewH int) []row {
@@ -1694,7 +3296,7 @@ func loadingRows(state *uiState, viewH int) []row {
 	}}
 	if viewH > 1 {
 		rows = append(rows, row{
-			label: loadingSecondaryText(),
+			label: loadingSecondaryText(state),
 			dim:   true,
 		})
 	}
@@ -1715,8 +3317,20 @@ func loadingPrimaryText(state *uiState) string {
 	return fmt.Sprintf("%s Loading Codex session history...", loadingFrame(state))
 }
 
-func loadingSecondaryText() string {
-	return "Large histories can take a while."
+func loadingSecondaryText(state *uiState) string {
+	processed, total := state.loadProgress.snapshot()
+	if total <= 0 {
+		return "Large histories can take a while."
+	}
+	return fmt.Sprintf("Read %d/%d session files %s", processed, total, progressBar(processed, total, 20))
+}
+
+func progressBar(processed, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = clamp(processed*width/total, 0, width)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
 }
 
 func loadingElapsedText(state *uiState) string {
@@ -1726,7 +3340,7 @@ func loadingElapsedText(state *uiState) string {
 func loadingPreviewLines(state *uiState) []string {
 	return []string{
 		loadingPrimaryText(state),
-		loadingSecondaryText(),
+		loadingSecondaryText(state),
 		loadingElapsedText(state),
 	}
 }
@@ -1808,7 +3422,7 @@ func previewFilePath(session *codexhistory.Session, subagent *codexhistory.Subag
 
 func previewTextForItem(state *uiState, session *codexhistory.Session, subagent *codexhistory.SubagentSession) string {
 	cacheKey := previewCacheKey(session, subagent)
-	if cacheKey == "" {
+	if cacheKey == "" || state.metadataOnly {
 		return ""
 	}
 	if errEntry, ok := state.previewError[cacheKey]; ok && errEntry.message != "" {
@@ -1826,6 +3440,9 @@ func previewTextForItem(state *uiState, session *codexhistory.Session, subagent
 	if _, ok := state.previewLoading[cacheKey]; ok {
 		return "Loading preview..."
 	}
+	if meta, ok := state.previewDeferred[cacheKey]; ok {
+		return largeSessionPromptText(meta.size, previewMessageCount(session, subagent))
+	}
 	return ""
 }
 
@@ -1854,7 +3471,7 @@ func wrappedPreviewLinesForSelection(
 	}
 	previewText := previewTextForItem(state, session, subagent)
 	lines := buildPreviewLines(project, session, subagent, selectedIsNew, state, previewText, opts)
-	wrapped := buildWrappedLines(lines, width)
+	wrapped := buildWrappedLines(lines, width, opts.tabWidth(), opts.PreviewMaxWidth)
 	entry := previewLinesCacheEntry{key: key, lines: wrapped, cost: previewLinesCost(wrapped)}
 	state.previewLines = entry
 	rememberPreviewLines(state, entry)
@@ -1930,6 +3547,8 @@ func previewLinesCacheKey(
 	}
 	parts := []string{
 		fmt.Sprintf("w:%d", width),
+		fmt.Sprintf("tab:%d", opts.tabWidth()),
+		fmt.Sprintf("maxw:%d", opts.PreviewMaxWidth),
 		fmt.Sprintf("loading:%t:%d", shouldShowLoadingRows(state), state.loadingStartedAt.UnixNano()),
 		"loaderr:" + errorString(state.loadError),
 		"project:" + strings.TrimSpace(project.Key),
@@ -1937,9 +3556,13 @@ func previewLinesCacheKey(
 		fmt.Sprintf("new:%t", selectedIsNew),
 		"default:" + strings.TrimSpace(opts.DefaultCwd),
 		"preview:" + previewContentRevision(state, session, subagent),
+		fmt.Sprintf("files:%t", state.showFilePaths),
+		fmt.Sprintf("compact:%t", state.compactPreviewHeader),
+		fmt.Sprintf("metadataOnly:%t", state.metadataOnly),
 	}
 	if shouldShowLoadingRows(state) {
-		parts = append(parts, fmt.Sprintf("loadingTick:%d", loadingElapsed(state)/(125*time.Millisecond)))
+		processed, total := state.loadProgress.snapshot()
+		parts = append(parts, fmt.Sprintf("loadingTick:%d:%d/%d", loadingElapsed(state)/(125*time.Millisecond), processed, total))
 	}
 	if session != nil {
 		parts = append(parts,
@@ -1950,6 +3573,7 @@ func previewLinesCacheKey(
 			fmt.Sprintf("subagents:%d", len(session.Subagents)),
 			fmt.Sprintf("created:%d", session.CreatedAt.UnixNano()),
 			fmt.Sprintf("modified:%d", session.ModifiedAt.UnixNano()),
+			"file:"+session.FilePath,
 		)
 	}
 	if subagent != nil {
@@ -1960,6 +3584,7 @@ func previewLinesCacheKey(
 			fmt.Sprintf("submessages:%d", subagent.MessageCount),
 			fmt.Sprintf("subcreated:%d", subagent.CreatedAt.UnixNano()),
 			fmt.Sprintf("submodified:%d", subagent.ModifiedAt.UnixNano()),
+			"subfile:"+subagent.FilePath,
 		)
 	}
 	return strings.Join(parts, "\x00")
@@ -1987,6 +3612,9 @@ func previewContentRevision(state *uiState, session *codexhistory.Session, subag
 	if loadingMeta, ok := state.previewLoading[cacheKey]; ok {
 		return "loading:" + previewMetaRevision(loadingMeta)
 	}
+	if deferredMeta, ok := state.previewDeferred[cacheKey]; ok {
+		return "deferred:" + previewMetaRevision(deferredMeta)
+	}
 	return "empty"
 }
 
@@ -2102,6 +3730,111 @@ func filterSessions(items []sessionItem, needle string) []sessionItem {
 	return out
 }
 
+// bodyMatchesLimit bounds the body search cache; it is dropped whole once
+// it grows past this many entries.
+const bodyMatchesLimit = 10000
+
+// bodySearchResult is what one background body search found: the sessions
+// it read for query, and which of them matched.
+type bodySearchResult struct {
+	query    string
+	searched []codexhistory.Session
+	matches  []codexhistory.SearchMatch
+}
+
+// bodyMatchKey identifies the body search result of query in session. The
+// modification time is part of it so a session that grew is searched again.
+func bodyMatchKey(query string, session codexhistory.Session) string {
+	return strings.ToLower(strings.TrimSpace(query)) + "\x00" + session.FilePath + "\x00" +
+		strconv.FormatInt(session.ModifiedAt.UnixNano(), 10)
+}
+
+// filterSessionItems applies the session filter to items. With body search
+// on it also keeps the main sessions whose conversation contains the
+// filter; sessions that have not been searched yet show up once their
+// result arrives.
+func filterSessionItems(state *uiState, items []sessionItem) []sessionItem {
+	needle := state.sessionFilter
+	if !state.bodySearch || strings.TrimSpace(needle) == "" {
+		return filterSessions(items, needle)
+	}
+	n := strings.ToLower(needle)
+	out := make([]sessionItem, 0, len(items))
+	for _, it := range items {
+		switch {
+		case it.alwaysVisible, strings.Contains(strings.ToLower(it.label), n):
+			out = append(out, it)
+		case it.kind == sessionItemMain && state.bodyMatches[bodyMatchKey(needle, it.session)]:
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func toggleBodySearch(screen tcell.Screen, state *uiState) {
+	state.bodySearch = !state.bodySearch
+	state.sessionState = listState{}
+	state.previewState.scroll = 0
+	if state.bodySearch {
+		showFlash(screen, state, "Search: titles and message text")
+	} else {
+		showFlash(screen, state, "Search: titles only")
+	}
+}
+
+// ensureBodySearch searches, in the background, the main sessions in items
+// that have no cached result for the session filter yet. One search runs at
+// a time; later draws pick up whatever is still missing, including for a
+// filter typed while it ran.
+func ensureBodySearch(screen tcell.Screen, state *uiState, items []sessionItem) {
+	query := strings.TrimSpace(state.sessionFilter)
+	if !state.bodySearch || query == "" || state.bodySearchRunning {
+		return
+	}
+	var pending []codexhistory.Session
+	for _, it := range items {
+		if it.kind != sessionItemMain || it.session.FilePath == "" {
+			continue
+		}
+		if _, ok := state.bodyMatches[bodyMatchKey(query, it.session)]; !ok {
+			pending = append(pending, it.session)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if state.bodySearchCh == nil {
+		state.bodySearchCh = make(chan bodySearchResult, 1)
+	}
+	state.bodySearchRunning = true
+	ch := state.bodySearchCh
+	done := state.done
+	go func() {
+		// Unreadable files count as searched without a match; discovery
+		// already reports them.
+		matches, _ := codexhistory.SearchSessions(context.Background(), []codexhistory.Project{{Sessions: pending}}, query)
+		select {
+		case ch <- bodySearchResult{query: query, searched: pending, matches: matches}:
+		case <-done:
+			return
+		}
+		postUIEventWithRetry(context.Background(), done, screen, &uiEvent{when: time.Now(), kind: "search"})
+	}()
+}
+
+func applyBodySearchResult(state *uiState, res bodySearchResult) {
+	state.bodySearchRunning = false
+	if state.bodyMatches == nil || len(state.bodyMatches) > bodyMatchesLimit {
+		state.bodyMatches = make(map[string]bool)
+	}
+	for _, session := range res.searched {
+		state.bodyMatches[bodyMatchKey(res.query, session)] = false
+	}
+	for _, match := range res.matches {
+		state.bodyMatches[bodyMatchKey(res.query, match.Session)] = true
+	}
+}
+
 func applyListNavigation(state *listState, nItems int, viewH int, ev *tcell.EventKey) {
 	if nItems <= 0 {
 		state.selected = 0
@@ -2181,15 +3914,21 @@ func (s *listState) ensureVisible(viewH int, nItems int) {
 	s.scroll = clamp(s.scroll, 0, maxScroll)
 }
 
-func drawBox(screen tcell.Screen, r rect, title string, focused bool, filter string) {
+// drawBox draws a pane frame. warn switches the frame to the theme's warning
+// colors (yellow borders, red focused title by default) while AAA mode is on,
+// so auto-approved launches are hard to miss.
+func drawBox(screen tcell.Screen, theme *Theme, r rect, title string, focused bool, filter string, warn bool) {
 	if r.w <= 0 || r.h <= 0 {
 		return
 	}
-	borderStyle := tcell.StyleDefault
-	if focused {
-		borderStyle = borderStyle.Bold(true)
-	} else {
-		borderStyle = borderStyle.Dim(true)
+	borderStyle, titleStyle := theme.Border, theme.Title
+	switch {
+	case warn && focused:
+		borderStyle, titleStyle = theme.WarnFocusBorder, theme.WarnFocusTitle
+	case warn:
+		borderStyle, titleStyle = theme.WarnBorder, theme.WarnTitle
+	case focused:
+		borderStyle, titleStyle = theme.FocusBorder, theme.FocusTitle
 	}
 	h := tcell.RuneHLine
 	v := tcell.RuneVLine
@@ -2210,9 +3949,7 @@ func drawBox(screen tcell.Screen, r rect, title string, focused bool, filter str
 	screen.SetContent(r.x, r.y+r.h-1, ll, nil, borderStyle)
 	screen.SetContent(r.x+r.w-1, r.y+r.h-1, lr, nil, borderStyle)
 
-	titleStyle := tcell.StyleDefault.Reverse(true)
 	if focused {
-		titleStyle = titleStyle.Bold(true)
 		title = "> " + title + " <"
 	} else {
 		title = " " + title + " "
@@ -2232,7 +3969,19 @@ func drawBox(screen tcell.Screen, r rect, title string, focused bool, filter str
 	}
 }
 
-func drawList(screen tcell.Screen, r rect, rows []row) {
+// monochromeScreen drops every color and attribute drawn through it, so the
+// whole UI renders as plain text when Options.Monochrome is set.
+type monochromeScreen struct {
+	tcell.Screen
+}
+
+func (s monochromeScreen) SetContent(x, y int, primary rune, combining []rune, _ tcell.Style) {
+	s.Screen.SetContent(x, y, primary, combining, tcell.StyleDefault)
+}
+
+// drawList draws list rows inside r. In monochrome mode styles are lost, so
+// the selected row is marked with "> " and the others are indented to match.
+func drawList(screen tcell.Screen, theme *Theme, r rect, rows []row, monochrome bool) {
 	if r.h < 3 || r.w < 4 {
 		return
 	}
@@ -2241,29 +3990,35 @@ func drawList(screen tcell.Screen, r rect, rows []row) {
 	for i := 0; i < innerH; i++ {
 		y := r.y + 1 + i
 		if i >= len(rows) {
-			writeText(screen, r.x+1, y, padRight("", innerW), tcell.StyleDefault)
+			writeText(screen, r.x+1, y, padRight("", innerW), theme.Text)
 			continue
 		}
 		row := rows[i]
-		style := tcell.StyleDefault
+		style := theme.Text
+		switch {
+		case row.selected && row.focused:
+			style = theme.Selected
+		case row.selected:
+			style = theme.SelectedBlur
+		case row.dim:
+			style = theme.Dim
+		}
 		if row.bold {
 			style = style.Bold(true)
 		}
-		if row.selected {
-			style = style.Reverse(true)
-			if row.focused {
-				style = style.Bold(true)
+		label := row.label
+		if monochrome {
+			if row.selected {
+				label = "> " + label
 			} else {
-				style = style.Dim(true)
+				label = "  " + label
 			}
-		} else if row.dim {
-			style = style.Dim(true)
 		}
-		writeText(screen, r.x+1, y, padRight(truncate(row.label, innerW), innerW), style)
+		writeText(screen, r.x+1, y, padRight(truncate(label, innerW), innerW), style)
 	}
 }
 
-func drawPreview(screen tcell.Screen, r rect, lines []string, scroll int, lineAttrs map[int]tcell.Style) {
+func drawPreview(screen tcell.Screen, theme *Theme, r rect, lines []string, scroll int, lineAttrs map[int]tcell.Style) {
 	if r.h < 3 || r.w < 4 {
 		return
 	}
@@ -2274,11 +4029,11 @@ func drawPreview(screen tcell.Screen, r rect, lines []string, scroll int, lineAt
 		y := r.y + 1 + i
 		idx := scroll + i
 		if idx >= len(lines) {
-			writeText(screen, r.x+1, y, padRight("", innerW), tcell.StyleDefault)
+			writeText(screen, r.x+1, y, padRight("", innerW), theme.Text)
 			continue
 		}
 		line := padRight(truncate(lines[idx], innerW), innerW)
-		style := tcell.StyleDefault
+		style := theme.Text
 		if attr, ok := lineAttrs[idx]; ok {
 			style = attr
 		}
@@ -2286,6 +4041,48 @@ func drawPreview(screen tcell.Screen, r rect, lines []string, scroll int, lineAt
 	}
 }
 
+// drawUnreadableFiles draws the rollout files discovery could not read, and
+// the session IDs it found under several projects, in a box centred over the
+// panes, which are width by height cells.
+func drawUnreadableFiles(screen tcell.Screen, theme *Theme, files []string, duplicates []string, width, height int) {
+	r := rect{w: min(width-4, 100), h: height - 2}
+	if r.w < 4 || r.h < 3 {
+		return
+	}
+	var lines []string
+	for _, file := range files {
+		lines = append(lines, wrapText(file, r.w-2)...)
+	}
+	title := "Unreadable files (any key: close)"
+	if len(duplicates) > 0 {
+		if len(files) > 0 {
+			title = "Unreadable files and duplicate session IDs (any key: close)"
+			lines = append(lines, "", "Duplicate session IDs:")
+		} else {
+			title = "Duplicate session IDs (any key: close)"
+		}
+		for _, dup := range duplicates {
+			lines = append(lines, wrapText(dup, r.w-2)...)
+		}
+	}
+	if len(lines) > r.h-2 {
+		hidden := len(lines) - (r.h - 3)
+		lines = append(lines[:r.h-3], fmt.Sprintf("... %d more lines", hidden))
+	}
+	r.h = len(lines) + 2
+	r.x = (width - r.w) / 2
+	r.y = max(0, (height-r.h)/2)
+	drawBox(screen, theme, r, title, true, "", false)
+	drawPreview(screen, theme, r, lines, 0, nil)
+}
+
+func pluralFiles(n int) string {
+	if n == 1 {
+		return "file"
+	}
+	return "files"
+}
+
 type statusSegment struct {
 	text  string
 	style tcell.Style
@@ -2302,6 +4099,55 @@ type statusLine struct {
 	rightBold bool
 }
 
+// minimalStatusSegments is the status bar of Options.MinimalStatus: the
+// focused pane, the keys to open, switch, search and quit, and the proxy
+// and AAA modes only while they are on.
+func minimalStatusSegments(state *uiState, opts Options, openLabel string, style, aaaStyle tcell.Style) []statusSegment {
+	focus := "Projects"
+	switch state.focus {
+	case "sessions":
+		focus = "Sessions"
+	case "preview":
+		focus = "Preview"
+	}
+	text := "[" + focus + "]  " + openLabel + "  Tab: switch  /: search  q: quit"
+	if counter := previewMatchCounter(state); counter != "" {
+		text += "  " + counter + "  n/N: next/prev"
+	}
+	if state.sessionLimit > 0 {
+		text += "  +: more"
+	}
+	if state.proxyEnabled && !opts.ReadOnly {
+		text += "  Proxy on"
+	}
+	segments := []statusSegment{{text: text, style: style}}
+	if state.aaaEnabled && !opts.ReadOnly {
+		segments = append(segments, statusSegment{text: "  [!] AAA on", style: aaaStyle})
+	}
+	return segments
+}
+
+// minPaneHeightForFullStatus is the number of rows the panes must keep for
+// the full, possibly multi-line, status bar to be drawn. Shorter terminals
+// get buildCompactStatusLine instead, so hints are summarized rather than
+// clipped away.
+const minPaneHeightForFullStatus = 8
+
+// buildCompactStatusLine renders the status bar as exactly one line: the
+// compact hint, the AAA warning when it is on, and the right-hand label when
+// there is room for it.
+func buildCompactStatusLine(width int, text string, style tcell.Style, aaaEnabled bool, aaaStyle tcell.Style, right string, rightBold bool) []statusLine {
+	segments := []statusSegment{{text: text + "  ", style: style}}
+	if aaaEnabled {
+		segments = append(segments, statusSegment{text: "[!] AAA on", style: aaaStyle})
+	}
+	lines := buildStatusLines(width, segments, right, rightBold)
+	if len(lines) > 1 {
+		lines = buildStatusLines(width, segments, "", false)
+	}
+	return lines[:1]
+}
+
 func buildStatusLines(width int, left []statusSegment, right string, rightBold bool) []statusLine {
 	tokens := buildStatusTokens(left)
 	lines := packStatusLines(width, tokens)
@@ -2430,7 +4276,7 @@ func lineWidthGroups(groups []statusToken) int {
 	return width
 }
 
-func drawStatusLines(screen tcell.Screen, lines []statusLine) {
+func drawStatusLines(screen tcell.Screen, theme *Theme, lines []statusLine) {
 	w, h := screen.Size()
 	if h <= 0 {
 		return
@@ -2442,7 +4288,7 @@ func drawStatusLines(screen tcell.Screen, lines []statusLine) {
 		lines = lines[len(lines)-h:]
 	}
 
-	baseStyle := tcell.StyleDefault.Reverse(true)
+	baseStyle := theme.Status
 	startY := h - len(lines)
 	for i, line := range lines {
 		y := startY + i
@@ -2492,7 +4338,7 @@ func drawStatusLines(screen tcell.Screen, lines []statusLine) {
 func writeText(screen tcell.Screen, x, y int, text string, style tcell.Style) {
 	offset := 0
 	for _, ch := range text {
-		width := runewidth.RuneWidth(ch)
+		width := runeCells(ch)
 		if width == 0 {
 			continue
 		}
@@ -2501,12 +4347,18 @@ func writeText(screen tcell.Screen, x, y int, text string, style tcell.Style) {
 	}
 }
 
-func buildWrappedLines(lines []string, width int) []string {
+// buildWrappedLines wraps lines to width, or to maxWidth when that is set and
+// narrower, leaving the rest of a wide pane blank.
+func buildWrappedLines(lines []string, width int, tabWidth int, maxWidth int) []string {
 	if width <= 0 {
 		return nil
 	}
+	if maxWidth > 0 {
+		width = min(width, maxWidth)
+	}
 	out := make([]string, 0, len(lines))
 	for _, ln := range lines {
+		ln = expandTabs(codexhistory.SanitizeTerminalText(ln), tabWidth)
 		for _, w := range wrapText(ln, width) {
 			out = append(out, w)
 		}
@@ -2514,6 +4366,48 @@ func buildWrappedLines(lines []string, width int) []string {
 	return out
 }
 
+func (o Options) tabWidth() int {
+	if o.TabWidth > 0 {
+		return o.TabWidth
+	}
+	return defaultTabWidth
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop. runewidth
+// counts a tab as one column while terminals jump to the next stop, so tabs
+// must be gone before any width math. Stops restart after every newline.
+func expandTabs(s string, tabWidth int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	if tabWidth <= 0 {
+		tabWidth = defaultTabWidth
+	}
+	var b strings.Builder
+	col := 0
+	for _, ch := range s {
+		switch ch {
+		case '\t':
+			pad := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", pad))
+			col += pad
+		case '\n':
+			b.WriteRune(ch)
+			col = 0
+		default:
+			b.WriteRune(ch)
+			col += runeCells(ch)
+		}
+	}
+	return b.String()
+}
+
+// listLabelText prepares free text for a single-line list row: escape
+// sequences are dropped and tabs become plain spaces.
+func listLabelText(s string) string {
+	return strings.ReplaceAll(codexhistory.SanitizeTerminalText(s), "\t", " ")
+}
+
 func wrapText(s string, width int) []string {
 	if width <= 0 {
 		return nil
@@ -2530,7 +4424,7 @@ func wrapText(s string, width int) []string {
 		var buf strings.Builder
 		curWidth := 0
 		for _, ch := range ln {
-			chWidth := runewidth.RuneWidth(ch)
+			chWidth := runeCells(ch)
 			if chWidth == 0 {
 				buf.WriteRune(ch)
 				continue
@@ -2565,7 +4459,7 @@ func truncate(s string, width int) string {
 	var buf strings.Builder
 	curWidth := 0
 	for _, ch := range s {
-		chWidth := runewidth.RuneWidth(ch)
+		chWidth := runeCells(ch)
 		if chWidth == 0 {
 			buf.WriteRune(ch)
 			continue
@@ -2586,8 +4480,30 @@ func padRight(s string, width int) string {
 	return s + strings.Repeat(" ", width-displayWidth(s))
 }
 
+// displayWidth measures s the way writeText draws it, summing runeCells over
+// its runes, with each invalid byte one U+FFFD. truncate and wrapText count
+// the same way. runewidth.StringWidth measures grapheme clusters instead, so
+// it counts an emoji ZWJ sequence as one emoji while writeText draws each
+// part.
 func displayWidth(s string) int {
-	return runewidth.StringWidth(s)
+	width := 0
+	for _, ch := range s {
+		width += runeCells(ch)
+	}
+	return width
+}
+
+// runeCells is the number of terminal cells writeText gives ch. Combining
+// marks, ZWJ and variation selectors take none and are not drawn;
+// runewidth gives variation selectors a cell of their own.
+func runeCells(ch rune) int {
+	switch {
+	case ch == '\u200d',
+		unicode.Is(unicode.Variation_Selector, ch),
+		unicode.In(ch, unicode.Mn, unicode.Me):
+		return 0
+	}
+	return runewidth.RuneWidth(ch)
 }
 
 func versionLabel(v string) string {
@@ -3,9 +3,12 @@ package tui
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -150,6 +153,8 @@ func newTestState(projects []codexhistory.Project) *uiState {
 		previewCache:      map[string]previewCacheEntry{},
 		previewError:      map[string]previewErrorEntry{},
 		previewLoading:    map[string]previewCacheMeta{},
+		previewDeferred:   map[string]previewCacheMeta{},
+		previewRequested:  map[string]bool{},
 		previewLinesCache: map[string]previewLinesCacheEntry{},
 	}
 }
@@ -195,7 +200,7 @@ func TestHandleKeyEnterSelectsSession(t *testing.T) {
 		Key:  "one",
 		Path: "/tmp/one",
 		Sessions: []codexhistory.Session{
-			{SessionID: "sess-1", Summary: "hello", ModifiedAt: now},
+			{SessionID: "11111111-1111-1111-1111-111111111111", Summary: "hello", ModifiedAt: now},
 		},
 	}
 	state := newTestState([]codexhistory.Project{project})
@@ -207,21 +212,49 @@ func TestHandleKeyEnterSelectsSession(t *testing.T) {
 	if err != nil {
 		t.Fatalf("handleKey error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-1" {
-		t.Fatalf("expected session sess-1, got %#v", selection)
+	if selection == nil || selection.Session.SessionID != "11111111-1111-1111-1111-111111111111" {
+		t.Fatalf("expected session 1111…, got %#v", selection)
 	}
 	if selection.UseProxy {
 		t.Fatalf("expected proxy to be disabled by default")
 	}
 }
 
+func TestHandleKeyEnterRefusesUnresumableSession(t *testing.T) {
+	screen := newTestScreen(t, 120, 40)
+	project := codexhistory.Project{
+		Key:      "one",
+		Path:     "/tmp/one",
+		Sessions: []codexhistory.Session{{SessionID: "rollout-notes", Summary: "hello"}},
+	}
+	state := newTestState([]codexhistory.Project{project})
+	state.focus = "sessions"
+	state.lastListFocus = "sessions"
+	state.sessionState.selected = 1
+
+	selection, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if err != nil {
+		t.Fatalf("handleKey error: %v", err)
+	}
+	if selection != nil {
+		t.Fatalf("expected no selection for an unresumable session, got %#v", selection)
+	}
+	if !strings.Contains(state.flashMessage, "no valid session ID") {
+		t.Fatalf("flash = %q, want the reason", state.flashMessage)
+	}
+	items := buildSessionItems(project, nil, "")
+	if !strings.Contains(items[1].label, "[no resume]") {
+		t.Fatalf("label = %q, want a no-resume marker", items[1].label)
+	}
+}
+
 func TestHandleKeyCtrlJSelectsSession(t *testing.T) {
 	screen := newTestScreen(t, 120, 40)
 	project := codexhistory.Project{
 		Key:  "one",
 		Path: "/tmp/one",
 		Sessions: []codexhistory.Session{
-			{SessionID: "sess-2", Summary: "hello"},
+			{SessionID: "22222222-2222-2222-2222-222222222222", Summary: "hello"},
 		},
 	}
 	state := newTestState([]codexhistory.Project{project})
@@ -233,8 +266,8 @@ func TestHandleKeyCtrlJSelectsSession(t *testing.T) {
 	if err != nil {
 		t.Fatalf("handleKey error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-2" {
-		t.Fatalf("expected session sess-2, got %#v", selection)
+	if selection == nil || selection.Session.SessionID != "22222222-2222-2222-2222-222222222222" {
+		t.Fatalf("expected session 2222…, got %#v", selection)
 	}
 	if selection.UseProxy {
 		t.Fatalf("expected proxy to be disabled by default")
@@ -286,6 +319,71 @@ func TestBuildProjectItemsMarksExistingCurrent(t *testing.T) {
 	}
 }
 
+func TestAgentsFileMarker(t *testing.T) {
+	configured, plain, cwd := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, dir := range []string{configured, cwd} {
+		if err := os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# agents\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var checked []string
+	prevExists := agentsFileExists
+	agentsFileExists = func(dir string) bool {
+		checked = append(checked, dir)
+		return prevExists(dir)
+	}
+	t.Cleanup(func() { agentsFileExists = prevExists })
+
+	now := time.Now()
+	state := newTestState([]codexhistory.Project{
+		{Key: configured, Path: configured, Sessions: []codexhistory.Session{{SessionID: "a", FirstPrompt: "a", ModifiedAt: now}}},
+		{Key: plain, Path: plain, Sessions: []codexhistory.Session{{SessionID: "b", FirstPrompt: "b", ModifiedAt: now.Add(-time.Minute)}}},
+		{Key: codexhistory.UnknownProjectKey, Sessions: []codexhistory.Session{{SessionID: "c", FirstPrompt: "c", ModifiedAt: now}}},
+	})
+	opts := Options{DefaultCwd: cwd, ShowAgentsMarker: true}
+	visibleProjectItems(state, opts)
+	items := visibleProjectItems(state, opts)
+
+	marked := map[string]bool{}
+	for _, item := range items {
+		marked[item.label] = item.hasAgentsFile
+	}
+	if !marked[configured] || marked[plain] || marked[cwd] {
+		t.Fatalf("marked = %v, want only %s", marked, configured)
+	}
+	if len(checked) != 2 {
+		t.Fatalf("checked %v, want the two real projects once each", checked)
+	}
+	if row := formatProjectRow(items[1], 200); !strings.HasPrefix(row, agentsFileMarker) {
+		t.Fatalf("row = %q, want the marker first", row)
+	}
+
+	if items := visibleProjectItems(newTestState(state.projects), Options{DefaultCwd: cwd}); items[1].hasAgentsFile {
+		t.Fatal("marker shown without ShowAgentsMarker")
+	}
+}
+
+func TestSelectFirstFilteredProjectSkipsUnmatchedCurrentPin(t *testing.T) {
+	projects := []codexhistory.Project{
+		{Key: "alpha", Path: "/work/alpha"},
+		{Key: "beta", Path: "/work/beta"},
+	}
+	state := newTestState(projects)
+	state.projectFilter = "beta"
+	selectFirstFilteredProject(state, Options{DefaultCwd: "/work/alpha"})
+	items := filterProjects(buildProjectItems(projects, "/work/alpha"), state.projectFilter)
+	if got := selectedProject(items, state.projectState.selected).Path; got != "/work/beta" {
+		t.Fatalf("selected = %q, want /work/beta", got)
+	}
+
+	state = newTestState(projects)
+	state.projectFilter = "alpha"
+	selectFirstFilteredProject(state, Options{DefaultCwd: "/work/alpha"})
+	if state.projectState.selected != 0 {
+		t.Fatalf("selected = %d, want the pinned current project", state.projectState.selected)
+	}
+}
+
 func TestBuildProjectItemsSortsByRecentActivity(t *testing.T) {
 	now := time.Now()
 	older := codexhistory.Project{
@@ -345,6 +443,32 @@ func TestBuildProjectItemsSortsBySubagentActivity(t *testing.T) {
 	}
 }
 
+func TestBuildProjectItemsSortsUnknownProjectLast(t *testing.T) {
+	now := time.Now()
+	unknown := codexhistory.Project{
+		Key: codexhistory.UnknownProjectKey,
+		Sessions: []codexhistory.Session{{
+			SessionID:  "sess-1",
+			ModifiedAt: now,
+		}},
+	}
+	known := codexhistory.Project{
+		Path: "/tmp/known",
+		Sessions: []codexhistory.Session{{
+			SessionID:  "sess-2",
+			ModifiedAt: now.Add(-time.Hour),
+		}},
+	}
+
+	items := buildProjectItems([]codexhistory.Project{unknown, known}, "")
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].project.Path != known.Path {
+		t.Fatalf("expected known project first, got %q", items[0].project.Key)
+	}
+}
+
 func TestFilterProjectsKeepsCurrentVisible(t *testing.T) {
 	cwd := t.TempDir()
 	items := buildProjectItems([]codexhistory.Project{{Path: "/tmp/other"}}, cwd)
@@ -362,7 +486,7 @@ func TestFilterProjectsKeepsCurrentVisible(t *testing.T) {
 
 func TestBuildSessionItemsIncludesNewAgent(t *testing.T) {
 	project := codexhistory.Project{Sessions: []codexhistory.Session{{SessionID: "sess-1"}}}
-	items := buildSessionItems(project, nil)
+	items := buildSessionItems(project, nil, "")
 	if len(items) == 0 || items[0].kind != sessionItemNew {
 		t.Fatalf("expected new agent item first, got %#v", items)
 	}
@@ -396,7 +520,7 @@ func TestBuildProjectAndSessionItemsHideHelperSessions(t *testing.T) {
 	if projectItems[0].project.Path != "/repo" {
 		t.Fatalf("project path = %q, want /repo", projectItems[0].project.Path)
 	}
-	sessionItems := buildSessionItems(projectItems[0].project, nil)
+	sessionItems := buildSessionItems(projectItems[0].project, nil, "")
 	if len(sessionItems) != 2 {
 		t.Fatalf("session items = %#v, want new agent plus visible session", sessionItems)
 	}
@@ -441,7 +565,7 @@ func TestBuildProjectItemsUsesSessionWorkspacePath(t *testing.T) {
 	if got := len(projectItems[0].project.Sessions); got != 2 {
 		t.Fatalf("session count = %d, want grouped and deduped count 2", got)
 	}
-	sessionItems := buildSessionItems(projectItems[0].project, nil)
+	sessionItems := buildSessionItems(projectItems[0].project, nil, "")
 	if len(sessionItems) != 3 {
 		t.Fatalf("session items = %#v, want new agent plus two sessions", sessionItems)
 	}
@@ -449,13 +573,33 @@ func TestBuildProjectItemsUsesSessionWorkspacePath(t *testing.T) {
 
 func TestFilterSessionsKeepsNewAgent(t *testing.T) {
 	project := codexhistory.Project{Sessions: []codexhistory.Session{{SessionID: "sess-1"}}}
-	items := buildSessionItems(project, nil)
+	items := buildSessionItems(project, nil, "")
 	filtered := filterSessions(items, "nomatch")
 	if len(filtered) == 0 || filtered[0].kind != sessionItemNew {
 		t.Fatalf("expected new agent item to remain visible")
 	}
 }
 
+func TestArrangeNewAgentItemLabelAndPosition(t *testing.T) {
+	project := codexhistory.Project{Sessions: []codexhistory.Session{{SessionID: "sess-1"}, {SessionID: "sess-2"}}}
+	items := arrangeNewAgentItem(buildSessionItems(project, nil, ""), "  + new  ", true)
+	if len(items) != 3 || items[0].kind != sessionItemMain || items[2].kind != sessionItemNew {
+		t.Fatalf("expected new agent row last, got %#v", items)
+	}
+	if items[2].label != "+ new" {
+		t.Fatalf("new agent label = %q, want %q", items[2].label, "+ new")
+	}
+	filtered := filterSessions(items, "nomatch")
+	if len(filtered) != 1 || filtered[0].kind != sessionItemNew {
+		t.Fatalf("expected new agent row to stay visible under a filter, got %#v", filtered)
+	}
+
+	items = arrangeNewAgentItem(buildSessionItems(project, nil, ""), " ", false)
+	if items[0].kind != sessionItemNew || items[0].label != "(New Agent)" {
+		t.Fatalf("blank label should keep the default first row, got %#v", items[0])
+	}
+}
+
 func TestBuildSessionItemsShowsSubagentMarkers(t *testing.T) {
 	now := time.Now()
 	project := codexhistory.Project{
@@ -466,7 +610,7 @@ func TestBuildSessionItemsShowsSubagentMarkers(t *testing.T) {
 		}},
 	}
 
-	collapsed := buildSessionItems(project, map[string]bool{})
+	collapsed := buildSessionItems(project, map[string]bool{}, "")
 	if len(collapsed) < 2 {
 		t.Fatalf("expected main session row, got %#v", collapsed)
 	}
@@ -474,7 +618,7 @@ func TestBuildSessionItemsShowsSubagentMarkers(t *testing.T) {
 		t.Fatalf("expected collapsed marker, got %q", collapsed[1].label)
 	}
 
-	expanded := buildSessionItems(project, map[string]bool{"sess-1": true})
+	expanded := buildSessionItems(project, map[string]bool{"sess-1": true}, "")
 	if len(expanded) < 3 {
 		t.Fatalf("expected subagent row when expanded, got %#v", expanded)
 	}
@@ -486,6 +630,78 @@ func TestBuildSessionItemsShowsSubagentMarkers(t *testing.T) {
 	}
 }
 
+func TestSubagentTypeShownInRowAndPreview(t *testing.T) {
+	session := codexhistory.Session{
+		SessionID: "sess-1",
+		Subagents: []codexhistory.SubagentSession{
+			{AgentID: "review", Summary: "check the diff"},
+			{Summary: "untyped worker"},
+		},
+	}
+	project := codexhistory.Project{Path: "/tmp/one", Sessions: []codexhistory.Session{session}}
+	items := buildSessionItems(project, map[string]bool{"sess-1": true}, "")
+	if len(items) != 4 {
+		t.Fatalf("items = %#v, want new agent, parent, and two subagents", items)
+	}
+	if !strings.HasPrefix(items[2].label, "  |- [review] check the diff") {
+		t.Fatalf("typed subagent label = %q", items[2].label)
+	}
+	if !strings.HasPrefix(items[3].label, "  |- subagent untyped worker") {
+		t.Fatalf("untyped subagent label = %q", items[3].label)
+	}
+
+	lines := buildPreviewLines(project, &session, &session.Subagents[0], false, newTestState([]codexhistory.Project{project}), "", Options{})
+	if !strings.Contains(strings.Join(lines, "\n"), "  Type: review") {
+		t.Fatalf("preview lines missing subagent type:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestPreviewShowsResumeCount(t *testing.T) {
+	session := codexhistory.Session{SessionID: "sess-1", FirstPrompt: "hello"}
+	project := codexhistory.Project{Path: "/tmp/one", Sessions: []codexhistory.Session{session}}
+	state := newTestState([]codexhistory.Project{project})
+
+	lines := strings.Join(buildPreviewLines(project, &session, nil, false, state, "", Options{ResumeCounts: map[string]int{"sess-1": 3}}), "\n")
+	if !strings.Contains(lines, "  Resumed 3 times") {
+		t.Fatalf("preview lines missing resume count:\n%s", lines)
+	}
+	lines = strings.Join(buildPreviewLines(project, &session, nil, false, state, "", Options{}), "\n")
+	if strings.Contains(lines, "Resumed") {
+		t.Fatalf("preview shows a resume count for a session never resumed:\n%s", lines)
+	}
+}
+
+func TestPreviewProjectOnlyWithoutSessions(t *testing.T) {
+	state := newTestState(nil)
+	project := codexhistory.Project{Path: "/tmp/repo"}
+	lines := strings.Join(buildPreviewLines(project, nil, nil, true, state, "", Options{ProjectOnly: true, DefaultCwd: "/tmp/repo"}), "\n")
+	if !strings.Contains(lines, projectOnlyEmptyText) || !strings.Contains(lines, "  /tmp/repo") {
+		t.Fatalf("preview lines = %q", lines)
+	}
+}
+
+func TestPreviewShowsProjectCodexPath(t *testing.T) {
+	session := codexhistory.Session{SessionID: "sess-1", FirstPrompt: "hello"}
+	project := codexhistory.Project{Path: "/tmp/one", Sessions: []codexhistory.Session{session}}
+	state := newTestState([]codexhistory.Project{project})
+	opts := Options{ProjectCodexPath: func(dir string) string {
+		if dir == "/tmp/one" {
+			return "/opt/codex-patched/codex"
+		}
+		return ""
+	}}
+
+	lines := strings.Join(buildPreviewLines(project, &session, nil, false, state, "", opts), "\n")
+	if !strings.Contains(lines, "  Custom Codex: /opt/codex-patched/codex") {
+		t.Fatalf("preview lines missing custom Codex:\n%s", lines)
+	}
+	other := codexhistory.Project{Path: "/tmp/two"}
+	lines = strings.Join(buildPreviewLines(other, nil, nil, true, state, "", opts), "\n")
+	if strings.Contains(lines, "Custom Codex") {
+		t.Fatalf("preview shows a custom Codex for a project without one:\n%s", lines)
+	}
+}
+
 func TestBuildSessionItemsFiltersHelperSubagents(t *testing.T) {
 	project := codexhistory.Project{
 		Sessions: []codexhistory.Session{{
@@ -497,7 +713,7 @@ func TestBuildSessionItemsFiltersHelperSubagents(t *testing.T) {
 			}},
 		}},
 	}
-	items := buildSessionItems(project, map[string]bool{"sess-1": true})
+	items := buildSessionItems(project, map[string]bool{"sess-1": true}, "")
 	if len(items) != 2 {
 		t.Fatalf("items = %#v, want new agent plus parent session only", items)
 	}
@@ -510,7 +726,7 @@ func TestBuildSessionItemsNoMarkerWithoutSubagents(t *testing.T) {
 	project := codexhistory.Project{
 		Sessions: []codexhistory.Session{{SessionID: "sess-1"}},
 	}
-	items := buildSessionItems(project, map[string]bool{})
+	items := buildSessionItems(project, map[string]bool{}, "")
 	if len(items) < 2 {
 		t.Fatalf("expected main session row, got %#v", items)
 	}
@@ -1570,8 +1786,8 @@ func TestEnsurePreviewCachesEmptyVisiblePreview(t *testing.T) {
 	ensurePreview(screen, state, Options{}, session, nil, previewCh)
 	ev := <-previewCh
 	applyPreviewEvent(state, ev)
-	if got := previewTextForItem(state, session, nil); got != "" {
-		t.Fatalf("empty visible preview should render empty text, got %q", got)
+	if got := previewTextForItem(state, session, nil); !strings.HasPrefix(got, "No message text") || strings.Contains(got, "hidden") {
+		t.Fatalf("empty visible preview should render the session outline, got %q", got)
 	}
 	if _, ok := state.previewCache[cacheKey]; !ok {
 		t.Fatalf("empty visible preview should still be cached")
@@ -1600,7 +1816,7 @@ func TestSelectSessionReturnsSelectionOnEnter(t *testing.T) {
 		Key:  "proj-1",
 		Path: projectPath,
 		Sessions: []codexhistory.Session{{
-			SessionID:   "sess-1",
+			SessionID:   "11111111-1111-1111-1111-111111111111",
 			ProjectPath: projectPath,
 			FilePath:    filepath.Join(projectPath, "sess-1.jsonl"),
 		}},
@@ -1610,7 +1826,7 @@ func TestSelectSessionReturnsSelectionOnEnter(t *testing.T) {
 	defer cancel()
 	go func() {
 		<-initDone
-		waitForScreenContains(t, screen, "sess-1")
+		waitForScreenContains(t, screen, "11111111-1111-1111-1111-111111111111")
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyDown, 0, 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
@@ -1624,7 +1840,7 @@ func TestSelectSessionReturnsSelectionOnEnter(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SelectSession error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-1" {
+	if selection == nil || selection.Session.SessionID != "11111111-1111-1111-1111-111111111111" {
 		t.Fatalf("unexpected selection: %#v", selection)
 	}
 }
@@ -1636,7 +1852,7 @@ func TestSelectSessionCtrlAUpdatesStatusPersistsAndCarriesSelection(t *testing.T
 		Key:  "proj-aaa",
 		Path: projectPath,
 		Sessions: []codexhistory.Session{{
-			SessionID:   "sess-aaa",
+			SessionID:   "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
 			ProjectPath: projectPath,
 			FilePath:    filepath.Join(projectPath, "sess-aaa.jsonl"),
 		}},
@@ -1646,7 +1862,7 @@ func TestSelectSessionCtrlAUpdatesStatusPersistsAndCarriesSelection(t *testing.T
 	defer cancel()
 	go func() {
 		<-initDone
-		waitForScreenContains(t, screen, "sess-aaa")
+		waitForScreenContains(t, screen, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyCtrlA, 0, 0))
 		waitForScreenContains(t, screen, "[!] AAA mode (Ctrl+A): on")
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
@@ -1663,7 +1879,7 @@ func TestSelectSessionCtrlAUpdatesStatusPersistsAndCarriesSelection(t *testing.T
 	if err != nil {
 		t.Fatal(err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-aaa" || !selection.UseAAA {
+	if selection == nil || selection.Session.SessionID != "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa" || !selection.UseAAA {
 		t.Fatalf("AAA selection = %#v", selection)
 	}
 	select {
@@ -1729,7 +1945,7 @@ func TestSelectSessionInitialLoadPreservesProjectsOnPartialError(t *testing.T) {
 		Key:  "proj-1",
 		Path: projectPath,
 		Sessions: []codexhistory.Session{{
-			SessionID:   "sess-1",
+			SessionID:   "11111111-1111-1111-1111-111111111111",
 			ProjectPath: projectPath,
 			FilePath:    filepath.Join(projectPath, "sess-1.jsonl"),
 		}},
@@ -1740,7 +1956,7 @@ func TestSelectSessionInitialLoadPreservesProjectsOnPartialError(t *testing.T) {
 
 	go func() {
 		<-initDone
-		waitForScreenContains(t, screen, "sess-1")
+		waitForScreenContains(t, screen, "11111111-1111-1111-1111-111111111111")
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyDown, 0, 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
@@ -1754,7 +1970,7 @@ func TestSelectSessionInitialLoadPreservesProjectsOnPartialError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SelectSession error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-1" {
+	if selection == nil || selection.Session.SessionID != "11111111-1111-1111-1111-111111111111" {
 		t.Fatalf("unexpected selection: %#v", selection)
 	}
 }
@@ -1796,6 +2012,19 @@ func TestSelectSessionCancelsInitialLoadOnQuit(t *testing.T) {
 	}
 }
 
+func TestSelectSessionRejectsNonTerminal(t *testing.T) {
+	prevIsTerminal := isTerminal
+	isTerminal = func() bool { return false }
+	t.Cleanup(func() { isTerminal = prevIsTerminal })
+
+	_, err := SelectSession(context.Background(), Options{
+		LoadProjects: func(context.Context) ([]codexhistory.Project, error) { return nil, nil },
+	})
+	if !errors.Is(err, ErrNotTerminal) {
+		t.Fatalf("SelectSession error = %v, want ErrNotTerminal", err)
+	}
+}
+
 func TestSelectSessionInitialLoadRecoversFromEventQueueFull(t *testing.T) {
 	screen := tcell.NewSimulationScreen("UTF-8")
 	initDone := make(chan struct{})
@@ -1814,7 +2043,7 @@ func TestSelectSessionInitialLoadRecoversFromEventQueueFull(t *testing.T) {
 		Key:  "proj-1",
 		Path: projectPath,
 		Sessions: []codexhistory.Session{{
-			SessionID:   "sess-1",
+			SessionID:   "11111111-1111-1111-1111-111111111111",
 			ProjectPath: projectPath,
 			FilePath:    filepath.Join(projectPath, "sess-1.jsonl"),
 		}},
@@ -1825,7 +2054,7 @@ func TestSelectSessionInitialLoadRecoversFromEventQueueFull(t *testing.T) {
 
 	go func() {
 		<-initDone
-		waitForScreenContains(t, screen, "sess-1")
+		waitForScreenContains(t, screen, "11111111-1111-1111-1111-111111111111")
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyDown, 0, 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
@@ -1839,7 +2068,7 @@ func TestSelectSessionInitialLoadRecoversFromEventQueueFull(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SelectSession error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-1" {
+	if selection == nil || selection.Session.SessionID != "11111111-1111-1111-1111-111111111111" {
 		t.Fatalf("unexpected selection: %#v", selection)
 	}
 }
@@ -2018,7 +2247,7 @@ func TestSelectSessionAutoRefreshPreservesSelection(t *testing.T) {
 			Key:  "proj-1",
 			Path: projectPath1,
 			Sessions: []codexhistory.Session{{
-				SessionID:   "sess-1",
+				SessionID:   "11111111-1111-1111-1111-111111111111",
 				ProjectPath: projectPath1,
 				FilePath:    filepath.Join(projectPath1, "sess-1.jsonl"),
 			}},
@@ -2027,7 +2256,7 @@ func TestSelectSessionAutoRefreshPreservesSelection(t *testing.T) {
 			Key:  "proj-2",
 			Path: projectPath2,
 			Sessions: []codexhistory.Session{{
-				SessionID:   "sess-2",
+				SessionID:   "22222222-2222-2222-2222-222222222222",
 				ProjectPath: projectPath2,
 				FilePath:    filepath.Join(projectPath2, "sess-2.jsonl"),
 			}},
@@ -2044,7 +2273,7 @@ func TestSelectSessionAutoRefreshPreservesSelection(t *testing.T) {
 
 	go func() {
 		<-initDone
-		waitForScreenContains(t, screen, "sess-1")
+		waitForScreenContains(t, screen, "11111111-1111-1111-1111-111111111111")
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'j', 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
 		screen.PostEvent(tcell.NewEventKey(tcell.KeyDown, 0, 0))
@@ -2068,7 +2297,7 @@ func TestSelectSessionAutoRefreshPreservesSelection(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SelectSession error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-2" {
+	if selection == nil || selection.Session.SessionID != "22222222-2222-2222-2222-222222222222" {
 		t.Fatalf("unexpected selection: %#v", selection)
 	}
 }
@@ -2143,6 +2372,42 @@ func TestHandleKeyProxyToggleDisablesProxy(t *testing.T) {
 	}
 }
 
+func TestHandleKeyProxyTogglePersistsInPlace(t *testing.T) {
+	screen := newTestScreen(t, 80, 20)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp"}})
+	state.proxyConfigured = true
+	var persisted []bool
+	opts := Options{PersistProxy: func(enabled bool) error {
+		persisted = append(persisted, enabled)
+		return nil
+	}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyCtrlP, 0, 0)); err != nil {
+			t.Fatalf("Ctrl+P: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(persisted, []bool{true, false}) || state.proxyEnabled {
+		t.Fatalf("persisted = %v, proxyEnabled = %v", persisted, state.proxyEnabled)
+	}
+
+	wantErr := errors.New("save failed")
+	opts.PersistProxy = func(bool) error { return wantErr }
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyCtrlP, 0, 0)); !errors.Is(err, wantErr) {
+		t.Fatalf("Ctrl+P error = %v, want %v", err, wantErr)
+	}
+	if state.proxyEnabled {
+		t.Fatal("proxy state changed after persistence failed")
+	}
+
+	state.proxyConfigured = false
+	_, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyCtrlP, 0, 0))
+	var toggle ProxyToggleRequested
+	if !errors.As(err, &toggle) || !toggle.RequireConfig {
+		t.Fatalf("enabling without a profile = %v, want ProxyToggleRequested with RequireConfig", err)
+	}
+}
+
 func TestHandleKeyCtrlATogglesAAAAndPersistsBeforeChangingState(t *testing.T) {
 	screen := newTestScreen(t, 80, 20)
 	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp"}})
@@ -2192,6 +2457,47 @@ func TestHandleKeyCtrlAIgnoredWhileEnteringSearch(t *testing.T) {
 	}
 }
 
+func TestProjectFilterAppliesWhileTypingAndEscRestores(t *testing.T) {
+	screen := newTestScreen(t, 120, 20)
+	state := newTestState([]codexhistory.Project{
+		{Key: "alpha", Path: "/tmp/alpha"},
+		{Key: "beta", Path: "/tmp/beta"},
+	})
+	state.projectFilter = "tmp"
+	press := func(ev *tcell.EventKey) {
+		t.Helper()
+		if _, err := handleKey(context.Background(), screen, state, Options{}, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	press(tcell.NewEventKey(tcell.KeyRune, '/', 0))
+	press(tcell.NewEventKey(tcell.KeyBackspace2, 0, 0))
+	press(tcell.NewEventKey(tcell.KeyBackspace2, 0, 0))
+	press(tcell.NewEventKey(tcell.KeyBackspace2, 0, 0))
+	for _, r := range "bet" {
+		press(tcell.NewEventKey(tcell.KeyRune, r, 0))
+	}
+	if state.inputMode != "projects" || state.projectFilter != "bet" {
+		t.Fatalf("while typing: inputMode=%q projectFilter=%q", state.inputMode, state.projectFilter)
+	}
+	if got := filterProjects(buildProjectItems(state.projects, ""), state.projectFilter); len(got) != 1 || got[0].label != "/tmp/beta" {
+		t.Fatalf("live filter matched %+v, want only /tmp/beta", got)
+	}
+
+	press(tcell.NewEventKey(tcell.KeyESC, 0, 0))
+	if state.inputMode != "" || state.projectFilter != "tmp" {
+		t.Fatalf("after Esc: inputMode=%q projectFilter=%q, want the pre-edit filter", state.inputMode, state.projectFilter)
+	}
+
+	press(tcell.NewEventKey(tcell.KeyRune, '/', 0))
+	press(tcell.NewEventKey(tcell.KeyRune, 'a', 0))
+	press(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if state.inputMode != "" || state.projectFilter != "tmpa" {
+		t.Fatalf("after Enter: inputMode=%q projectFilter=%q", state.inputMode, state.projectFilter)
+	}
+}
+
 func TestHandleKeySelectionCarriesAAA(t *testing.T) {
 	screen := newTestScreen(t, 80, 20)
 	dir := t.TempDir()
@@ -2206,6 +2512,57 @@ func TestHandleKeySelectionCarriesAAA(t *testing.T) {
 	}
 }
 
+func TestLaunchProfileKeyCyclesAndCarriesOnSelection(t *testing.T) {
+	screen := newTestScreen(t, 120, 20)
+	dir := t.TempDir()
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: dir}})
+	press := func(opts Options, ev *tcell.EventKey) *Selection {
+		t.Helper()
+		selection, err := handleKey(context.Background(), screen, state, opts, ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return selection
+	}
+	w := tcell.NewEventKey(tcell.KeyRune, 'w', 0)
+
+	press(Options{}, w)
+	if state.launchProfile != "" || !strings.Contains(state.flashMessage, "No launch profiles") {
+		t.Fatalf("without profiles: launchProfile=%q flash=%q", state.launchProfile, state.flashMessage)
+	}
+
+	opts := Options{LaunchProfiles: []string{"safe", "yolo"}}
+	for _, want := range []string{"safe", "yolo", "", "safe"} {
+		press(opts, w)
+		if state.launchProfile != want {
+			t.Fatalf("launchProfile = %q, want %q", state.launchProfile, want)
+		}
+	}
+	state.flashMessage = ""
+	if err := draw(screen, state, opts, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	_, h := screen.Size()
+	status := ""
+	for y := h - state.statusHeight; y < h; y++ {
+		status += readScreenLine(screen, y)
+	}
+	if !strings.Contains(status, "Launch profile (w): safe") {
+		t.Fatalf("status does not show the launch profile: %q", status)
+	}
+
+	selection := press(opts, tcell.NewEventKey(tcell.KeyCtrlN, 0, 0))
+	if selection == nil || selection.LaunchProfile != "safe" {
+		t.Fatalf("selection did not carry the launch profile: %#v", selection)
+	}
+
+	state.launchProfile = ""
+	press(Options{LaunchProfiles: []string{"safe"}, ReadOnly: true}, w)
+	if state.launchProfile != "" || state.flashMessage != readOnlyHint {
+		t.Fatalf("read-only: launchProfile=%q flash=%q", state.launchProfile, state.flashMessage)
+	}
+}
+
 func TestPreviewPageDownScrollsWhenFocused(t *testing.T) {
 	screen := newTestScreen(t, 60, 12)
 	project := codexhistory.Project{
@@ -2244,6 +2601,55 @@ func TestDisplayWidthHelpers(t *testing.T) {
 	}
 }
 
+func TestDisplayWidthCountsZeroWidthRunesLikeWriteText(t *testing.T) {
+	combining := "e\u0301e\u0301e\u0301" // é written as e + combining acute
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467"
+	for _, tc := range []struct {
+		text  string
+		width int
+	}{
+		{combining, 3},
+		{family, 6},
+		{"\u2764\ufe0f ok", 4},
+	} {
+		if got := displayWidth(tc.text); got != tc.width {
+			t.Fatalf("displayWidth(%q) = %d, want %d", tc.text, got, tc.width)
+		}
+
+		screen := newTestScreen(t, 20, 1)
+		writeText(screen, 0, 0, tc.text+"|", tcell.StyleDefault)
+		marker := -1
+		for x := 0; x < 20 && marker < 0; x++ {
+			if ch, _, _, _ := screen.GetContent(x, 0); ch == '|' {
+				marker = x
+			}
+		}
+		if marker != tc.width {
+			t.Fatalf("writeText(%q) put the next text at column %d, want %d", tc.text, marker, tc.width)
+		}
+
+		for w := 0; w <= tc.width+1; w++ {
+			if got := displayWidth(truncate(tc.text, w)); got > w {
+				t.Fatalf("truncate(%q, %d) is %d wide", tc.text, w, got)
+			}
+			for _, line := range wrapText(tc.text, max(1, w)) {
+				if got := displayWidth(line); got > max(2, w) {
+					t.Fatalf("wrapText(%q, %d) line %q is %d wide", tc.text, w, line, got)
+				}
+			}
+		}
+		if got := displayWidth(padRight(tc.text, tc.width+2)); got != tc.width+2 {
+			t.Fatalf("padRight(%q) is %d wide, want %d", tc.text, got, tc.width+2)
+		}
+	}
+	if got := truncate(combining, 2); got != "e\u0301e\u0301" {
+		t.Fatalf("truncate should keep combining marks with their base letter, got %q", got)
+	}
+	if got := wrapText(combining, 2); !slices.Equal(got, []string{"e\u0301e\u0301", "e\u0301"}) {
+		t.Fatalf("wrapText = %q", got)
+	}
+}
+
 func TestPreviewSearchMatches(t *testing.T) {
 	screen := newTestScreen(t, 80, 12)
 	project := codexhistory.Project{
@@ -2281,6 +2687,42 @@ func TestPreviewSearchMatches(t *testing.T) {
 	if len(state.previewMatches) != 2 {
 		t.Fatalf("expected 2 matches, got %d", len(state.previewMatches))
 	}
+
+	statusText := func() string {
+		if err := draw(screen, state, Options{}, previewCh); err != nil {
+			t.Fatalf("draw error: %v", err)
+		}
+		_, h := screen.Size()
+		var lines []string
+		for y := h - state.statusHeight; y < h; y++ {
+			lines = append(lines, readScreenLine(screen, y))
+		}
+		return strings.Join(lines, "\n")
+	}
+	if got := statusText(); !strings.Contains(got, "1/2  n/N: next/prev") {
+		t.Fatalf("expected the match counter in the status, got %q", got)
+	}
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'n', 0)); err != nil {
+		t.Fatalf("handleKey error: %v", err)
+	}
+	if got := statusText(); !strings.Contains(got, "2/2") || state.flashMessage != "" {
+		t.Fatalf("expected n to advance the match counter without a flash, got %q / %q", got, state.flashMessage)
+	}
+
+	for _, tc := range []struct {
+		key  rune
+		want string
+	}{
+		{'n', "Search wrapped to top: 1/2"},
+		{'N', "Search wrapped to bottom: 2/2"},
+	} {
+		if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, tc.key, 0)); err != nil {
+			t.Fatalf("handleKey error: %v", err)
+		}
+		if got := statusText(); !strings.Contains(got, tc.want) {
+			t.Fatalf("%c: expected %q in the status, got %q", tc.key, tc.want, got)
+		}
+	}
 }
 
 func TestHandleKeyCtrlURequestsUpdateWhenAvailable(t *testing.T) {
@@ -2436,53 +2878,223 @@ func TestDrawShowsAAAStatusInBothModes(t *testing.T) {
 	}
 }
 
-func TestDrawShowsLoadingStatusWhenProjectsLoading(t *testing.T) {
-	screen := newTestScreen(t, 160, 20)
-	state := newTestState(nil)
-	state.loadingProjects = true
-
-	previewCh := make(chan previewEvent, 1)
-	if err := draw(screen, state, Options{}, previewCh); err != nil {
-		t.Fatalf("draw error: %v", err)
+func TestDrawMinimalStatusFitsOnOneLine(t *testing.T) {
+	projects := []codexhistory.Project{{Key: "one", Path: "/tmp/one", Sessions: []codexhistory.Session{{SessionID: "s1"}}}}
+	status := func(opts Options, aaa bool) []string {
+		t.Helper()
+		screen := newTestScreen(t, 80, 24)
+		state := newTestState(projects)
+		state.focus = "sessions"
+		state.sessionState.selected = 1
+		state.aaaEnabled = aaa
+		if err := draw(screen, state, opts, make(chan previewEvent, 1)); err != nil {
+			t.Fatal(err)
+		}
+		_, h := screen.Size()
+		lines := make([]string, 0, state.statusHeight)
+		for y := h - state.statusHeight; y < h; y++ {
+			lines = append(lines, strings.TrimSpace(readScreenLine(screen, y)))
+		}
+		return lines
 	}
 
-	_, h := screen.Size()
-	line := readScreenLine(screen, h-1)
-	if !strings.Contains(line, "Loading Codex session history...") {
-		t.Fatalf("expected loading hint in status line, got %q", strings.TrimSpace(line))
+	if full := status(Options{}, false); len(full) < 2 {
+		t.Fatalf("expected the full hint list to wrap at 80 columns, got %q", full)
 	}
-	if !strings.Contains(line, "Elapsed:") {
-		t.Fatalf("expected elapsed time in loading state, got %q", strings.TrimSpace(line))
+	minimal := status(Options{MinimalStatus: true}, false)
+	if len(minimal) != 1 || !strings.HasPrefix(minimal[0], "[Sessions]  Enter: open  Tab: switch  /: search  q: quit") {
+		t.Fatalf("minimal status = %q", minimal)
+	}
+	if strings.Contains(minimal[0], "AAA") || strings.Contains(minimal[0], "Proxy") {
+		t.Fatalf("minimal status should leave out modes that are off: %q", minimal[0])
+	}
+	if withAAA := status(Options{MinimalStatus: true}, true); !strings.Contains(withAAA[0], "[!] AAA on") {
+		t.Fatalf("minimal status should keep the AAA warning: %q", withAAA)
 	}
 }
 
-func TestDrawShowsLoadingRowsWhenProjectsLoading(t *testing.T) {
-	screen := newTestScreen(t, 160, 20)
-	state := newTestState(nil)
-	state.loadingProjects = true
-	state.loadingStartedAt = time.Now().Add(-1500 * time.Millisecond)
-
-	previewCh := make(chan previewEvent, 1)
-	if err := draw(screen, state, Options{}, previewCh); err != nil {
-		t.Fatalf("draw error: %v", err)
+func TestDrawDistinguishesMissingSessionsDirFromEmptyHistory(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		loadError error
+		want      string
+	}{
+		{name: "missing", loadError: fmt.Errorf("%w: /tmp/x/sessions", codexhistory.ErrNoSessionsDir), want: "Codex has never run here (no sessions directory)."},
+		{name: "other", loadError: errors.New("history unavailable"), want: "No history found."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			screen := newTestScreen(t, 240, 20)
+			state := newTestState(nil)
+			state.loadError = tc.loadError
+			if err := draw(screen, state, Options{DefaultCwd: t.TempDir()}, make(chan previewEvent, 1)); err != nil {
+				t.Fatal(err)
+			}
+			_, h := screen.Size()
+			if line := readScreenLine(screen, h-1); !strings.Contains(line, tc.want) {
+				t.Fatalf("status = %q, want %q", strings.TrimSpace(line), tc.want)
+			}
+		})
 	}
 
-	line1 := readScreenLine(screen, 1)
-	line2 := readScreenLine(screen, 2)
-	line3 := readScreenLine(screen, 3)
-	if !strings.Contains(line1, "Loading Codex session history...") {
-		t.Fatalf("expected loading rows in list panes, got %q", strings.TrimSpace(line1))
+	// Without a directory to start a new session in, the missing sessions
+	// directory status still says how to quit.
+	screen := newTestScreen(t, 240, 20)
+	state := newTestState(nil)
+	state.loadError = fmt.Errorf("%w: /tmp/x/sessions", codexhistory.ErrNoSessionsDir)
+	if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(line2, "Large histories can take a while.") {
-		t.Fatalf("expected loading hint in list panes, got %q", strings.TrimSpace(line2))
+	_, h := screen.Size()
+	if line := readScreenLine(screen, h-1); !strings.Contains(line, "no sessions directory") || !strings.Contains(line, "q: quit") {
+		t.Fatalf("status = %q, want the missing sessions dir and the quit hint", strings.TrimSpace(line))
 	}
-	if !strings.Contains(line3, "Elapsed: ") {
-		t.Fatalf("expected elapsed line in list panes, got %q", strings.TrimSpace(line3))
+
+	state = newTestState(nil)
+	lines := buildPreviewLines(codexhistory.Project{}, nil, nil, false, state, "", Options{})
+	if len(lines) == 0 || lines[0] != "No sessions yet." {
+		t.Fatalf("empty history preview = %q", lines)
 	}
 }
 
-func TestDrawPreservesExistingRowsWhileProjectsLoading(t *testing.T) {
-	screen := newTestScreen(t, 160, 20)
+func TestUnreadableFilesStatusAndOverlay(t *testing.T) {
+	projects := []codexhistory.Project{{Key: "/tmp/proj", Path: "/tmp/proj", Sessions: []codexhistory.Session{{SessionID: "s1", FirstPrompt: "hello"}}}}
+	opts := Options{LoadProjects: func(context.Context) ([]codexhistory.Project, error) {
+		return projects, &codexhistory.UnreadableFilesError{Errs: []error{
+			errors.New("read session /tmp/a.jsonl: permission denied"),
+			errors.New("read session /tmp/b.jsonl: permission denied"),
+		}}
+	}}
+	screen := newTestScreen(t, 200, 24)
+	state := newTestState(nil)
+	refreshState(context.Background(), state, opts)
+	if state.loadError != nil || len(state.projects) != 1 {
+		t.Fatalf("loadError = %v, projects = %d; unreadable files should not fail the load", state.loadError, len(state.projects))
+	}
+	render := func() string {
+		t.Helper()
+		if err := draw(screen, state, opts, make(chan previewEvent, 1)); err != nil {
+			t.Fatal(err)
+		}
+		_, h := screen.Size()
+		var rendered strings.Builder
+		for y := 0; y < h; y++ {
+			rendered.WriteString(readScreenLine(screen, y) + "\n")
+		}
+		return rendered.String()
+	}
+	if out := render(); !strings.Contains(out, "⚠ 2 unreadable files (!: list)") || strings.Contains(out, "Unreadable files") {
+		t.Fatalf("status without overlay:\n%s", out)
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, '!', tcell.ModNone)); err != nil {
+		t.Fatal(err)
+	}
+	out := render()
+	for _, want := range []string{"Unreadable files", "/tmp/a.jsonl: permission denied", "/tmp/b.jsonl: permission denied"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("overlay missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)); err != nil {
+		t.Fatalf("closing the overlay should not quit: %v", err)
+	}
+	if out := render(); strings.Contains(out, "Unreadable files") {
+		t.Fatalf("overlay still shown:\n%s", out)
+	}
+
+	state.unreadableFiles = nil
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, '!', tcell.ModNone)); err != nil || state.showUnreadable {
+		t.Fatalf("! without unreadable files: showUnreadable = %v, err = %v", state.showUnreadable, err)
+	}
+}
+
+func TestDuplicateSessionIDsAreNotCountedAsUnreadable(t *testing.T) {
+	projects := []codexhistory.Project{{Key: "/tmp/proj", Path: "/tmp/proj", Sessions: []codexhistory.Session{{SessionID: "s1", FirstPrompt: "hello"}}}}
+	opts := Options{LoadProjects: func(context.Context) ([]codexhistory.Project, error) {
+		return projects, &codexhistory.UnreadableFilesError{Conflicts: []*codexhistory.SessionProjectConflictError{
+			{SessionID: "s1", Paths: []string{"/tmp/copy", "/tmp/proj"}, Chosen: "/tmp/proj"},
+		}}
+	}}
+	screen := newTestScreen(t, 200, 24)
+	state := newTestState(nil)
+	refreshState(context.Background(), state, opts)
+	if state.loadError != nil || len(state.unreadableFiles) != 0 || len(state.duplicateSessions) != 1 {
+		t.Fatalf("loadError = %v, unreadable = %q, duplicates = %q", state.loadError, state.unreadableFiles, state.duplicateSessions)
+	}
+	render := func() string {
+		t.Helper()
+		if err := draw(screen, state, opts, make(chan previewEvent, 1)); err != nil {
+			t.Fatal(err)
+		}
+		_, h := screen.Size()
+		var rendered strings.Builder
+		for y := 0; y < h; y++ {
+			rendered.WriteString(readScreenLine(screen, y) + "\n")
+		}
+		return rendered.String()
+	}
+	if out := render(); !strings.Contains(out, "⚠ 1 duplicate session ID (!: list)") || strings.Contains(out, "unreadable") {
+		t.Fatalf("status:\n%s", out)
+	}
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, '!', tcell.ModNone)); err != nil {
+		t.Fatal(err)
+	}
+	out := render()
+	for _, want := range []string{"Duplicate session IDs (any key: close)", "session s1 is recorded in projects /tmp/copy, /tmp/proj; listing it under /tmp/proj"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("overlay missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDrawShowsLoadingStatusWhenProjectsLoading(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	state := newTestState(nil)
+	state.loadingProjects = true
+
+	previewCh := make(chan previewEvent, 1)
+	if err := draw(screen, state, Options{}, previewCh); err != nil {
+		t.Fatalf("draw error: %v", err)
+	}
+
+	_, h := screen.Size()
+	line := readScreenLine(screen, h-1)
+	if !strings.Contains(line, "Loading Codex session history...") {
+		t.Fatalf("expected loading hint in status line, got %q", strings.TrimSpace(line))
+	}
+	if !strings.Contains(line, "Elapsed:") {
+		t.Fatalf("expected elapsed time in loading state, got %q", strings.TrimSpace(line))
+	}
+}
+
+func TestDrawShowsLoadingRowsWhenProjectsLoading(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	state := newTestState(nil)
+	state.loadingProjects = true
+	state.loadingStartedAt = time.Now().Add(-1500 * time.Millisecond)
+
+	previewCh := make(chan previewEvent, 1)
+	if err := draw(screen, state, Options{}, previewCh); err != nil {
+		t.Fatalf("draw error: %v", err)
+	}
+
+	line1 := readScreenLine(screen, 1)
+	line2 := readScreenLine(screen, 2)
+	line3 := readScreenLine(screen, 3)
+	if !strings.Contains(line1, "Loading Codex session history...") {
+		t.Fatalf("expected loading rows in list panes, got %q", strings.TrimSpace(line1))
+	}
+	if !strings.Contains(line2, "Large histories can take a while.") {
+		t.Fatalf("expected loading hint in list panes, got %q", strings.TrimSpace(line2))
+	}
+	if !strings.Contains(line3, "Elapsed: ") {
+		t.Fatalf("expected elapsed line in list panes, got %q", strings.TrimSpace(line3))
+	}
+}
+
+func TestDrawPreservesExistingRowsWhileProjectsLoading(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
 	state := newTestState([]codexhistory.Project{{
 		Key:  "proj-1",
 		Path: "/tmp/visible-project",
@@ -2615,7 +3227,7 @@ func TestHandleKeyEnterOpensParentForSubagent(t *testing.T) {
 		Key:  "one",
 		Path: "/tmp/one",
 		Sessions: []codexhistory.Session{{
-			SessionID:  "sess-1",
+			SessionID:  "11111111-1111-1111-1111-111111111111",
 			ModifiedAt: now,
 			Subagents:  []codexhistory.SubagentSession{{AgentID: "agent-1", ModifiedAt: now}},
 		}},
@@ -2623,15 +3235,15 @@ func TestHandleKeyEnterOpensParentForSubagent(t *testing.T) {
 	state := newTestState([]codexhistory.Project{project})
 	state.focus = "sessions"
 	state.lastListFocus = "sessions"
-	state.expandedSessions["sess-1"] = true
+	state.expandedSessions["11111111-1111-1111-1111-111111111111"] = true
 	state.sessionState.selected = 2
 
 	selection, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
 	if err != nil {
 		t.Fatalf("handleKey error: %v", err)
 	}
-	if selection == nil || selection.Session.SessionID != "sess-1" {
-		t.Fatalf("expected parent session sess-1, got %#v", selection)
+	if selection == nil || selection.Session.SessionID != "11111111-1111-1111-1111-111111111111" {
+		t.Fatalf("expected parent session 1111…, got %#v", selection)
 	}
 }
 
@@ -2663,3 +3275,1477 @@ func readScreenLine(screen tcell.Screen, y int) string {
 	}
 	return buf.String()
 }
+
+func TestComputeLayoutAppliesClampedWidthBias(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+	base := computeLayout(screen, 1, layoutOptions{})
+	wider := computeLayout(screen, 1, layoutOptions{widthBias: 6})
+	if wider.projects.w != base.projects.w+6 {
+		t.Fatalf("projects width = %d, want %d", wider.projects.w, base.projects.w+6)
+	}
+	if wider.sessions.x != wider.projects.w || wider.preview.x+wider.preview.w != 160 {
+		t.Fatalf("panes do not tile the screen: %+v", wider)
+	}
+	narrow := computeLayout(screen, 1, layoutOptions{widthBias: -maxPaneWidthBias})
+	if narrow.projects.w != minListPaneWidth {
+		t.Fatalf("projects width = %d, want clamp at %d", narrow.projects.w, minListPaneWidth)
+	}
+	huge := computeLayout(screen, 1, layoutOptions{widthBias: maxPaneWidthBias})
+	if huge.preview.w < minPreviewWidth {
+		t.Fatalf("preview width = %d, want at least %d", huge.preview.w, minPreviewWidth)
+	}
+}
+
+func TestResizeKeysAdjustAndPersistWidthBias(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp"}})
+	var persisted []int
+	opts := Options{PersistPaneWidthBias: func(bias int) error {
+		persisted = append(persisted, bias)
+		return nil
+	}}
+	for _, r := range []rune{'>', '>', '<'} {
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, r, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.paneWidthBias != paneWidthBiasStep {
+		t.Fatalf("paneWidthBias = %d, want %d", state.paneWidthBias, paneWidthBiasStep)
+	}
+	if want := []int{2, 4, 2}; !reflect.DeepEqual(persisted, want) {
+		t.Fatalf("persisted = %v, want %v", persisted, want)
+	}
+
+	state.paneWidthBias = maxPaneWidthBias
+	persisted = nil
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, '>', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.paneWidthBias != maxPaneWidthBias || len(persisted) != 0 {
+		t.Fatalf("bias at limit changed: bias=%d persisted=%v", state.paneWidthBias, persisted)
+	}
+}
+
+func TestComputeLayoutCompactKeepsPreviewStrip(t *testing.T) {
+	screen := newTestScreen(t, 60, 6)
+	auto := computeLayout(screen, 1, layoutOptions{})
+	if auto.mode != layoutMode1Col || auto.preview.h >= 3 {
+		t.Fatalf("auto layout = %+v, want 1col with a hidden preview", auto)
+	}
+	compact := computeLayout(screen, 1, layoutOptions{mode: layoutModeCompact})
+	if compact.mode != layoutModeCompact || !compact.singleColumn() {
+		t.Fatalf("compact mode = %q", compact.mode)
+	}
+	if compact.preview.h < 3 || compact.projects.h+compact.preview.h != 5 {
+		t.Fatalf("compact layout = %+v, want a preview strip of at least 3 rows", compact)
+	}
+	if compact.preview.y != compact.projects.h || compact.preview.w != 60 {
+		t.Fatalf("compact preview = %+v, want a full-width bottom strip", compact.preview)
+	}
+}
+
+func TestHideProjectsKeyCollapsesAndRestoresProjectsPane(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp"}})
+	press := func(ev *tcell.EventKey) {
+		t.Helper()
+		if _, err := handleKey(context.Background(), screen, state, Options{}, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	press(tcell.NewEventKey(tcell.KeyRune, 'p', 0))
+	if !state.hideProjects || state.focus != "sessions" {
+		t.Fatalf("after p: hideProjects=%v focus=%q", state.hideProjects, state.focus)
+	}
+	hidden := computeLayout(screen, 1, state.layoutOptions())
+	if hidden.projects.w != 0 || hidden.sessions.x != 0 || hidden.preview.x+hidden.preview.w != 160 {
+		t.Fatalf("hidden layout = %+v, want sessions and preview tiling the screen", hidden)
+	}
+	if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+		t.Fatalf("draw with hidden projects: %v", err)
+	}
+
+	press(tcell.NewEventKey(tcell.KeyTab, 0, 0))
+	press(tcell.NewEventKey(tcell.KeyTab, 0, 0))
+	if state.focus != "sessions" {
+		t.Fatalf("Tab from preview focused %q while projects are hidden", state.focus)
+	}
+
+	press(tcell.NewEventKey(tcell.KeyRune, 'h', 0))
+	if state.hideProjects || state.focus != "projects" {
+		t.Fatalf("after h: hideProjects=%v focus=%q", state.hideProjects, state.focus)
+	}
+	if shown := computeLayout(screen, 1, state.layoutOptions()); shown.projects.w == 0 || shown.sessions.x != shown.projects.w {
+		t.Fatalf("restored layout = %+v", shown)
+	}
+
+	state.layoutMode = layoutMode2Col
+	state.hideProjects = true
+	stacked := computeLayout(screen, 1, state.layoutOptions())
+	if stacked.sessions.w != 160 || stacked.preview.w != 160 || stacked.preview.y != stacked.sessions.h {
+		t.Fatalf("hidden 2col layout = %+v, want full-width stacked panes", stacked)
+	}
+}
+
+func TestFlatSessionsListsAllProjectsNewestFirst(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+	now := time.Now()
+	state := newTestState([]codexhistory.Project{
+		{Key: "alpha", Path: "/tmp/alpha", Sessions: []codexhistory.Session{
+			{SessionID: "11111111-1111-1111-1111-111111111111", FirstPrompt: "old alpha", ModifiedAt: now.Add(-2 * time.Hour)},
+		}},
+		{Key: "beta", Path: "/tmp/beta", Sessions: []codexhistory.Session{
+			{SessionID: "22222222-2222-2222-2222-222222222222", FirstPrompt: "new beta", ModifiedAt: now},
+		}},
+	})
+	press := func(ev *tcell.EventKey) *Selection {
+		t.Helper()
+		selection, err := handleKey(context.Background(), screen, state, Options{}, ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return selection
+	}
+
+	press(tcell.NewEventKey(tcell.KeyRune, 'a', 0))
+	if !state.flatSessions || state.focus != "sessions" || !state.projectsHidden() {
+		t.Fatalf("after a: flat=%v focus=%q", state.flatSessions, state.focus)
+	}
+	projects := visibleProjectItems(state, Options{})
+	items := visibleSessionItems(state, selectedProject(projects, 0))
+	if len(items) != 3 {
+		t.Fatalf("flat items = %#v, want new agent plus both sessions", items)
+	}
+	if !strings.Contains(items[1].label, "new beta") || !strings.HasSuffix(items[1].label, "  /tmp/beta") {
+		t.Fatalf("first flat row = %q, want the newest session with its project", items[1].label)
+	}
+	if !strings.HasSuffix(items[2].label, "  /tmp/alpha") {
+		t.Fatalf("second flat row = %q", items[2].label)
+	}
+
+	state.sessionState.selected = 2
+	selection := press(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if selection == nil || selection.Project.Path != "/tmp/alpha" || selection.Session.FirstPrompt != "old alpha" {
+		t.Fatalf("selection = %#v, want the alpha session in its own project", selection)
+	}
+
+	press(tcell.NewEventKey(tcell.KeyRune, 'h', 0))
+	if state.flatSessions || state.focus != "projects" {
+		t.Fatalf("after h: flat=%v focus=%q", state.flatSessions, state.focus)
+	}
+}
+
+func TestRecentSessionsEntryListsNewestAcrossProjects(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+	now := time.Now()
+	state := newTestState([]codexhistory.Project{
+		{Key: "alpha", Path: "/tmp/alpha", Sessions: []codexhistory.Session{
+			{SessionID: "11111111-1111-1111-1111-111111111111", FirstPrompt: "old alpha", ModifiedAt: now.Add(-2 * time.Hour)},
+			{SessionID: "33333333-3333-3333-3333-333333333333", FirstPrompt: "mid alpha", ModifiedAt: now.Add(-time.Hour)},
+		}},
+		{Key: "beta", Path: "/tmp/beta", Sessions: []codexhistory.Session{
+			{SessionID: "22222222-2222-2222-2222-222222222222", FirstPrompt: "new beta", ModifiedAt: now},
+		}},
+	})
+	opts := Options{RecentSessions: 2}
+
+	projects := visibleProjectItems(state, opts)
+	if len(projects) != 3 || projects[0].label != "Recent sessions" {
+		t.Fatalf("projects = %#v, want Recent sessions first", projects)
+	}
+	items := visibleSessionItems(state, projects[0].project)
+	if len(items) != 3 {
+		t.Fatalf("recent items = %#v, want new agent plus the two newest sessions", items)
+	}
+	if !strings.Contains(items[1].label, "new beta") || !strings.HasSuffix(items[1].label, "  /tmp/beta") {
+		t.Fatalf("first recent row = %q", items[1].label)
+	}
+	if !strings.Contains(items[2].label, "mid alpha") {
+		t.Fatalf("second recent row = %q", items[2].label)
+	}
+
+	state.focus = "sessions"
+	state.sessionState.selected = 2
+	selection, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection == nil || selection.Project.Path != "/tmp/alpha" || selection.Session.FirstPrompt != "mid alpha" {
+		t.Fatalf("selection = %#v, want the alpha session in its own project", selection)
+	}
+
+	withCurrent := visibleProjectItems(state, Options{RecentSessions: 2, DefaultCwd: "/tmp/beta"})
+	if !withCurrent[0].isCurrent || withCurrent[1].label != "Recent sessions" {
+		t.Fatalf("with a current project = %#v, want Recent sessions right after it", withCurrent)
+	}
+}
+
+func TestSubagentKeyHidesPromotedAndAttachedSubagents(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+	state := newTestState([]codexhistory.Project{
+		{Key: "alpha", Path: "/tmp/alpha", Sessions: []codexhistory.Session{{
+			SessionID: "11111111-1111-1111-1111-111111111111",
+			Summary:   "main",
+			Subagents: []codexhistory.SubagentSession{{SessionID: "sub-1", AgentID: "review"}},
+		}}},
+		{Key: codexhistory.UnknownProjectKey, Sessions: []codexhistory.Session{{
+			SessionID:        "22222222-2222-2222-2222-222222222222",
+			Summary:          "[review subagent]",
+			PromotedSubagent: true,
+		}}},
+	})
+	state.expandedSessions["11111111-1111-1111-1111-111111111111"] = true
+
+	if got := len(visibleProjectItems(state, Options{})); got != 2 {
+		t.Fatalf("projects before s = %d, want 2", got)
+	}
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 's', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	projects := visibleProjectItems(state, Options{})
+	if len(projects) != 1 || projects[0].project.Path != "/tmp/alpha" {
+		t.Fatalf("projects after s = %#v, want only alpha", projects)
+	}
+	items := visibleSessionItems(state, projects[0].project)
+	if len(items) != 2 || strings.Contains(items[1].label, "[-]") || strings.Contains(items[1].label, "[+]") {
+		t.Fatalf("session items after s = %#v, want the main session without subagents", items)
+	}
+}
+
+func TestFailedOnlyKeyFiltersAndMarksSessionsWithErrors(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+	state := newTestState([]codexhistory.Project{
+		{Key: "alpha", Path: "/tmp/alpha", Sessions: []codexhistory.Session{
+			{SessionID: "11111111-1111-1111-1111-111111111111", Summary: "broke", HadError: true},
+			{SessionID: "22222222-2222-2222-2222-222222222222", Summary: "fine"},
+		}},
+		{Key: "beta", Path: "/tmp/beta", Sessions: []codexhistory.Session{
+			{SessionID: "33333333-3333-3333-3333-333333333333", Summary: "also fine"},
+		}},
+	})
+
+	projects := visibleProjectItems(state, Options{})
+	items := visibleSessionItems(state, projects[0].project)
+	if len(items) != 3 || !strings.Contains(items[1].label, "! broke") || strings.Contains(items[2].label, "!") {
+		t.Fatalf("session items = %#v, want only the failed session marked", items)
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'u', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	projects = visibleProjectItems(state, Options{})
+	if len(projects) != 1 || projects[0].project.Path != "/tmp/alpha" {
+		t.Fatalf("projects after u = %#v, want only alpha", projects)
+	}
+	items = visibleSessionItems(state, projects[0].project)
+	if len(items) != 2 || items[1].session.SessionID != "11111111-1111-1111-1111-111111111111" {
+		t.Fatalf("session items after u = %#v, want only the failed session", items)
+	}
+	state.flashMessage = ""
+	if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if status := readScreenLine(screen, 29); !strings.Contains(status, "Errors only (u: all)") {
+		t.Fatalf("status = %q, want the errors-only note", status)
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'u', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	if got := len(visibleProjectItems(state, Options{})); got != 2 {
+		t.Fatalf("projects after second u = %d, want 2", got)
+	}
+}
+
+func TestComputeLayoutForcedModeFallsBackWhenTooSmall(t *testing.T) {
+	wide := newTestScreen(t, 100, 30)
+	if got := computeLayout(wide, 1, layoutOptions{mode: layoutMode3Col}).mode; got != layoutMode3Col {
+		t.Fatalf("forced 3col on 100 cols = %q", got)
+	}
+	narrow := newTestScreen(t, 40, 30)
+	if got := computeLayout(narrow, 1, layoutOptions{mode: layoutMode3Col}).mode; got != layoutMode1Col {
+		t.Fatalf("forced 3col on 40 cols = %q, want auto fallback to 1col", got)
+	}
+}
+
+func TestLayoutModeKeyCyclesAndPersists(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp"}})
+	state.layoutMode = normalizeLayoutMode("")
+	var persisted []string
+	opts := Options{PersistLayoutMode: func(mode string) error {
+		persisted = append(persisted, mode)
+		return nil
+	}}
+	for range layoutModeCycle {
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'm', 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := append(append([]string(nil), layoutModeCycle[1:]...), layoutModeAuto)
+	if !reflect.DeepEqual(persisted, want) {
+		t.Fatalf("persisted = %v, want %v", persisted, want)
+	}
+	if state.layoutMode != layoutModeAuto {
+		t.Fatalf("layoutMode = %q, want auto after a full cycle", state.layoutMode)
+	}
+}
+
+func TestLoadProgressReporterFeedsLoadingText(t *testing.T) {
+	if LoadProgressReporter(context.Background()) != nil {
+		t.Fatal("expected no reporter outside the TUI load")
+	}
+	state := newTestState(nil)
+	state.loadProgress = &loadProgress{}
+	if got := loadingSecondaryText(state); got != "Large histories can take a while." {
+		t.Fatalf("text before progress = %q", got)
+	}
+	report := LoadProgressReporter(context.WithValue(context.Background(), loadProgressKey{}, state.loadProgress))
+	if report == nil {
+		t.Fatal("expected a reporter")
+	}
+	report(5, 20)
+	if got := loadingSecondaryText(state); got != "Read 5/20 session files [#####---------------]" {
+		t.Fatalf("text = %q", got)
+	}
+}
+
+func TestEmptySessionsKeyReloadsWithToggle(t *testing.T) {
+	screen := newTestScreen(t, 120, 40)
+	state := newTestState(nil)
+	var seen []bool
+	opts := Options{LoadProjects: func(ctx context.Context) ([]codexhistory.Project, error) {
+		seen = append(seen, ShowEmptySessions(ctx))
+		return []codexhistory.Project{{
+			Key:      "one",
+			Path:     "/tmp/one",
+			Sessions: []codexhistory.Session{{SessionID: "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}},
+		}}, nil
+	}}
+	for i := 0; i < 2; i++ {
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'x', 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(seen, []bool{true, false}) {
+		t.Fatalf("LoadProjects saw show-empty = %v, want [true false]", seen)
+	}
+	items := buildSessionItems(state.projects[0], nil, "")
+	if !strings.Contains(items[1].label, "(empty session)") {
+		t.Fatalf("label = %q, want (empty session)", items[1].label)
+	}
+}
+
+func TestLoadMoreKeyDoublesSessionLimit(t *testing.T) {
+	screen := newTestScreen(t, 160, 40)
+	state := newTestState(nil)
+	var seen []int
+	opts := Options{LoadProjects: func(ctx context.Context) ([]codexhistory.Project, error) {
+		seen = append(seen, SessionLimit(ctx))
+		return nil, nil
+	}}
+
+	press := func() {
+		t.Helper()
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, '+', 0)); err != nil {
+			t.Fatal(err)
+		}
+		if state.flashTimer != nil {
+			state.flashTimer.Stop()
+		}
+	}
+	press()
+	if len(seen) != 0 {
+		t.Fatalf("+ reloaded without a limit: %v", seen)
+	}
+
+	state.sessionLimit = 50
+	press()
+	press()
+	if !reflect.DeepEqual(seen, []int{100, 200}) {
+		t.Fatalf("LoadProjects saw limits %v, want [100 200]", seen)
+	}
+	state.flashUntil = time.Time{}
+	if err := draw(screen, state, opts, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	_, h := screen.Size()
+	status := readScreenLine(screen, h-2) + readScreenLine(screen, h-1)
+	if !strings.Contains(status, "Latest 200 files (+: more)") {
+		t.Fatalf("status = %q, want the load-more hint", status)
+	}
+}
+
+func TestDrawUsesWarningBordersWhenAAAEnabled(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp/one"}})
+	borderFg := func() tcell.Color {
+		if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+			t.Fatal(err)
+		}
+		_, _, style, _ := screen.GetContent(0, 1)
+		fg, _, _ := style.Decompose()
+		return fg
+	}
+	if fg := borderFg(); fg == tcell.ColorYellow {
+		t.Fatalf("border should use the default color when AAA is off")
+	}
+	state.aaaEnabled = true
+	if fg := borderFg(); fg != tcell.ColorYellow {
+		t.Fatalf("border fg = %v, want yellow when AAA is on", fg)
+	}
+}
+
+func TestDrawMonochromeUsesPlainStylesAndSelectionMarker(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp/one"}, {Key: "two", Path: "/tmp/two"}})
+	state.aaaEnabled = true
+	state.monochrome = true
+	if err := draw(screen, state, Options{Version: "1.0.0"}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	w, h := screen.Size()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if _, _, style, _ := screen.GetContent(x, y); style != tcell.StyleDefault {
+				t.Fatalf("cell (%d,%d) style = %v, want plain in monochrome mode", x, y, style)
+			}
+		}
+	}
+	found := false
+	for y := 0; y < h; y++ {
+		if strings.Contains(readScreenLine(screen, y), "> ") && strings.Contains(readScreenLine(screen, y), "one") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatal("selected project should be marked with > in monochrome mode")
+	}
+}
+
+func TestDrawUsesActiveTheme(t *testing.T) {
+	for _, theme := range []*Theme{nil, &highContrastTheme, &solarizedTheme} {
+		want := theme
+		if want == nil {
+			want = &defaultTheme
+		}
+		t.Run(want.Name, func(t *testing.T) {
+			screen := newTestScreen(t, 120, 30)
+			state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp/one"}})
+			state.theme = theme
+			if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+				t.Fatal(err)
+			}
+			_, h := screen.Size()
+			for _, tc := range []struct {
+				name string
+				x, y int
+				want tcell.Style
+			}{
+				{"focused border", 0, 1, want.FocusBorder},
+				{"selected row", 1, 1, want.Selected},
+				{"status bar", 0, h - 1, want.Status},
+			} {
+				if _, _, style, _ := screen.GetContent(tc.x, tc.y); style != tc.want {
+					t.Fatalf("%s style = %v, want %v", tc.name, style, tc.want)
+				}
+			}
+		})
+	}
+	if defaultTheme.Status != tcell.StyleDefault.Reverse(true) || defaultTheme.Text != tcell.StyleDefault {
+		t.Fatal("default theme should keep the plain reverse-video look")
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	if names := ThemeNames(); len(names) == 0 || names[0] != DefaultThemeName {
+		t.Fatalf("ThemeNames() = %v, want %q first", names, DefaultThemeName)
+	}
+	for _, name := range []string{"", " default ", "DEFAULT"} {
+		if theme, ok := LookupTheme(name); !ok || theme != &defaultTheme {
+			t.Fatalf("LookupTheme(%q) = %v, %v, want default", name, theme, ok)
+		}
+	}
+	if theme, ok := LookupTheme("Solarized"); !ok || theme != &solarizedTheme {
+		t.Fatalf("LookupTheme(Solarized) = %v, %v, want solarized", theme, ok)
+	}
+	if _, ok := LookupTheme("neon"); ok {
+		t.Fatal("LookupTheme(neon) should fail")
+	}
+}
+
+func TestDrawShowsCodexVersionNextToHelperVersion(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp/one"}})
+	if err := draw(screen, state, Options{Version: "0.1.13", CodexVersion: "0.46.0"}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	_, h := screen.Size()
+	if line := readScreenLine(screen, h-1); !strings.Contains(line, "codex v0.46.0  v0.1.13") {
+		t.Fatalf("status line = %q, want codex and helper versions", line)
+	}
+
+	if err := draw(screen, state, Options{Version: "0.1.13"}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if line := readScreenLine(screen, h-1); strings.Contains(line, "codex v") {
+		t.Fatalf("status line = %q, want no codex version when unknown", line)
+	}
+
+	state.codexVersion = "0.47.0"
+	if err := draw(screen, state, Options{Version: "0.1.13"}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if line := readScreenLine(screen, h-1); !strings.Contains(line, "codex v0.47.0  v0.1.13") {
+		t.Fatalf("status line = %q, want the background-detected codex version", line)
+	}
+}
+
+func TestDrawCollapsesStatusBarOnShortTerminals(t *testing.T) {
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp/one"}})
+	state.aaaEnabled = true
+
+	tall := newTestScreen(t, 60, 40)
+	if err := draw(tall, state, Options{Version: "1.0.0"}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if state.statusHeight < 2 {
+		t.Fatalf("statusHeight = %d, want the full multi-line status on a tall terminal", state.statusHeight)
+	}
+
+	short := newTestScreen(t, 60, 9)
+	if err := draw(short, state, Options{Version: "1.0.0"}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if state.statusHeight != 1 {
+		t.Fatalf("statusHeight = %d, want a single compact line", state.statusHeight)
+	}
+	line := readScreenLine(short, 8)
+	for _, want := range []string{"Tab: switch  q: quit", "[!] AAA on", "v1.0.0"} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("compact status %q missing %q", line, want)
+		}
+	}
+}
+
+func TestRenderSessionRowsShowsLastReplyLine(t *testing.T) {
+	items := []sessionItem{
+		{label: "(New Agent)", kind: sessionItemNew},
+		{label: "fix tests", kind: sessionItemMain, session: codexhistory.Session{LastAssistantSnippet: "All tests pass now."}},
+		{label: "other", kind: sessionItemMain},
+	}
+	rows := renderSessionRows(items, true, listState{selected: 1}, 10, true)
+	if len(rows) != 6 {
+		t.Fatalf("rows = %d, want two per item", len(rows))
+	}
+	if !strings.Contains(rows[3].label, "All tests pass now.") || !rows[3].selected {
+		t.Fatalf("reply row = %+v, want selected snippet", rows[3])
+	}
+	if rows[1].label != "" || rows[5].label != "" {
+		t.Fatalf("items without a reply should get a blank second line: %+v", rows)
+	}
+
+	compact := renderSessionRows(items, true, listState{selected: 1}, 10, false)
+	if len(compact) != 3 {
+		t.Fatalf("compact rows = %d, want one per item", len(compact))
+	}
+	if got := sessionListCapacity(5, true); got != 2 {
+		t.Fatalf("capacity = %d, want 2", got)
+	}
+}
+
+func TestLastReplyKeyTogglesAndPersists(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	state := newTestState([]codexhistory.Project{{Key: "one", Path: "/tmp"}})
+	var persisted []bool
+	opts := Options{PersistShowLastReply: func(show bool) error {
+		persisted = append(persisted, show)
+		return nil
+	}}
+	for i := 0; i < 2; i++ {
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'e', 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(persisted, []bool{true, false}) || state.showLastReply {
+		t.Fatalf("persisted = %v, showLastReply = %v", persisted, state.showLastReply)
+	}
+}
+
+func TestSessionTimeKeyCyclesCreatedAndModifiedLabels(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	created := time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC)
+	modified := time.Date(2026, 3, 4, 18, 5, 0, 0, time.UTC)
+	project := codexhistory.Project{Key: "one", Path: "/tmp/one", Sessions: []codexhistory.Session{{
+		SessionID:  "11111111-1111-1111-1111-111111111111",
+		Summary:    "work",
+		CreatedAt:  created,
+		ModifiedAt: modified,
+	}}}
+	state := newTestState([]codexhistory.Project{project})
+
+	want := []string{
+		"(2026-01-02 09:30)",
+		"(2026-03-04 18:05)",
+	}
+	if label := buildSessionItems(project, nil, state.sessionTimeMode)[1].label; !strings.Contains(label, want[1]) || strings.Contains(label, want[0]) {
+		t.Fatalf("default label = %q, want modified time only", label)
+	}
+	for _, tc := range []struct {
+		mode  string
+		label string
+	}{
+		{sessionTimeCreated, "(started 2026-01-02 09:30)"},
+		{sessionTimeBoth, "(started 2026-01-02 09:30, modified 2026-03-04 18:05)"},
+		{sessionTimeModified, "(2026-03-04 18:05)"},
+	} {
+		if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 't', 0)); err != nil {
+			t.Fatal(err)
+		}
+		if state.sessionTimeMode != tc.mode {
+			t.Fatalf("mode = %q, want %q", state.sessionTimeMode, tc.mode)
+		}
+		if label := buildSessionItems(project, nil, state.sessionTimeMode)[1].label; !strings.Contains(label, tc.label) {
+			t.Fatalf("label in %s mode = %q, want %q", tc.mode, label, tc.label)
+		}
+	}
+}
+
+func TestLocationConvertsListAndPreviewTimes(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	modified := time.Date(2026, 3, 4, 18, 5, 0, 0, time.UTC)
+	session := codexhistory.Session{
+		SessionID:  "11111111-1111-1111-1111-111111111111",
+		Summary:    "work",
+		CreatedAt:  modified,
+		ModifiedAt: modified,
+	}
+	project := codexhistory.Project{Key: "one", Path: "/tmp/one", Sessions: []codexhistory.Session{session}}
+
+	if label := buildSessionItemsIn(project, nil, "", tokyo)[1].label; !strings.Contains(label, "(2026-03-05 03:05)") {
+		t.Fatalf("label = %q, want time in JST", label)
+	}
+
+	state := newTestState([]codexhistory.Project{project})
+	lines := strings.Join(buildPreviewLines(project, &session, nil, false, state, "", Options{Location: tokyo}), "\n")
+	if !strings.Contains(lines, "  Modified: 2026-03-05T03:05:00+09:00") {
+		t.Fatalf("preview lines missing modified time in JST:\n%s", lines)
+	}
+}
+
+func TestFilePathKeyAddsDimmedRolloutPathToPreview(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	project := codexhistory.Project{Key: "one", Path: "/tmp/one"}
+	session := codexhistory.Session{SessionID: "sess-1", FilePath: "/home/u/.codex/sessions/rollout-1.jsonl"}
+	subagent := codexhistory.SubagentSession{AgentID: "agent-1", FilePath: "/home/u/.codex/sessions/rollout-2.jsonl"}
+	state := newTestState([]codexhistory.Project{project})
+
+	lines := buildPreviewLines(project, &session, nil, false, state, "", Options{})
+	if strings.Contains(strings.Join(lines, "\n"), session.FilePath) {
+		t.Fatalf("file path should be hidden by default: %q", lines)
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'f', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !state.showFilePaths {
+		t.Fatal("f should turn file paths on")
+	}
+	lines = buildPreviewLines(project, &session, nil, false, state, "body", Options{})
+	dimmed := previewFilePathLines(lines)
+	if len(dimmed) != 1 || lines[dimmed[0]] != "  File: "+session.FilePath {
+		t.Fatalf("dimmed lines = %v in %q, want the session file line", dimmed, lines)
+	}
+	lines = buildPreviewLines(project, &session, &subagent, false, state, "", Options{})
+	if dimmed := previewFilePathLines(lines); len(dimmed) != 1 || lines[dimmed[0]] != "  File: "+subagent.FilePath {
+		t.Fatalf("dimmed lines = %v in %q, want the subagent file line", dimmed, lines)
+	}
+}
+
+func TestCompactPreviewHeaderKeyFoldsDetailsIntoOneLine(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	project := codexhistory.Project{Key: "one", Path: "/tmp/one"}
+	session := codexhistory.Session{
+		SessionID:    "sess-1",
+		FirstPrompt:  "fix the build",
+		MessageCount: 12,
+		Subagents:    []codexhistory.SubagentSession{{AgentID: "agent-1"}, {AgentID: "agent-2"}},
+		CreatedAt:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		ModifiedAt:   time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC),
+		FilePath:     "/home/u/.codex/sessions/rollout-1.jsonl",
+	}
+	subagent := codexhistory.SubagentSession{AgentID: "agent-1", MessageCount: 1, FilePath: "/home/u/.codex/sessions/rollout-2.jsonl"}
+	state := newTestState([]codexhistory.Project{project})
+	opts := Options{Location: time.UTC}
+
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'b', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !state.compactPreviewHeader {
+		t.Fatal("b should turn the compact header on")
+	}
+	lines := buildPreviewLines(project, &session, nil, false, state, "body", opts)
+	want := []string{"12 msgs · 2 subagents · created 2026-03-01 09:00 · modified 2026-03-02 17:30", "", "body"}
+	if !slices.Equal(lines, want) {
+		t.Fatalf("compact preview = %q, want %q", lines, want)
+	}
+	lines = buildPreviewLines(project, &session, &subagent, false, state, "", opts)
+	if want := []string{"subagent agent-1 · 1 msg"}; !slices.Equal(lines, want) {
+		t.Fatalf("compact subagent preview = %q, want %q", lines, want)
+	}
+
+	state.showFilePaths = true
+	lines = buildPreviewLines(project, &session, nil, false, state, "body", opts)
+	if dimmed := previewFilePathLines(lines); len(dimmed) != 1 || lines[dimmed[0]] != "  File: "+session.FilePath {
+		t.Fatalf("dimmed lines = %v in %q, want the session file line", dimmed, lines)
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'b', 0)); err != nil {
+		t.Fatal(err)
+	}
+	lines = buildPreviewLines(project, &session, nil, false, state, "body", opts)
+	if joined := strings.Join(lines, "\n"); !strings.Contains(joined, "Session:\n  ID: sess-1") || !strings.Contains(joined, "  Messages: 12") {
+		t.Fatalf("b again should restore the detail block:\n%s", joined)
+	}
+}
+
+func TestMetadataOnlyKeySkipsTranscriptReads(t *testing.T) {
+	isolatePreviewPersistentCache(t)
+	path := filepath.Join(t.TempDir(), "sess.jsonl")
+	if err := os.WriteFile(path, []byte(`{"timestamp":"2026-01-01T00:00:00Z","type":"event_msg","payload":{"type":"agent_message","message":"transcript text"}}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	screen := newTestScreen(t, 120, 20)
+	project := codexhistory.Project{
+		Key:      "one",
+		Path:     "/tmp/one",
+		Sessions: []codexhistory.Session{{SessionID: "sess-1", MessageCount: 3, FilePath: path}},
+	}
+	state := newTestState([]codexhistory.Project{project})
+	state.focus = "sessions"
+	state.lastListFocus = "sessions"
+	state.sessionState.selected = 1
+	cacheKey := previewCacheKey(&project.Sessions[0], nil)
+	previewCh := make(chan previewEvent, 1)
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'z', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !state.metadataOnly {
+		t.Fatal("z should turn metadata-only on")
+	}
+	if err := draw(screen, state, Options{}, previewCh); err != nil {
+		t.Fatalf("draw error: %v", err)
+	}
+	if _, ok := state.previewLoading[cacheKey]; ok {
+		t.Fatal("metadata-only view should not start reading the transcript")
+	}
+	state.previewCache[cacheKey] = previewCacheEntry{text: "transcript text"}
+	lines := strings.Join(wrappedPreviewLinesForSelection(state, project, &project.Sessions[0], nil, false, Options{}, 80), "\n")
+	if strings.Contains(lines, "transcript text") || !strings.Contains(lines, "  ID: sess-1") || !strings.Contains(lines, "  Messages: 3") {
+		t.Fatalf("metadata-only preview should show just the details:\n%s", lines)
+	}
+
+	delete(state.previewCache, cacheKey)
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'z', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := draw(screen, state, Options{}, previewCh); err != nil {
+		t.Fatalf("draw error: %v", err)
+	}
+	if _, ok := state.previewLoading[cacheKey]; !ok {
+		t.Fatal("z again should resume loading the selected transcript")
+	}
+	select {
+	case ev := <-previewCh:
+		if ev.cacheKey != cacheKey || !strings.Contains(ev.text, "transcript text") {
+			t.Fatalf("preview event = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for preview event")
+	}
+}
+
+func TestEnsurePreviewDebouncesSelectionChanges(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte(`{"timestamp":"2026-01-01T00:00:00Z","type":"event_msg","payload":{"type":"agent_message","message":"hi"}}`+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	screen := newTestScreen(t, 80, 24)
+	state := newTestState(nil)
+	state.previewDebounce = time.Hour
+	previewCh := make(chan previewEvent, 2)
+
+	firstSession := &codexhistory.Session{SessionID: "first", FilePath: first}
+	ensurePreview(screen, state, Options{}, firstSession, nil, previewCh)
+	if _, ok := state.previewLoading[previewCacheKey(firstSession, nil)]; !ok {
+		t.Fatal("first selection should load without waiting")
+	}
+
+	secondSession := &codexhistory.Session{SessionID: "second", FilePath: second}
+	ensurePreview(screen, state, Options{}, secondSession, nil, previewCh)
+	if _, ok := state.previewLoading[previewCacheKey(secondSession, nil)]; ok {
+		t.Fatal("selection change should wait for the debounce delay")
+	}
+	if state.previewDebounceTimer == nil {
+		t.Fatal("expected a debounce timer to retry the preview")
+	}
+	state.previewDebounceTimer.Stop()
+
+	state.previewPendingSince = time.Now().Add(-2 * time.Hour)
+	ensurePreview(screen, state, Options{}, secondSession, nil, previewCh)
+	if _, ok := state.previewLoading[previewCacheKey(secondSession, nil)]; !ok {
+		t.Fatal("settled selection should start loading")
+	}
+}
+
+func TestPreviewDebounceTimerPostsPreviewEvent(t *testing.T) {
+	screen := newTestScreen(t, 80, 24)
+	state := newTestState(nil)
+	state.done = make(chan struct{})
+	state.previewDebounce = 20 * time.Millisecond
+
+	if !previewSelectionSettled(screen, state, "first") {
+		t.Fatal("first selection should settle at once")
+	}
+	if previewSelectionSettled(screen, state, "second") {
+		t.Fatal("selection change should wait for the debounce delay")
+	}
+	polled := make(chan tcell.Event, 1)
+	go func() { polled <- screen.PollEvent() }()
+	select {
+	case ev := <-polled:
+		if ui, ok := ev.(*uiEvent); !ok || ui.kind != "preview" {
+			t.Fatalf("event = %#v, want a preview uiEvent", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("debounce timer did not post a preview event")
+	}
+}
+
+func TestEnsurePreviewBoundsConcurrentReads(t *testing.T) {
+	dir := t.TempDir()
+	const burst = 10
+	const limit = 2
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	release := make(chan struct{})
+	prevRead := readSessionPreviewText
+	readSessionPreviewText = func(string, int, int) (string, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return "ok", nil
+	}
+	t.Cleanup(func() { readSessionPreviewText = prevRead })
+
+	screen := newTestScreen(t, 80, 24)
+	state := newTestState(nil)
+	state.previewReadSlots = make(chan struct{}, limit)
+	previewCh := make(chan previewEvent, burst)
+	for i := 0; i < burst; i++ {
+		path := filepath.Join(dir, "s"+strconv.Itoa(i)+".jsonl")
+		if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ensurePreview(screen, state, Options{}, &codexhistory.Session{SessionID: "s" + strconv.Itoa(i), FilePath: path}, nil, previewCh)
+	}
+	if len(state.previewLoading) != burst {
+		t.Fatalf("previewLoading = %d, want %d", len(state.previewLoading), burst)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for i := 0; i < burst; i++ {
+		select {
+		case <-previewCh:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for preview %d", i)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > limit {
+		t.Fatalf("peak concurrent reads = %d, want <= %d", peak, limit)
+	}
+}
+
+func TestPreviewConversationToggleShowsPrompts(t *testing.T) {
+	lines := []string{
+		`{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"hello"}]}}`,
+		`{"timestamp":"2026-01-01T00:02:00Z","type":"response_item","payload":{"type":"function_call","name":"read","arguments":"{}"}}`,
+		`{"timestamp":"2026-01-01T00:03:00Z","type":"response_item","payload":{"type":"function_call_output","output":"file data"}}`,
+		`{"timestamp":"2026-01-01T00:04:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hi there"}]}}`,
+	}
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session := &codexhistory.Session{SessionID: "s1", FilePath: path}
+	screen := newTestScreen(t, 80, 24)
+	state := newTestState(nil)
+	previewCh := make(chan previewEvent, 1)
+	load := func() string {
+		t.Helper()
+		ensurePreview(screen, state, Options{}, session, nil, previewCh)
+		select {
+		case ev := <-previewCh:
+			applyPreviewEvent(state, ev)
+			return previewTextForItem(state, session, nil)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for preview")
+			return ""
+		}
+	}
+
+	if text := load(); text != "Codex answer:\nhi there" {
+		t.Fatalf("default preview = %q", text)
+	}
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone)); err != nil {
+		t.Fatal(err)
+	}
+	if text := load(); text != "User prompt:\nhello\n\nCodex answer:\nhi there" {
+		t.Fatalf("conversation preview = %q", text)
+	}
+	if view := captureView(state, Options{}); !view.Conversation {
+		t.Fatal("conversation mode not captured in the saved view")
+	}
+}
+
+func TestPreviewDiffToggleShowsSubagentFileChanges(t *testing.T) {
+	lines := []string{
+		`{"timestamp":"2026-01-01T00:01:00Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Update File: app.go\n@@\n-old\n+new\n*** End Patch"}}`,
+		`{"timestamp":"2026-01-01T00:02:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"patched"}]}}`,
+	}
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session := &codexhistory.Session{SessionID: "parent"}
+	subagent := &codexhistory.SubagentSession{SessionID: "child", ParentSessionID: "parent", FilePath: path}
+	screen := newTestScreen(t, 80, 24)
+	state := newTestState(nil)
+	previewCh := make(chan previewEvent, 1)
+	load := func() string {
+		t.Helper()
+		ensurePreview(screen, state, Options{}, session, subagent, previewCh)
+		select {
+		case ev := <-previewCh:
+			applyPreviewEvent(state, ev)
+			return previewTextForItem(state, session, subagent)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for preview")
+			return ""
+		}
+	}
+
+	if text := load(); text != "Codex answer:\npatched" {
+		t.Fatalf("default preview = %q", text)
+	}
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone)); err != nil {
+		t.Fatal(err)
+	}
+	if text := load(); text != "Files changed:\n  update app.go\n\nupdate app.go\n@@\n-old\n+new" {
+		t.Fatalf("diff preview = %q", text)
+	}
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone)); err != nil {
+		t.Fatal(err)
+	}
+	if text := load(); text != "Codex answer:\npatched" {
+		t.Fatalf("preview after toggling diff mode off = %q", text)
+	}
+}
+
+func TestLargeSessionPreviewLoadsOnRequest(t *testing.T) {
+	line := `{"timestamp":"2026-01-01T00:04:00Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hi there"}]}}`
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session := codexhistory.Session{SessionID: "s1", FilePath: path, MessageCount: 1}
+	state := newTestState([]codexhistory.Project{{Key: "/tmp/proj", Path: "/tmp/proj", Sessions: []codexhistory.Session{session}}})
+	opts := Options{LargeSessionBytes: 64}
+	screen := newTestScreen(t, 80, 24)
+	previewCh := make(chan previewEvent, 1)
+
+	ensurePreview(screen, state, opts, &session, nil, previewCh)
+	if len(state.previewLoading) != 0 {
+		t.Fatal("large session preview loaded without being asked for")
+	}
+	if text := previewTextForItem(state, &session, nil); !strings.Contains(text, "Large session (0.0 MB, 1 messages) - press v to load the preview.") {
+		t.Fatalf("preview text = %q", text)
+	}
+
+	sessions := filterSessions(visibleSessionItems(state, state.projects[0]), "")
+	state.sessionState.selected = slices.IndexFunc(sessions, func(item sessionItem) bool { return item.kind == sessionItemMain })
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone)); err != nil {
+		t.Fatal(err)
+	}
+	ensurePreview(screen, state, opts, &session, nil, previewCh)
+	select {
+	case ev := <-previewCh:
+		applyPreviewEvent(state, ev)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the requested preview")
+	}
+	if text := previewTextForItem(state, &session, nil); text != "Codex answer:\nhi there" {
+		t.Fatalf("requested preview = %q", text)
+	}
+
+	// Negative thresholds always load.
+	other := codexhistory.Session{SessionID: "s2", FilePath: path, MessageCount: 5000}
+	ensurePreview(screen, state, Options{LargeSessionBytes: -1, LargeSessionMessages: -1}, &other, nil, previewCh)
+	if _, loading := state.previewLoading[previewCacheKey(&other, nil)]; !loading {
+		t.Fatal("disabled thresholds should load the preview right away")
+	}
+	<-previewCh
+}
+
+func TestEnsurePreviewReadersExitOnTeardown(t *testing.T) {
+	dir := t.TempDir()
+	const burst = 32
+
+	prevRead := readSessionPreviewText
+	readSessionPreviewText = func(string, int, int) (string, error) {
+		return "ok", nil
+	}
+	t.Cleanup(func() { readSessionPreviewText = prevRead })
+
+	screen := newTestScreen(t, 80, 24)
+	state := newTestState(nil)
+	state.previewReadSlots = make(chan struct{}, 1)
+	done := make(chan struct{})
+	state.done = done
+	// Nobody drains previewCh, like after the main loop has returned.
+	previewCh := make(chan previewEvent, 1)
+
+	baseline := runtime.NumGoroutine()
+	for i := 0; i < burst; i++ {
+		path := filepath.Join(dir, "s"+strconv.Itoa(i)+".jsonl")
+		if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ensurePreview(screen, state, Options{}, &codexhistory.Session{SessionID: "s" + strconv.Itoa(i), FilePath: path}, nil, previewCh)
+	}
+	if len(state.previewLoading) != burst {
+		t.Fatalf("previewLoading = %d, want %d", len(state.previewLoading), burst)
+	}
+	close(done)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("preview goroutines still running after teardown: %d > %d", runtime.NumGoroutine(), baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReadOnlyBlocksSelectionAndToggles(t *testing.T) {
+	screen := newTestScreen(t, 160, 20)
+	session := codexhistory.Session{SessionID: "11111111-1111-1111-1111-111111111111", Summary: "work"}
+	state := newTestState([]codexhistory.Project{{Key: "/tmp", Path: "/tmp", Sessions: []codexhistory.Session{session}}})
+	state.focus = "sessions"
+	state.sessionState.selected = 1
+	opts := Options{
+		ReadOnly: true,
+		PersistAAA: func(bool) error {
+			t.Fatal("read-only mode must not toggle AAA")
+			return nil
+		},
+	}
+
+	for _, ev := range []*tcell.EventKey{
+		tcell.NewEventKey(tcell.KeyEnter, 0, 0),
+		tcell.NewEventKey(tcell.KeyCtrlN, 0, 0),
+		tcell.NewEventKey(tcell.KeyCtrlP, 0, 0),
+		tcell.NewEventKey(tcell.KeyCtrlA, 0, 0),
+		tcell.NewEventKey(tcell.KeyCtrlK, 0, 0),
+	} {
+		selection, err := handleKey(context.Background(), screen, state, opts, ev)
+		if err != nil || selection != nil {
+			t.Fatalf("key %v: selection = %#v, err = %v; want neither", ev.Name(), selection, err)
+		}
+		if state.flashMessage != readOnlyHint {
+			t.Fatalf("key %v: flash = %q, want read-only hint", ev.Name(), state.flashMessage)
+		}
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	if state.proxyEnabled || state.aaaEnabled {
+		t.Fatalf("toggles changed in read-only mode: proxy=%v aaa=%v", state.proxyEnabled, state.aaaEnabled)
+	}
+
+	state.flashMessage = ""
+	state.flashUntil = time.Time{}
+	if err := draw(screen, state, opts, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	_, h := screen.Size()
+	var rendered strings.Builder
+	for y := 0; y < h; y++ {
+		rendered.WriteString(readScreenLine(screen, y) + "\n")
+	}
+	status := rendered.String()
+	if strings.Contains(status, "Proxy mode") || strings.Contains(status, "AAA mode") || strings.Contains(status, "Enter: open") {
+		t.Fatalf("read-only status still offers launch toggles:\n%s", status)
+	}
+}
+
+func TestReadOnlyNeitherSpawnsNorPersists(t *testing.T) {
+	prevStart := startFileManager
+	t.Cleanup(func() { startFileManager = prevStart })
+	startFileManager = func(name string, _ ...string) error {
+		t.Fatalf("read-only mode started %s", name)
+		return nil
+	}
+	screen := newTestScreen(t, 160, 20)
+	session := codexhistory.Session{SessionID: "11111111-1111-1111-1111-111111111111", Summary: "work", FilePath: "/tmp/rollout.jsonl"}
+	state := newTestState([]codexhistory.Project{{Key: "/tmp", Path: "/tmp", Sessions: []codexhistory.Session{session}}})
+	state.focus = "sessions"
+	state.sessionState.selected = 1
+	opts := Options{
+		ReadOnly: true,
+		PersistShowLastReply: func(bool) error {
+			t.Fatal("read-only mode persisted the last-reply toggle")
+			return nil
+		},
+		PersistLayoutMode: func(string) error {
+			t.Fatal("read-only mode persisted the layout mode")
+			return nil
+		},
+		PersistPaneWidthBias: func(int) error {
+			t.Fatal("read-only mode persisted the pane width")
+			return nil
+		},
+	}
+	press := func(r rune) {
+		t.Helper()
+		if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, r, 0)); err != nil {
+			t.Fatalf("key %q: %v", r, err)
+		}
+	}
+
+	press('o')
+	if state.flashMessage != readOnlyHint {
+		t.Fatalf("o: flash = %q, want read-only hint", state.flashMessage)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	showLastReply, layoutMode, bias := state.showLastReply, state.layoutMode, state.paneWidthBias
+	for _, r := range []rune{'e', 'm', '>', '<', '<'} {
+		press(r)
+	}
+	if state.showLastReply == showLastReply || state.layoutMode == layoutMode || state.paneWidthBias == bias {
+		t.Fatalf("view toggles should still apply to this run: lastReply=%v layout=%q bias=%d", state.showLastReply, state.layoutMode, state.paneWidthBias)
+	}
+}
+
+func TestRevealKeyOpensSessionFolder(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	prevStart := startFileManager
+	startFileManager = func(name string, args ...string) error {
+		gotName, gotArgs = name, args
+		return nil
+	}
+	t.Cleanup(func() { startFileManager = prevStart })
+
+	screen := newTestScreen(t, 160, 20)
+	filePath := filepath.Join("/tmp", "rollouts", "sess-1.jsonl")
+	session := codexhistory.Session{SessionID: "sess-1", FilePath: filePath}
+	state := newTestState([]codexhistory.Project{{Key: "/tmp", Path: "/tmp", Sessions: []codexhistory.Session{session}}})
+	state.focus = "sessions"
+	state.sessionState.selected = 1
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'o', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	wantName, wantArgs := "xdg-open", []string{filepath.Dir(filePath)}
+	switch runtime.GOOS {
+	case "darwin":
+		wantName, wantArgs = "open", []string{"-R", filePath}
+	case "windows":
+		wantName, wantArgs = "explorer", []string{"/select," + filePath}
+	}
+	if gotName != wantName || !reflect.DeepEqual(gotArgs, wantArgs) {
+		t.Fatalf("started %q %q, want %q %q", gotName, gotArgs, wantName, wantArgs)
+	}
+
+	gotName = ""
+	state.sessionState.selected = 0
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'o', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	if gotName != "" || state.flashMessage != "No session file to show." {
+		t.Fatalf("reveal on the new agent row started %q, flash %q", gotName, state.flashMessage)
+	}
+}
+
+func TestCopyPreviewKeyCopiesPreviewTextAndFlashesStatus(t *testing.T) {
+	var copied string
+	prevCopy := copyToClipboard
+	copyToClipboard = func(_ tcell.Screen, text string) { copied = text }
+	t.Cleanup(func() { copyToClipboard = prevCopy })
+
+	screen := newTestScreen(t, 160, 20)
+	session := codexhistory.Session{SessionID: "sess-1", FilePath: "/tmp/sess-1.jsonl"}
+	state := newTestState([]codexhistory.Project{{Key: "/tmp", Path: "/tmp", Sessions: []codexhistory.Session{session}}})
+	state.focus = "preview"
+	state.lastListFocus = "sessions"
+	state.sessionState.selected = 1
+	state.previewCache[previewCacheKey(&session, nil)] = previewCacheEntry{text: "first\nsecond\nthird\n"}
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyRune, 'y', 0)); err != nil {
+		t.Fatal(err)
+	}
+	if state.flashTimer != nil {
+		state.flashTimer.Stop()
+	}
+	if copied != "first\nsecond\nthird" {
+		t.Fatalf("copied = %q", copied)
+	}
+	if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+		t.Fatal(err)
+	}
+	_, h := screen.Size()
+	if line := readScreenLine(screen, h-1); !strings.Contains(line, "Copied 3 lines") {
+		t.Fatalf("status = %q, want copy confirmation", strings.TrimSpace(line))
+	}
+}
+
+func TestBuildWrappedLinesStripsANSIEscapes(t *testing.T) {
+	lines := buildWrappedLines([]string{"\x1b[31mred\x1b[0m " + strings.Repeat("x", 10)}, 8, defaultTabWidth, 0)
+	want := []string{"red xxxx", "xxxxxx"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("wrapped = %q, want %q", lines, want)
+	}
+
+	screen := newTestScreen(t, 20, 5)
+	drawPreview(screen, &defaultTheme, rect{y: 0, x: 0, h: 3, w: 12}, lines, 0, nil)
+	if got := readScreenLine(screen, 1); !strings.HasPrefix(got, " red xxxx") {
+		t.Fatalf("preview row = %q", got)
+	}
+}
+
+func TestInvalidUTF8KeepsPreviewAligned(t *testing.T) {
+	// A truncated three-byte sequence and a lone 0xff, as a binary paste
+	// would leave them.
+	bad := "ab\xe4\xb8cd\xffef"
+	if got, want := displayWidth(bad), 9; got != want {
+		t.Fatalf("displayWidth(%q) = %d, want %d", bad, got, want)
+	}
+	if got := truncate(bad, 5); displayWidth(got) > 5 {
+		t.Fatalf("truncate = %q, wider than 5 columns", got)
+	}
+
+	lines := buildWrappedLines([]string{bad + "|"}, 40, defaultTabWidth, 0)
+	if len(lines) != 1 || lines[0] != "ab\uFFFD\uFFFDcd\uFFFDef|" {
+		t.Fatalf("wrapped = %q", lines)
+	}
+	screen := newTestScreen(t, 20, 3)
+	writeText(screen, 0, 0, bad+"|", tcell.StyleDefault)
+	if ch, _, _, _ := screen.GetContent(displayWidth(bad), 0); ch != '|' {
+		t.Fatalf("row = %q, want | at column %d", readScreenLine(screen, 0), displayWidth(bad))
+	}
+}
+
+func TestBuildWrappedLinesCapsAtPreviewMaxWidth(t *testing.T) {
+	text := strings.Repeat("word ", 40)
+	capped := buildWrappedLines([]string{text}, 200, defaultTabWidth, 20)
+	if len(capped) < 2 {
+		t.Fatalf("capped = %q, want the text wrapped below the pane width", capped)
+	}
+	for _, line := range capped {
+		if displayWidth(line) > 20 {
+			t.Fatalf("line %q is wider than the 20 column cap", line)
+		}
+	}
+	if narrow := buildWrappedLines([]string{text}, 10, defaultTabWidth, 20); displayWidth(narrow[0]) > 10 {
+		t.Fatalf("pane narrower than the cap wrapped at %q", narrow[0])
+	}
+}
+
+func TestBuildWrappedLinesExpandsTabs(t *testing.T) {
+	lines := buildWrappedLines([]string{"func f() {\n\treturn 1\n}", "a\tb\tc"}, 40, defaultTabWidth, 0)
+	want := []string{"func f() {", "    return 1", "}", "a   b   c"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("wrapped = %q, want %q", lines, want)
+	}
+
+	narrow := buildWrappedLines([]string{"\t\tdeep"}, 6, 2, 0)
+	if want := []string{"    de", "ep"}; !reflect.DeepEqual(narrow, want) {
+		t.Fatalf("wrapped = %q, want %q", narrow, want)
+	}
+
+	items := buildSessionItems(codexhistory.Project{Sessions: []codexhistory.Session{{SessionID: "s", Summary: "col1\tcol2"}}}, nil, "")
+	if strings.Contains(items[1].label, "\t") || !strings.Contains(items[1].label, "col1 col2") {
+		t.Fatalf("session label = %q", items[1].label)
+	}
+}
+
+func TestHandleKeyReloadsSelectedSession(t *testing.T) {
+	screen := newTestScreen(t, 120, 40)
+	sessionID := "11111111-1111-1111-1111-111111111111"
+	project := codexhistory.Project{
+		Key:      "one",
+		Path:     "/tmp/one",
+		Sessions: []codexhistory.Session{{SessionID: sessionID, FilePath: "/tmp/one.jsonl", MessageCount: 2}},
+	}
+	state := newTestState([]codexhistory.Project{project})
+	state.focus = "sessions"
+	state.lastListFocus = "sessions"
+	state.sessionState.selected = 1
+	state.previewCache["session:"+sessionID] = previewCacheEntry{}
+
+	prev := reloadSessionMeta
+	t.Cleanup(func() { reloadSessionMeta = prev })
+	reloadSessionMeta = func(_ context.Context, s codexhistory.Session) (codexhistory.Session, error) {
+		s.MessageCount = 5
+		s.LastAssistantSnippet = "done"
+		return s, nil
+	}
+	loads := 0
+	opts := Options{LoadProjects: func(context.Context) ([]codexhistory.Project, error) {
+		loads++
+		return nil, nil
+	}}
+
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'i', 0)); err != nil {
+		t.Fatalf("handleKey error: %v", err)
+	}
+	got := state.projects[0].Sessions[0]
+	if got.MessageCount != 5 || got.LastAssistantSnippet != "done" {
+		t.Fatalf("session was not updated in place: %#v", got)
+	}
+	if _, ok := state.previewCache["session:"+sessionID]; ok {
+		t.Fatalf("expected the session's preview cache entry to be dropped")
+	}
+	if loads != 0 {
+		t.Fatalf("expected no full reload, got %d", loads)
+	}
+
+	reloadSessionMeta = func(_ context.Context, s codexhistory.Session) (codexhistory.Session, error) {
+		return s, &os.PathError{Op: "stat", Path: s.FilePath, Err: os.ErrNotExist}
+	}
+	if _, err := handleKey(context.Background(), screen, state, opts, tcell.NewEventKey(tcell.KeyRune, 'i', 0)); err != nil {
+		t.Fatalf("handleKey error: %v", err)
+	}
+	if loads != 1 {
+		t.Fatalf("expected a full reload when the file is gone, got %d", loads)
+	}
+	if !strings.Contains(state.flashMessage, "gone") {
+		t.Fatalf("flash = %q", state.flashMessage)
+	}
+}
+
+func TestBodySearchKeyMatchesMessageText(t *testing.T) {
+	dir := t.TempDir()
+	writeRollout := func(name, text string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		line := `{"timestamp":"2026-01-01T00:00:00Z","type":"event_msg","payload":{"type":"agent_message","message":"` + text + `"}}`
+		if err := os.WriteFile(path, []byte(line+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	screen := newTestScreen(t, 120, 20)
+	project := codexhistory.Project{
+		Key:  "one",
+		Path: "/tmp/one",
+		Sessions: []codexhistory.Session{
+			{SessionID: "sess-1", Summary: "fix parser", FilePath: writeRollout("one.jsonl", "the zebra crossing is fixed")},
+			{SessionID: "sess-2", Summary: "zebra docs", FilePath: writeRollout("two.jsonl", "docs updated")},
+			{SessionID: "sess-3", Summary: "tidy imports", FilePath: writeRollout("three.jsonl", "imports sorted")},
+		},
+	}
+	state := newTestState([]codexhistory.Project{project})
+	state.focus = "sessions"
+	state.sessionFilter = "Zebra"
+	ids := func() []string {
+		var out []string
+		for _, it := range filterSessionItems(state, visibleSessionItems(state, state.projects[0])) {
+			if it.kind == sessionItemMain {
+				out = append(out, it.session.SessionID)
+			}
+		}
+		return out
+	}
+	if got := ids(); !slices.Equal(got, []string{"sess-2"}) {
+		t.Fatalf("title-only filter = %v", got)
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyCtrlF, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !state.bodySearch {
+		t.Fatal("Ctrl+F should turn body search on")
+	}
+	if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+		t.Fatalf("draw error: %v", err)
+	}
+	if !state.bodySearchRunning {
+		t.Fatal("draw should start the body search")
+	}
+	if got := ids(); !slices.Equal(got, []string{"sess-2"}) {
+		t.Fatalf("filter before the search finished = %v", got)
+	}
+	select {
+	case res := <-state.bodySearchCh:
+		applyBodySearchResult(state, res)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the body search")
+	}
+	if got := ids(); !slices.Equal(got, []string{"sess-1", "sess-2"}) {
+		t.Fatalf("body search filter = %v", got)
+	}
+	if len(state.bodyMatches) != 3 {
+		t.Fatalf("every session should be cached, got %v", state.bodyMatches)
+	}
+	if err := draw(screen, state, Options{}, make(chan previewEvent, 1)); err != nil {
+		t.Fatalf("draw error: %v", err)
+	}
+	if state.bodySearchRunning {
+		t.Fatal("cached results should not be searched again")
+	}
+
+	if _, err := handleKey(context.Background(), screen, state, Options{}, tcell.NewEventKey(tcell.KeyCtrlF, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(); !slices.Equal(got, []string{"sess-2"}) {
+		t.Fatalf("Ctrl+F again should match titles only, got %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// viewAutoSaveInterval is how often the view is handed to PersistView while
+// the picker stays open, so a crash or a killed terminal loses little.
+const viewAutoSaveInterval = 30 * time.Second
+
+// ViewState is the part of the picker's view that can be saved on the way
+// out and restored on the next start: what is selected, how the lists are
+// filtered and which panes are shown. Selections are kept by project key and
+// session ID rather than by row, so new sessions do not shift them.
+type ViewState struct {
+	Project       string   `json:"project,omitempty"`
+	Session       string   `json:"session,omitempty"`
+	Subagent      string   `json:"subagent,omitempty"`
+	ProjectFilter string   `json:"projectFilter,omitempty"`
+	SessionFilter string   `json:"sessionFilter,omitempty"`
+	Focus         string   `json:"focus,omitempty"`
+	Expanded      []string `json:"expanded,omitempty"`
+	SessionTimes  string   `json:"sessionTimes,omitempty"`
+	HideProjects  bool     `json:"hideProjects,omitempty"`
+	FlatSessions  bool     `json:"flatSessions,omitempty"`
+	Conversation  bool     `json:"conversation,omitempty"`
+	PreviewScroll int      `json:"previewScroll,omitempty"`
+}
+
+func (v ViewState) equal(other ViewState) bool {
+	return v.Project == other.Project &&
+		v.Session == other.Session &&
+		v.Subagent == other.Subagent &&
+		v.ProjectFilter == other.ProjectFilter &&
+		v.SessionFilter == other.SessionFilter &&
+		v.Focus == other.Focus &&
+		slices.Equal(v.Expanded, other.Expanded) &&
+		v.SessionTimes == other.SessionTimes &&
+		v.HideProjects == other.HideProjects &&
+		v.FlatSessions == other.FlatSessions &&
+		v.Conversation == other.Conversation &&
+		v.PreviewScroll == other.PreviewScroll
+}
+
+// captureView records the current view.
+func captureView(state *uiState, opts Options) ViewState {
+	view := ViewState{
+		ProjectFilter: state.projectFilter,
+		SessionFilter: state.sessionFilter,
+		Focus:         state.focus,
+		SessionTimes:  state.sessionTimeMode,
+		HideProjects:  state.hideProjects,
+		FlatSessions:  state.flatSessions,
+		Conversation:  state.previewConversation,
+		PreviewScroll: state.previewState.scroll,
+	}
+	for id, expanded := range state.expandedSessions {
+		if expanded {
+			view.Expanded = append(view.Expanded, id)
+		}
+	}
+	sort.Strings(view.Expanded)
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	if state.projectState.selected < 0 || state.projectState.selected >= len(projects) {
+		return view
+	}
+	project := projects[state.projectState.selected].project
+	view.Project = project.Key
+	sessions := filterSessionItems(state, visibleSessionItems(state, project))
+	if item, ok := selectedSessionItem(sessions, state.sessionState.selected); ok {
+		switch item.kind {
+		case sessionItemMain:
+			view.Session = item.session.SessionID
+		case sessionItemSubagent:
+			view.Session = item.parentSession.SessionID
+			view.Subagent = item.subagent.SessionID
+		}
+	}
+	return view
+}
+
+// applyViewSettings restores the filters and display toggles of view. They
+// do not depend on the history, so this runs before it has loaded. An
+// explicit Options.ProjectFilter wins over the saved one.
+func applyViewSettings(state *uiState, view ViewState) {
+	if state.projectFilter == "" {
+		state.projectFilter = view.ProjectFilter
+	}
+	state.sessionFilter = view.SessionFilter
+	switch view.Focus {
+	case "projects", "sessions", "preview":
+		state.focus = view.Focus
+		if view.Focus != "preview" {
+			state.lastListFocus = view.Focus
+		}
+	}
+	for _, id := range view.Expanded {
+		state.expandedSessions[id] = true
+	}
+	if slices.Contains(sessionTimeModeCycle, view.SessionTimes) {
+		state.sessionTimeMode = view.SessionTimes
+	}
+	state.hideProjects = view.HideProjects
+	state.flatSessions = view.FlatSessions
+	state.previewConversation = view.Conversation
+}
+
+// restoreViewSelection selects the saved project and session once the
+// history has loaded. Anything that no longer exists keeps the default
+// selection.
+func restoreViewSelection(state *uiState, opts Options, view ViewState) {
+	projects := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	projectIdx := slices.IndexFunc(projects, func(item projectItem) bool {
+		return view.Project != "" && item.project.Key == view.Project
+	})
+	if projectIdx < 0 {
+		return
+	}
+	state.projectState = listState{selected: projectIdx}
+	if view.Session == "" {
+		return
+	}
+	sessions := filterSessionItems(state, visibleSessionItems(state, projects[projectIdx].project))
+	sessionIdx := slices.IndexFunc(sessions, func(item sessionItem) bool {
+		switch item.kind {
+		case sessionItemMain:
+			return view.Subagent == "" && item.session.SessionID == view.Session
+		case sessionItemSubagent:
+			return item.parentSession.SessionID == view.Session && item.subagent.SessionID == view.Subagent
+		}
+		return false
+	})
+	if sessionIdx < 0 {
+		return
+	}
+	state.sessionState = listState{selected: sessionIdx}
+	state.previewState.scroll = max(0, view.PreviewScroll)
+}
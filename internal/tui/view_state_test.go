@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/baaaaaaaka/codex-helper/internal/codexhistory"
+)
+
+func viewStateTestProjects() []codexhistory.Project {
+	now := time.Now()
+	return []codexhistory.Project{
+		{Key: "/tmp/one", Path: "/tmp/one", Sessions: []codexhistory.Session{
+			{SessionID: "one-a", FirstPrompt: "alpha", ModifiedAt: now},
+		}},
+		{Key: "/tmp/two", Path: "/tmp/two", Sessions: []codexhistory.Session{
+			{SessionID: "two-a", FirstPrompt: "bravo", ModifiedAt: now},
+			{SessionID: "two-b", FirstPrompt: "charlie", ModifiedAt: now.Add(-time.Minute), Subagents: []codexhistory.SubagentSession{
+				{AgentID: "review", SessionID: "two-b-sub", ModifiedAt: now.Add(-time.Minute)},
+			}},
+		}},
+	}
+}
+
+func TestViewStateRoundTrip(t *testing.T) {
+	state := newTestState(viewStateTestProjects())
+	state.expandedSessions["two-b"] = true
+	state.sessionTimeMode = sessionTimeCreated
+	state.focus = "sessions"
+	state.projectState.selected = 1
+	// (New Agent), bravo, charlie, then charlie's subagent.
+	state.sessionState.selected = 3
+	state.previewState.scroll = 7
+
+	view := captureView(state, Options{})
+	want := ViewState{
+		Project:       "/tmp/two",
+		Session:       "two-b",
+		Subagent:      "two-b-sub",
+		Focus:         "sessions",
+		Expanded:      []string{"two-b"},
+		SessionTimes:  sessionTimeCreated,
+		PreviewScroll: 7,
+	}
+	if !view.equal(want) {
+		t.Fatalf("captured %+v, want %+v", view, want)
+	}
+
+	restored := newTestState(viewStateTestProjects())
+	applyViewSettings(restored, view)
+	restoreViewSelection(restored, Options{}, view)
+	if restored.projectState.selected != 1 || restored.sessionState.selected != 3 || restored.previewState.scroll != 7 {
+		t.Fatalf("restored selection project=%d session=%d scroll=%d", restored.projectState.selected, restored.sessionState.selected, restored.previewState.scroll)
+	}
+	if restored.focus != "sessions" || !restored.expandedSessions["two-b"] || restored.sessionTimeMode != sessionTimeCreated {
+		t.Fatalf("restored settings focus=%q expanded=%v times=%q", restored.focus, restored.expandedSessions, restored.sessionTimeMode)
+	}
+}
+
+func TestViewStateIgnoresWhatNoLongerExists(t *testing.T) {
+	state := newTestState(viewStateTestProjects())
+	view := ViewState{Project: "/tmp/gone", Session: "gone", Focus: "nowhere", SessionTimes: "sideways"}
+	applyViewSettings(state, view)
+	restoreViewSelection(state, Options{}, view)
+	if state.projectState.selected != 0 || state.sessionState.selected != 0 || state.focus != "projects" || state.sessionTimeMode != "" {
+		t.Fatalf("stale view changed state: %+v", state)
+	}
+}
+
+func TestSelectMostRecentSessionSkipsRecentEntry(t *testing.T) {
+	projects := viewStateTestProjects()
+	projects[1].Sessions[1].ModifiedAt = time.Now().Add(time.Hour)
+	state := newTestState(projects)
+	opts := Options{RecentSessions: DefaultRecentSessions}
+	selectMostRecentSession(state, opts)
+
+	items := filterProjects(visibleProjectItems(state, opts), state.projectFilter)
+	project := selectedProject(items, state.projectState.selected)
+	sessions := filterSessions(visibleSessionItems(state, project), state.sessionFilter)
+	item, ok := selectedSessionItem(sessions, state.sessionState.selected)
+	if project.Key != "/tmp/two" || !ok || item.session.SessionID != "two-b" {
+		t.Fatalf("selected %q in %q, want two-b in /tmp/two", item.session.SessionID, project.Key)
+	}
+	if state.focus != "sessions" {
+		t.Fatalf("focus = %q, want sessions", state.focus)
+	}
+}
+
+func TestSelectSessionPersistsViewOnQuit(t *testing.T) {
+	screen, initDone := newSelectSessionTestScreen(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	saved := make(chan ViewState, 4)
+	result := make(chan error, 1)
+	go func() {
+		_, err := SelectSession(ctx, Options{
+			LoadProjects: func(context.Context) ([]codexhistory.Project, error) {
+				return viewStateTestProjects(), nil
+			},
+			InitialView: &ViewState{Project: "/tmp/two", Session: "two-a", Focus: "sessions"},
+			PersistView: func(view ViewState) error {
+				saved <- view
+				return nil
+			},
+		})
+		result <- err
+	}()
+	waitForScreenInit(t, initDone)
+	waitForScreenContains(t, screen, "charlie")
+	screen.PostEvent(tcell.NewEventKey(tcell.KeyDown, 0, 0))
+	screen.PostEvent(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("SelectSession: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for SelectSession")
+	}
+	select {
+	case view := <-saved:
+		if view.Project != "/tmp/two" || view.Session != "two-b" || view.Focus != "sessions" {
+			t.Fatalf("saved view = %+v, want charlie selected in /tmp/two", view)
+		}
+	default:
+		t.Fatal("view not saved on quit")
+	}
+}
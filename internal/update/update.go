@@ -45,6 +45,10 @@ var (
 	}
 )
 
+// checkRetryDelays are the pauses between release lookups in CheckForUpdate;
+// a lookup is attempted len(checkRetryDelays)+1 times.
+var checkRetryDelays = []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond}
+
 type Status struct {
 	Supported        bool
 	Repo             string
@@ -186,7 +190,12 @@ func CheckForUpdate(ctx context.Context, opts CheckOptions) Status {
 	var remoteTag string
 	var err error
 	if opts.IncludePrerelease {
-		releases, err := ListReleases(ctx, ReleaseListOptions{Repo: repo, Timeout: timeout})
+		var releases []GitHubRelease
+		err := retryCheck(ctx, func() error {
+			var lookupErr error
+			releases, lookupErr = ListReleases(ctx, ReleaseListOptions{Repo: repo, Timeout: timeout})
+			return lookupErr
+		})
 		if err != nil {
 			return Status{
 				Supported:        false,
@@ -208,7 +217,11 @@ func CheckForUpdate(ctx context.Context, opts CheckOptions) Status {
 			remote = local
 		}
 	} else {
-		remoteTag, remote, err = fetchLatestRelease(ctx, repo, timeout)
+		err = retryCheck(ctx, func() error {
+			var lookupErr error
+			remoteTag, remote, lookupErr = fetchLatestRelease(ctx, repo, timeout)
+			return lookupErr
+		})
 		if err != nil {
 			return Status{
 				Supported:        false,
@@ -256,6 +269,27 @@ func CheckForUpdate(ctx context.Context, opts CheckOptions) Status {
 	}
 }
 
+// retryCheck runs lookup until it succeeds, retrying after each delay in
+// checkRetryDelays, so one dropped connection or flaky GitHub response does
+// not surface as a failed update check. It gives up early once ctx is done.
+func retryCheck(ctx context.Context, lookup func() error) error {
+	err := lookup()
+	for _, delay := range checkRetryDelays {
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = lookup()
+	}
+	return err
+}
+
 func PerformUpdate(ctx context.Context, opts UpdateOptions) (ApplyResult, error) {
 	repo := ResolveRepo(opts.Repo)
 	version := ResolveVersion(opts.Version)
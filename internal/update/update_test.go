@@ -84,6 +84,81 @@ func TestCheckForUpdateUsesRedirectBeforeAPI(t *testing.T) {
 	}
 }
 
+func TestCheckForUpdateRetriesTransientLookupFailures(t *testing.T) {
+	requireRuntimeAsset(t)
+	prevDelays := checkRetryDelays
+	checkRetryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { checkRetryDelays = prevDelays }()
+
+	tagPath := "/owner/name/releases/tag/v1.4.0"
+	latestCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/repos/"):
+			http.Error(w, "api unavailable", http.StatusServiceUnavailable)
+		case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+			latestCalls++
+			if latestCalls < 3 {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("Location", tagPath)
+			w.WriteHeader(http.StatusFound)
+		case r.URL.Path == tagPath:
+			_, _ = w.Write([]byte("ok"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	restore := overrideGitHubBases(server.URL)
+	defer restore()
+
+	st := CheckForUpdate(context.Background(), CheckOptions{
+		Repo:             "owner/name",
+		InstalledVersion: "1.0.0",
+		Timeout:          time.Second,
+	})
+	if !st.Supported || st.Error != "" {
+		t.Fatalf("expected retries to recover the check, got error=%q", st.Error)
+	}
+	if st.RemoteVersion != "1.4.0" {
+		t.Fatalf("expected remote version 1.4.0, got %s", st.RemoteVersion)
+	}
+	if latestCalls != 3 {
+		t.Fatalf("expected 3 latest-release lookups, got %d", latestCalls)
+	}
+}
+
+func TestCheckForUpdateReportsErrorAfterRetriesFail(t *testing.T) {
+	prevDelays := checkRetryDelays
+	checkRetryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { checkRetryDelays = prevDelays }()
+
+	latestCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/releases/latest") && !strings.Contains(r.URL.Path, "/repos/") {
+			latestCalls++
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	restore := overrideGitHubBases(server.URL)
+	defer restore()
+
+	st := CheckForUpdate(context.Background(), CheckOptions{
+		Repo:             "owner/name",
+		InstalledVersion: "1.0.0",
+		Timeout:          time.Second,
+	})
+	if st.Supported || st.Error == "" {
+		t.Fatalf("expected failed check after retries, got %+v", st)
+	}
+	if latestCalls != len(checkRetryDelays)+1 {
+		t.Fatalf("expected %d lookups, got %d", len(checkRetryDelays)+1, latestCalls)
+	}
+}
+
 func TestCheckForUpdateIncludePrereleaseTreatsStableSameVersionAsNewer(t *testing.T) {
 	requireRuntimeAsset(t)
 	tag := "v1.2.4"